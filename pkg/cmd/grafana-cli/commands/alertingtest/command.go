@@ -0,0 +1,48 @@
+package alertingtest
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+)
+
+var errTestsFailed = errors.New("alert rule tests failed")
+
+// RunTests runs the alert rule unit tests in the test files passed as arguments.
+// It returns an error if a file cannot be read or if any test fails.
+func RunTests(c utils.CommandLine) error {
+	files := c.Args().Slice()
+	if len(files) == 0 {
+		return errors.New("missing test file argument")
+	}
+
+	failed := false
+	for _, file := range files {
+		logger.Infof("Running tests in %s\n", file)
+		results, err := RunTestFile(context.Background(), file)
+		if err != nil {
+			logger.Errorf("%s %s: %s\n", color.RedString("ERROR"), file, err)
+			failed = true
+			continue
+		}
+		for _, result := range results {
+			if len(result.Failures) == 0 {
+				logger.Infof("  %s %s\n", color.GreenString("PASS"), result.Name)
+				continue
+			}
+			failed = true
+			logger.Infof("  %s %s\n", color.RedString("FAIL"), result.Name)
+			for _, f := range result.Failures {
+				logger.Infof("    %s\n", f)
+			}
+		}
+	}
+	if failed {
+		return errTestsFailed
+	}
+	return nil
+}
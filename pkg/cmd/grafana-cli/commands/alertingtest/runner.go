@@ -0,0 +1,360 @@
+package alertingtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultInterval = time.Minute
+	// testDataSourceType is the type of the data sources that return the input series.
+	testDataSourceType = "__alerting_test__"
+	evaluationTimeout  = 30 * time.Second
+)
+
+// epoch is the time of the first value of the input series and of the first evaluation.
+var epoch = time.Unix(0, 0).UTC()
+
+// ruleGroup is a rule group read from a rule file.
+type ruleGroup struct {
+	folderTitle string
+	interval    time.Duration
+	rules       []*ngmodels.AlertRule
+}
+
+// alertSnapshot is an alert of a rule at the time of an assertion.
+type alertSnapshot struct {
+	labels      map[string]string
+	annotations map[string]string
+	state       string
+}
+
+// TestResult is the result of a test.
+type TestResult struct {
+	Name     string
+	Failures []string
+}
+
+// RunTestFile runs the tests in the file and returns their results.
+// It returns an error if the test file or the rule files cannot be read.
+func RunTestFile(ctx context.Context, path string) ([]TestResult, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning because the path is passed by the user of the command.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file TestFile
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse test file %s: %w", path, err)
+	}
+
+	var groups []ruleGroup
+	for _, ruleFile := range file.RuleFiles {
+		if !filepath.IsAbs(ruleFile) {
+			ruleFile = filepath.Join(filepath.Dir(path), ruleFile)
+		}
+		g, err := readRuleFile(ruleFile)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g...)
+	}
+	if file.EvaluationInterval > 0 {
+		for i := range groups {
+			groups[i].interval = time.Duration(file.EvaluationInterval)
+		}
+	}
+
+	results := make([]TestResult, 0, len(file.Tests))
+	for i, test := range file.Tests {
+		name := test.Name
+		if name == "" {
+			name = fmt.Sprintf("test %d", i+1)
+		}
+		failures, err := runTest(ctx, groups, test)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		results = append(results, TestResult{Name: name, Failures: failures})
+	}
+	return results, nil
+}
+
+func readRuleFile(path string) ([]ruleGroup, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning because the path is passed by the user of the command.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fileV1 alerting.AlertingFileV1
+	if err := yaml.Unmarshal(b, &fileV1); err != nil {
+		return nil, fmt.Errorf("failed to parse rule file %s: %w", path, err)
+	}
+	file, err := fileV1.MapToModel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to map rule file %s: %w", path, err)
+	}
+	groups := make([]ruleGroup, 0, len(file.Groups))
+	for _, g := range file.Groups {
+		group := ruleGroup{
+			folderTitle: g.FolderTitle,
+			interval:    time.Duration(g.Interval) * time.Second,
+		}
+		if group.interval <= 0 {
+			return nil, fmt.Errorf("rule group %s in rule file %s must have an interval", g.Title, path)
+		}
+		for i := range g.Rules {
+			rule := g.Rules[i]
+			rule.RuleGroup = g.Title
+			rule.IntervalSeconds = g.Interval
+			group.rules = append(group.rules, &rule)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func runTest(ctx context.Context, groups []ruleGroup, test TestGroup) ([]string, error) {
+	interval := time.Duration(test.Interval)
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	series := make([]inputSeries, 0, len(test.InputSeries))
+	for _, s := range test.InputSeries {
+		values, err := parseSeriesValues(s.Values)
+		if err != nil {
+			return nil, fmt.Errorf("input series %v of query %s: %w", s.Labels, s.RefID, err)
+		}
+		series = append(series, inputSeries{InputSeries: s, values: values})
+	}
+
+	tests := make(map[string][]AlertRuleTest)
+	var maxEvalTime time.Duration
+	for _, t := range test.AlertRuleTests {
+		tests[t.RuleUID] = append(tests[t.RuleUID], t)
+		if d := time.Duration(t.EvalTime); d > maxEvalTime {
+			maxEvalTime = d
+		}
+	}
+	for _, ts := range tests {
+		sort.SliceStable(ts, func(i, j int) bool {
+			return ts[i].EvalTime < ts[j].EvalTime
+		})
+	}
+
+	manager := state.NewManager(state.ManagerCfg{
+		Images: &noopImageService{},
+		Clock:  clock.New(),
+	})
+
+	var failures []string
+	found := make(map[string]struct{}, len(tests))
+	for _, g := range groups {
+		for _, rule := range g.rules {
+			ruleTests, ok := tests[rule.UID]
+			if !ok {
+				continue
+			}
+			found[rule.UID] = struct{}{}
+			if rule.IsRecordingRule() {
+				return nil, fmt.Errorf("rule %s is a recording rule, which does not produce alerts", rule.UID)
+			}
+			evaluator, err := newEvaluator(ctx, rule, series, interval)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create evaluator of rule %s: %w", rule.UID, err)
+			}
+			extraLabels := data.Labels(rule.GetRuleExtraLabels(g.folderTitle, true))
+			// evaluate the rule until the last assertion, and check each assertion after the last evaluation before it
+			next := 0
+			for t := time.Duration(0); t <= maxEvalTime && next < len(ruleTests); t += g.interval {
+				for ; next < len(ruleTests) && time.Duration(ruleTests[next].EvalTime) < t; next++ {
+					failures = append(failures, checkAlerts(manager, rule, ruleTests[next])...)
+				}
+				now := epoch.Add(t)
+				results, err := evaluator.Evaluate(ctx, now)
+				if err != nil {
+					results = eval.Results{eval.NewResultFromError(err, now, 0)}
+				}
+				manager.ProcessEvalResults(ctx, now, rule, results, extraLabels)
+			}
+			for ; next < len(ruleTests); next++ {
+				failures = append(failures, checkAlerts(manager, rule, ruleTests[next])...)
+			}
+		}
+	}
+	for uid := range tests {
+		if _, ok := found[uid]; !ok {
+			return nil, fmt.Errorf("rule %s does not exist in the rule files", uid)
+		}
+	}
+	return failures, nil
+}
+
+// checkAlerts compares the alerts of the rule that are not Normal with the expected alerts. The extra labels that are
+// added to all alerts of the rule, such as alertname, grafana_folder and the private labels, are not compared.
+func checkAlerts(manager *state.Manager, rule *ngmodels.AlertRule, test AlertRuleTest) []string {
+	var actual []alertSnapshot
+	for _, s := range manager.GetStatesForRuleUID(rule.OrgID, rule.UID) {
+		if s.State == eval.Normal {
+			continue
+		}
+		labels := make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			if k == prometheusModel.AlertNameLabel || k == ngmodels.FolderTitleLabel || isPrivateLabel(k) {
+				continue
+			}
+			labels[k] = v
+		}
+		actual = append(actual, alertSnapshot{labels: labels, annotations: s.Annotations, state: s.State.String()})
+	}
+
+	var failures []string
+	prefix := fmt.Sprintf("rule %s at %s", rule.UID, test.EvalTime)
+	matched := make([]bool, len(actual))
+	for _, exp := range test.ExpAlerts {
+		found := false
+		for i, a := range actual {
+			if matched[i] || !labelsEqual(exp.Labels, a.labels) {
+				continue
+			}
+			matched[i] = true
+			found = true
+			if !strings.EqualFold(exp.State, a.state) {
+				failures = append(failures, fmt.Sprintf("%s: alert %v: expected state %s, got %s", prefix, a.labels, exp.State, a.state))
+			}
+			if exp.Annotations != nil && !labelsEqual(exp.Annotations, a.annotations) {
+				failures = append(failures, fmt.Sprintf("%s: alert %v: expected annotations %v, got %v", prefix, a.labels, exp.Annotations, a.annotations))
+			}
+			break
+		}
+		if !found {
+			failures = append(failures, fmt.Sprintf("%s: expected alert %v with state %s, got none", prefix, exp.Labels, exp.State))
+		}
+	}
+	for i, a := range actual {
+		if !matched[i] {
+			failures = append(failures, fmt.Sprintf("%s: unexpected alert %v with state %s", prefix, a.labels, a.state))
+		}
+	}
+	return failures
+}
+
+// isPrivateLabel returns true if the label is an internal label of Grafana, such as the UID of the rule.
+func isPrivateLabel(name string) bool {
+	return strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__")
+}
+
+func labelsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// newEvaluator creates an evaluator for the rule, where all queries to data sources return the input series.
+func newEvaluator(ctx context.Context, rule *ngmodels.AlertRule, series []inputSeries, interval time.Duration) (eval.ConditionEvaluator, error) {
+	handler := &inputSeriesHandler{interval: interval}
+	for _, s := range series {
+		if s.RuleUID == "" || s.RuleUID == rule.UID {
+			handler.series = append(handler.series, s)
+		}
+	}
+	factory := eval.NewEvaluatorFactory(
+		setting.UnifiedAlertingSettings{EvaluationTimeout: evaluationTimeout},
+		testDataSourceCache{},
+		expr.NewQueryDataService(handler),
+		nil,
+	)
+	evalCtx := eval.Context(ctx, &user.SignedInUser{OrgID: rule.OrgID})
+	return factory.Create(evalCtx, rule.GetEvalCondition())
+}
+
+type inputSeries struct {
+	InputSeries
+	values []*float64
+}
+
+// inputSeriesHandler responds to the query of each refID with the values of its input series that are in the time range of the query.
+type inputSeriesHandler struct {
+	interval time.Duration
+	series   []inputSeries
+}
+
+func (h *inputSeriesHandler) QueryData(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		frames := data.Frames{}
+		for _, s := range h.series {
+			if s.RefID != q.RefID {
+				continue
+			}
+			times := make([]time.Time, 0)
+			values := make([]*float64, 0)
+			for i, v := range s.values {
+				t := epoch.Add(time.Duration(i) * h.interval)
+				if t.Before(q.TimeRange.From) || t.After(q.TimeRange.To) {
+					continue
+				}
+				times = append(times, t)
+				values = append(values, v)
+			}
+			frames = append(frames, data.NewFrame("",
+				data.NewField("time", nil, times),
+				data.NewField("value", s.Labels, values),
+			))
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: frames}
+	}
+	return resp, nil
+}
+
+// testDataSourceCache returns a data source for any UID, so that queries are sent to the inputSeriesHandler.
+type testDataSourceCache struct{}
+
+func (testDataSourceCache) GetDatasource(_ context.Context, _ int64, _ *user.SignedInUser, _ bool) (*datasources.DataSource, error) {
+	return nil, datasources.ErrDataSourceNotFound
+}
+
+func (testDataSourceCache) GetDatasourceByUID(_ context.Context, uid string, _ *user.SignedInUser, _ bool) (*datasources.DataSource, error) {
+	return &datasources.DataSource{
+		UID:            uid,
+		Name:           uid,
+		Type:           testDataSourceType,
+		JsonData:       simplejson.New(),
+		SecureJsonData: make(map[string][]byte),
+	}, nil
+}
+
+type noopImageService struct{}
+
+func (s *noopImageService) NewImage(_ context.Context, _ *ngmodels.AlertRule) (*ngmodels.Image, error) {
+	return &ngmodels.Image{}, nil
+}
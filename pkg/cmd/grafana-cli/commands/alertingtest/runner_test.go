@@ -0,0 +1,61 @@
+package alertingtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRunTestFile(t *testing.T) {
+	results, err := RunTestFile(context.Background(), "testdata/tests.yaml")
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	require.Equal(t, "pending and firing", results[0].Name)
+	require.Empty(t, results[0].Failures)
+
+	require.Equal(t, "wrong expectation", results[1].Name)
+	require.Equal(t, []string{
+		"rule high-cpu at 2m: expected alert map[instance:server-2 severity:critical] with state Alerting, got none",
+		"rule high-cpu at 2m: unexpected alert map[instance:server-1 severity:critical] with state Alerting",
+	}, results[1].Failures)
+}
+
+func TestRunTestNotificationSettings(t *testing.T) {
+	groups, err := readRuleFile("testdata/rules.yaml")
+	require.NoError(t, err)
+	groups[0].rules[0].NotificationSettings = &ngmodels.NotificationSettings{
+		Receiver: "team-a",
+		GroupBy:  []string{"instance"},
+	}
+
+	// the labels of the notification settings must not be compared with the expected labels
+	failures, err := runTest(context.Background(), groups, TestGroup{
+		InputSeries: []InputSeries{
+			{RefID: "A", Labels: map[string]string{"instance": "server-1"}, Values: "90x3"},
+		},
+		AlertRuleTests: []AlertRuleTest{
+			{
+				EvalTime: model.Duration(time.Minute),
+				RuleUID:  "high-cpu",
+				ExpAlerts: []ExpAlert{
+					{Labels: map[string]string{"instance": "server-1", "severity": "critical"}, State: "Pending"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Empty(t, failures)
+}
+
+func TestRunTestFileErrors(t *testing.T) {
+	t.Run("fails if test file does not exist", func(t *testing.T) {
+		_, err := RunTestFile(context.Background(), "testdata/missing.yaml")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+package alertingtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseSeriesValues parses values in expanding notation. Values are separated by whitespace, where
+//   - "a" is the value a, and "_" is a missing value.
+//   - "a+bxn" are the n+1 values a, a+b, ..., a+n*b, and "a-bxn" are the n+1 values a, a-b, ..., a-n*b.
+//   - "axn" are the n+1 values a, and "_xn" are n missing values.
+func parseSeriesValues(input string) ([]*float64, error) {
+	var values []*float64
+	for _, token := range strings.Fields(input) {
+		expanded, err := expandValue(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: %w", token, err)
+		}
+		values = append(values, expanded...)
+	}
+	return values, nil
+}
+
+func expandValue(token string) ([]*float64, error) {
+	idx := strings.LastIndex(token, "x")
+	if idx < 0 {
+		if token == "_" {
+			return []*float64{nil}, nil
+		}
+		f, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, err
+		}
+		return []*float64{&f}, nil
+	}
+
+	n, err := strconv.Atoi(token[idx+1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid number of repetitions: %w", err)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("number of repetitions must not be negative")
+	}
+	expr := token[:idx]
+	if expr == "_" {
+		return make([]*float64, n), nil
+	}
+
+	start, step := expr, "0"
+	// the operator is the first sign that is not at the start of the number or part of an exponent
+	for i := 1; i < len(expr); i++ {
+		if (expr[i] == '+' || expr[i] == '-') && expr[i-1] != 'e' && expr[i-1] != 'E' {
+			start, step = expr[:i], expr[i:]
+			break
+		}
+	}
+	a, err := strconv.ParseFloat(start, 64)
+	if err != nil {
+		return nil, err
+	}
+	b, err := strconv.ParseFloat(step, 64)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*float64, 0, n+1)
+	for i := 0; i <= n; i++ {
+		f := a + float64(i)*b
+		values = append(values, &f)
+	}
+	return values, nil
+}
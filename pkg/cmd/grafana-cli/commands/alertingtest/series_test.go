@@ -0,0 +1,43 @@
+package alertingtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeriesValues(t *testing.T) {
+	f := func(v float64) *float64 {
+		return &v
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []*float64
+		err      bool
+	}{
+		{name: "single values", input: "1 -2.5 1e3", expected: []*float64{f(1), f(-2.5), f(1000)}},
+		{name: "missing values", input: "1 _ 2 _x2", expected: []*float64{f(1), nil, f(2), nil, nil}},
+		{name: "increasing values", input: "1+2x3", expected: []*float64{f(1), f(3), f(5), f(7)}},
+		{name: "decreasing values", input: "-1-1x2", expected: []*float64{f(-1), f(-2), f(-3)}},
+		{name: "repeated values", input: "5x2", expected: []*float64{f(5), f(5), f(5)}},
+		{name: "exponent", input: "1e+2+1x1", expected: []*float64{f(100), f(101)}},
+		{name: "empty", input: "", expected: nil},
+		{name: "invalid value", input: "a", err: true},
+		{name: "invalid repetitions", input: "1x", err: true},
+		{name: "invalid step", input: "1+ax2", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := parseSeriesValues(tc.input)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, values)
+		})
+	}
+}
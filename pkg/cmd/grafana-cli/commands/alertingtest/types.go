@@ -0,0 +1,49 @@
+package alertingtest
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// TestFile is a file with unit tests for alert rules.
+type TestFile struct {
+	// RuleFiles are the alerting provisioning files with the rules under test. Paths are relative to the test file.
+	RuleFiles []string `yaml:"ruleFiles"`
+	// EvaluationInterval overrides the interval of the rule groups.
+	EvaluationInterval model.Duration `yaml:"evaluationInterval"`
+	Tests              []TestGroup    `yaml:"tests"`
+}
+
+// TestGroup is a single unit test, with input series and the expected alerts at given evaluation times.
+type TestGroup struct {
+	Name string `yaml:"name"`
+	// Interval is the time between the values of the input series. It defaults to one minute.
+	Interval       model.Duration  `yaml:"interval"`
+	InputSeries    []InputSeries   `yaml:"inputSeries"`
+	AlertRuleTests []AlertRuleTest `yaml:"alertRuleTests"`
+}
+
+// InputSeries is a series that is returned for the query with RefID.
+type InputSeries struct {
+	RefID string `yaml:"refId"`
+	// RuleUID limits the series to the queries of a single rule. If empty, the series is used by all rules.
+	RuleUID string            `yaml:"ruleUid"`
+	Labels  map[string]string `yaml:"labels"`
+	// Values are the values of the series in expanding notation, for example "1 2 _ 3+1x2 5x3".
+	Values string `yaml:"values"`
+}
+
+// AlertRuleTest describes the alerts of a rule expected after the last evaluation at or before EvalTime.
+type AlertRuleTest struct {
+	EvalTime model.Duration `yaml:"evalTime"`
+	RuleUID  string         `yaml:"ruleUid"`
+	// ExpAlerts are all alerts of the rule that are not Normal.
+	ExpAlerts []ExpAlert `yaml:"expAlerts"`
+}
+
+// ExpAlert is an expected alert.
+type ExpAlert struct {
+	Labels map[string]string `yaml:"labels"`
+	// Annotations are only compared if they are set.
+	Annotations map[string]string `yaml:"annotations"`
+	State       string            `yaml:"state"`
+}
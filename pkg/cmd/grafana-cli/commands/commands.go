@@ -7,6 +7,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/alertingtest"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
@@ -255,6 +256,39 @@ grafana-cli user-manager conflicts ingest-file <filepath>
 	},
 }
 
+var alertingCommands = []*cli.Command{
+	{
+		Name:      "test",
+		Usage:     "runs the alert rule unit tests in the test files",
+		ArgsUsage: "<test file>...",
+		CustomHelpTemplate: `
+This command evaluates alert rules in the alerting provisioning format against input series,
+and compares the alerts with the expected alerts at given evaluation times.
+
+Example test file:
+
+ruleFiles:
+  - rules.yaml
+tests:
+  - name: high cpu usage
+    interval: 1m
+    inputSeries:
+      - refId: A
+        labels:
+          instance: server-1
+        values: "10 20 90x5"
+    alertRuleTests:
+      - evalTime: 4m
+        ruleUid: high-cpu
+        expAlerts:
+          - labels:
+              instance: server-1
+            state: Alerting
+`,
+		Action: runPluginCommand(alertingtest.RunTests),
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -266,4 +300,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "alerting",
+		Usage:       "Grafana alerting commands",
+		Subcommands: alertingCommands,
+	},
 }
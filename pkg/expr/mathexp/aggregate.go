@@ -0,0 +1,181 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// aggregateFunc aggregates the non-null values of a group. It is never called with an empty slice.
+type aggregateFunc func(fs []float64) float64
+
+var aggregateFuncs = map[string]aggregateFunc{
+	"sum": func(fs []float64) float64 {
+		var sum float64
+		for _, f := range fs {
+			sum += f
+		}
+		return sum
+	},
+	"avg": func(fs []float64) float64 {
+		var sum float64
+		for _, f := range fs {
+			sum += f
+		}
+		return sum / float64(len(fs))
+	},
+	"min": func(fs []float64) float64 {
+		m := fs[0]
+		for _, f := range fs[1:] {
+			m = math.Min(m, f)
+		}
+		return m
+	},
+	"max": func(fs []float64) float64 {
+		m := fs[0]
+		for _, f := range fs[1:] {
+			m = math.Max(m, f)
+		}
+		return m
+	},
+	"count": func(fs []float64) float64 {
+		return float64(len(fs))
+	},
+}
+
+// aggregateGroup is the set of values whose labels are equal after applying the by or without clause.
+type aggregateGroup struct {
+	labels data.Labels
+	values []Value
+}
+
+// walkAggregate groups the numbers or series of the argument by their labels and aggregates
+// each group into a single number or series. Null values are ignored; if all values are null the result is null.
+// Series are aggregated at each timestamp of the series in the group.
+func (e *State) walkAggregate(node *parse.AggregateNode) (Results, error) {
+	res, err := e.walk(node.Arg)
+	if err != nil {
+		return Results{}, err
+	}
+	aggF, ok := aggregateFuncs[node.Op]
+	if !ok {
+		return Results{}, fmt.Errorf("unsupported aggregation operator %s", node.Op)
+	}
+
+	var groups []*aggregateGroup
+	byKey := map[string]*aggregateGroup{}
+	var valType parse.ReturnType
+	for _, val := range res.Values {
+		switch val.(type) {
+		case Number, Series:
+		case NoData:
+			continue
+		default:
+			return Results{}, fmt.Errorf("can not aggregate type %v, expected %v or %v", val.Type(), parse.TypeNumberSet, parse.TypeSeriesSet)
+		}
+		if len(groups) == 0 {
+			valType = val.Type()
+		} else if val.Type() != valType {
+			return Results{}, fmt.Errorf("can not aggregate mixed types %v and %v", valType, val.Type())
+		}
+		labels := groupLabels(val.GetLabels(), node.Grouping, node.Without)
+		key := labels.String()
+		g, ok := byKey[key]
+		if !ok {
+			g = &aggregateGroup{labels: labels}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.values = append(g.values, val)
+	}
+
+	if len(groups) == 0 {
+		return Results{Values: Values{NewNoData()}}, nil
+	}
+
+	newRes := Results{}
+	for _, g := range groups {
+		if valType == parse.TypeNumberSet {
+			newRes.Values = append(newRes.Values, e.aggregateNumbers(g, aggF))
+		} else {
+			newRes.Values = append(newRes.Values, e.aggregateSeries(g, aggF))
+		}
+	}
+	return newRes, nil
+}
+
+func (e *State) aggregateNumbers(g *aggregateGroup, aggF aggregateFunc) Number {
+	fs := make([]float64, 0, len(g.values))
+	for _, val := range g.values {
+		if f := val.(Number).GetFloat64Value(); f != nil {
+			fs = append(fs, *f)
+		}
+	}
+	n := NewNumber(e.RefID, g.labels)
+	if len(fs) > 0 {
+		f := aggF(fs)
+		n.SetValue(&f)
+	}
+	return n
+}
+
+func (e *State) aggregateSeries(g *aggregateGroup, aggF aggregateFunc) Series {
+	var times []time.Time
+	byTime := map[time.Time][]float64{}
+	for _, val := range g.values {
+		s := val.(Series)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			fs, ok := byTime[t]
+			if !ok {
+				times = append(times, t)
+			}
+			if f != nil {
+				fs = append(fs, *f)
+			}
+			byTime[t] = fs
+		}
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	newSeries := NewSeries(e.RefID, g.labels, len(times))
+	for i, t := range times {
+		var f *float64
+		if fs := byTime[t]; len(fs) > 0 {
+			v := aggF(fs)
+			f = &v
+		}
+		newSeries.SetPoint(i, t, f)
+	}
+	return newSeries
+}
+
+// groupLabels returns the labels that identify the group of a value. If without is false
+// these are the labels in grouping, otherwise these are all labels except the labels in grouping.
+func groupLabels(labels data.Labels, grouping []string, without bool) data.Labels {
+	newLabels := data.Labels{}
+	if without {
+		for k, v := range labels {
+			newLabels[k] = v
+		}
+		for _, k := range grouping {
+			delete(newLabels, k)
+		}
+	} else {
+		for _, k := range grouping {
+			if v, ok := labels[k]; ok {
+				newLabels[k] = v
+			}
+		}
+	}
+	if len(newLabels) == 0 {
+		return nil
+	}
+	return newLabels
+}
@@ -0,0 +1,152 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	numbers := Vars{
+		"A": Results{
+			[]Value{
+				makeNumber("", data.Labels{"service": "api", "instance": "a"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"service": "api", "instance": "b"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"service": "db", "instance": "c"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"service": "db", "instance": "d"}, nil),
+			},
+		},
+	}
+	series := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("", data.Labels{"service": "api", "instance": "a"},
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(2)}),
+				makeSeries("", data.Labels{"service": "api", "instance": "b"},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(15, 0), nil}),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "sum by on numbers",
+			expr:      "sum by(service)($A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api"}, float64Pointer(5)),
+				makeNumber("", data.Labels{"service": "db"}, float64Pointer(2)),
+			}},
+		},
+		{
+			name:      "max without on numbers",
+			expr:      "max without(instance)($A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"service": "db"}, float64Pointer(2)),
+			}},
+		},
+		{
+			name:      "count without grouping on numbers",
+			expr:      "count($A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", nil, float64Pointer(3)),
+			}},
+		},
+		{
+			name:      "avg by a missing label on numbers",
+			expr:      `avg by("cluster")($A)`,
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", nil, float64Pointer(7.0/3)),
+			}},
+		},
+		{
+			name:      "sum by on series",
+			expr:      "sum by(service)($A)",
+			vars:      series,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", data.Labels{"service": "api"},
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(5)},
+					tp{time.Unix(15, 0), nil}),
+			}},
+		},
+		{
+			name:      "aggregation in a binary operation",
+			expr:      "sum by(service)($A) / count by(service)($A)",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api"}, float64Pointer(2.5)),
+				makeNumber("", data.Labels{"service": "db"}, float64Pointer(2)),
+			}},
+		},
+		{
+			name:      "aggregation as a function argument",
+			expr:      "abs(min without(instance)(-$A))",
+			vars:      numbers,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"service": "db"}, float64Pointer(2)),
+			}},
+		},
+		{
+			name:      "aggregation of no data",
+			expr:      "sum by(service)($A)",
+			vars:      Vars{"A": Results{[]Value{NewNoData()}}},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{NewNoData()}},
+		},
+		{
+			name:     "aggregation of a scalar - should error",
+			expr:     "sum(1)",
+			vars:     Vars{},
+			newErrIs: require.Error,
+		},
+		{
+			name:     "aggregation with an unterminated grouping - should error",
+			expr:     "sum by(service,)($A)",
+			vars:     numbers,
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
@@ -89,6 +89,8 @@ func (e *State) walk(node parse.Node) (res Results, err error) {
 		res, err = e.walkUnary(node)
 	case *parse.FuncNode:
 		res, err = e.walkFunc(node)
+	case *parse.AggregateNode:
+		res, err = e.walkAggregate(node)
 	default:
 		return res, fmt.Errorf("expr: can not walk node type: %s", node.Type())
 	}
@@ -521,6 +523,8 @@ func (e *State) walkFunc(node *parse.FuncNode) (Results, error) {
 			v, err = e.walkUnary(t)
 		case *parse.BinaryNode:
 			v, err = e.walkBinary(t)
+		case *parse.AggregateNode:
+			v, err = e.walkAggregate(t)
 		default:
 			return res, fmt.Errorf("expr: unknown func arg type: %T", t)
 		}
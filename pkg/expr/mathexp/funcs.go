@@ -1,8 +1,11 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
 
@@ -68,6 +71,76 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"reduce": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeNumberSet,
+		F:      reduce,
+		Check:  checkReducer,
+	},
+	"reduce_drop_nn": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeNumberSet,
+		F:      reduceDropNN,
+		Check:  checkReducer,
+	},
+	"reduce_replace_nn": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString, parse.TypeScalar},
+		Return: parse.TypeNumberSet,
+		F:      reduceReplaceNN,
+		Check:  checkReducer,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkWindow,
+	},
+	"moving_sum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingSum,
+		Check:  checkWindow,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+		Check:  checkWindow,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+		Check:  checkWindow,
+	},
+	"time_shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+		Check:  checkDuration,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+}
+
+// checkReducer validates at parse time that the second argument of a reduce function is a supported reducer.
+func checkReducer(t *parse.Tree, f *parse.FuncNode) error {
+	reducer, ok := f.Args[1].(*parse.StringNode)
+	if !ok {
+		return fmt.Errorf("parse: expected the reducer of %s to be a string, got %v", f.Name, f.Args[1])
+	}
+	if _, err := GetSeriesReduceFunc(reducer.Text); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+	return nil
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +380,49 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// reduce turns each series into a number using the given reducer, for example `reduce($A, "p90")`.
+func reduce(e *State, varSet Results, rFunc string) (Results, error) {
+	return reduceWithMapper(e, varSet, rFunc, nil)
+}
+
+// reduceDropNN is like reduce but drops null, NaN and +/-Inf values of the series before reducing it.
+func reduceDropNN(e *State, varSet Results, rFunc string) (Results, error) {
+	return reduceWithMapper(e, varSet, rFunc, DropNonNumber{})
+}
+
+// reduceReplaceNN is like reduce but replaces null, NaN and +/-Inf values of the series with the given value before reducing it.
+func reduceReplaceNN(e *State, varSet Results, rFunc string, value Results) (Results, error) {
+	f, err := scalarArg(value)
+	if err != nil {
+		return Results{}, err
+	}
+	return reduceWithMapper(e, varSet, rFunc, ReplaceNonNumberWithValue{Value: f})
+}
+
+func reduceWithMapper(e *State, varSet Results, rFunc string, mapper ReduceMapper) (Results, error) {
+	newRes := Results{}
+	for _, val := range varSet.Values {
+		switch v := val.(type) {
+		case Series:
+			num, err := v.Reduce(e.RefID, rFunc, mapper)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, num)
+		case Number: // the value is already reduced, so the reduction is a noop.
+			copyV := NewNumber(e.RefID, v.GetLabels())
+			copyV.SetValue(v.GetFloat64Value())
+			copyV.AddNotice(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     "Reduce operation is not needed. The input is already reduced data.",
+			})
+			newRes.Values = append(newRes.Values, copyV)
+		case NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
@@ -159,3 +159,75 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestReduceFunc(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		resultIs  require.ComparisonAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "reduce series",
+			expr:      `reduce($A, "median")`,
+			vars:      counterSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			resultIs:  require.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(5))}},
+		},
+		{
+			name:      "reduce series in a binary operation",
+			expr:      `reduce($A, "last") - reduce($A, "first")`,
+			vars:      counterSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			resultIs:  require.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(5))}},
+		},
+		{
+			name:      "reduce_drop_nn series with a nil value",
+			expr:      `reduce_drop_nn($A, "max")`,
+			vars:      seriesWithNil,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			resultIs:  require.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(2))}},
+		},
+		{
+			name:      "reduce_replace_nn series with a nil value",
+			expr:      `reduce_replace_nn($A, "sum", 5)`,
+			vars:      seriesWithNil,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			resultIs:  require.Equal,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(7))}},
+		},
+		{
+			name:     "reduce with unknown reducer - should error",
+			expr:     `reduce($A, "foo")`,
+			vars:     counterSeries,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "reduce on scalar - should error",
+			expr:     `reduce(1, "sum")`,
+			vars:     Vars{},
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				tt.execErrIs(t, err)
+				tt.resultIs(t, tt.results, res)
+			}
+		})
+	}
+}
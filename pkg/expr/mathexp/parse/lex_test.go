@@ -113,6 +113,17 @@ var lexTests = []lexTest{
 		{itemVar, 0, "$A"},
 		tEOF,
 	}},
+	{"aggregation by label with digits", "sum by(k8s_pod)($A)", []item{
+		{itemFunc, 0, "sum"},
+		{itemFunc, 0, "by"},
+		{itemLeftParen, 0, "("},
+		{itemFunc, 0, "k8s_pod"},
+		{itemRightParen, 0, ")"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
 	// errors
 	{"unclosed quote", "\"", []item{
 		{itemError, 0, "unterminated string"},
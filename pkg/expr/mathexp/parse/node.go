@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -62,6 +63,8 @@ const (
 	NodeNumber
 	// NodeVar is variable: $A
 	NodeVar
+	// NodeAggregate is an aggregation across series or numbers: sum by(service)($A)
+	NodeAggregate
 )
 
 // String returns the string representation of the NodeType
@@ -77,6 +80,8 @@ func (t NodeType) String() string {
 		return "NodeString"
 	case NodeNumber:
 		return "NodeNumber"
+	case NodeAggregate:
+		return "NodeAggregate"
 	default:
 		return "NodeUnknown"
 	}
@@ -367,6 +372,65 @@ func (u *UnaryNode) Return() ReturnType {
 	return u.Arg.Return()
 }
 
+// AggregateNode holds an aggregation operator, the labels to group by, and its argument.
+type AggregateNode struct {
+	NodeType
+	Pos
+	Op       string   // One of the AggregateOps
+	Grouping []string // The labels in the by or without clause
+	Without  bool     // If true, the result is grouped by all labels except Grouping
+	Arg      Node
+}
+
+// AggregateOps are the supported aggregation operators.
+var AggregateOps = map[string]struct{}{
+	"sum":   {},
+	"avg":   {},
+	"min":   {},
+	"max":   {},
+	"count": {},
+}
+
+func newAggregate(pos Pos, op string) *AggregateNode {
+	return &AggregateNode{NodeType: NodeAggregate, Pos: pos, Op: op}
+}
+
+// String returns the string representation of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) String() string {
+	return fmt.Sprintf("%s%s(%s)", a.Op, a.groupingString(), a.Arg)
+}
+
+// StringAST returns the string representation of abstract syntax tree of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) StringAST() string {
+	return fmt.Sprintf("%s%s(%s)", a.Op, a.groupingString(), a.Arg.StringAST())
+}
+
+func (a *AggregateNode) groupingString() string {
+	if len(a.Grouping) == 0 && !a.Without {
+		return ""
+	}
+	clause := "by"
+	if a.Without {
+		clause = "without"
+	}
+	return fmt.Sprintf(" %s(%s)", clause, strings.Join(a.Grouping, ", "))
+}
+
+// Check performs parse time checking on the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Check(t *Tree) error {
+	switch rt := a.Arg.Return(); rt {
+	case TypeNumberSet, TypeSeriesSet:
+		return a.Arg.Check(t)
+	default:
+		return fmt.Errorf("parse: type error in %s, expected %v or %v, got %v", a, TypeNumberSet, TypeSeriesSet, rt)
+	}
+}
+
+// Return returns the result type of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Return() ReturnType {
+	return a.Arg.Return()
+}
+
 // Walk invokes f on n and sub-nodes of n.
 func Walk(n Node, f func(Node)) {
 	f(n)
@@ -382,6 +446,8 @@ func Walk(n Node, f func(Node)) {
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
+	case *AggregateNode:
+		Walk(n.Arg, f)
 	default:
 		panic(fmt.Errorf("other type: %T", n))
 	}
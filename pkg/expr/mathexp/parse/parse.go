@@ -172,9 +172,10 @@ P -> M {( "+" | "-" ) M}
 M -> E {( "*" | "/" ) F}
 E -> F {( "**" ) F}
 F -> v | "(" O ")" | "!" O | "-" O
-v -> number | func(..) | queryVar
+v -> number | func(..) | agg(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+Agg -> op [("by" | "without") "(" [label {"," label}] ")"] "(" O ")"
 */
 
 // expr:
@@ -286,6 +287,9 @@ func (t *Tree) v() Node {
 		return n
 	case itemFunc:
 		t.backup()
+		if _, ok := AggregateOps[token.val]; ok {
+			return t.Aggregate()
+		}
 		return t.Func()
 	case itemVar:
 		t.backup()
@@ -316,6 +320,10 @@ func (t *Tree) Func() (f *FuncNode) {
 	t.expect(itemLeftParen, "func")
 	for {
 		switch token = t.next(); token.typ {
+		case itemComma:
+			if len(f.Args) == 0 {
+				t.unexpected(token, "func")
+			}
 		default:
 			t.backup()
 			node := t.O()
@@ -335,6 +343,60 @@ func (t *Tree) Func() (f *FuncNode) {
 	}
 }
 
+// Aggregate parses an AggregateNode.
+func (t *Tree) Aggregate() (a *AggregateNode) {
+	token := t.next()
+	a = newAggregate(token.pos, token.val)
+	if token = t.peek(); token.typ == itemFunc && (token.val == "by" || token.val == "without") {
+		t.next()
+		a.Without = token.val == "without"
+		a.Grouping = t.grouping()
+	}
+	t.expect(itemLeftParen, "aggregation")
+	a.Arg = t.O()
+	t.expect(itemRightParen, "aggregation")
+	return a
+}
+
+// grouping parses the list of labels of a by or without clause.
+func (t *Tree) grouping() []string {
+	labels := []string{}
+	t.expect(itemLeftParen, "grouping")
+	expectLabel := true
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			if !expectLabel {
+				t.unexpected(token, "grouping")
+			}
+			labels = append(labels, token.val)
+			expectLabel = false
+		case itemString:
+			if !expectLabel {
+				t.unexpected(token, "grouping")
+			}
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+			expectLabel = false
+		case itemComma:
+			if expectLabel {
+				t.unexpected(token, "grouping")
+			}
+			expectLabel = true
+		case itemRightParen:
+			if expectLabel && len(labels) > 0 {
+				t.unexpected(token, "grouping")
+			}
+			return labels
+		default:
+			t.unexpected(token, "grouping")
+		}
+	}
+}
+
 // GetFunction gets a parsed Func from the functions available on the tree's func property.
 func (t *Tree) GetFunction(name string) (v Func, ok bool) {
 	for _, funcMap := range t.funcs {
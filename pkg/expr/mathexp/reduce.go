@@ -3,6 +3,8 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -10,6 +12,9 @@ import (
 
 type ReducerFunc = func(fv *Float64Field) *float64
 
+// SeriesReducerFunc is a reduction function that needs the time of each point in addition to its value.
+type SeriesReducerFunc = func(s Series) *float64
+
 func Sum(fv *Float64Field) *float64 {
 	var sum float64
 	for i := 0; i < fv.Len(); i++ {
@@ -81,8 +86,130 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	first, last := First(fv), Last(fv)
+	if first == nil || last == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *last - *first
+	return &f
+}
+
+// Variance returns the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	mean := Avg(fv)
+	if math.IsNaN(*mean) {
+		return mean
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *mean
+		sum += d * d
+	}
+	f := sum / float64(fv.Len())
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a reducer that computes the p-th percentile of the values, where p is in the range [0, 100].
+// The percentile is linearly interpolated between the two closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		nan := math.NaN()
+		if fv.Len() == 0 {
+			return &nan
+		}
+		vals := make([]float64, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			v := fv.GetValue(i)
+			if v == nil || math.IsNaN(*v) {
+				return &nan
+			}
+			vals = append(vals, *v)
+		}
+		sort.Float64s(vals)
+		rank := p / 100 * float64(len(vals)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// Increase returns the increase of a counter over the series. A value lower than its predecessor is
+// considered a counter reset, in which case the counter is assumed to have restarted from zero.
+func Increase(s Series) *float64 {
+	nan := math.NaN()
+	if s.Len() < 2 {
+		return &nan
+	}
+	var increase float64
+	_, prev := s.GetPoint(0)
+	if prev == nil || math.IsNaN(*prev) {
+		return &nan
+	}
+	for i := 1; i < s.Len(); i++ {
+		_, v := s.GetPoint(i)
+		if v == nil || math.IsNaN(*v) {
+			return &nan
+		}
+		increase += counterDelta(*prev, *v)
+		prev = v
+	}
+	return &increase
+}
+
+// counterDelta returns how much a counter increased from prev to cur, assuming it restarted from zero if cur is lower than prev.
+func counterDelta(prev, cur float64) float64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// Rate returns the per-second increase of a counter over the series, handling counter resets as Increase does.
+func Rate(s Series) *float64 {
+	increase := Increase(s)
+	if math.IsNaN(*increase) {
+		return increase
+	}
+	first := s.GetTime(0)
+	last := s.GetTime(s.Len() - 1)
+	seconds := last.Sub(first).Seconds()
+	if seconds <= 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *increase / seconds
+	return &f
+}
+
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
-	switch strings.ToLower(rFunc) {
+	name := strings.ToLower(rFunc)
+	if p, ok := parsePercentile(name); ok {
+		return Percentile(p), nil
+	}
+	switch name {
 	case "sum":
 		return Sum, nil
 	case "mean":
@@ -95,14 +222,54 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 		return Count, nil
 	case "last":
 		return Last, nil
+	case "first":
+		return First, nil
+	case "diff":
+		return Diff, nil
+	case "median":
+		return Median, nil
+	case "stddev":
+		return StdDev, nil
+	case "variance":
+		return Variance, nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
+// GetSeriesReduceFunc returns the reduction function with the given name, including the ones that need the time of each point.
+func GetSeriesReduceFunc(rFunc string) (SeriesReducerFunc, error) {
+	switch strings.ToLower(rFunc) {
+	case "rate":
+		return Rate, nil
+	case "increase":
+		return Increase, nil
+	}
+	reduceFunc, err := GetReduceFunc(rFunc)
+	if err != nil {
+		return nil, err
+	}
+	return func(s Series) *float64 {
+		floatField := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+		return reduceFunc(&floatField)
+	}, nil
+}
+
+// parsePercentile parses reducer names in the form of pNN, for example p90 or p99.9.
+func parsePercentile(name string) (float64, bool) {
+	if !strings.HasPrefix(name, "p") {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(name[1:], 64)
+	if err != nil || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "first", "diff", "median", "p50", "p90", "p95", "p99", "stddev", "variance", "rate", "increase"}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
@@ -119,13 +286,11 @@ func (s Series) Reduce(refID, rFunc string, mapper ReduceMapper) (Number, error)
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetSeriesReduceFunc(rFunc)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
+	f = reduceFunc(series)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
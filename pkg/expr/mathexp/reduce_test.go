@@ -514,3 +514,163 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+var counterSeries = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("temp", nil,
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(5)},
+				tp{time.Unix(20, 0), float64Pointer(9)},
+				tp{time.Unix(30, 0), float64Pointer(2)},
+				tp{time.Unix(40, 0), float64Pointer(6)}),
+		},
+	},
+}
+
+func TestSeriesReduceExtended(t *testing.T) {
+	var tests = []struct {
+		name        string
+		red         string
+		vars        Vars
+		varToReduce string
+		mapper      ReduceMapper
+		results     Results
+	}{
+		{
+			name:        "first series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        counterSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+		},
+		{
+			name:        "first empty series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			results:     Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:        "diff series",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        counterSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(5))}},
+		},
+		{
+			name:        "median series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        counterSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(5))}},
+		},
+		{
+			name:        "median series with even number of points",
+			red:         "median",
+			varToReduce: "A",
+			vars:        aSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(1.5))}},
+		},
+		{
+			name:        "p75 series",
+			red:         "p75",
+			varToReduce: "A",
+			vars:        counterSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(6))}},
+		},
+		{
+			name:        "p100 series is the max",
+			red:         "p100",
+			varToReduce: "A",
+			vars:        counterSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(9))}},
+		},
+		{
+			name:        "p99 series with a nil value",
+			red:         "p99",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			results:     Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:        "p99 series with a nil value dropNN",
+			red:         "p99",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			mapper:      DropNonNumber{},
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(2))}},
+		},
+		{
+			name:        "variance series",
+			red:         "variance",
+			varToReduce: "A",
+			vars:        aSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(0.25))}},
+		},
+		{
+			name:        "stddev series",
+			red:         "stdDev",
+			varToReduce: "A",
+			vars:        aSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(0.5))}},
+		},
+		{
+			name:        "stddev empty series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			results:     Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:        "increase series handles counter resets",
+			red:         "increase",
+			varToReduce: "A",
+			vars:        counterSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(14))}},
+		},
+		{
+			name:        "rate series handles counter resets",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        counterSeries,
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(0.35))}},
+		},
+		{
+			name:        "rate series with a single point",
+			red:         "rate",
+			varToReduce: "A",
+			vars: Vars{"A": Results{[]Value{
+				makeSeries("temp", nil, tp{time.Unix(0, 0), float64Pointer(1)}),
+			}}},
+			results: Results{[]Value{makeNumber("", nil, NaN)}},
+		},
+		{
+			name:        "rate series with a nil value replaceNN",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			mapper:      ReplaceNonNumberWithValue{Value: 7},
+			results:     Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Results{}
+			seriesSet := tt.vars[tt.varToReduce]
+			for _, series := range seriesSet.Values {
+				ns, err := series.Value().(*Series).Reduce("", tt.red, tt.mapper)
+				require.NoError(t, err)
+				results.Values = append(results.Values, ns)
+			}
+			opt := cmp.Comparer(func(x, y float64) bool {
+				return (math.IsNaN(x) && math.IsNaN(y)) || math.Abs(x-y) < 1e-9
+			})
+			options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+			if diff := cmp.Diff(tt.results, results, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
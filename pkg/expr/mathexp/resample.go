@@ -16,10 +16,13 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
+	// lastNonNull is the last point with a value that is before the current bucket.
+	var lastNonNull *point
 	idx := 0
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
 		vals := make([]*float64, 0)
+		points := make([]point, 0)
 		sIdx := bookmark
 		for {
 			if sIdx == s.Len() {
@@ -33,6 +36,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 			sIdx++
 			lastSeen = v
 			vals = append(vals, v)
+			points = append(points, point{t: st, v: v})
 		}
 		var value *float64
 		if len(vals) == 0 { // upsampling
@@ -51,9 +55,21 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 				}
 			case "fillna":
 				value = nil
+			case "linear":
+				value = s.interpolate(t, lastNonNull, sIdx)
+			case "last_before_end":
+				// Unlike pad, which repeats the last point even if its value is null, this
+				// repeats the last point with a value.
+				if lastNonNull != nil {
+					value = lastNonNull.v
+				}
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
+		} else if downsampler == "time_weighted_mean" {
+			value = timeWeightedMean(t.Add(-interval), t, lastNonNull, points)
+		} else if downsampler == "last_before_end" {
+			value = lastNonNullValue(points, lastNonNull)
 		} else if len(vals) == 1 {
 			value = vals[0]
 		} else { // downsampling
@@ -76,9 +92,102 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 			}
 			value = tmp
 		}
+		for i := len(points) - 1; i >= 0; i-- {
+			if points[i].v != nil {
+				lastNonNull = &points[i]
+				break
+			}
+		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
 		idx++
 	}
 	return resampled, nil
 }
+
+// interpolate returns the value at t on the line between prev and the first point with a value
+// at or after the point at index next. It returns nil if either point does not exist.
+func (s Series) interpolate(t time.Time, prev *point, next int) *float64 {
+	if prev == nil {
+		return nil
+	}
+	for ; next < s.Len(); next++ {
+		nt, nv := s.GetPoint(next)
+		if nv == nil {
+			continue
+		}
+		span := nt.Sub(prev.t)
+		if span <= 0 {
+			return nil
+		}
+		f := *prev.v + (*nv-*prev.v)*float64(t.Sub(prev.t))/float64(span)
+		return &f
+	}
+	return nil
+}
+
+// timeWeightedMean returns the average of the values in the bucket (start, end], where each value is weighted
+// by the time until the next point or the end of the bucket, limited to the bucket. The value of prev, the last point
+// before the bucket, is used from the start of the bucket to the first point in it. Points before the start of the
+// bucket, which the first bucket can have, only count for the part of their duration inside the bucket. Null values
+// do not contribute to the average. If the points have no duration in the bucket, for example a single point at the
+// end of the bucket, the plain average of the points in the bucket is returned.
+func timeWeightedMean(start, end time.Time, prev *point, points []point) *float64 {
+	var sum, weight float64
+	add := func(v float64, from, until time.Time) {
+		if from.Before(start) {
+			from = start
+		}
+		if until.After(end) {
+			until = end
+		}
+		if w := until.Sub(from).Seconds(); w > 0 {
+			sum += v * w
+			weight += w
+		}
+	}
+	if prev != nil && len(points) > 0 {
+		add(*prev.v, start, points[0].t)
+	}
+	for i, p := range points {
+		if p.v == nil {
+			continue
+		}
+		until := end
+		if i+1 < len(points) {
+			until = points[i+1].t
+		}
+		add(*p.v, p.t, until)
+	}
+	if weight > 0 {
+		f := sum / weight
+		return &f
+	}
+	sum = 0
+	count := 0
+	for _, p := range points {
+		if p.v != nil && p.t.After(start) {
+			sum += *p.v
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	f := sum / float64(count)
+	return &f
+}
+
+// lastNonNullValue returns the value of the last point with a value, or the value of prev
+// if none of the points have a value.
+func lastNonNullValue(points []point, prev *point) *float64 {
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i].v != nil {
+			return points[i].v
+		}
+	}
+	if prev != nil {
+		return prev.v
+	}
+	return nil
+}
@@ -277,6 +277,128 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: upsampling (mean / linear)",
+			interval:    time.Second,
+			downsampler: "mean",
+			upsampler:   "linear",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(5, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(4),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(3, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(5, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: downsampling (time_weighted_mean / pad)",
+			interval:    time.Second * 10,
+			downsampler: "time_weighted_mean",
+			upsampler:   "pad",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(20, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(12, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(18, 0), float64Pointer(8),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(20, 0), float64Pointer(2.4),
+			}),
+		},
+		{
+			name:        "resample series: time_weighted_mean limits points before the first bucket to the bucket",
+			interval:    time.Second * 10,
+			downsampler: "time_weighted_mean",
+			upsampler:   "pad",
+			timeRange: backend.TimeRange{
+				From: time.Unix(10, 0),
+				To:   time.Unix(20, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(-50, 0), float64Pointer(100),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(0),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(10, 0), float64Pointer(50),
+			}, tp{
+				time.Unix(20, 0), float64Pointer(0),
+			}),
+		},
+		{
+			name:        "resample series: upsampling repeats the last point even if its value is null (last / pad)",
+			interval:    time.Second * 5,
+			downsampler: "last",
+			upsampler:   "pad",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(3, 0), nil,
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), nil,
+			}, tp{
+				time.Unix(10, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: last value before the end of the bucket (last_before_end / last_before_end)",
+			interval:    time.Second * 5,
+			downsampler: "last_before_end",
+			upsampler:   "last_before_end",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(15, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(3, 0), nil,
+			}, tp{
+				time.Unix(6, 0), nil,
+			}, tp{
+				time.Unix(7, 0), nil,
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(1),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,263 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// windowFunc computes the value of a point from the points in its window.
+// points are sorted by time, and the window of the point at idx spans from start to idx, both inclusive.
+type windowFunc func(points []point, start, idx int) *float64
+
+type point struct {
+	t time.Time
+	v *float64
+}
+
+// movingAvg returns for each point of each series the average of the non-null values in the window that ends at the point.
+func movingAvg(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(points []point, start, idx int) *float64 {
+		var sum float64
+		count := 0
+		for _, p := range points[start : idx+1] {
+			if p.v != nil {
+				sum += *p.v
+				count++
+			}
+		}
+		if count == 0 {
+			return nil
+		}
+		f := sum / float64(count)
+		return &f
+	})
+}
+
+// movingSum returns for each point of each series the sum of the non-null values in the window that ends at the point.
+func movingSum(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(points []point, start, idx int) *float64 {
+		var sum float64
+		count := 0
+		for _, p := range points[start : idx+1] {
+			if p.v != nil {
+				sum += *p.v
+				count++
+			}
+		}
+		if count == 0 {
+			return nil
+		}
+		return &sum
+	})
+}
+
+// delta returns for each point of each series the difference between its value and the first non-null value in the window.
+// The result is null if the window has fewer than two non-null values.
+func delta(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(points []point, start, idx int) *float64 {
+		last := points[idx].v
+		if last == nil {
+			return nil
+		}
+		for _, p := range points[start:idx] {
+			if p.v != nil {
+				f := *last - *p.v
+				return &f
+			}
+		}
+		return nil
+	})
+}
+
+// rate returns for each point of each series the per-second increase of a counter in the window that ends at the point.
+// Counter resets are handled the same way as the rate reducer does. The result is null if the window has fewer than two non-null values.
+func rate(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(points []point, start, idx int) *float64 {
+		var first, prev *point
+		var increase float64
+		for i := start; i <= idx; i++ {
+			p := points[i]
+			if p.v == nil {
+				continue
+			}
+			if prev == nil {
+				first = &points[i]
+			} else {
+				increase += counterDelta(*prev.v, *p.v)
+			}
+			prev = &points[i]
+		}
+		if first == nil || first == prev {
+			return nil
+		}
+		seconds := prev.t.Sub(first.t).Seconds()
+		if seconds <= 0 {
+			return nil
+		}
+		f := increase / seconds
+		return &f
+	})
+}
+
+// timeShift moves each point of each series forward in time by the given duration, or backwards if the duration is negative.
+// For example `$A - time_shift($A, "1h")` compares each point to the value of an hour before.
+func timeShift(e *State, varSet Results, shift string) (Results, error) {
+	d, err := gtime.ParseDuration(shift)
+	if err != nil {
+		return Results{}, fmt.Errorf("failed to parse time shift %q: %w", shift, err)
+	}
+	newRes := Results{}
+	for _, val := range varSet.Values {
+		switch v := val.(type) {
+		case Series:
+			newSeries := NewSeries(e.RefID, v.GetLabels(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				newSeries.SetPoint(i, t.Add(d), f)
+			}
+			newRes.Values = append(newRes.Values, newSeries)
+		case NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("time_shift can only be applied to type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// clampMin returns the maximum of each value in NumberSet, SeriesSet, or Scalar and the given minimum.
+func clampMin(e *State, varSet Results, minimum Results) (Results, error) {
+	m, err := scalarArg(minimum)
+	if err != nil {
+		return Results{}, err
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Max(f, m)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// clampMax returns the minimum of each value in NumberSet, SeriesSet, or Scalar and the given maximum.
+func clampMax(e *State, varSet Results, maximum Results) (Results, error) {
+	m, err := scalarArg(maximum)
+	if err != nil {
+		return Results{}, err
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Min(f, m)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// perWindow applies windowF to every point of each series in varSet. The window of a point
+// covers the points whose time is after the time of the point minus the window duration, up to the point itself.
+func perWindow(e *State, varSet Results, window string, windowF windowFunc) (Results, error) {
+	d, err := gtime.ParseDuration(window)
+	if err != nil {
+		return Results{}, fmt.Errorf("failed to parse window %q: %w", window, err)
+	}
+	if d <= 0 {
+		return Results{}, fmt.Errorf("window must be greater than zero, got %q", window)
+	}
+	newRes := Results{}
+	for _, val := range varSet.Values {
+		switch v := val.(type) {
+		case Series:
+			points := make([]point, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				points[i] = point{t: t, v: f}
+			}
+			sort.SliceStable(points, func(i, j int) bool {
+				return points[i].t.Before(points[j].t)
+			})
+			newSeries := NewSeries(e.RefID, v.GetLabels(), len(points))
+			start := 0
+			for i, p := range points {
+				for !points[start].t.After(p.t.Add(-d)) {
+					start++
+				}
+				newSeries.SetPoint(i, p.t, windowF(points, start, i))
+			}
+			newRes.Values = append(newRes.Values, newSeries)
+		case NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("window functions can only be applied to type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(arg Results) (float64, error) {
+	if len(arg.Values) != 1 {
+		return 0, fmt.Errorf("expected a single scalar argument, got %d values", len(arg.Values))
+	}
+	scalar, ok := arg.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("expected a scalar argument, got %v", arg.Values[0].Type())
+	}
+	f := scalar.GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("expected a number argument, got null")
+	}
+	return *f, nil
+}
+
+// checkDuration validates at parse time that the second argument of the function is a non-zero duration.
+func checkDuration(t *parse.Tree, f *parse.FuncNode) error {
+	d, err := durationArg(f)
+	if err != nil {
+		return err
+	}
+	if d == 0 {
+		return fmt.Errorf("parse: duration for %s must not be zero", f.Name)
+	}
+	return nil
+}
+
+// checkWindow validates at parse time that the second argument of the function is a positive duration.
+func checkWindow(t *parse.Tree, f *parse.FuncNode) error {
+	d, err := durationArg(f)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("parse: window for %s must be greater than zero, got %s", f.Name, f.Args[1].(*parse.StringNode).Quoted)
+	}
+	return nil
+}
+
+// durationArg parses the second argument of the function as a duration.
+func durationArg(f *parse.FuncNode) (time.Duration, error) {
+	s, ok := f.Args[1].(*parse.StringNode)
+	if !ok {
+		return 0, fmt.Errorf("parse: expected the second argument of %s to be a duration string, got %v", f.Name, f.Args[1])
+	}
+	d, err := gtime.ParseDuration(s.Text)
+	if err != nil {
+		return 0, fmt.Errorf("parse: invalid duration %s for %s: %w", s.Quoted, f.Name, err)
+	}
+	return d, nil
+}
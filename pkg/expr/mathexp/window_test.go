@@ -0,0 +1,214 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var windowSeries = Vars{
+	"A": Results{
+		[]Value{
+			makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(60, 0), float64Pointer(3)},
+				tp{time.Unix(120, 0), nil},
+				tp{time.Unix(180, 0), float64Pointer(8)},
+				tp{time.Unix(240, 0), float64Pointer(2)}),
+		},
+	},
+}
+
+func TestWindowFuncs(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "moving_avg on series",
+			expr:      `moving_avg($A, "2m")`,
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(2)},
+					tp{time.Unix(120, 0), float64Pointer(3)},
+					tp{time.Unix(180, 0), float64Pointer(8)},
+					tp{time.Unix(240, 0), float64Pointer(5)}),
+			}},
+		},
+		{
+			name:      "moving_sum on series",
+			expr:      `moving_sum($A, "90s")`,
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(4)},
+					tp{time.Unix(120, 0), float64Pointer(3)},
+					tp{time.Unix(180, 0), float64Pointer(8)},
+					tp{time.Unix(240, 0), float64Pointer(10)}),
+			}},
+		},
+		{
+			name:      "delta on series",
+			expr:      `delta($A, "2m")`,
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), nil},
+					tp{time.Unix(60, 0), float64Pointer(2)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), nil},
+					tp{time.Unix(240, 0), float64Pointer(-6)}),
+			}},
+		},
+		{
+			name:      "rate on series handles counter resets",
+			expr:      `rate($A, "3m")`,
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), nil},
+					tp{time.Unix(60, 0), float64Pointer(2.0 / 60)},
+					tp{time.Unix(120, 0), float64Pointer(2.0 / 60)},
+					tp{time.Unix(180, 0), float64Pointer(5.0 / 120)},
+					tp{time.Unix(240, 0), float64Pointer(2.0 / 60)}),
+			}},
+		},
+		{
+			name:      "time_shift on series",
+			expr:      `time_shift($A, "1h")`,
+			vars:      aSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(3605, 0), float64Pointer(2)},
+					tp{time.Unix(3610, 0), float64Pointer(1)}),
+			}},
+		},
+		{
+			name:      "compare series to its shifted self",
+			expr:      `$A - time_shift($A, "1m")`,
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(60, 0), float64Pointer(2)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), nil},
+					tp{time.Unix(240, 0), float64Pointer(-6)}),
+			}},
+		},
+		{
+			name:      "clamp_min on series",
+			expr:      `clamp_min($A, 2)`,
+			vars:      aSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: Results{[]Value{
+				makeSeries("", nil,
+					tp{time.Unix(5, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(2)}),
+			}},
+		},
+		{
+			name:      "clamp_max on number",
+			expr:      `clamp_max($A, 1.5)`,
+			vars:      Vars{"A": Results{[]Value{makeNumber("", nil, float64Pointer(3))}}},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   Results{[]Value{makeNumber("", nil, float64Pointer(1.5))}},
+		},
+		{
+			name:     "moving_avg with an invalid window - should error",
+			expr:     `moving_avg($A, "foo")`,
+			vars:     windowSeries,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg with a negative window - should error",
+			expr:     `moving_avg($A, "-1m")`,
+			vars:     windowSeries,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "rate with a zero window - should error",
+			expr:     `rate($A, "0s")`,
+			vars:     windowSeries,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "time_shift with a zero shift - should error",
+			expr:     `time_shift($A, "0s")`,
+			vars:     windowSeries,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "delta on scalar - should error",
+			expr:     `delta(1, "1m")`,
+			vars:     Vars{},
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			res, err := e.Execute("", tt.vars)
+			tt.execErrIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Len(t, res.Values, len(tt.results.Values))
+			for i, v := range tt.results.Values {
+				expected := v.AsDataFrame()
+				actual := res.Values[i].AsDataFrame()
+				require.Equal(t, expected.Rows(), actual.Rows())
+				for f := range expected.Fields {
+					for r := 0; r < expected.Rows(); r++ {
+						exp, act := expected.Fields[f].At(r), actual.Fields[f].At(r)
+						if ef, ok := exp.(*float64); ok && ef != nil {
+							af := act.(*float64)
+							require.NotNil(t, af)
+							require.InDelta(t, *ef, *af, 1e-9)
+							continue
+						}
+						require.Equal(t, exp, act)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRateOfSeriesWithNaN(t *testing.T) {
+	e, err := New(`rate($A, "5m")`)
+	require.NoError(t, err)
+	res, err := e.Execute("", Vars{"A": Results{[]Value{
+		makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(60, 0), NaN}),
+	}}})
+	require.NoError(t, err)
+	require.True(t, math.IsNaN(*res.Values[0].(Series).GetValue(1)))
+}
@@ -404,7 +404,11 @@ func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 				labels = make(data.Labels)
 			}
 			key := stringFieldNames[i] // TODO check for duplicate string column names
-			val, _ := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
+			val, ok := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
+			if !ok {
+				// a null value in a nullable string field means that the row does not have the label
+				continue
+			}
 			labels[key] = val.(string) // TODO check assertion / return error
 		}
 
@@ -50,6 +50,24 @@ func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, dataSourceSer
 	}
 }
 
+// NewQueryDataService creates a Service that sends the queries of data source nodes to handler
+// instead of the data source plugins. The data sources in the requests must not have secure settings.
+func NewQueryDataService(handler backend.QueryDataHandler) *Service {
+	return &Service{
+		dataService: handler,
+	}
+}
+
+// WithQueryDataMiddleware returns a copy of the Service that sends the queries of data source nodes to the handler
+// returned by middleware, which is given the handler of the Service to pass on the queries that it does not handle.
+func (s *Service) WithQueryDataMiddleware(middleware func(next backend.QueryDataHandler) backend.QueryDataHandler) *Service {
+	return &Service{
+		cfg:               s.cfg,
+		dataService:       middleware(s.dataService),
+		dataSourceService: s.dataSourceService,
+	}
+}
+
 func (s *Service) isDisabled() bool {
 	if s.cfg == nil {
 		return true
@@ -3,18 +3,34 @@ package expr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
 type ThresholdCommand struct {
-	ReferenceVar  string
-	RefID         string
+	ReferenceVar string
+	RefID        string
+	Conditions   []ThresholdCondition
+	// LoadedDimensions contains the labels of the series that were firing at the previous evaluation,
+	// keyed by their string representation. The resolving threshold is used for these series instead of the firing threshold.
+	LoadedDimensions map[string]struct{}
+}
+
+// ThresholdCondition is a single condition of a threshold expression. A series fires if its value
+// crosses the firing threshold. If the condition has a resolving threshold, a firing series only
+// resolves once its value crosses the resolving threshold.
+type ThresholdCondition struct {
 	ThresholdFunc string
-	Conditions    []float64
+	Params        []float64
+	// UnloadThresholdFunc and UnloadParams are the resolving threshold. They are optional.
+	UnloadThresholdFunc string
+	UnloadParams        []float64
 }
 
 const (
@@ -30,15 +46,20 @@ var (
 
 func NewThresholdCommand(refID, referenceVar, thresholdFunc string, conditions []float64) (*ThresholdCommand, error) {
 	return &ThresholdCommand{
-		RefID:         refID,
-		ReferenceVar:  referenceVar,
-		ThresholdFunc: thresholdFunc,
-		Conditions:    conditions,
+		RefID:        refID,
+		ReferenceVar: referenceVar,
+		Conditions: []ThresholdCondition{
+			{
+				ThresholdFunc: thresholdFunc,
+				Params:        conditions,
+			},
+		},
 	}, nil
 }
 
 type ThresholdConditionJSON struct {
-	Evaluator ConditionEvalJSON `json:"evaluator"`
+	Evaluator       ConditionEvalJSON  `json:"evaluator"`
+	UnloadEvaluator *ConditionEvalJSON `json:"unloadEvaluator,omitempty"`
 }
 
 type ConditionEvalJSON struct {
@@ -68,19 +89,62 @@ func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
 		return nil, fmt.Errorf("failed to unmarshal remarshaled threshold expression body: %w", err)
 	}
 
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("threshold expression requires at least one condition")
+	}
+
+	cmd := &ThresholdCommand{
+		RefID:        rn.RefID,
+		ReferenceVar: referenceVar,
+	}
 	for _, condition := range conditions {
-		if !IsSupportedThresholdFunc(condition.Evaluator.Type) {
-			return nil, fmt.Errorf("expected threshold function to be one of %s, got %s", strings.Join(supportedThresholdFuncs, ", "), condition.Evaluator.Type)
+		if err := validateThresholdEvaluator(condition.Evaluator); err != nil {
+			return nil, err
 		}
+		c := ThresholdCondition{
+			ThresholdFunc: condition.Evaluator.Type,
+			Params:        condition.Evaluator.Params,
+		}
+		if condition.UnloadEvaluator != nil {
+			if err := validateThresholdEvaluator(*condition.UnloadEvaluator); err != nil {
+				return nil, fmt.Errorf("invalid resolving threshold: %w", err)
+			}
+			c.UnloadThresholdFunc = condition.UnloadEvaluator.Type
+			c.UnloadParams = condition.UnloadEvaluator.Params
+		}
+		cmd.Conditions = append(cmd.Conditions, c)
 	}
 
-	// we only support one condition for now, we might want to turn this in to "OR" expressions later
-	if len(conditions) != 1 {
-		return nil, fmt.Errorf("threshold expression requires exactly one condition")
+	if rawDimensions, ok := rawQuery[loadedDimensionsKey]; ok && rawDimensions != nil {
+		jsonFromM, err := json.Marshal(rawDimensions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remarshal loaded dimensions: %w", err)
+		}
+		var dimensions []data.Labels
+		if err = json.Unmarshal(jsonFromM, &dimensions); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loaded dimensions: %w", err)
+		}
+		cmd.LoadedDimensions = make(map[string]struct{}, len(dimensions))
+		for _, d := range dimensions {
+			cmd.LoadedDimensions[d.String()] = struct{}{}
+		}
 	}
-	firstCondition := conditions[0]
 
-	return NewThresholdCommand(rn.RefID, referenceVar, firstCondition.Evaluator.Type, firstCondition.Evaluator.Params)
+	return cmd, nil
+}
+
+func validateThresholdEvaluator(evaluator ConditionEvalJSON) error {
+	if !IsSupportedThresholdFunc(evaluator.Type) {
+		return fmt.Errorf("expected threshold function to be one of %s, got %s", strings.Join(supportedThresholdFuncs, ", "), evaluator.Type)
+	}
+	required := 1
+	if evaluator.Type == ThresholdIsWithinRange || evaluator.Type == ThresholdIsOutsideRange {
+		required = 2
+	}
+	if len(evaluator.Params) < required {
+		return fmt.Errorf("threshold function %s requires %d parameters, got %d", evaluator.Type, required, len(evaluator.Params))
+	}
+	return nil
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -89,11 +153,67 @@ func (tc *ThresholdCommand) NeedsVars() []string {
 	return []string{tc.ReferenceVar}
 }
 
+// HasHysteresis returns true if any of the conditions has a resolving threshold.
+func (tc *ThresholdCommand) HasHysteresis() bool {
+	for _, c := range tc.Conditions {
+		if c.UnloadThresholdFunc != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute evaluates the firing threshold for each series. If the command has a resolving threshold,
+// series that were firing at the previous evaluation keep firing until they cross the resolving threshold.
 func (tc *ThresholdCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars) (mathexp.Results, error) {
-	mathExpression, err := createMathExpression(tc.ReferenceVar, tc.ThresholdFunc, tc.Conditions)
+	firing, err := tc.executeExpression(ctx, now, vars, false)
+	if err != nil || !tc.HasHysteresis() || len(tc.LoadedDimensions) == 0 {
+		return firing, err
+	}
+
+	stillFiring, err := tc.executeExpression(ctx, now, vars, true)
 	if err != nil {
 		return mathexp.Results{}, err
 	}
+	stillFiringByLabels := make(map[string]mathexp.Value, len(stillFiring.Values))
+	for _, v := range stillFiring.Values {
+		stillFiringByLabels[v.GetLabels().String()] = v
+	}
+	for i, v := range firing.Values {
+		key := v.GetLabels().String()
+		if _, ok := tc.LoadedDimensions[key]; !ok {
+			continue
+		}
+		if sv, ok := stillFiringByLabels[key]; ok {
+			firing.Values[i] = sv
+		}
+	}
+	return firing, nil
+}
+
+// executeExpression evaluates the threshold as a Math expression. Multiple conditions are combined with OR.
+// If loaded is true, the expression is true for series that have not yet crossed the resolving threshold.
+func (tc *ThresholdCommand) executeExpression(ctx context.Context, now time.Time, vars mathexp.Vars, loaded bool) (mathexp.Results, error) {
+	expressions := make([]string, 0, len(tc.Conditions))
+	for _, c := range tc.Conditions {
+		var e string
+		var err error
+		if loaded && c.UnloadThresholdFunc != "" {
+			e, err = createMathExpression(tc.ReferenceVar, c.UnloadThresholdFunc, c.UnloadParams)
+			e = fmt.Sprintf("!(%s)", e)
+		} else {
+			e, err = createMathExpression(tc.ReferenceVar, c.ThresholdFunc, c.Params)
+		}
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		expressions = append(expressions, e)
+	}
+
+	mathExpression := expressions[0]
+	if len(expressions) > 1 {
+		mathExpression = "(" + strings.Join(expressions, ") || (") + ")"
+	}
 
 	mathCommand, err := NewMathCommand(tc.ReferenceVar, mathExpression)
 	if err != nil {
@@ -103,6 +223,44 @@ func (tc *ThresholdCommand) Execute(ctx context.Context, now time.Time, vars mat
 	return mathCommand.Execute(ctx, now, vars)
 }
 
+// loadedDimensionsKey is the property of the query model of a threshold expression that contains
+// the labels of the series that were firing at the previous evaluation.
+const loadedDimensionsKey = "loadedDimensions"
+
+// IsHysteresisExpression returns true if the query model is a threshold expression with a resolving threshold.
+func IsHysteresisExpression(query map[string]interface{}) bool {
+	if t, ok := query["type"].(string); !ok || t != TypeThreshold.String() {
+		return false
+	}
+	conditions, ok := query["conditions"].([]interface{})
+	if !ok {
+		return false
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if unload, ok := condition["unloadEvaluator"]; ok && unload != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLoadedDimensionsToHysteresisCommand sets the labels of the series that were firing at the previous
+// evaluation in the query model of a threshold expression.
+func SetLoadedDimensionsToHysteresisCommand(query map[string]interface{}, dimensions []data.Labels) error {
+	if !IsHysteresisExpression(query) {
+		return errors.New("query is not a threshold expression with a resolving threshold")
+	}
+	if dimensions == nil {
+		dimensions = []data.Labels{}
+	}
+	query[loadedDimensionsKey] = dimensions
+	return nil
+}
+
 // createMathExpression converts all the info we have about a "threshold" expression in to a Math expression
 func createMathExpression(referenceVar string, thresholdFunc string, args []float64) (string, error) {
 	switch thresholdFunc {
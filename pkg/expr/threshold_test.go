@@ -1,10 +1,15 @@
 package expr
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
 
 func TestNewThresholdCommand(t *testing.T) {
@@ -44,7 +49,7 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 				"conditions": []
 			}`,
 			shouldError:   true,
-			expectedError: "requires exactly one condition",
+			expectedError: "requires at least one condition",
 		},
 		{
 			description: "unmarshal with missing conditions should error",
@@ -54,7 +59,64 @@ func TestUnmarshalThresholdCommand(t *testing.T) {
 				"conditions": []
 			}`,
 			shouldError:   true,
-			expectedError: "requires exactly one condition",
+			expectedError: "requires at least one condition",
+		},
+		{
+			description: "unmarshal multiple conditions with a resolving threshold",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [90]
+					},
+					"unloadEvaluator": {
+						"type": "lt",
+						"params": [80]
+					}
+				}, {
+					"evaluator": {
+						"type": "lt",
+						"params": [0]
+					}
+				}]
+			}`,
+			shouldError: false,
+		},
+		{
+			description: "unmarshal with missing threshold params",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "within_range",
+						"params": [20]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "requires 2 parameters",
+		},
+		{
+			description: "unmarshal with unsupported resolving threshold function",
+			query: `{
+				"expression" : "A",
+				"type": "threshold",
+				"conditions": [{
+					"evaluator": {
+						"type": "gt",
+						"params": [90]
+					},
+					"unloadEvaluator": {
+						"type": "foo",
+						"params": [80]
+					}
+				}]
+			}`,
+			shouldError:   true,
+			expectedError: "invalid resolving threshold",
 		},
 		{
 			description: "unmarshal with unsupported threshold function",
@@ -209,3 +271,87 @@ func TestIsSupportedThresholdFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestThresholdCommandHysteresis(t *testing.T) {
+	query := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"expression" : "A",
+		"type": "threshold",
+		"conditions": [{
+			"evaluator": {
+				"type": "gt",
+				"params": [90]
+			},
+			"unloadEvaluator": {
+				"type": "lt",
+				"params": [80]
+			}
+		}]
+	}`), &query))
+	require.True(t, IsHysteresisExpression(query))
+	require.NoError(t, SetLoadedDimensionsToHysteresisCommand(query, []data.Labels{{"host": "b"}, {"host": "c"}}))
+
+	// The query is remarshaled the same way as it is sent to the expression service.
+	b, err := json.Marshal(query)
+	require.NoError(t, err)
+	query = map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(b, &query))
+
+	cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "B", Query: query})
+	require.NoError(t, err)
+	require.True(t, cmd.HasHysteresis())
+
+	number := func(host string, f float64) mathexp.Number {
+		n := mathexp.NewNumber("", data.Labels{"host": host})
+		n.SetValue(&f)
+		return n
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			number("a", 85), // was not firing and is below the firing threshold
+			number("b", 85), // was firing and is above the resolving threshold
+			number("c", 75), // was firing and is below the resolving threshold
+			number("d", 95), // was not firing and is above the firing threshold
+		}},
+	}
+	res, err := cmd.Execute(context.Background(), time.Now(), vars)
+	require.NoError(t, err)
+
+	actual := map[string]float64{}
+	for _, v := range res.Values {
+		actual[v.GetLabels()["host"]] = *v.(mathexp.Number).GetFloat64Value()
+	}
+	require.Equal(t, map[string]float64{"a": 0, "b": 1, "c": 0, "d": 1}, actual)
+}
+
+func TestThresholdCommandMultipleConditions(t *testing.T) {
+	cmd := &ThresholdCommand{
+		RefID:        "B",
+		ReferenceVar: "A",
+		Conditions: []ThresholdCondition{
+			{ThresholdFunc: ThresholdIsAbove, Params: []float64{90}},
+			{ThresholdFunc: ThresholdIsBelow, Params: []float64{10}},
+		},
+	}
+	for _, tc := range []struct {
+		value    float64
+		expected float64
+	}{{5, 1}, {50, 0}, {95, 1}} {
+		f := tc.value
+		n := mathexp.NewNumber("", nil)
+		n.SetValue(&f)
+		res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{n}}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, tc.expected, *res.Values[0].(mathexp.Number).GetFloat64Value())
+	}
+}
+
+func TestIsHysteresisExpression(t *testing.T) {
+	require.False(t, IsHysteresisExpression(map[string]interface{}{"type": "math", "expression": "$A > 1"}))
+	require.False(t, IsHysteresisExpression(map[string]interface{}{
+		"type":       "threshold",
+		"conditions": []interface{}{map[string]interface{}{"evaluator": map[string]interface{}{"type": "gt", "params": []interface{}{1}}}},
+	}))
+	require.Error(t, SetLoadedDimensionsToHysteresisCommand(map[string]interface{}{"type": "math"}, nil))
+}
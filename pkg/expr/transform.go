@@ -154,6 +154,9 @@ func hiddenRefIDs(queries []Query) (map[string]struct{}, error) {
 
 func (s *Service) decryptSecureJsonDataFn(ctx context.Context) func(ds *datasources.DataSource) (map[string]string, error) {
 	return func(ds *datasources.DataSource) (map[string]string, error) {
+		if s.dataSourceService == nil {
+			return map[string]string{}, nil
+		}
 		return s.dataSourceService.DecryptedValues(ctx, ds)
 	}
 }
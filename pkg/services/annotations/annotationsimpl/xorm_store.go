@@ -261,6 +261,16 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 			params = append(params, query.To, query.From)
 		}
 
+		if query.PrevState != "" {
+			sql.WriteString(` AND (a.prev_state = ? OR a.prev_state LIKE ?)`)
+			params = append(params, query.PrevState, query.PrevState+" (%")
+		}
+
+		if query.NewState != "" {
+			sql.WriteString(` AND (a.new_state = ? OR a.new_state LIKE ?)`)
+			params = append(params, query.NewState, query.NewState+" (%")
+		}
+
 		if query.Type == "alert" {
 			sql.WriteString(` AND a.alert_id > 0`)
 		} else if query.Type == "annotation" {
@@ -312,8 +322,16 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query *annotations.ItemQue
 			query.Limit = 100
 		}
 
+		limit := r.db.GetDialect().Limit(query.Limit)
+		if query.Offset > 0 {
+			limit = r.db.GetDialect().LimitOffset(query.Limit, query.Offset)
+		}
 		// order of ORDER BY arguments match the order of a sql index for performance
-		sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + r.db.GetDialect().Limit(query.Limit) + " ) dt on dt.id = annotation.id")
+		if query.Ascending {
+			sql.WriteString(" ORDER BY a.org_id, a.epoch_end, a.epoch" + limit + " ) dt on dt.id = annotation.id")
+		} else {
+			sql.WriteString(" ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC" + limit + " ) dt on dt.id = annotation.id")
+		}
 		if err := sess.SQL(sql.String(), params...).Find(&items); err != nil {
 			items = nil
 			return err
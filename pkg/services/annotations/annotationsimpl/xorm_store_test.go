@@ -3,6 +3,7 @@ package annotationsimpl
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -212,6 +213,52 @@ func TestIntegrationAnnotations(t *testing.T) {
 			assert.Len(t, inserted, count)
 		})
 
+		t.Run("Can query alert annotations by state and page them from the oldest", func(t *testing.T) {
+			states := []struct{ prev, next string }{
+				{"Normal", "Alerting"},
+				{"Alerting", "Normal"},
+				{"Normal", "Alerting"},
+				{"Alerting", "Normal (NoData)"},
+				{"Normal (NoData)", "Alerting"},
+			}
+			items := make([]annotations.Item, 0, len(states))
+			for i, s := range states {
+				items = append(items, annotations.Item{
+					OrgID:     102,
+					AlertID:   5,
+					PrevState: s.prev,
+					NewState:  s.next,
+					Epoch:     int64(100 + i),
+				})
+			}
+			require.NoError(t, repo.AddMany(context.Background(), items))
+
+			epochs := func(items []*annotations.ItemDTO) []int64 {
+				result := make([]int64, 0, len(items))
+				for _, item := range items {
+					result = append(result, item.Time)
+				}
+				sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+				return result
+			}
+
+			found, err := repo.Get(context.Background(), &annotations.ItemQuery{OrgID: 102, AlertID: 5, NewState: "Normal", SignedInUser: testUser})
+			require.NoError(t, err)
+			require.Equal(t, []int64{101, 103}, epochs(found))
+
+			found, err = repo.Get(context.Background(), &annotations.ItemQuery{OrgID: 102, AlertID: 5, PrevState: "Normal", NewState: "Alerting", SignedInUser: testUser})
+			require.NoError(t, err)
+			require.Equal(t, []int64{100, 102, 104}, epochs(found))
+
+			found, err = repo.Get(context.Background(), &annotations.ItemQuery{OrgID: 102, AlertID: 5, Ascending: true, Offset: 1, Limit: 2, SignedInUser: testUser})
+			require.NoError(t, err)
+			require.Equal(t, []int64{101, 102}, epochs(found))
+
+			found, err = repo.Get(context.Background(), &annotations.ItemQuery{OrgID: 102, AlertID: 5, Offset: 1, Limit: 2, SignedInUser: testUser})
+			require.NoError(t, err)
+			require.Equal(t, []int64{102, 103}, epochs(found))
+		})
+
 		t.Run("Can query for annotation by id", func(t *testing.T) {
 			items, err := repo.Get(context.Background(), &annotations.ItemQuery{
 				OrgID:        1,
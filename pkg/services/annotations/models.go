@@ -18,9 +18,17 @@ type ItemQuery struct {
 	Tags         []string `json:"tags"`
 	Type         string   `json:"type"`
 	MatchAny     bool     `json:"matchAny"`
+	// PrevState and NewState filter alert annotations by the state before and after the state transition. A state
+	// with a reason, such as "Normal (NoData)", matches the state without the reason too.
+	PrevState    string `json:"-"`
+	NewState     string `json:"-"`
 	SignedInUser *user.SignedInUser
 
 	Limit int64 `json:"limit"`
+	// Offset is the number of annotations to skip, in the order of the query.
+	Offset int64 `json:"-"`
+	// Ascending returns the oldest annotations first, instead of the newest.
+	Ascending bool `json:"-"`
 }
 
 // TagsQuery is the query for a tags search.
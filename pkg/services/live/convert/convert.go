@@ -5,12 +5,25 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
 	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
 )
 
+// Supported input formats.
+const (
+	InputFormatInflux     = "influx"
+	InputFormatPrometheus = "prometheus"
+	InputFormatOTLP       = "otlp"
+	InputFormatOTLPJSON   = "otlp_json"
+)
+
 type Converter struct {
 	telegrafConverterWide         *telegraf.Converter
 	telegrafConverterLabelsColumn *telegraf.Converter
+	prometheusConverter           *prometheus.Converter
+	otlpConverter                 *otlp.Converter
+	otlpJSONConverter             *otlp.Converter
 }
 
 func NewConverter() *Converter {
@@ -22,19 +35,42 @@ func NewConverter() *Converter {
 			telegraf.WithUseLabelsColumn(true),
 			telegraf.WithFloat64Numbers(true),
 		),
+		prometheusConverter: prometheus.NewConverter(),
+		otlpConverter:       otlp.NewConverter(),
+		otlpJSONConverter:   otlp.NewConverter(otlp.WithJSONEncoding(true)),
 	}
 }
 
-var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+var (
+	ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+	ErrUnsupportedInputFormat = errors.New("unsupported input format")
+)
+
+// Convert converts data in the input format to frames in the frame format.
+// Prometheus and OTLP input can only be converted to labels_column frames.
+func (c *Converter) Convert(data []byte, inputFormat string, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	if frameFormat != "wide" && frameFormat != "labels_column" {
+		return nil, ErrUnsupportedFrameFormat
+	}
 
-func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
 	var converter telemetry.Converter
-	switch frameFormat {
-	case "wide":
-		converter = c.telegrafConverterWide
-	case "labels_column":
-		converter = c.telegrafConverterLabelsColumn
+	switch inputFormat {
+	case InputFormatInflux:
+		if frameFormat == "wide" {
+			converter = c.telegrafConverterWide
+		} else {
+			converter = c.telegrafConverterLabelsColumn
+		}
+	case InputFormatPrometheus:
+		converter = c.prometheusConverter
+	case InputFormatOTLP:
+		converter = c.otlpConverter
+	case InputFormatOTLPJSON:
+		converter = c.otlpJSONConverter
 	default:
+		return nil, ErrUnsupportedInputFormat
+	}
+	if inputFormat != InputFormatInflux && frameFormat != "labels_column" {
 		return nil, ErrUnsupportedFrameFormat
 	}
 
@@ -161,6 +161,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
 	var managedStreamRunner *managedstream.Runner
+	var frameStorage pipeline.FrameStorage
 	if g.IsHA() {
 		redisClient := redis.NewClient(&redis.Options{
 			Addr: g.Cfg.LiveHAEngineAddress,
@@ -174,40 +175,59 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
 		)
+		// Share last frames and channel history between Grafana instances.
+		frameStorage = pipeline.NewRedisFrameStorage(redisClient)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewMemoryFrameCache(),
 		)
+		frameStorage = pipeline.NewMemoryFrameStorage()
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 	if g.Features.IsEnabled(featuremgmt.FlagLivePipeline) {
 		var builder pipeline.RuleBuilder
+		var sqlStorage *pipeline.SQLStorage
 		if os.Getenv("GF_LIVE_DEV_BUILDER") != "" {
 			builder = &pipeline.DevRuleBuilder{
 				Node:                 node,
 				ManagedStream:        g.ManagedStreamRunner,
-				FrameStorage:         pipeline.NewFrameStorage(),
+				FrameStorage:         frameStorage,
 				ChannelHandlerGetter: g,
 			}
 		} else {
-			storage := &pipeline.FileStorage{
-				DataPath:       cfg.DataPath,
-				SecretsService: g.SecretsService,
+			var storage pipeline.Storage
+			switch cfg.LivePipelineStorage {
+			case "database":
+				sqlStorage = &pipeline.SQLStorage{
+					SQLStore:       sqlStore,
+					SecretsService: g.SecretsService,
+				}
+				storage = sqlStorage
+			default:
+				storage = &pipeline.FileStorage{
+					DataPath:       cfg.DataPath,
+					SecretsService: g.SecretsService,
+				}
 			}
 			g.pipelineStorage = storage
 			builder = &pipeline.StorageRuleBuilder{
 				Node:                 node,
 				ManagedStream:        g.ManagedStreamRunner,
-				FrameStorage:         pipeline.NewFrameStorage(),
+				FrameStorage:         frameStorage,
 				Storage:              storage,
 				ChannelHandlerGetter: g,
 				SecretsService:       g.SecretsService,
+				InstanceName:         setting.InstanceName,
 			}
 		}
 		channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
+		if sqlStorage != nil {
+			// Rebuild channel rules on all nodes when they are changed through any of them.
+			sqlStorage.ChangeNotifier = pipeline.NewNodeStorageChangeNotifier(node, channelRuleGetter)
+		}
 
 		// Pre-build/validate channel rules for all organizations on start.
 		// This can be unreasonable to have in production scenario with many
@@ -1157,7 +1177,7 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 	builder := &pipeline.StorageRuleBuilder{
 		Node:                 g.node,
 		ManagedStream:        g.ManagedStreamRunner,
-		FrameStorage:         pipeline.NewFrameStorage(),
+		FrameStorage:         pipeline.NewMemoryFrameStorage(),
 		Storage:              storage,
 		ChannelHandlerGetter: g,
 	}
@@ -1221,6 +1241,9 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	rule, err := g.pipelineStorage.UpdateChannelRule(c.Req.Context(), c.OrgID, cmd)
 	if err != nil {
+		if errors.Is(err, pipeline.ErrVersionConflict) {
+			return response.Error(http.StatusConflict, err.Error(), err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -1332,6 +1355,9 @@ func (g *GrafanaLive) HandleWriteConfigsPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	result, err := g.pipelineStorage.UpdateWriteConfig(c.Req.Context(), c.OrgID, cmd)
 	if err != nil {
+		if errors.Is(err, pipeline.ErrVersionConflict) {
+			return response.Error(http.StatusConflict, err.Error(), err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to update write config", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -32,6 +32,8 @@ type ChannelRule struct {
 	OrgId    int64               `json:"-"`
 	Pattern  string              `json:"pattern"`
 	Settings ChannelRuleSettings `json:"settings"`
+	// Version is incremented on every update by storages that support optimistic versioning.
+	Version int64 `json:"version,omitempty"`
 }
 
 type ConverterConfig struct {
@@ -40,6 +42,8 @@ type ConverterConfig struct {
 	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
 	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
 	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	PrometheusConverterConfig *PrometheusConverterConfig `json:"prometheus,omitempty"`
+	OTLPConverterConfig       *OTLPConverterConfig       `json:"otlp,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -50,11 +54,28 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type WindowAggregateFieldConfig struct {
+	FieldName string `json:"fieldName"`
+	// Reducers applied to field values in a window: min, max, mean, count or last.
+	Reducers []string `json:"reducers"`
+}
+
+type WindowAggregateFrameProcessorConfig struct {
+	WindowMilliseconds int64 `json:"windowMilliseconds"`
+	// SlideMilliseconds is the interval between sliding window starts. Tumbling
+	// windows are used when it is not set. Window duration must be a multiple of it.
+	SlideMilliseconds int64                        `json:"slideMilliseconds,omitempty"`
+	Fields            []WindowAggregateFieldConfig `json:"fields"`
+	// GroupBy is a list of label names (from labels column or field labels) to aggregate separately.
+	GroupBy []string `json:"groupBy,omitempty"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                           string                               `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig      *DropFieldsFrameProcessorConfig      `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig      *KeepFieldsFrameProcessorConfig      `json:"keepFields,omitempty"`
+	MultipleProcessorConfig        *MultipleFrameProcessorConfig        `json:"multiple,omitempty"`
+	WindowAggregateProcessorConfig *WindowAggregateFrameProcessorConfig `json:"windowAggregate,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -79,6 +100,26 @@ type LokiOutputConfig struct {
 	UID string `json:"uid"`
 }
 
+type KafkaOutputConfig struct {
+	UID string `json:"uid"`
+	// Topic to write messages to. By default, the channel with / replaced by . is used.
+	Topic string `json:"topic,omitempty"`
+}
+
+type MQTTOutputConfig struct {
+	UID string `json:"uid"`
+	// Topic to publish messages to. By default, the channel is used.
+	Topic    string `json:"topic,omitempty"`
+	QoS      byte   `json:"qos,omitempty"`
+	Retained bool   `json:"retained,omitempty"`
+}
+
+type NATSOutputConfig struct {
+	UID string `json:"uid"`
+	// Subject to publish messages to. By default, the channel with / replaced by . is used.
+	Subject string `json:"subject,omitempty"`
+}
+
 type MultipleSubscriberConfig struct {
 	Subscribers []SubscriberConfig `json:"subscribers"`
 }
@@ -86,6 +127,7 @@ type MultipleSubscriberConfig struct {
 type SubscriberConfig struct {
 	Type                     string                    `json:"type" ts_type:"Omit<keyof SubscriberConfig, 'type'>"`
 	MultipleSubscriberConfig *MultipleSubscriberConfig `json:"multiple,omitempty"`
+	HistorySubscriberConfig  *HistorySubscriberConfig  `json:"history,omitempty"`
 }
 
 // RedirectDataOutputConfig ...
@@ -97,6 +139,9 @@ type DataOutputterConfig struct {
 	Type                     string                    `json:"type" ts_type:"Omit<keyof DataOutputterConfig, 'type'>"`
 	RedirectDataOutputConfig *RedirectDataOutputConfig `json:"redirect,omitempty"`
 	LokiOutputConfig         *LokiOutputConfig         `json:"loki,omitempty"`
+	KafkaOutputConfig        *KafkaOutputConfig        `json:"kafka,omitempty"`
+	MQTTOutputConfig         *MQTTOutputConfig         `json:"mqtt,omitempty"`
+	NATSOutputConfig         *NATSOutputConfig         `json:"nats,omitempty"`
 }
 
 type FrameOutputterConfig struct {
@@ -109,6 +154,10 @@ type FrameOutputterConfig struct {
 	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
 	LokiOutputConfig        *LokiOutputConfig          `json:"loki,omitempty"`
 	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	KafkaOutputConfig       *KafkaOutputConfig         `json:"kafka,omitempty"`
+	MQTTOutputConfig        *MQTTOutputConfig          `json:"mqtt,omitempty"`
+	NATSOutputConfig        *NATSOutputConfig          `json:"nats,omitempty"`
+	HistoryOutputConfig     *HistoryOutputConfig       `json:"history,omitempty"`
 }
 
 type MultipleFrameConditionCheckerConfig struct {
@@ -152,4 +201,11 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type PrometheusConverterConfig struct{}
+
+type OTLPConverterConfig struct {
+	// Encoding of OTLP metrics export requests: protobuf (default) or json.
+	Encoding string `json:"encoding,omitempty"`
+}
+
 type ManagedStreamOutputConfig struct{}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
 )
 
 // AutoInfluxConverter decodes Influx line protocol input and transforms it
@@ -26,16 +27,22 @@ func (c *AutoInfluxConverter) Type() string {
 }
 
 func (c *AutoInfluxConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
-	frameWrappers, err := c.converter.Convert(body, c.config.FrameFormat)
+	frameWrappers, err := c.converter.Convert(body, convert.InputFormatInflux, c.config.FrameFormat)
 	if err != nil {
 		return nil, err
 	}
+	return toChannelFrames(vars.Channel, frameWrappers), nil
+}
+
+// toChannelFrames transforms converted metric frames to ChannelFrame objects where
+// Channel is constructed from original channel + / + <metric_name>.
+func toChannelFrames(channel string, frameWrappers []telemetry.FrameWrapper) []*ChannelFrame {
 	channelFrames := make([]*ChannelFrame, 0, len(frameWrappers))
 	for _, fw := range frameWrappers {
 		channelFrames = append(channelFrames, &ChannelFrame{
-			Channel: vars.Channel + "/" + fw.Key(),
+			Channel: channel + "/" + fw.Key(),
 			Frame:   fw.Frame(),
 		})
 	}
-	return channelFrames, nil
+	return channelFrames
 }
@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+)
+
+// OTLPConverter decodes OTLP metrics export request input and transforms it
+// to several ChannelFrame objects in labels column format where Channel is
+// constructed from original channel + / + <metric_name>.
+type OTLPConverter struct {
+	config    OTLPConverterConfig
+	converter *otlp.Converter
+}
+
+// NewOTLPConverter creates new OTLPConverter.
+func NewOTLPConverter(config OTLPConverterConfig) *OTLPConverter {
+	return &OTLPConverter{
+		config:    config,
+		converter: otlp.NewConverter(otlp.WithJSONEncoding(config.Encoding == "json")),
+	}
+}
+
+const ConverterTypeOTLP = "otlp"
+
+func (c *OTLPConverter) Type() string {
+	return ConverterTypeOTLP
+}
+
+func (c *OTLPConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	frameWrappers, err := c.converter.Convert(body)
+	if err != nil {
+		return nil, err
+	}
+	return toChannelFrames(vars.Channel, frameWrappers), nil
+}
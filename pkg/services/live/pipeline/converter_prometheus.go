@@ -0,0 +1,34 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
+)
+
+// PrometheusConverter decodes Prometheus text exposition format input and transforms
+// it to several ChannelFrame objects in labels column format where Channel is
+// constructed from original channel + / + <metric_family_name>.
+type PrometheusConverter struct {
+	config    PrometheusConverterConfig
+	converter *prometheus.Converter
+}
+
+// NewPrometheusConverter creates new PrometheusConverter.
+func NewPrometheusConverter(config PrometheusConverterConfig) *PrometheusConverter {
+	return &PrometheusConverter{config: config, converter: prometheus.NewConverter()}
+}
+
+const ConverterTypePrometheus = "prometheus"
+
+func (c *PrometheusConverter) Type() string {
+	return ConverterTypePrometheus
+}
+
+func (c *PrometheusConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	frameWrappers, err := c.converter.Convert(body)
+	if err != nil {
+		return nil, err
+	}
+	return toChannelFrames(vars.Channel, frameWrappers), nil
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"context"
+)
+
+const (
+	DataOutputTypeKafka = "kafka"
+	DataOutputTypeMQTT  = "mqtt"
+	DataOutputTypeNATS  = "nats"
+)
+
+// KafkaDataOutput writes raw data to a Kafka topic, using the channel as message key.
+type KafkaDataOutput struct {
+	publisher messagePublisher
+	config    KafkaOutputConfig
+}
+
+func NewKafkaDataOutput(publisher messagePublisher, config KafkaOutputConfig) *KafkaDataOutput {
+	return &KafkaDataOutput{publisher: publisher, config: config}
+}
+
+func (out *KafkaDataOutput) Type() string {
+	return DataOutputTypeKafka
+}
+
+func (out *KafkaDataOutput) OutputData(ctx context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	topic := out.config.Topic
+	if topic == "" {
+		topic = dotTopic(vars.Channel)
+	}
+	return nil, out.publisher.Publish(ctx, busMessage{Topic: topic, Key: vars.Channel, Payload: data})
+}
+
+// MQTTDataOutput publishes raw data to an MQTT topic.
+type MQTTDataOutput struct {
+	publisher messagePublisher
+	config    MQTTOutputConfig
+}
+
+func NewMQTTDataOutput(publisher messagePublisher, config MQTTOutputConfig) *MQTTDataOutput {
+	return &MQTTDataOutput{publisher: publisher, config: config}
+}
+
+func (out *MQTTDataOutput) Type() string {
+	return DataOutputTypeMQTT
+}
+
+func (out *MQTTDataOutput) OutputData(ctx context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	topic := out.config.Topic
+	if topic == "" {
+		topic = vars.Channel
+	}
+	return nil, out.publisher.Publish(ctx, busMessage{
+		Topic:    topic,
+		Key:      vars.Channel,
+		Payload:  data,
+		QoS:      out.config.QoS,
+		Retained: out.config.Retained,
+	})
+}
+
+// NATSDataOutput publishes raw data to a NATS subject.
+type NATSDataOutput struct {
+	publisher messagePublisher
+	config    NATSOutputConfig
+}
+
+func NewNATSDataOutput(publisher messagePublisher, config NATSOutputConfig) *NATSDataOutput {
+	return &NATSDataOutput{publisher: publisher, config: config}
+}
+
+func (out *NATSDataOutput) Type() string {
+	return DataOutputTypeNATS
+}
+
+func (out *NATSDataOutput) OutputData(ctx context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	subject := out.config.Subject
+	if subject == "" {
+		subject = dotTopic(vars.Channel)
+	}
+	return nil, out.publisher.Publish(ctx, busMessage{Topic: subject, Key: vars.Channel, Payload: data})
+}
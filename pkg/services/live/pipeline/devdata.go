@@ -92,7 +92,7 @@ func postTestData() {
 type DevRuleBuilder struct {
 	Node                 *centrifuge.Node
 	ManagedStream        *managedstream.Runner
-	FrameStorage         *FrameStorage
+	FrameStorage         FrameStorage
 	ChannelHandlerGetter ChannelHandlerGetter
 }
 
@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// maxHistorySize limits the number of frames kept in channel history.
+const maxHistorySize = 1000
+
+type HistoryOutputConfig struct {
+	// Size is the number of last frames kept in channel history.
+	Size int `json:"size"`
+}
+
+// HistoryFrameOutput appends frames to the channel history so that
+// they can be replayed to new subscribers by HistorySubscriber.
+type HistoryFrameOutput struct {
+	frameStorage FrameStorage
+	config       HistoryOutputConfig
+}
+
+func NewHistoryFrameOutput(frameStorage FrameStorage, config HistoryOutputConfig) *HistoryFrameOutput {
+	return &HistoryFrameOutput{frameStorage: frameStorage, config: config}
+}
+
+const FrameOutputTypeHistory = "history"
+
+func (out *HistoryFrameOutput) Type() string {
+	return FrameOutputTypeHistory
+}
+
+func (out *HistoryFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	if frame == nil {
+		return nil, nil
+	}
+	return nil, out.frameStorage.AppendHistory(vars.OrgID, vars.Channel, frame, out.config.Size)
+}
@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	FrameOutputTypeKafka = "kafka"
+	FrameOutputTypeMQTT  = "mqtt"
+	FrameOutputTypeNATS  = "nats"
+)
+
+func frameMessage(vars Vars, frame *data.Frame) (busMessage, error) {
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return busMessage{}, err
+	}
+	return busMessage{Key: vars.Channel, Payload: frameJSON}, nil
+}
+
+// KafkaFrameOutput writes frames encoded to JSON to a Kafka topic, using the channel as message key.
+type KafkaFrameOutput struct {
+	publisher messagePublisher
+	config    KafkaOutputConfig
+}
+
+func NewKafkaFrameOutput(publisher messagePublisher, config KafkaOutputConfig) *KafkaFrameOutput {
+	return &KafkaFrameOutput{publisher: publisher, config: config}
+}
+
+func (out *KafkaFrameOutput) Type() string {
+	return FrameOutputTypeKafka
+}
+
+func (out *KafkaFrameOutput) OutputFrame(ctx context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	msg, err := frameMessage(vars, frame)
+	if err != nil {
+		return nil, err
+	}
+	msg.Topic = out.config.Topic
+	if msg.Topic == "" {
+		msg.Topic = dotTopic(vars.Channel)
+	}
+	return nil, out.publisher.Publish(ctx, msg)
+}
+
+// MQTTFrameOutput publishes frames encoded to JSON to an MQTT topic.
+type MQTTFrameOutput struct {
+	publisher messagePublisher
+	config    MQTTOutputConfig
+}
+
+func NewMQTTFrameOutput(publisher messagePublisher, config MQTTOutputConfig) *MQTTFrameOutput {
+	return &MQTTFrameOutput{publisher: publisher, config: config}
+}
+
+func (out *MQTTFrameOutput) Type() string {
+	return FrameOutputTypeMQTT
+}
+
+func (out *MQTTFrameOutput) OutputFrame(ctx context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	msg, err := frameMessage(vars, frame)
+	if err != nil {
+		return nil, err
+	}
+	msg.Topic = out.config.Topic
+	if msg.Topic == "" {
+		msg.Topic = vars.Channel
+	}
+	msg.QoS = out.config.QoS
+	msg.Retained = out.config.Retained
+	return nil, out.publisher.Publish(ctx, msg)
+}
+
+// NATSFrameOutput publishes frames encoded to JSON to a NATS subject.
+type NATSFrameOutput struct {
+	publisher messagePublisher
+	config    NATSOutputConfig
+}
+
+func NewNATSFrameOutput(publisher messagePublisher, config NATSOutputConfig) *NATSFrameOutput {
+	return &NATSFrameOutput{publisher: publisher, config: config}
+}
+
+func (out *NATSFrameOutput) Type() string {
+	return FrameOutputTypeNATS
+}
+
+func (out *NATSFrameOutput) OutputFrame(ctx context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	msg, err := frameMessage(vars, frame)
+	if err != nil {
+		return nil, err
+	}
+	msg.Topic = out.config.Subject
+	if msg.Topic == "" {
+		msg.Topic = dotTopic(vars.Channel)
+	}
+	return nil, out.publisher.Publish(ctx, msg)
+}
@@ -0,0 +1,343 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+type testPublisher struct {
+	messages []busMessage
+	closed   bool
+}
+
+func (p *testPublisher) Publish(_ context.Context, msg busMessage) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *testPublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestMessageBusFrameOutputs(t *testing.T) {
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	require.NoError(t, err)
+	vars := Vars{Channel: "stream/test/metrics"}
+
+	testCases := []struct {
+		name     string
+		output   func(p messagePublisher) FrameOutputter
+		expected busMessage
+	}{
+		{
+			name: "kafka with default topic",
+			output: func(p messagePublisher) FrameOutputter {
+				return NewKafkaFrameOutput(p, KafkaOutputConfig{})
+			},
+			expected: busMessage{Topic: "stream.test.metrics", Key: vars.Channel, Payload: frameJSON},
+		},
+		{
+			name: "kafka with topic",
+			output: func(p messagePublisher) FrameOutputter {
+				return NewKafkaFrameOutput(p, KafkaOutputConfig{Topic: "metrics"})
+			},
+			expected: busMessage{Topic: "metrics", Key: vars.Channel, Payload: frameJSON},
+		},
+		{
+			name: "mqtt with default topic",
+			output: func(p messagePublisher) FrameOutputter {
+				return NewMQTTFrameOutput(p, MQTTOutputConfig{QoS: 1, Retained: true})
+			},
+			expected: busMessage{Topic: vars.Channel, Key: vars.Channel, Payload: frameJSON, QoS: 1, Retained: true},
+		},
+		{
+			name: "nats with default subject",
+			output: func(p messagePublisher) FrameOutputter {
+				return NewNATSFrameOutput(p, NATSOutputConfig{})
+			},
+			expected: busMessage{Topic: "stream.test.metrics", Key: vars.Channel, Payload: frameJSON},
+		},
+		{
+			name: "nats with subject",
+			output: func(p messagePublisher) FrameOutputter {
+				return NewNATSFrameOutput(p, NATSOutputConfig{Subject: "grafana.metrics"})
+			},
+			expected: busMessage{Topic: "grafana.metrics", Key: vars.Channel, Payload: frameJSON},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &testPublisher{}
+			frames, err := tc.output(p).OutputFrame(context.Background(), vars, frame)
+			require.NoError(t, err)
+			require.Nil(t, frames)
+			require.Equal(t, []busMessage{tc.expected}, p.messages)
+		})
+	}
+}
+
+func TestMessageBusDataOutputs(t *testing.T) {
+	payload := []byte(`{"value": 1}`)
+	vars := Vars{Channel: "stream/test/metrics"}
+
+	testCases := []struct {
+		name     string
+		output   func(p messagePublisher) DataOutputter
+		expected busMessage
+	}{
+		{
+			name: "kafka",
+			output: func(p messagePublisher) DataOutputter {
+				return NewKafkaDataOutput(p, KafkaOutputConfig{})
+			},
+			expected: busMessage{Topic: "stream.test.metrics", Key: vars.Channel, Payload: payload},
+		},
+		{
+			name: "mqtt",
+			output: func(p messagePublisher) DataOutputter {
+				return NewMQTTDataOutput(p, MQTTOutputConfig{Topic: "grafana/metrics", QoS: 2})
+			},
+			expected: busMessage{Topic: "grafana/metrics", Key: vars.Channel, Payload: payload, QoS: 2},
+		},
+		{
+			name: "nats",
+			output: func(p messagePublisher) DataOutputter {
+				return NewNATSDataOutput(p, NATSOutputConfig{})
+			},
+			expected: busMessage{Topic: "stream.test.metrics", Key: vars.Channel, Payload: payload},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &testPublisher{}
+			out, err := tc.output(p).OutputData(context.Background(), vars, payload)
+			require.NoError(t, err)
+			require.Nil(t, out)
+			require.Equal(t, []busMessage{tc.expected}, p.messages)
+		})
+	}
+}
+
+func TestMessagePublisherCache(t *testing.T) {
+	var cache messagePublisherCache
+	var created []*testPublisher
+	create := func() messagePublisher {
+		p := &testPublisher{}
+		created = append(created, p)
+		return p
+	}
+	writeConfig := WriteConfig{OrgId: 1, UID: "kafka", Settings: WriteSettings{Endpoint: "localhost:9092"}}
+
+	p1 := cache.get(FrameOutputTypeKafka, writeConfig, nil, create)
+	p2 := cache.get(FrameOutputTypeKafka, writeConfig, nil, create)
+	require.Same(t, p1, p2)
+	require.Len(t, created, 1)
+
+	// a different type or uid gets another publisher
+	cache.get(FrameOutputTypeNATS, writeConfig, nil, create)
+	require.Len(t, created, 2)
+	cache.commit(1, map[string]struct{}{
+		messagePublisherKey(FrameOutputTypeKafka, 1, "kafka"): {},
+		messagePublisherKey(FrameOutputTypeNATS, 1, "kafka"):  {},
+	})
+	require.Same(t, p1, cache.get(FrameOutputTypeKafka, writeConfig, nil, create))
+
+	// a changed write config gets another publisher, the previous publisher is closed once committed
+	p3 := cache.get(FrameOutputTypeKafka, writeConfig, &BasicAuth{User: "user", Password: "password"}, create)
+	require.NotSame(t, p1, p3)
+	require.False(t, created[0].closed)
+	require.Len(t, created, 3)
+	cache.commit(1, map[string]struct{}{messagePublisherKey(FrameOutputTypeKafka, 1, "kafka"): {}})
+	require.True(t, created[0].closed)
+	require.False(t, created[2].closed)
+	require.Same(t, p3, cache.get(FrameOutputTypeKafka, writeConfig, &BasicAuth{User: "user", Password: "password"}, create))
+}
+
+func TestMessagePublisherCacheDiscard(t *testing.T) {
+	var cache messagePublisherCache
+	var created []*testPublisher
+	create := func() messagePublisher {
+		p := &testPublisher{}
+		created = append(created, p)
+		return p
+	}
+	writeConfig := WriteConfig{OrgId: 1, UID: "kafka", Settings: WriteSettings{Endpoint: "localhost:9092"}}
+	keys := map[string]struct{}{messagePublisherKey(FrameOutputTypeKafka, 1, "kafka"): {}}
+	p1 := cache.get(FrameOutputTypeKafka, writeConfig, nil, create)
+	cache.commit(1, keys)
+
+	changed := writeConfig
+	changed.Settings.Endpoint = "localhost:9093"
+	cache.get(FrameOutputTypeKafka, changed, nil, create)
+	cache.discard(1)
+
+	require.False(t, created[0].closed, "the publisher of the current rules should be kept")
+	require.True(t, created[1].closed, "the publisher of the failed build should be closed")
+	require.Same(t, p1, cache.get(FrameOutputTypeKafka, writeConfig, nil, create))
+}
+
+func TestMessagePublisherCacheCommit(t *testing.T) {
+	var cache messagePublisherCache
+	var created []*testPublisher
+	create := func() messagePublisher {
+		p := &testPublisher{}
+		created = append(created, p)
+		return p
+	}
+	kafka := WriteConfig{OrgId: 1, UID: "kafka", Settings: WriteSettings{Endpoint: "localhost:9092"}}
+	nats := WriteConfig{OrgId: 1, UID: "nats", Settings: WriteSettings{Endpoint: "nats://localhost:4222"}}
+	otherOrg := WriteConfig{OrgId: 2, UID: "nats", Settings: WriteSettings{Endpoint: "nats://localhost:4222"}}
+	cache.get(FrameOutputTypeKafka, kafka, nil, create)
+	cache.get(FrameOutputTypeNATS, nats, nil, create)
+	cache.get(FrameOutputTypeNATS, otherOrg, nil, create)
+	cache.commit(2, map[string]struct{}{messagePublisherKey(FrameOutputTypeNATS, 2, "nats"): {}})
+
+	rules := []ChannelRule{{
+		OrgId:   1,
+		Pattern: "stream/test",
+		Settings: ChannelRuleSettings{
+			FrameOutputters: []*FrameOutputterConfig{{
+				Type: FrameOutputTypeMultiple,
+				MultipleOutputterConfig: &MultipleOutputterConfig{Outputters: []FrameOutputterConfig{{
+					Type:              FrameOutputTypeKafka,
+					KafkaOutputConfig: &KafkaOutputConfig{UID: "kafka"},
+				}}},
+			}},
+		},
+	}}
+	cache.commit(1, messagePublisherKeys(1, rules))
+
+	require.False(t, created[0].closed, "the publisher used by the rules should be kept")
+	require.True(t, created[1].closed, "the publisher that is no longer used should be closed")
+	require.False(t, created[2].closed, "the publishers of other organizations should be kept")
+	require.Len(t, cache.publishers, 2)
+}
+
+// messageBusRuleStorage is a Storage with fixed channel rules and write configs.
+type messageBusRuleStorage struct {
+	Storage
+	rules        []ChannelRule
+	writeConfigs []WriteConfig
+}
+
+func (s *messageBusRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]ChannelRule, error) {
+	return s.rules, nil
+}
+
+func (s *messageBusRuleStorage) ListWriteConfigs(_ context.Context, _ int64) ([]WriteConfig, error) {
+	return s.writeConfigs, nil
+}
+
+func TestStorageRuleBuilderKeepsPublishersOnFailedBuild(t *testing.T) {
+	kafkaRule := ChannelRule{
+		OrgId:   1,
+		Pattern: "stream/test/kafka",
+		Settings: ChannelRuleSettings{
+			FrameOutputters: []*FrameOutputterConfig{{
+				Type:              FrameOutputTypeKafka,
+				KafkaOutputConfig: &KafkaOutputConfig{UID: "kafka"},
+			}},
+		},
+	}
+	storage := &messageBusRuleStorage{
+		rules:        []ChannelRule{kafkaRule},
+		writeConfigs: []WriteConfig{{OrgId: 1, UID: "kafka", Settings: WriteSettings{Endpoint: "localhost:9092"}}},
+	}
+	builder := &StorageRuleBuilder{Storage: storage}
+	key := messagePublisherKey(FrameOutputTypeKafka, 1, "kafka")
+
+	_, err := builder.BuildRules(context.Background(), 1)
+	require.NoError(t, err)
+	publisher := builder.publishers.publishers[key].publisher
+	require.NotNil(t, publisher)
+
+	// the write config changes, and the build fails after the kafka output was built
+	storage.writeConfigs[0].Settings.Endpoint = "localhost:9093"
+	storage.rules = []ChannelRule{kafkaRule, {
+		OrgId:    1,
+		Pattern:  "stream/test/invalid",
+		Settings: ChannelRuleSettings{Converter: &ConverterConfig{Type: "unknown"}},
+	}}
+	_, err = builder.BuildRules(context.Background(), 1)
+	require.Error(t, err)
+	require.Same(t, publisher, builder.publishers.publishers[key].publisher, "the publisher of the current rules should be kept")
+	require.Empty(t, builder.publishers.pending)
+
+	// the publisher is replaced once a build succeeds
+	storage.rules = []ChannelRule{kafkaRule}
+	_, err = builder.BuildRules(context.Background(), 1)
+	require.NoError(t, err)
+	require.NotSame(t, publisher, builder.publishers.publishers[key].publisher)
+	require.Equal(t, "localhost:9093", builder.publishers.publishers[key].endpoint)
+}
+
+func TestValidateMessageBusSettings(t *testing.T) {
+	auth := &BasicAuth{User: "user", Password: "password"}
+	testCases := []struct {
+		name       string
+		outputType string
+		settings   WriteSettings
+		basicAuth  *BasicAuth
+		err        string
+	}{
+		{
+			name:       "no auth without TLS",
+			outputType: FrameOutputTypeKafka,
+			settings:   WriteSettings{Endpoint: "localhost:9092"},
+		},
+		{
+			name:       "basic auth with TLS",
+			outputType: FrameOutputTypeKafka,
+			settings:   WriteSettings{Endpoint: "localhost:9092", TLS: &TLSSettings{}},
+			basicAuth:  auth,
+		},
+		{
+			name:       "basic auth without TLS",
+			outputType: FrameOutputTypeNATS,
+			settings:   WriteSettings{Endpoint: "nats://localhost:4222"},
+			basicAuth:  auth,
+			err:        "nats output with basic auth requires TLS",
+		},
+		{
+			name:       "MQTT with TLS and ssl endpoint",
+			outputType: FrameOutputTypeMQTT,
+			settings:   WriteSettings{Endpoint: "ssl://localhost:8883", TLS: &TLSSettings{}},
+			basicAuth:  auth,
+		},
+		{
+			name:       "MQTT with TLS and tcp endpoint",
+			outputType: FrameOutputTypeMQTT,
+			settings:   WriteSettings{Endpoint: "tcp://localhost:1883", TLS: &TLSSettings{}},
+			err:        "MQTT output with TLS requires an ssl:// endpoint, got tcp://",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateMessageBusSettings(tc.outputType, tc.settings, tc.basicAuth)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestMessageBusTLSConfig(t *testing.T) {
+	config, err := messageBusTLSConfig(nil)
+	require.NoError(t, err)
+	require.Nil(t, config)
+
+	config, err = messageBusTLSConfig(&TLSSettings{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	require.True(t, config.InsecureSkipVerify)
+	require.Nil(t, config.RootCAs)
+
+	_, err = messageBusTLSConfig(&TLSSettings{CACert: "not a certificate"})
+	require.Error(t, err)
+}
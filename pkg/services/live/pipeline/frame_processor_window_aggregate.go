@@ -0,0 +1,434 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// Reducers supported by WindowAggregateFrameProcessor.
+const (
+	WindowReducerMin   = "min"
+	WindowReducerMax   = "max"
+	WindowReducerMean  = "mean"
+	WindowReducerCount = "count"
+	WindowReducerLast  = "last"
+)
+
+// WindowAggregateFrameProcessor aggregates field values of frames over tumbling or
+// sliding time windows. Row time is taken from the first time field of a frame (the
+// time of processing is used for frames without it). A window is complete when a row
+// with time after the window end comes, until then the processor returns no frame
+// which stops further processing. Completed windows are returned as a frame with
+// one row per window and group where the time is the window end. Each channel
+// matching a rule has its own windows. Windows are kept when channel rules are
+// rebuilt with the same processor configuration, and are dropped for channels
+// without data for windowAggregateIdleTimeout or twice the window length,
+// whichever is longer.
+type WindowAggregateFrameProcessor struct {
+	config    WindowAggregateFrameProcessorConfig
+	configKey string
+	now       func() time.Time
+	states    *windowAggregateStateStore
+}
+
+func NewWindowAggregateFrameProcessor(config WindowAggregateFrameProcessorConfig) *WindowAggregateFrameProcessor {
+	return newWindowAggregateFrameProcessor(&windowAggregateStateStore{}, config)
+}
+
+// newWindowAggregateFrameProcessor returns a processor which keeps its windows in
+// states, so that they outlive the processor.
+func newWindowAggregateFrameProcessor(states *windowAggregateStateStore, config WindowAggregateFrameProcessorConfig) *WindowAggregateFrameProcessor {
+	return &WindowAggregateFrameProcessor{
+		config:    config,
+		configKey: fmt.Sprintf("%+v", config),
+		now:       time.Now,
+		states:    states,
+	}
+}
+
+const (
+	// windowAggregateIdleTimeout is the minimal time windows of a channel are kept
+	// without data. Such windows are never complete as windows are completed by
+	// newer rows.
+	windowAggregateIdleTimeout = time.Hour
+	// windowAggregateEvictInterval is how often idle windows are looked for.
+	windowAggregateEvictInterval = time.Minute
+)
+
+// windowAggregateStateStore keeps windows of WindowAggregateFrameProcessor by
+// organization, channel and processor configuration outside of channel rules,
+// so that rebuilding the rules does not lose windows which are not complete yet.
+type windowAggregateStateStore struct {
+	mu        sync.Mutex
+	states    map[string]*storedWindowAggregateState
+	lastEvict time.Time
+}
+
+type storedWindowAggregateState struct {
+	state       *windowAggregateState
+	lastUsed    time.Time
+	idleTimeout time.Duration
+}
+
+// update calls fn with the state of key, creating it if needed, and evicts
+// states which were not used for longer than their idle timeout.
+func (s *windowAggregateStateStore) update(key string, now time.Time, idleTimeout time.Duration, fn func(state *windowAggregateState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.states == nil {
+		s.states = map[string]*storedWindowAggregateState{}
+	}
+	if now.Sub(s.lastEvict) >= windowAggregateEvictInterval {
+		for k, stored := range s.states {
+			if now.Sub(stored.lastUsed) > stored.idleTimeout {
+				delete(s.states, k)
+			}
+		}
+		s.lastEvict = now
+	}
+	stored, ok := s.states[key]
+	if !ok {
+		stored = &storedWindowAggregateState{state: &windowAggregateState{panes: map[int64]map[string]*windowGroup{}}}
+		s.states[key] = stored
+	}
+	stored.lastUsed = now
+	stored.idleTimeout = idleTimeout
+	fn(stored.state)
+}
+
+const FrameProcessorTypeWindowAggregate = "windowAggregate"
+
+func (p *WindowAggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeWindowAggregate
+}
+
+func validateWindowAggregateConfig(config WindowAggregateFrameProcessorConfig) error {
+	if config.WindowMilliseconds <= 0 {
+		return errors.New("windowMilliseconds must be positive")
+	}
+	if config.SlideMilliseconds < 0 || config.SlideMilliseconds > config.WindowMilliseconds {
+		return errors.New("slideMilliseconds must be between 0 and windowMilliseconds")
+	}
+	if config.SlideMilliseconds > 0 && config.WindowMilliseconds%config.SlideMilliseconds != 0 {
+		return errors.New("windowMilliseconds must be a multiple of slideMilliseconds")
+	}
+	if len(config.Fields) == 0 {
+		return errors.New("no fields to aggregate")
+	}
+	for _, f := range config.Fields {
+		if len(f.Reducers) == 0 {
+			return fmt.Errorf("no reducers for field %s", f.FieldName)
+		}
+		for _, r := range f.Reducers {
+			switch r {
+			case WindowReducerMin, WindowReducerMax, WindowReducerMean, WindowReducerCount, WindowReducerLast:
+			default:
+				return fmt.Errorf("unknown reducer for field %s: %s", f.FieldName, r)
+			}
+		}
+	}
+	return nil
+}
+
+// windowFieldAggregate keeps mergeable aggregates of field values.
+type windowFieldAggregate struct {
+	count    int
+	sum      float64
+	min      float64
+	max      float64
+	last     float64
+	lastTime int64
+}
+
+func (a *windowFieldAggregate) add(t int64, v float64) {
+	a.merge(&windowFieldAggregate{count: 1, sum: v, min: v, max: v, last: v, lastTime: t})
+}
+
+func (a *windowFieldAggregate) merge(o *windowFieldAggregate) {
+	if a.count == 0 {
+		*a = *o
+		return
+	}
+	a.count += o.count
+	a.sum += o.sum
+	if o.min < a.min {
+		a.min = o.min
+	}
+	if o.max > a.max {
+		a.max = o.max
+	}
+	if o.lastTime >= a.lastTime {
+		a.last = o.last
+		a.lastTime = o.lastTime
+	}
+}
+
+func (a *windowFieldAggregate) reduce(reducer string) float64 {
+	switch reducer {
+	case WindowReducerMin:
+		return a.min
+	case WindowReducerMax:
+		return a.max
+	case WindowReducerMean:
+		return a.sum / float64(a.count)
+	case WindowReducerCount:
+		return float64(a.count)
+	default:
+		return a.last
+	}
+}
+
+type windowGroup struct {
+	labels data.Labels
+	fields map[string]*windowFieldAggregate
+}
+
+func newWindowGroup(labels data.Labels) *windowGroup {
+	return &windowGroup{labels: labels, fields: map[string]*windowFieldAggregate{}}
+}
+
+func (g *windowGroup) field(name string) *windowFieldAggregate {
+	a, ok := g.fields[name]
+	if !ok {
+		a = &windowFieldAggregate{}
+		g.fields[name] = a
+	}
+	return a
+}
+
+type windowAggregateState struct {
+	// panes keep aggregates of slide interval long time ranges by their start
+	// time and group key. Windows are built by merging panes.
+	panes map[int64]map[string]*windowGroup
+	// nextWindowEnd is the end of the next window to output, zero until first row.
+	nextWindowEnd int64
+	// watermark is the max row time seen.
+	watermark int64
+}
+
+func (s *windowAggregateState) group(paneStart int64, labels data.Labels) *windowGroup {
+	groups, ok := s.panes[paneStart]
+	if !ok {
+		groups = map[string]*windowGroup{}
+		s.panes[paneStart] = groups
+	}
+	key := labels.String()
+	g, ok := groups[key]
+	if !ok {
+		g = newWindowGroup(labels)
+		groups[key] = g
+	}
+	return g
+}
+
+func (p *WindowAggregateFrameProcessor) slide() int64 {
+	if p.config.SlideMilliseconds > 0 {
+		return p.config.SlideMilliseconds
+	}
+	return p.config.WindowMilliseconds
+}
+
+func (p *WindowAggregateFrameProcessor) idleTimeout() time.Duration {
+	if timeout := 2 * time.Duration(p.config.WindowMilliseconds) * time.Millisecond; timeout > windowAggregateIdleTimeout {
+		return timeout
+	}
+	return windowAggregateIdleTimeout
+}
+
+func (p *WindowAggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	key := p.configKey + "/" + orgchannel.PrependOrgID(vars.OrgID, vars.Channel)
+
+	var out *data.Frame
+	p.states.update(key, p.now(), p.idleTimeout(), func(state *windowAggregateState) {
+		p.addFrame(state, frame)
+		out = p.completeWindows(state, frame.Name)
+	})
+	return out, nil
+}
+
+func (p *WindowAggregateFrameProcessor) addFrame(state *windowAggregateState, frame *data.Frame) {
+	timeIndex, labelsIndex := -1, -1
+	fieldIndexes := map[string]int{}
+	for i, f := range frame.Fields {
+		switch {
+		case timeIndex < 0 && (f.Type() == data.FieldTypeTime || f.Type() == data.FieldTypeNullableTime):
+			timeIndex = i
+		case labelsIndex < 0 && f.Name == "labels" && (f.Type() == data.FieldTypeString || f.Type() == data.FieldTypeNullableString):
+			labelsIndex = i
+		}
+		if _, ok := fieldIndexes[f.Name]; !ok {
+			fieldIndexes[f.Name] = i
+		}
+	}
+
+	slide := p.slide()
+	now := p.now().UnixMilli()
+	for row := 0; row < frame.Rows(); row++ {
+		t := now
+		if timeIndex >= 0 {
+			if v, ok := frame.Fields[timeIndex].ConcreteAt(row); ok {
+				t = v.(time.Time).UnixMilli()
+			}
+		}
+		paneStart := t - t%slide
+		if state.nextWindowEnd == 0 {
+			state.nextWindowEnd = paneStart + slide
+		}
+		if t < state.nextWindowEnd-p.config.WindowMilliseconds {
+			// Windows with this row are already complete.
+			continue
+		}
+		var rowLabels data.Labels
+		if labelsIndex >= 0 && len(p.config.GroupBy) > 0 {
+			if v, ok := frame.Fields[labelsIndex].ConcreteAt(row); ok {
+				rowLabels, _ = data.LabelsFromString(v.(string))
+			}
+		}
+		for _, fieldConfig := range p.config.Fields {
+			index, ok := fieldIndexes[fieldConfig.FieldName]
+			if !ok {
+				continue
+			}
+			field := frame.Fields[index]
+			v, err := field.NullableFloatAt(row)
+			if err != nil || v == nil {
+				continue
+			}
+			state.group(paneStart, p.groupLabels(rowLabels, field.Labels)).field(fieldConfig.FieldName).add(t, *v)
+		}
+		if t > state.watermark {
+			state.watermark = t
+		}
+	}
+}
+
+func (p *WindowAggregateFrameProcessor) groupLabels(rowLabels data.Labels, fieldLabels data.Labels) data.Labels {
+	if len(p.config.GroupBy) == 0 {
+		return nil
+	}
+	labels := data.Labels{}
+	for _, name := range p.config.GroupBy {
+		if v, ok := fieldLabels[name]; ok {
+			labels[name] = v
+		} else if v, ok := rowLabels[name]; ok {
+			labels[name] = v
+		}
+	}
+	return labels
+}
+
+// completeWindows returns a frame with aggregates of all windows which ended
+// before the watermark or nil if there are no such windows.
+func (p *WindowAggregateFrameProcessor) completeWindows(state *windowAggregateState, name string) *data.Frame {
+	window, slide := p.config.WindowMilliseconds, p.slide()
+	var out *windowAggregateOutput
+	for len(state.panes) > 0 && state.nextWindowEnd <= state.watermark {
+		minPaneStart := int64(-1)
+		for paneStart := range state.panes {
+			if minPaneStart < 0 || paneStart < minPaneStart {
+				minPaneStart = paneStart
+			}
+		}
+		if minPaneStart+slide > state.nextWindowEnd {
+			// Skip windows without data.
+			state.nextWindowEnd = minPaneStart + slide
+			continue
+		}
+
+		groups := map[string]*windowGroup{}
+		for paneStart, paneGroups := range state.panes {
+			if paneStart < state.nextWindowEnd-window || paneStart >= state.nextWindowEnd {
+				continue
+			}
+			for key, g := range paneGroups {
+				merged, ok := groups[key]
+				if !ok {
+					merged = newWindowGroup(g.labels)
+					groups[key] = merged
+				}
+				for fieldName, a := range g.fields {
+					merged.field(fieldName).merge(a)
+				}
+			}
+		}
+		if out == nil {
+			out = p.newWindowAggregateOutput()
+		}
+		out.append(time.UnixMilli(state.nextWindowEnd), groups)
+
+		state.nextWindowEnd += slide
+		for paneStart := range state.panes {
+			if paneStart < state.nextWindowEnd-window {
+				delete(state.panes, paneStart)
+			}
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	return out.frame(name)
+}
+
+type windowAggregateOutput struct {
+	config WindowAggregateFrameProcessorConfig
+	labels []string
+	times  []time.Time
+	fields []*data.Field
+}
+
+func (p *WindowAggregateFrameProcessor) newWindowAggregateOutput() *windowAggregateOutput {
+	out := &windowAggregateOutput{config: p.config}
+	for _, fieldConfig := range p.config.Fields {
+		for _, reducer := range fieldConfig.Reducers {
+			field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+			field.Name = fieldConfig.FieldName + "_" + reducer
+			out.fields = append(out.fields, field)
+		}
+	}
+	return out
+}
+
+func (out *windowAggregateOutput) append(t time.Time, groups map[string]*windowGroup) {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		g := groups[key]
+		out.labels = append(out.labels, key)
+		out.times = append(out.times, t)
+		i := 0
+		for _, fieldConfig := range out.config.Fields {
+			a, ok := g.fields[fieldConfig.FieldName]
+			for _, reducer := range fieldConfig.Reducers {
+				if ok {
+					v := a.reduce(reducer)
+					out.fields[i].Append(&v)
+				} else {
+					out.fields[i].Append(nil)
+				}
+				i++
+			}
+		}
+	}
+}
+
+func (out *windowAggregateOutput) frame(name string) *data.Frame {
+	fields := make([]*data.Field, 0, len(out.fields)+2)
+	if len(out.config.GroupBy) > 0 {
+		fields = append(fields, data.NewField("labels", nil, out.labels))
+	}
+	fields = append(fields, data.NewField("time", nil, out.times))
+	fields = append(fields, out.fields...)
+	return data.NewFrame(name, fields...)
+}
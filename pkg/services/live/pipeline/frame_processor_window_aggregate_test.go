@@ -0,0 +1,237 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func windowTestFrame(start time.Time, labels []string, offsetsMs []int64, values []float64) *data.Frame {
+	times := make([]time.Time, 0, len(offsetsMs))
+	for _, ms := range offsetsMs {
+		times = append(times, start.Add(time.Duration(ms)*time.Millisecond))
+	}
+	fields := []*data.Field{}
+	if labels != nil {
+		fields = append(fields, data.NewField("labels", nil, labels))
+	}
+	fields = append(fields,
+		data.NewField("time", nil, times),
+		data.NewField("value", nil, values),
+	)
+	return data.NewFrame("test", fields...)
+}
+
+func nullableFloatValues(t *testing.T, field *data.Field) []float64 {
+	t.Helper()
+	values := make([]float64, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		v, err := field.NullableFloatAt(i)
+		require.NoError(t, err)
+		require.NotNil(t, v)
+		values = append(values, *v)
+	}
+	return values
+}
+
+func TestWindowAggregateFrameProcessor_Tumbling(t *testing.T) {
+	p := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Fields: []WindowAggregateFieldConfig{
+			{FieldName: "value", Reducers: []string{WindowReducerMin, WindowReducerMax, WindowReducerMean, WindowReducerCount, WindowReducerLast}},
+		},
+	})
+	start := time.UnixMilli(10000)
+	vars := Vars{OrgID: 1, Channel: "stream/test/window"}
+
+	// Window is not complete yet.
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start, nil, []int64{0, 200, 400}, []float64{1, 5, 3}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	// Frames of another channel do not affect windows.
+	frame, err = p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/other"}, windowTestFrame(start, nil, []int64{5000}, []float64{100}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	// Row after the window end completes the window.
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame(start, nil, []int64{900, 1100}, []float64{2, 7}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, "test", frame.Name)
+	require.Equal(t, []string{"time", "value_min", "value_max", "value_mean", "value_count", "value_last"}, fieldNamesOf(frame))
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start.Add(time.Second), frame.Fields[0].At(0))
+	require.Equal(t, []float64{1}, nullableFloatValues(t, frame.Fields[1]))
+	require.Equal(t, []float64{5}, nullableFloatValues(t, frame.Fields[2]))
+	require.Equal(t, []float64{2.75}, nullableFloatValues(t, frame.Fields[3]))
+	require.Equal(t, []float64{4}, nullableFloatValues(t, frame.Fields[4]))
+	require.Equal(t, []float64{2}, nullableFloatValues(t, frame.Fields[5]))
+
+	// Late rows are dropped, windows without data are skipped.
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame(start, nil, []int64{500, 5000}, []float64{100, 1}))
+	require.NoError(t, err)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start.Add(2*time.Second), frame.Fields[0].At(0))
+	require.Equal(t, []float64{7}, nullableFloatValues(t, frame.Fields[5]))
+}
+
+func TestWindowAggregateFrameProcessor_Sliding(t *testing.T) {
+	p := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		SlideMilliseconds:  500,
+		Fields: []WindowAggregateFieldConfig{
+			{FieldName: "value", Reducers: []string{WindowReducerCount}},
+		},
+	})
+	start := time.UnixMilli(10000)
+	vars := Vars{OrgID: 1, Channel: "stream/test/window"}
+
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start, nil, []int64{0, 100, 600, 1200, 1600}, []float64{1, 1, 1, 1, 1}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	// Windows ending at 10.5s, 11s and 11.5s.
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, start.Add(500*time.Millisecond), frame.Fields[0].At(0))
+	require.Equal(t, start.Add(1500*time.Millisecond), frame.Fields[0].At(2))
+	require.Equal(t, []float64{2, 3, 2}, nullableFloatValues(t, frame.Fields[1]))
+}
+
+func TestWindowAggregateFrameProcessor_GroupBy(t *testing.T) {
+	p := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		GroupBy:            []string{"device"},
+		Fields: []WindowAggregateFieldConfig{
+			{FieldName: "value", Reducers: []string{WindowReducerMax}},
+		},
+	})
+	start := time.UnixMilli(10000)
+	vars := Vars{OrgID: 1, Channel: "stream/test/window"}
+
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]string{"device=b, room=1", "device=a, room=1", "device=b, room=2", "device=a, room=1"},
+		[]int64{0, 100, 200, 1000},
+		[]float64{3, 1, 4, 9},
+	))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, []string{"labels", "time", "value_max"}, fieldNamesOf(frame))
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, "device=a", frame.Fields[0].At(0))
+	require.Equal(t, "device=b", frame.Fields[0].At(1))
+	require.Equal(t, []float64{1, 4}, nullableFloatValues(t, frame.Fields[2]))
+}
+
+// windowRuleStorage is a Storage with a single channel rule.
+type windowRuleStorage struct {
+	Storage
+	rule ChannelRule
+}
+
+func (s *windowRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]ChannelRule, error) {
+	return []ChannelRule{s.rule}, nil
+}
+
+func (s *windowRuleStorage) ListWriteConfigs(_ context.Context, _ int64) ([]WriteConfig, error) {
+	return nil, nil
+}
+
+func TestWindowAggregateFrameProcessor_RulesRebuilt(t *testing.T) {
+	config := WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Fields:             []WindowAggregateFieldConfig{{FieldName: "value", Reducers: []string{WindowReducerCount}}},
+	}
+	storage := &windowRuleStorage{rule: ChannelRule{
+		Pattern: "stream/test/window",
+		Settings: ChannelRuleSettings{
+			FrameProcessors: []*FrameProcessorConfig{{Type: FrameProcessorTypeWindowAggregate, WindowAggregateProcessorConfig: &config}},
+		},
+	}}
+	builder := &StorageRuleBuilder{Storage: storage}
+	start := time.UnixMilli(10000)
+	vars := Vars{OrgID: 1, Channel: "stream/test/window"}
+
+	process := func(frame *data.Frame) *data.Frame {
+		t.Helper()
+		rules, err := builder.BuildRules(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		require.Len(t, rules[0].FrameProcessors, 1)
+		out, err := rules[0].FrameProcessors[0].ProcessFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		return out
+	}
+
+	// Rules are rebuilt before every frame, the window keeps rows of all of them.
+	require.Nil(t, process(windowTestFrame(start, nil, []int64{0, 200}, []float64{1, 2})))
+	require.Nil(t, process(windowTestFrame(start, nil, []int64{400}, []float64{3})))
+	frame := process(windowTestFrame(start, nil, []int64{1100}, []float64{4}))
+	require.NotNil(t, frame)
+	require.Equal(t, []float64{3}, nullableFloatValues(t, frame.Fields[1]))
+
+	// Windows of a changed configuration start from scratch.
+	config.Fields[0].Reducers = []string{WindowReducerLast}
+	require.Nil(t, process(windowTestFrame(start, nil, []int64{1200}, []float64{5})))
+	frame = process(windowTestFrame(start, nil, []int64{2100}, []float64{6}))
+	require.NotNil(t, frame)
+	require.Equal(t, []float64{5}, nullableFloatValues(t, frame.Fields[1]))
+}
+
+func TestWindowAggregateFrameProcessor_EvictsIdleChannels(t *testing.T) {
+	p := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Fields:             []WindowAggregateFieldConfig{{FieldName: "value", Reducers: []string{WindowReducerCount}}},
+	})
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	start := time.UnixMilli(10000)
+	idle := Vars{OrgID: 1, Channel: "stream/test/idle"}
+	active := Vars{OrgID: 1, Channel: "stream/test/active"}
+
+	_, err := p.ProcessFrame(context.Background(), idle, windowTestFrame(start, nil, []int64{0}, []float64{1}))
+	require.NoError(t, err)
+	_, err = p.ProcessFrame(context.Background(), active, windowTestFrame(start, nil, []int64{0}, []float64{1}))
+	require.NoError(t, err)
+	require.Len(t, p.states.states, 2)
+
+	now = now.Add(windowAggregateIdleTimeout / 2)
+	_, err = p.ProcessFrame(context.Background(), active, windowTestFrame(start, nil, []int64{100}, []float64{1}))
+	require.NoError(t, err)
+	require.Len(t, p.states.states, 2)
+
+	now = now.Add(windowAggregateIdleTimeout/2 + time.Second)
+	frame, err := p.ProcessFrame(context.Background(), active, windowTestFrame(start, nil, []int64{1100}, []float64{1}))
+	require.NoError(t, err)
+	require.Len(t, p.states.states, 1)
+	// Windows of active channels are kept.
+	require.Equal(t, []float64{2}, nullableFloatValues(t, frame.Fields[1]))
+
+	// Frames of an evicted channel start new windows.
+	frame, err = p.ProcessFrame(context.Background(), idle, windowTestFrame(start, nil, []int64{1100}, []float64{1}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+}
+
+func TestValidateWindowAggregateConfig(t *testing.T) {
+	fields := []WindowAggregateFieldConfig{{FieldName: "value", Reducers: []string{WindowReducerMean}}}
+	require.NoError(t, validateWindowAggregateConfig(WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000, Fields: fields}))
+	require.NoError(t, validateWindowAggregateConfig(WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000, SlideMilliseconds: 250, Fields: fields}))
+	require.Error(t, validateWindowAggregateConfig(WindowAggregateFrameProcessorConfig{Fields: fields}))
+	require.Error(t, validateWindowAggregateConfig(WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000, SlideMilliseconds: 300, Fields: fields}))
+	require.Error(t, validateWindowAggregateConfig(WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000}))
+	require.Error(t, validateWindowAggregateConfig(WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Fields:             []WindowAggregateFieldConfig{{FieldName: "value", Reducers: []string{"median"}}},
+	}))
+}
+
+func fieldNamesOf(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
@@ -8,19 +8,32 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 )
 
-// FrameStorage keeps last channel frame in memory. Not usable in HA setup.
-type FrameStorage struct {
-	mu     sync.RWMutex
-	frames map[string]*data.Frame
+// FrameStorage keeps last frame and an optional bounded history of frames
+// for channels. Implementations shared between Grafana instances (like
+// RedisFrameStorage) make stateful outputs work in HA setup.
+type FrameStorage interface {
+	FrameGetSetter
+	// AppendHistory adds frame to channel history keeping at most size last frames.
+	AppendHistory(orgID int64, channel string, frame *data.Frame, size int) error
+	// History returns at most n last frames from channel history, oldest first.
+	History(orgID int64, channel string, n int) ([]*data.Frame, error)
 }
 
-func NewFrameStorage() *FrameStorage {
-	return &FrameStorage{
-		frames: map[string]*data.Frame{},
+// MemoryFrameStorage keeps channel frames in memory. Not usable in HA setup.
+type MemoryFrameStorage struct {
+	mu      sync.RWMutex
+	frames  map[string]*data.Frame
+	history map[string][]*data.Frame
+}
+
+func NewMemoryFrameStorage() *MemoryFrameStorage {
+	return &MemoryFrameStorage{
+		frames:  map[string]*data.Frame{},
+		history: map[string][]*data.Frame{},
 	}
 }
 
-func (s *FrameStorage) Set(orgID int64, channel string, frame *data.Frame) error {
+func (s *MemoryFrameStorage) Set(orgID int64, channel string, frame *data.Frame) error {
 	key := orgchannel.PrependOrgID(orgID, channel)
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -28,10 +41,40 @@ func (s *FrameStorage) Set(orgID int64, channel string, frame *data.Frame) error
 	return nil
 }
 
-func (s *FrameStorage) Get(orgID int64, channel string) (*data.Frame, bool, error) {
+func (s *MemoryFrameStorage) Get(orgID int64, channel string) (*data.Frame, bool, error) {
 	key := orgchannel.PrependOrgID(orgID, channel)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	f, ok := s.frames[key]
 	return f, ok, nil
 }
+
+func (s *MemoryFrameStorage) AppendHistory(orgID int64, channel string, frame *data.Frame, size int) error {
+	if size <= 0 {
+		return nil
+	}
+	key := orgchannel.PrependOrgID(orgID, channel)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	frames := append(s.history[key], frame)
+	if len(frames) > size {
+		// Copy to let the dropped frames be garbage collected.
+		frames = append([]*data.Frame(nil), frames[len(frames)-size:]...)
+	}
+	s.history[key] = frames
+	return nil
+}
+
+func (s *MemoryFrameStorage) History(orgID int64, channel string, n int) ([]*data.Frame, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	key := orgchannel.PrependOrgID(orgID, channel)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	frames := s.history[key]
+	if len(frames) > n {
+		frames = frames[len(frames)-n:]
+	}
+	return append([]*data.Frame(nil), frames...), nil
+}
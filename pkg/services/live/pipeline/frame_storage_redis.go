@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+const (
+	redisFrameStorageTTL     = 7 * 24 * time.Hour
+	redisFrameStorageTimeout = 5 * time.Second
+)
+
+// RedisFrameStorage keeps channel frames in Redis so that they are shared
+// between Grafana instances in HA setup.
+type RedisFrameStorage struct {
+	redisClient *redis.Client
+}
+
+func NewRedisFrameStorage(redisClient *redis.Client) *RedisFrameStorage {
+	return &RedisFrameStorage{redisClient: redisClient}
+}
+
+func (s *RedisFrameStorage) Set(orgID int64, channel string, frame *data.Frame) error {
+	frameJSON, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisFrameStorageTimeout)
+	defer cancel()
+	return s.redisClient.Set(ctx, getFrameKey(orgID, channel), frameJSON, redisFrameStorageTTL).Err()
+}
+
+func (s *RedisFrameStorage) Get(orgID int64, channel string) (*data.Frame, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisFrameStorageTimeout)
+	defer cancel()
+	frameJSON, err := s.redisClient.Get(ctx, getFrameKey(orgID, channel)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	frame, err := unmarshalFrame(frameJSON)
+	if err != nil {
+		return nil, false, err
+	}
+	return frame, true, nil
+}
+
+func (s *RedisFrameStorage) AppendHistory(orgID int64, channel string, frame *data.Frame, size int) error {
+	if size <= 0 {
+		return nil
+	}
+	frameJSON, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	key := getHistoryKey(orgID, channel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisFrameStorageTimeout)
+	defer cancel()
+
+	pipe := s.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	pipe.RPush(ctx, key, frameJSON)
+	pipe.LTrim(ctx, key, int64(-size), -1)
+	pipe.Expire(ctx, key, redisFrameStorageTTL)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisFrameStorage) History(orgID int64, channel string, n int) ([]*data.Frame, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisFrameStorageTimeout)
+	defer cancel()
+	result, err := s.redisClient.LRange(ctx, getHistoryKey(orgID, channel), int64(-n), -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]*data.Frame, 0, len(result))
+	for _, frameJSON := range result {
+		frame, err := unmarshalFrame([]byte(frameJSON))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+func unmarshalFrame(frameJSON []byte) (*data.Frame, error) {
+	var frame data.Frame
+	if err := json.Unmarshal(frameJSON, &frame); err != nil {
+		return nil, err
+	}
+	return &frame, nil
+}
+
+func getFrameKey(orgID int64, channel string) string {
+	return "gf_live.pipeline.frame." + orgchannel.PrependOrgID(orgID, channel)
+}
+
+func getHistoryKey(orgID int64, channel string) string {
+	return "gf_live.pipeline.history." + orgchannel.PrependOrgID(orgID, channel)
+}
@@ -0,0 +1,25 @@
+//go:build redis
+// +build redis
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisFrameStorage(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	for _, key := range []string{
+		getFrameKey(1, "test/frame_storage/last"),
+		getHistoryKey(1, "test/frame_storage/history"),
+	} {
+		require.NoError(t, redisClient.Del(context.Background(), key).Err())
+	}
+	testFrameStorage(t, NewRedisFrameStorage(redisClient))
+}
@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func testFrameStorage(t *testing.T, s FrameStorage) {
+	// Unknown channel has no frame and no history.
+	_, ok, err := s.Get(1, "test/frame_storage/unknown")
+	require.NoError(t, err)
+	require.False(t, ok)
+	frames, err := s.History(1, "test/frame_storage/unknown", 10)
+	require.NoError(t, err)
+	require.Len(t, frames, 0)
+
+	// Make sure the last frame is saved per organization.
+	err = s.Set(1, "test/frame_storage/last", data.NewFrame("test", data.NewField("value", nil, []float64{1})))
+	require.NoError(t, err)
+	frame, ok, err := s.Get(1, "test/frame_storage/last")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1.0, frame.Fields[0].At(0))
+	_, ok, err = s.Get(2, "test/frame_storage/last")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Make sure history keeps only the configured number of last frames.
+	for i := 0; i < 5; i++ {
+		err = s.AppendHistory(1, "test/frame_storage/history", data.NewFrame("test", data.NewField("value", nil, []float64{float64(i)})), 3)
+		require.NoError(t, err)
+	}
+	frames, err = s.History(1, "test/frame_storage/history", 10)
+	require.NoError(t, err)
+	require.Len(t, frames, 3)
+	for i, f := range frames {
+		require.Equal(t, float64(i+2), f.Fields[0].At(0))
+	}
+
+	// Make sure only last n frames returned, oldest first.
+	frames, err = s.History(1, "test/frame_storage/history", 2)
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+	require.Equal(t, 3.0, frames[0].Fields[0].At(0))
+	require.Equal(t, 4.0, frames[1].Fields[0].At(0))
+
+	frames, err = s.History(2, "test/frame_storage/history", 10)
+	require.NoError(t, err)
+	require.Len(t, frames, 0)
+}
+
+func TestMemoryFrameStorage(t *testing.T) {
+	testFrameStorage(t, NewMemoryFrameStorage())
+}
@@ -0,0 +1,359 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+const (
+	messageBusConnectTimeout = 5 * time.Second
+	mqttPublishTimeout       = 2 * time.Second
+)
+
+// busMessage is a message published to a message bus.
+type busMessage struct {
+	// Topic is the Kafka topic, MQTT topic or NATS subject.
+	Topic   string
+	Key     string
+	Payload []byte
+	// QoS and Retained are only used by MQTT.
+	QoS      byte
+	Retained bool
+}
+
+// messagePublisher publishes messages to a message bus. Implementations connect
+// lazily on first publish, so that a broker being down does not prevent building rules.
+type messagePublisher interface {
+	Publish(ctx context.Context, msg busMessage) error
+	Close() error
+}
+
+// messagePublisherCache keeps a publisher for each write config, so that rebuilding
+// the channel rules does not open new connections if the write config did not change.
+// Publishers created while building the rules of an organization are pending until the
+// build succeeds, so that a failed build does not close the publishers of the current rules.
+type messagePublisherCache struct {
+	mu         sync.Mutex
+	publishers map[string]cachedPublisher
+	pending    map[int64]map[string]cachedPublisher
+}
+
+type cachedPublisher struct {
+	orgID     int64
+	endpoint  string
+	basicAuth BasicAuth
+	tls       TLSSettings
+	publisher messagePublisher
+}
+
+func (p cachedPublisher) sameConfig(other cachedPublisher) bool {
+	return p.endpoint == other.endpoint && p.basicAuth == other.basicAuth && p.tls == other.tls
+}
+
+func messagePublisherKey(outputType string, orgID int64, uid string) string {
+	return fmt.Sprintf("%s/%d/%s", outputType, orgID, uid)
+}
+
+// get returns the cached publisher for the write config, or a pending publisher created with create
+// if the write config is new or changed. Pending publishers are cached by commit and closed by discard.
+func (c *messagePublisherCache) get(outputType string, writeConfig WriteConfig, basicAuth *BasicAuth, create func() messagePublisher) messagePublisher {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := cachedPublisher{
+		orgID:    writeConfig.OrgId,
+		endpoint: writeConfig.Settings.Endpoint,
+	}
+	if basicAuth != nil {
+		entry.basicAuth = *basicAuth
+	}
+	if writeConfig.Settings.TLS != nil {
+		entry.tls = *writeConfig.Settings.TLS
+	}
+	key := messagePublisherKey(outputType, writeConfig.OrgId, writeConfig.UID)
+	if cached, ok := c.pending[entry.orgID][key]; ok {
+		if cached.sameConfig(entry) {
+			return cached.publisher
+		}
+		closePublisher(key, cached.publisher)
+	}
+	if cached, ok := c.publishers[key]; ok && cached.sameConfig(entry) {
+		return cached.publisher
+	}
+	if c.pending == nil {
+		c.pending = map[int64]map[string]cachedPublisher{}
+	}
+	if c.pending[entry.orgID] == nil {
+		c.pending[entry.orgID] = map[string]cachedPublisher{}
+	}
+	entry.publisher = create()
+	c.pending[entry.orgID][key] = entry
+	return entry.publisher
+}
+
+// commit caches the pending publishers of the organization, closing the publishers they replace, and
+// closes and removes the publishers of the organization that are not in keys, which are the publishers
+// used by the new channel rules of the organization. This closes the publishers of write configs that
+// were deleted or are no longer used. It is called once the new rules are built successfully.
+func (c *messagePublisherCache) commit(orgID int64, keys map[string]struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.publishers == nil {
+		c.publishers = map[string]cachedPublisher{}
+	}
+	for key, p := range c.pending[orgID] {
+		if cached, ok := c.publishers[key]; ok {
+			closePublisher(key, cached.publisher)
+		}
+		c.publishers[key] = p
+	}
+	delete(c.pending, orgID)
+	for key, cached := range c.publishers {
+		if cached.orgID != orgID {
+			continue
+		}
+		if _, ok := keys[key]; ok {
+			continue
+		}
+		closePublisher(key, cached.publisher)
+		delete(c.publishers, key)
+	}
+}
+
+// discard closes the pending publishers of the organization, after building its rules failed.
+// The cached publishers are still used by the current rules, so they are kept.
+func (c *messagePublisherCache) discard(orgID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, p := range c.pending[orgID] {
+		closePublisher(key, p.publisher)
+	}
+	delete(c.pending, orgID)
+}
+
+func closePublisher(key string, p messagePublisher) {
+	if err := p.Close(); err != nil {
+		logger.Error("Error closing message bus publisher", "key", key, "error", err)
+	}
+}
+
+// messageBusTLSConfig returns the TLS configuration for the settings, or nil if TLS is not enabled.
+func messageBusTLSConfig(settings *TLSSettings) (*tls.Config, error) {
+	if settings == nil {
+		return nil, nil
+	}
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: settings.InsecureSkipVerify, // #nosec G402 -- explicitly configured by the user.
+	}
+	if settings.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(settings.CACert)) {
+			return nil, errors.New("invalid CA certificate")
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// mqttTLSSchemes are the schemes of MQTT endpoints that the MQTT client connects to with TLS.
+var mqttTLSSchemes = map[string]bool{"ssl": true, "tls": true, "mqtts": true, "tcps": true, "wss": true}
+
+// validateMessageBusSettings checks that credentials are only sent over TLS.
+func validateMessageBusSettings(outputType string, settings WriteSettings, basicAuth *BasicAuth) error {
+	if basicAuth != nil && settings.TLS == nil {
+		return fmt.Errorf("%s output with basic auth requires TLS", outputType)
+	}
+	if outputType == FrameOutputTypeMQTT && settings.TLS != nil {
+		u, err := url.Parse(settings.Endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid MQTT endpoint: %w", err)
+		}
+		if !mqttTLSSchemes[u.Scheme] {
+			return fmt.Errorf("MQTT output with TLS requires an ssl:// endpoint, got %s://", u.Scheme)
+		}
+	}
+	return nil
+}
+
+// dotTopic returns the channel with / replaced by ., as used by default for Kafka topics and NATS subjects.
+func dotTopic(channel string) string {
+	return strings.ReplaceAll(channel, "/", ".")
+}
+
+// kafkaPublisher writes messages asynchronously to the Kafka brokers in the comma-separated endpoint.
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func newKafkaPublisher(endpoint string, basicAuth *BasicAuth, tlsConfig *tls.Config) *kafkaPublisher {
+	transport := &kafka.Transport{
+		DialTimeout: messageBusConnectTimeout,
+		TLS:         tlsConfig,
+	}
+	if basicAuth != nil {
+		transport.SASL = plain.Mechanism{
+			Username: basicAuth.User,
+			Password: basicAuth.Password,
+		}
+	}
+	return &kafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(strings.Split(endpoint, ",")...),
+			Balancer:     &kafka.Hash{},
+			Transport:    transport,
+			Async:        true,
+			BatchTimeout: 100 * time.Millisecond,
+			Completion: func(messages []kafka.Message, err error) {
+				if err != nil {
+					logger.Error("Error writing to Kafka", "numMessages", len(messages), "error", err)
+				}
+			},
+		},
+	}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, msg busMessage) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: msg.Topic,
+		Key:   []byte(msg.Key),
+		Value: msg.Payload,
+	})
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// mqttPublisher publishes messages to the MQTT broker at endpoint, for example tcp://localhost:1883.
+type mqttPublisher struct {
+	mu      sync.Mutex
+	options *mqtt.ClientOptions
+	client  mqtt.Client
+}
+
+func newMQTTPublisher(endpoint string, basicAuth *BasicAuth, tlsConfig *tls.Config, clientID string) *mqttPublisher {
+	options := mqtt.NewClientOptions().
+		AddBroker(endpoint).
+		SetClientID(clientID).
+		SetConnectTimeout(messageBusConnectTimeout).
+		SetAutoReconnect(true)
+	if tlsConfig != nil {
+		options.SetTLSConfig(tlsConfig)
+	}
+	if basicAuth != nil {
+		options.SetUsername(basicAuth.User).SetPassword(basicAuth.Password)
+	}
+	return &mqttPublisher{options: options}
+}
+
+func (p *mqttPublisher) connect() (mqtt.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return p.client, nil
+	}
+	client := mqtt.NewClient(p.options)
+	token := client.Connect()
+	if !token.WaitTimeout(messageBusConnectTimeout) {
+		return nil, errors.New("timeout connecting to MQTT broker")
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("error connecting to MQTT broker: %w", err)
+	}
+	p.client = client
+	return client, nil
+}
+
+func (p *mqttPublisher) Publish(_ context.Context, msg busMessage) error {
+	client, err := p.connect()
+	if err != nil {
+		return err
+	}
+	token := client.Publish(msg.Topic, msg.QoS, msg.Retained, msg.Payload)
+	if msg.QoS == 0 {
+		// at most once delivery, there is no acknowledgement to wait for.
+		return nil
+	}
+	if !token.WaitTimeout(mqttPublishTimeout) {
+		return errors.New("timeout publishing to MQTT broker")
+	}
+	return token.Error()
+}
+
+func (p *mqttPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		p.client.Disconnect(250)
+		p.client = nil
+	}
+	return nil
+}
+
+// natsPublisher publishes messages to the NATS servers at endpoint, for example nats://localhost:4222.
+type natsPublisher struct {
+	mu        sync.Mutex
+	endpoint  string
+	basicAuth *BasicAuth
+	tlsConfig *tls.Config
+	conn      *nats.Conn
+}
+
+func newNATSPublisher(endpoint string, basicAuth *BasicAuth, tlsConfig *tls.Config) *natsPublisher {
+	return &natsPublisher{endpoint: endpoint, basicAuth: basicAuth, tlsConfig: tlsConfig}
+}
+
+func (p *natsPublisher) connect() (*nats.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil {
+		return p.conn, nil
+	}
+	options := []nats.Option{
+		nats.Name("grafana-live"),
+		nats.Timeout(messageBusConnectTimeout),
+		nats.MaxReconnects(-1),
+	}
+	if p.basicAuth != nil {
+		options = append(options, nats.UserInfo(p.basicAuth.User, p.basicAuth.Password))
+	}
+	if p.tlsConfig != nil {
+		options = append(options, nats.Secure(p.tlsConfig))
+	}
+	conn, err := nats.Connect(p.endpoint, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to NATS: %w", err)
+	}
+	p.conn = conn
+	return conn, nil
+}
+
+func (p *natsPublisher) Publish(_ context.Context, msg busMessage) error {
+	conn, err := p.connect()
+	if err != nil {
+		return err
+	}
+	return conn.Publish(msg.Topic, msg.Payload)
+}
+
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+	return nil
+}
@@ -59,6 +59,7 @@ func WriteConfigToDto(b WriteConfig) WriteConfigDto {
 		UID:          b.UID,
 		Settings:     b.Settings,
 		SecureFields: secureFields,
+		Version:      b.Version,
 	}
 }
 
@@ -66,6 +67,7 @@ type WriteConfigDto struct {
 	UID          string          `json:"uid"`
 	Settings     WriteSettings   `json:"settings"`
 	SecureFields map[string]bool `json:"secureFields"`
+	Version      int64           `json:"version,omitempty"`
 }
 
 type WriteConfigGetCmd struct {
@@ -78,11 +80,13 @@ type WriteConfigCreateCmd struct {
 	SecureSettings map[string]string `json:"secureSettings"`
 }
 
-// TODO: add version field later.
 type WriteConfigUpdateCmd struct {
 	UID            string            `json:"uid"`
 	Settings       WriteSettings     `json:"settings"`
 	SecureSettings map[string]string `json:"secureSettings"`
+	// Version is the version of the write config the update is based on. If set, storages that support
+	// optimistic versioning reject the update with ErrVersionConflict when the write config was changed since.
+	Version int64 `json:"version,omitempty"`
 }
 
 type WriteConfigDeleteCmd struct {
@@ -94,6 +98,7 @@ type WriteConfig struct {
 	UID            string            `json:"uid"`
 	Settings       WriteSettings     `json:"settings"`
 	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
+	Version        int64             `json:"version,omitempty"`
 }
 
 func (r WriteConfig) Valid() (bool, string) {
@@ -119,6 +124,17 @@ type WriteSettings struct {
 	Endpoint string `json:"endpoint"`
 	// BasicAuth is an optional basic auth settings.
 	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+	// TLS enables TLS for Kafka, MQTT and NATS outputs, which require it to use basic auth.
+	// Remote write and Loki outputs use TLS if the endpoint is an https URL.
+	TLS *TLSSettings `json:"tls,omitempty"`
+}
+
+type TLSSettings struct {
+	// CACert is an optional PEM encoded CA certificate to verify the server certificate with.
+	// The system CA certificates are used by default.
+	CACert string `json:"caCert,omitempty"`
+	// InsecureSkipVerify disables the verification of the server certificate.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 type WriteConfigs struct {
@@ -154,6 +170,9 @@ type ChannelRuleCreateCmd struct {
 type ChannelRuleUpdateCmd struct {
 	Pattern  string              `json:"pattern"`
 	Settings ChannelRuleSettings `json:"settings"`
+	// Version is the version of the channel rule the update is based on. If set, storages that support
+	// optimistic versioning reject the update with ErrVersionConflict when the channel rule was changed since.
+	Version int64 `json:"version,omitempty"`
 }
 
 type ChannelRuleDeleteCmd struct {
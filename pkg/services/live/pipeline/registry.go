@@ -15,6 +15,11 @@ var SubscribersRegistry = []EntityInfo{
 		Type:        SubscriberTypeManagedStream,
 		Description: "apply managed stream subscribe logic",
 	},
+	{
+		Type:        SubscriberTypeHistory,
+		Description: "send last frames kept by history output on subscribe (the number can be requested with {\"history\": N} subscribe data)",
+		Example:     HistorySubscriberConfig{},
+	},
 }
 
 var FrameOutputsRegistry = []EntityInfo{
@@ -40,6 +45,11 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeChangeLog,
 		Description: "output field changes into new channel",
 	},
+	{
+		Type:        FrameOutputTypeHistory,
+		Description: "keep last frames of the channel to replay them to new subscribers (note this also requires a matching subscriber)",
+		Example:     HistoryOutputConfig{},
+	},
 	{
 		Type:        FrameOutputTypeRemoteWrite,
 		Description: "output to remote write endpoint",
@@ -48,6 +58,21 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeKafka,
+		Description: "output frame as JSON to a Kafka topic (the channel with / replaced by . by default)",
+		Example:     KafkaOutputConfig{},
+	},
+	{
+		Type:        FrameOutputTypeMQTT,
+		Description: "output frame as JSON to an MQTT topic (the channel by default)",
+		Example:     MQTTOutputConfig{},
+	},
+	{
+		Type:        FrameOutputTypeNATS,
+		Description: "output frame as JSON to a NATS subject (the channel with / replaced by . by default)",
+		Example:     NATSOutputConfig{},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -66,6 +91,17 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypePrometheus,
+		Description: "accept Prometheus text exposition format",
+	},
+	{
+		Type:        ConverterTypeOTLP,
+		Description: "accept OTLP metrics export requests (protobuf or JSON encoded)",
+		Example: OTLPConverterConfig{
+			Encoding: "protobuf",
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -79,6 +115,16 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeWindowAggregate,
+		Description: "aggregate field values over tumbling or sliding time windows",
+		Example: WindowAggregateFrameProcessorConfig{
+			WindowMilliseconds: 1000,
+			Fields: []WindowAggregateFieldConfig{
+				{FieldName: "value", Reducers: []string{WindowReducerMean, WindowReducerMax}},
+			},
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
@@ -94,4 +140,19 @@ var DataOutputsRegistry = []EntityInfo{
 		Type:        DataOutputTypeLoki,
 		Description: "output data to Loki as logs",
 	},
+	{
+		Type:        DataOutputTypeKafka,
+		Description: "output data to a Kafka topic (the channel with / replaced by . by default)",
+		Example:     KafkaOutputConfig{},
+	},
+	{
+		Type:        DataOutputTypeMQTT,
+		Description: "output data to an MQTT topic (the channel by default)",
+		Example:     MQTTOutputConfig{},
+	},
+	{
+		Type:        DataOutputTypeNATS,
+		Description: "output data to a NATS subject (the channel with / replaced by . by default)",
+		Example:     NATSOutputConfig{},
+	},
 }
@@ -13,10 +13,16 @@ import (
 type StorageRuleBuilder struct {
 	Node                 *centrifuge.Node
 	ManagedStream        *managedstream.Runner
-	FrameStorage         *FrameStorage
+	FrameStorage         FrameStorage
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	// InstanceName identifies this Grafana instance in connections to message buses,
+	// for example in MQTT client IDs, which must be unique per broker.
+	InstanceName string
+
+	publishers   messagePublisherCache
+	windowStates windowAggregateStateStore
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -43,6 +49,14 @@ func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscr
 			subscribers = append(subscribers, sub)
 		}
 		return NewMultipleSubscriber(subscribers...), nil
+	case SubscriberTypeHistory:
+		if config.HistorySubscriberConfig == nil {
+			return nil, missingConfiguration
+		}
+		if config.HistorySubscriberConfig.MaxFrames <= 0 || config.HistorySubscriberConfig.MaxFrames > maxHistorySize {
+			return nil, fmt.Errorf("history subscriber maxFrames must be between 1 and %d", maxHistorySize)
+		}
+		return NewHistorySubscriber(f.FrameStorage, *config.HistorySubscriberConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown subscriber type: %s", config.Type)
 	}
@@ -69,6 +83,21 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypePrometheus:
+		if config.PrometheusConverterConfig == nil {
+			config.PrometheusConverterConfig = &PrometheusConverterConfig{}
+		}
+		return NewPrometheusConverter(*config.PrometheusConverterConfig), nil
+	case ConverterTypeOTLP:
+		if config.OTLPConverterConfig == nil {
+			config.OTLPConverterConfig = &OTLPConverterConfig{}
+		}
+		switch config.OTLPConverterConfig.Encoding {
+		case "", "protobuf", "json":
+		default:
+			return nil, fmt.Errorf("unknown otlp converter encoding: %s", config.OTLPConverterConfig.Encoding)
+		}
+		return NewOTLPConverter(*config.OTLPConverterConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
@@ -104,6 +133,14 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeWindowAggregate:
+		if config.WindowAggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		if err := validateWindowAggregateConfig(*config.WindowAggregateProcessorConfig); err != nil {
+			return nil, fmt.Errorf("invalid %s configuration: %w", config.Type, err)
+		}
+		return newWindowAggregateFrameProcessor(&f.windowStates, *config.WindowAggregateProcessorConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -164,6 +201,38 @@ func (f *StorageRuleBuilder) constructBasicAuth(writeConfig WriteConfig) (*Basic
 	}, nil
 }
 
+// getMessagePublisher returns the publisher for the write config with the given uid,
+// reusing the publisher built for a previous set of rules if the write config did not change.
+func (f *StorageRuleBuilder) getMessagePublisher(outputType string, uid string, writeConfigs []WriteConfig) (messagePublisher, error) {
+	writeConfig, ok := f.getWriteConfig(uid, writeConfigs)
+	if !ok {
+		return nil, fmt.Errorf("unknown %s write config uid: %s", outputType, uid)
+	}
+	basicAuth, err := f.constructBasicAuth(writeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error constructing basicAuth: %w", err)
+	}
+	if err := validateMessageBusSettings(outputType, writeConfig.Settings, basicAuth); err != nil {
+		return nil, fmt.Errorf("invalid write config %s: %w", uid, err)
+	}
+	tlsConfig, err := messageBusTLSConfig(writeConfig.Settings.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings of write config %s: %w", uid, err)
+	}
+	return f.publishers.get(outputType, writeConfig, basicAuth, func() messagePublisher {
+		// frame and data outputs to the same message bus have the same type.
+		switch outputType {
+		case FrameOutputTypeKafka:
+			return newKafkaPublisher(writeConfig.Settings.Endpoint, basicAuth, tlsConfig)
+		case FrameOutputTypeMQTT:
+			clientID := fmt.Sprintf("grafana-live-%s-%d-%s", f.InstanceName, writeConfig.OrgId, writeConfig.UID)
+			return newMQTTPublisher(writeConfig.Settings.Endpoint, basicAuth, tlsConfig, clientID)
+		default:
+			return newNATSPublisher(writeConfig.Settings.Endpoint, basicAuth, tlsConfig)
+		}
+	}), nil
+}
+
 func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig, writeConfigs []WriteConfig) (FrameOutputter, error) {
 	if config == nil {
 		return nil, nil
@@ -249,6 +318,41 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeHistory:
+		if config.HistoryOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		if config.HistoryOutputConfig.Size <= 0 || config.HistoryOutputConfig.Size > maxHistorySize {
+			return nil, fmt.Errorf("history output size must be between 1 and %d", maxHistorySize)
+		}
+		return NewHistoryFrameOutput(f.FrameStorage, *config.HistoryOutputConfig), nil
+	case FrameOutputTypeKafka:
+		if config.KafkaOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		publisher, err := f.getMessagePublisher(config.Type, config.KafkaOutputConfig.UID, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewKafkaFrameOutput(publisher, *config.KafkaOutputConfig), nil
+	case FrameOutputTypeMQTT:
+		if config.MQTTOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		publisher, err := f.getMessagePublisher(config.Type, config.MQTTOutputConfig.UID, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewMQTTFrameOutput(publisher, *config.MQTTOutputConfig), nil
+	case FrameOutputTypeNATS:
+		if config.NATSOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		publisher, err := f.getMessagePublisher(config.Type, config.NATSOutputConfig.UID, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewNATSFrameOutput(publisher, *config.NATSOutputConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
@@ -281,6 +385,33 @@ func (f *StorageRuleBuilder) extractDataOutputter(config *DataOutputterConfig, w
 			writeConfig.Settings.Endpoint,
 			basicAuth,
 		), nil
+	case DataOutputTypeKafka:
+		if config.KafkaOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		publisher, err := f.getMessagePublisher(config.Type, config.KafkaOutputConfig.UID, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewKafkaDataOutput(publisher, *config.KafkaOutputConfig), nil
+	case DataOutputTypeMQTT:
+		if config.MQTTOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		publisher, err := f.getMessagePublisher(config.Type, config.MQTTOutputConfig.UID, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewMQTTDataOutput(publisher, *config.MQTTOutputConfig), nil
+	case DataOutputTypeNATS:
+		if config.NATSOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		publisher, err := f.getMessagePublisher(config.Type, config.NATSOutputConfig.UID, writeConfigs)
+		if err != nil {
+			return nil, err
+		}
+		return NewNATSDataOutput(publisher, *config.NATSOutputConfig), nil
 	case DataOutputTypeBuiltin:
 		return NewBuiltinDataOutput(f.ChannelHandlerGetter), nil
 	case DataOutputTypeLocalSubscribers:
@@ -310,6 +441,17 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		return nil, err
 	}
 
+	rules, err := f.buildChannelRules(orgID, channelRules, writeConfigs)
+	if err != nil {
+		// the current rules still use the cached publishers.
+		f.publishers.discard(orgID)
+		return nil, err
+	}
+	f.publishers.commit(orgID, messagePublisherKeys(orgID, channelRules))
+	return rules, nil
+}
+
+func (f *StorageRuleBuilder) buildChannelRules(orgID int64, channelRules []ChannelRule, writeConfigs []WriteConfig) ([]*LiveChannelRule, error) {
 	rules := make([]*LiveChannelRule, 0, len(channelRules))
 
 	for _, ruleConfig := range channelRules {
@@ -375,6 +517,50 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 
 		rules = append(rules, rule)
 	}
-
 	return rules, nil
 }
+
+// messagePublisherKeys returns the keys of the message bus publishers that the outputs of the channel rules use.
+func messagePublisherKeys(orgID int64, channelRules []ChannelRule) map[string]struct{} {
+	keys := map[string]struct{}{}
+	add := func(outputType string, uid string) {
+		keys[messagePublisherKey(outputType, orgID, uid)] = struct{}{}
+	}
+	var addFrameOutputter func(config *FrameOutputterConfig)
+	addFrameOutputter = func(config *FrameOutputterConfig) {
+		if config == nil {
+			return
+		}
+		switch {
+		case config.KafkaOutputConfig != nil:
+			add(config.Type, config.KafkaOutputConfig.UID)
+		case config.MQTTOutputConfig != nil:
+			add(config.Type, config.MQTTOutputConfig.UID)
+		case config.NATSOutputConfig != nil:
+			add(config.Type, config.NATSOutputConfig.UID)
+		case config.MultipleOutputterConfig != nil:
+			for i := range config.MultipleOutputterConfig.Outputters {
+				addFrameOutputter(&config.MultipleOutputterConfig.Outputters[i])
+			}
+		case config.ConditionalOutputConfig != nil:
+			addFrameOutputter(config.ConditionalOutputConfig.Outputter)
+		}
+	}
+	for _, rule := range channelRules {
+		for _, config := range rule.Settings.DataOutputters {
+			switch {
+			case config == nil:
+			case config.KafkaOutputConfig != nil:
+				add(config.Type, config.KafkaOutputConfig.UID)
+			case config.MQTTOutputConfig != nil:
+				add(config.Type, config.MQTTOutputConfig.UID)
+			case config.NATSOutputConfig != nil:
+				add(config.Type, config.NATSOutputConfig.UID)
+			}
+		}
+		for _, config := range rule.Settings.FrameOutputters {
+			addFrameOutputter(config)
+		}
+	}
+	return keys
+}
@@ -9,16 +9,24 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
 )
 
+// maxFillAttempts is how many times Get builds the channel rules of an org when
+// they are invalidated while building.
+const maxFillAttempts = 3
+
 // CacheSegmentedTree provides a fast access to channel rule configuration.
 type CacheSegmentedTree struct {
-	radixMu     sync.RWMutex
-	radix       map[int64]*tree.Node
+	radixMu sync.RWMutex
+	radix   map[int64]*tree.Node
+	// generations are incremented when the channel rules of an org are invalidated,
+	// so that rules built concurrently from outdated storage data are discarded.
+	generations map[int64]uint64
 	ruleBuilder RuleBuilder
 }
 
 func NewCacheSegmentedTree(storage RuleBuilder) *CacheSegmentedTree {
 	s := &CacheSegmentedTree{
 		radix:       map[int64]*tree.Node{},
+		generations: map[int64]uint64{},
 		ruleBuilder: storage,
 	}
 	go s.updatePeriodically()
@@ -34,7 +42,7 @@ func (s *CacheSegmentedTree) updatePeriodically() {
 		}
 		s.radixMu.Unlock()
 		for _, orgID := range orgIDs {
-			err := s.fillOrg(orgID)
+			_, _, err := s.fillOrg(orgID)
 			if err != nil {
 				logger.Error("error filling orgId", "error", err, "orgId", orgID)
 			}
@@ -43,38 +51,69 @@ func (s *CacheSegmentedTree) updatePeriodically() {
 	}
 }
 
-func (s *CacheSegmentedTree) fillOrg(orgID int64) error {
+// fillOrg builds the channel rules of an org and caches them, unless they were
+// invalidated while building. It returns the built rules and whether they were cached.
+func (s *CacheSegmentedTree) fillOrg(orgID int64) (*tree.Node, bool, error) {
+	s.radixMu.RLock()
+	generation := s.generations[orgID]
+	s.radixMu.RUnlock()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	channels, err := s.ruleBuilder.BuildRules(ctx, orgID)
 	if err != nil {
-		return err
+		return nil, false, err
+	}
+	t := tree.New()
+	for _, ch := range channels {
+		t.AddRoute("/"+ch.Pattern, ch)
 	}
 	s.radixMu.Lock()
 	defer s.radixMu.Unlock()
-	s.radix[orgID] = tree.New()
-	for _, ch := range channels {
-		s.radix[orgID].AddRoute("/"+ch.Pattern, ch)
+	if s.generations[orgID] != generation {
+		// Invalidated while building, the rules may be built from outdated storage data.
+		return t, false, nil
 	}
-	return nil
+	s.radix[orgID] = t
+	return t, true, nil
+}
+
+// fillOrgRetrying fills the channel rules of an org, building them again if they are
+// invalidated while building. If they are invalidated during every attempt, the rules
+// built last are returned without caching them: they were built after Get was called,
+// so they are at least as recent as the storage data at that time.
+func (s *CacheSegmentedTree) fillOrgRetrying(orgID int64) (*tree.Node, error) {
+	for attempt := 1; ; attempt++ {
+		t, cached, err := s.fillOrg(orgID)
+		if err != nil {
+			return nil, err
+		}
+		if cached || attempt == maxFillAttempts {
+			return t, nil
+		}
+	}
+}
+
+// Invalidate drops the channel rules of an org, so that they are built again
+// from storage on next access.
+func (s *CacheSegmentedTree) Invalidate(orgID int64) {
+	s.radixMu.Lock()
+	defer s.radixMu.Unlock()
+	delete(s.radix, orgID)
+	s.generations[orgID]++
 }
 
 func (s *CacheSegmentedTree) Get(orgID int64, channel string) (*LiveChannelRule, bool, error) {
 	s.radixMu.RLock()
-	_, ok := s.radix[orgID]
+	t, ok := s.radix[orgID]
 	s.radixMu.RUnlock()
 	if !ok {
-		err := s.fillOrg(orgID)
+		var err error
+		t, err = s.fillOrgRetrying(orgID)
 		if err != nil {
 			return nil, false, fmt.Errorf("error filling org: %w", err)
 		}
 	}
-	s.radixMu.RLock()
-	defer s.radixMu.RUnlock()
-	t, ok := s.radix[orgID]
-	if !ok {
-		return nil, false, nil
-	}
 	nodeValue := t.GetValue("/"+channel, true)
 	if nodeValue.Handler == nil {
 		return nil, false, nil
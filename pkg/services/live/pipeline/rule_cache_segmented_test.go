@@ -4,7 +4,10 @@ import (
 	"context"
 	"testing"
 
+	"github.com/centrifugal/centrifuge"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
 )
 
 type testBuilder struct{}
@@ -53,6 +56,105 @@ func TestStorage_Get(t *testing.T) {
 	require.Equal(t, "stream/boom:er", rule.Pattern)
 }
 
+type countingBuilder struct {
+	patterns []string
+	calls    int
+}
+
+func (b *countingBuilder) BuildRules(_ context.Context, orgID int64) ([]*LiveChannelRule, error) {
+	b.calls++
+	rules := make([]*LiveChannelRule, 0, len(b.patterns))
+	for _, p := range b.patterns {
+		rules = append(rules, &LiveChannelRule{OrgId: orgID, Pattern: p})
+	}
+	return rules, nil
+}
+
+func TestStorage_InvalidateOnStorageChange(t *testing.T) {
+	node, err := centrifuge.New(centrifuge.Config{})
+	require.NoError(t, err)
+	require.NoError(t, node.Run())
+	t.Cleanup(func() { _ = node.Shutdown(context.Background()) })
+	builder := &countingBuilder{patterns: []string{"stream/test/cpu"}}
+	s := NewCacheSegmentedTree(builder)
+	notifier := NewNodeStorageChangeNotifier(node, s)
+
+	_, ok, err := s.Get(1, "stream/test/mem")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, 1, builder.calls)
+
+	builder.patterns = append(builder.patterns, "stream/test/mem")
+	require.NoError(t, notifier.NotifyStorageChange(1))
+
+	rule, ok, err := s.Get(1, "stream/test/mem")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "stream/test/mem", rule.Pattern)
+	require.Equal(t, 2, builder.calls)
+}
+
+// invalidatingBuilder invalidates the channel rules while building them, for the first invalidations calls.
+type invalidatingBuilder struct {
+	countingBuilder
+	cache         *CacheSegmentedTree
+	invalidations int
+}
+
+func (b *invalidatingBuilder) BuildRules(ctx context.Context, orgID int64) ([]*LiveChannelRule, error) {
+	if b.calls < b.invalidations {
+		b.cache.Invalidate(orgID)
+	}
+	return b.countingBuilder.BuildRules(ctx, orgID)
+}
+
+// newTestCacheSegmentedTree returns a cache that does not update the rules periodically.
+func newTestCacheSegmentedTree(builder RuleBuilder) *CacheSegmentedTree {
+	return &CacheSegmentedTree{
+		radix:       map[int64]*tree.Node{},
+		generations: map[int64]uint64{},
+		ruleBuilder: builder,
+	}
+}
+
+func TestStorage_InvalidateDuringFill(t *testing.T) {
+	t.Run("should build the rules again", func(t *testing.T) {
+		builder := &invalidatingBuilder{countingBuilder: countingBuilder{patterns: []string{"stream/test/cpu"}}, invalidations: 1}
+		s := newTestCacheSegmentedTree(builder)
+		builder.cache = s
+
+		rule, ok, err := s.Get(1, "stream/test/cpu")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "stream/test/cpu", rule.Pattern)
+		require.Equal(t, 2, builder.calls)
+
+		// the rules built again are cached
+		_, ok, err = s.Get(1, "stream/test/cpu")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 2, builder.calls)
+	})
+
+	t.Run("should use the rules built last if always invalidated", func(t *testing.T) {
+		builder := &invalidatingBuilder{countingBuilder: countingBuilder{patterns: []string{"stream/test/cpu"}}, invalidations: maxFillAttempts}
+		s := newTestCacheSegmentedTree(builder)
+		builder.cache = s
+
+		rule, ok, err := s.Get(1, "stream/test/cpu")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "stream/test/cpu", rule.Pattern)
+		require.Equal(t, maxFillAttempts, builder.calls)
+
+		// the rules were not cached, they are built on next access
+		_, ok, err = s.Get(1, "stream/test/cpu")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, maxFillAttempts+1, builder.calls)
+	})
+}
+
 func BenchmarkRuleGet(b *testing.B) {
 	s := NewCacheSegmentedTree(&testBuilder{})
 	for i := 0; i < b.N; i++ {
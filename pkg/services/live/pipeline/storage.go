@@ -1,6 +1,19 @@
 package pipeline
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrVersionConflict is returned by storages that support optimistic versioning when an
+// update is based on a version of an entity that is not the current one.
+var ErrVersionConflict = errors.New("version conflict: entity was changed since it was read")
+
+// StorageChangeNotifier is notified when the channel rules or write configs of an
+// organization were changed in Storage.
+type StorageChangeNotifier interface {
+	NotifyStorageChange(orgID int64) error
+}
 
 // Storage describes all methods to manage Live pipeline persistent data.
 type Storage interface {
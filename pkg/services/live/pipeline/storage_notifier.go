@@ -0,0 +1,55 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+const storageChangeNotificationOp = "pipeline_storage_change"
+
+type storageChangeNotification struct {
+	OrgID int64 `json:"orgId"`
+}
+
+// NodeStorageChangeNotifier sends storage changes to all Grafana Live nodes over
+// the Centrifuge control channel, which goes through the HA engine if it is
+// configured. Every node, including the current one, invalidates the cached
+// channel rules of the organization when it receives the notification.
+type NodeStorageChangeNotifier struct {
+	node  *centrifuge.Node
+	cache *CacheSegmentedTree
+}
+
+// NewNodeStorageChangeNotifier creates NodeStorageChangeNotifier and registers it
+// as the notification handler of the node.
+func NewNodeStorageChangeNotifier(node *centrifuge.Node, cache *CacheSegmentedTree) *NodeStorageChangeNotifier {
+	n := &NodeStorageChangeNotifier{
+		node:  node,
+		cache: cache,
+	}
+	node.OnNotification(n.handleNotification)
+	return n
+}
+
+func (n *NodeStorageChangeNotifier) NotifyStorageChange(orgID int64) error {
+	data, err := json.Marshal(storageChangeNotification{OrgID: orgID})
+	if err != nil {
+		return fmt.Errorf("can't marshal storage change notification: %w", err)
+	}
+	return n.node.Notify(storageChangeNotificationOp, data, "")
+}
+
+func (n *NodeStorageChangeNotifier) handleNotification(e centrifuge.NotificationEvent) {
+	if e.Op != storageChangeNotificationOp {
+		return
+	}
+	var notification storageChangeNotification
+	if err := json.Unmarshal(e.Data, &notification); err != nil {
+		logger.Error("Error decoding storage change notification", "error", err, "fromNodeId", e.FromNodeID)
+		return
+	}
+	logger.Debug("Invalidating channel rules on storage change", "orgId", notification.OrgID, "fromNodeId", e.FromNodeID)
+	n.cache.Invalidate(notification.OrgID)
+}
@@ -0,0 +1,407 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SQLStorage keeps channel rules and write configs in the Grafana database, so that
+// all Grafana instances of an HA setup share them. Updates are versioned: an update
+// that is based on an outdated version is rejected with ErrVersionConflict.
+type SQLStorage struct {
+	SQLStore       db.DB
+	SecretsService secrets.Service
+	// ChangeNotifier is notified after the channel rules or write configs of an
+	// organization were changed. Optional.
+	ChangeNotifier StorageChangeNotifier
+}
+
+type channelRuleEntity struct {
+	ID       int64 `xorm:"pk autoincr 'id'"`
+	OrgID    int64 `xorm:"org_id"`
+	Pattern  string
+	Settings string
+	Version  int64
+	Created  time.Time
+	Updated  time.Time
+}
+
+func (e *channelRuleEntity) TableName() string {
+	return "live_channel_rule"
+}
+
+func (e *channelRuleEntity) toChannelRule() (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:   e.OrgID,
+		Pattern: e.Pattern,
+		Version: e.Version,
+	}
+	if err := json.Unmarshal([]byte(e.Settings), &rule.Settings); err != nil {
+		return ChannelRule{}, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", e.Pattern, err)
+	}
+	return rule, nil
+}
+
+type writeConfigEntity struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	OrgID          int64  `xorm:"org_id"`
+	UID            string `xorm:"uid"`
+	Settings       string
+	SecureSettings string
+	Version        int64
+	Created        time.Time
+	Updated        time.Time
+}
+
+func (e *writeConfigEntity) TableName() string {
+	return "live_write_config"
+}
+
+func (e *writeConfigEntity) toWriteConfig() (WriteConfig, error) {
+	writeConfig := WriteConfig{
+		OrgId:   e.OrgID,
+		UID:     e.UID,
+		Version: e.Version,
+	}
+	if err := json.Unmarshal([]byte(e.Settings), &writeConfig.Settings); err != nil {
+		return WriteConfig{}, fmt.Errorf("can't unmarshal settings of write config %s: %w", e.UID, err)
+	}
+	if e.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(e.SecureSettings), &writeConfig.SecureSettings); err != nil {
+			return WriteConfig{}, fmt.Errorf("can't unmarshal secure settings of write config %s: %w", e.UID, err)
+		}
+	}
+	return writeConfig, nil
+}
+
+func (s *SQLStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]WriteConfig, error) {
+	var entities []writeConfigEntity
+	err := s.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("uid").Find(&entities)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list write configs: %w", err)
+	}
+	writeConfigs := make([]WriteConfig, 0, len(entities))
+	for _, e := range entities {
+		writeConfig, err := e.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		writeConfigs = append(writeConfigs, writeConfig)
+	}
+	return writeConfigs, nil
+}
+
+func (s *SQLStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigGetCmd) (WriteConfig, bool, error) {
+	var entity writeConfigEntity
+	var exists bool
+	err := s.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&entity)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, false, fmt.Errorf("can't get write config: %w", err)
+	}
+	if !exists {
+		return WriteConfig{}, false, nil
+	}
+	writeConfig, err := entity.toWriteConfig()
+	return writeConfig, err == nil, err
+}
+
+func (s *SQLStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	writeConfig, entity, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Exist(&writeConfigEntity{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("backend already exists in org: %s", cmd.UID)
+		}
+		entity.Version = 1
+		entity.Created = entity.Updated
+		_, err = sess.Insert(entity)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, fmt.Errorf("can't create write config: %w", err)
+	}
+	s.notifyChange(orgID)
+	writeConfig.Version = entity.Version
+	return writeConfig, nil
+}
+
+func (s *SQLStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	writeConfig, entity, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing writeConfigEntity
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			// Same as in FileStorage, updating a write config that does not exist creates it.
+			if cmd.Version != 0 {
+				return ErrVersionConflict
+			}
+			entity.Version = 1
+			entity.Created = entity.Updated
+			_, err = sess.Insert(entity)
+			return err
+		}
+		if cmd.Version != 0 && cmd.Version != existing.Version {
+			return ErrVersionConflict
+		}
+		entity.Version = existing.Version + 1
+		return updateVersioned(sess, entity, existing.ID, existing.Version, "settings", "secure_settings")
+	})
+	if err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return WriteConfig{}, err
+		}
+		return WriteConfig{}, fmt.Errorf("can't update write config: %w", err)
+	}
+	s.notifyChange(orgID)
+	writeConfig.Version = entity.Version
+	return writeConfig, nil
+}
+
+func (s *SQLStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	err := s.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Delete(&writeConfigEntity{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return errors.New("write config not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete write config: %w", err)
+	}
+	s.notifyChange(orgID)
+	return nil
+}
+
+// newWriteConfig encrypts the secure settings and validates the write config.
+func (s *SQLStorage) newWriteConfig(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string) (WriteConfig, *writeConfigEntity, error) {
+	encrypted, err := s.SecretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return WriteConfig{}, nil, fmt.Errorf("error encrypting data: %w", err)
+	}
+	writeConfig := WriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       settings,
+		SecureSettings: encrypted,
+	}
+	ok, reason := writeConfig.Valid()
+	if !ok {
+		return WriteConfig{}, nil, fmt.Errorf("invalid write config: %s", reason)
+	}
+	settingsJSON, err := json.Marshal(writeConfig.Settings)
+	if err != nil {
+		return WriteConfig{}, nil, fmt.Errorf("can't marshal write config settings: %w", err)
+	}
+	secureSettingsJSON, err := json.Marshal(writeConfig.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, nil, fmt.Errorf("can't marshal write config secure settings: %w", err)
+	}
+	return writeConfig, &writeConfigEntity{
+		OrgID:          orgID,
+		UID:            uid,
+		Settings:       string(settingsJSON),
+		SecureSettings: string(secureSettingsJSON),
+		Updated:        time.Now(),
+	}, nil
+}
+
+func (s *SQLStorage) ListChannelRules(ctx context.Context, orgID int64) ([]ChannelRule, error) {
+	var rules []ChannelRule
+	err := s.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		rules, err = listChannelRules(sess, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't list channel rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *SQLStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	rule, entity, err := newChannelRule(orgID, cmd.Pattern, cmd.Settings)
+	if err != nil {
+		return rule, err
+	}
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rules, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return err
+		}
+		for _, existingRule := range rules {
+			if existingRule.Pattern == rule.Pattern {
+				return fmt.Errorf("pattern already exists in org: %s", rule.Pattern)
+			}
+		}
+		if ok, reason := checkRulesValid(orgID, append(rules, rule)); !ok {
+			return errors.New(reason)
+		}
+		entity.Version = 1
+		entity.Created = entity.Updated
+		_, err = sess.Insert(entity)
+		return err
+	})
+	if err != nil {
+		return rule, fmt.Errorf("can't create channel rule: %w", err)
+	}
+	s.notifyChange(orgID)
+	rule.Version = entity.Version
+	return rule, nil
+}
+
+func (s *SQLStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	rule, entity, err := newChannelRule(orgID, cmd.Pattern, cmd.Settings)
+	if err != nil {
+		return rule, err
+	}
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing channelRuleEntity
+		exists, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if cmd.Version != 0 && cmd.Version != existing.Version {
+				return ErrVersionConflict
+			}
+			entity.Version = existing.Version + 1
+			return updateVersioned(sess, entity, existing.ID, existing.Version, "settings")
+		}
+		// Same as in FileStorage, updating a channel rule that does not exist creates it.
+		if cmd.Version != 0 {
+			return ErrVersionConflict
+		}
+		rules, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return err
+		}
+		if ok, reason := checkRulesValid(orgID, append(rules, rule)); !ok {
+			return errors.New(reason)
+		}
+		entity.Version = 1
+		entity.Created = entity.Updated
+		_, err = sess.Insert(entity)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return rule, err
+		}
+		return rule, fmt.Errorf("can't update channel rule: %w", err)
+	}
+	s.notifyChange(orgID)
+	rule.Version = entity.Version
+	return rule, nil
+}
+
+func (s *SQLStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	err := s.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Delete(&channelRuleEntity{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return errors.New("rule not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't delete channel rule: %w", err)
+	}
+	s.notifyChange(orgID)
+	return nil
+}
+
+func (s *SQLStorage) notifyChange(orgID int64) {
+	if s.ChangeNotifier == nil {
+		return
+	}
+	if err := s.ChangeNotifier.NotifyStorageChange(orgID); err != nil {
+		// Other nodes still pick up the change with the periodic update of channel rules.
+		logger.Error("Error notifying about pipeline storage change", "error", err, "orgId", orgID)
+	}
+}
+
+func listChannelRules(sess *db.Session, orgID int64) ([]ChannelRule, error) {
+	var entities []channelRuleEntity
+	if err := sess.Where("org_id = ?", orgID).Asc("pattern").Find(&entities); err != nil {
+		return nil, err
+	}
+	rules := make([]ChannelRule, 0, len(entities))
+	for _, e := range entities {
+		rule, err := e.toChannelRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func newChannelRule(orgID int64, pattern string, settings ChannelRuleSettings) (ChannelRule, *channelRuleEntity, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  pattern,
+		Settings: settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, nil, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+	settingsJSON, err := json.Marshal(rule.Settings)
+	if err != nil {
+		return rule, nil, fmt.Errorf("can't marshal channel rule settings: %w", err)
+	}
+	return rule, &channelRuleEntity{
+		OrgID:    orgID,
+		Pattern:  pattern,
+		Settings: string(settingsJSON),
+		Updated:  time.Now(),
+	}, nil
+}
+
+// updateVersioned updates the columns and the version of an existing row, if the row still has the version it had
+// when it was read. Otherwise, it was updated concurrently and ErrVersionConflict is returned.
+func updateVersioned(sess *db.Session, entity interface{}, id, version int64, cols ...string) error {
+	affected, err := sess.Where("id = ? AND version = ?", id, version).
+		Cols(append(cols, "version", "updated")...).
+		Update(entity)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+type fakeStorageChangeNotifier struct {
+	orgIDs []int64
+}
+
+func (n *fakeStorageChangeNotifier) NotifyStorageChange(orgID int64) error {
+	n.orgIDs = append(n.orgIDs, orgID)
+	return nil
+}
+
+func TestIntegrationSQLStorage_ChannelRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	notifier := &fakeStorageChangeNotifier{}
+	s := &SQLStorage{
+		SQLStore:       db.InitTestDB(t),
+		SecretsService: fakes.NewFakeSecretsService(),
+		ChangeNotifier: notifier,
+	}
+	settings := ChannelRuleSettings{
+		Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+	}
+
+	rule, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/test/:metric", Settings: settings})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rule.Version)
+
+	_, err = s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/test/:metric", Settings: settings})
+	require.ErrorContains(t, err, "pattern already exists")
+	_, err = s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/test/:other", Settings: settings})
+	require.Error(t, err, "conflicting wildcard patterns must be rejected")
+
+	// Channel rules are scoped by organization.
+	_, err = s.CreateChannelRule(ctx, 2, ChannelRuleCreateCmd{Pattern: "stream/test/:metric", Settings: settings})
+	require.NoError(t, err)
+
+	settings.Converter.AutoJsonConverterConfig = &AutoJsonConverterConfig{}
+	rule, err = s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/test/:metric", Settings: settings, Version: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rule.Version)
+
+	_, err = s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/test/:metric", Settings: settings, Version: 1})
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	rule, err = s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/test/:metric", Settings: settings})
+	require.NoError(t, err, "updates without version are not checked")
+	require.Equal(t, int64(3), rule.Version)
+
+	_, err = s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/missing", Settings: settings, Version: 1})
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	rules, err := s.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, int64(1), rules[0].OrgId)
+	require.Equal(t, int64(3), rules[0].Version)
+	require.NotNil(t, rules[0].Settings.Converter.AutoJsonConverterConfig)
+
+	require.NoError(t, s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/test/:metric"}))
+	require.Error(t, s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/test/:metric"}))
+
+	rules, err = s.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+	rules, err = s.ListChannelRules(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+
+	require.Equal(t, []int64{1, 2, 1, 1, 1}, notifier.orgIDs)
+}
+
+func TestIntegrationSQLStorage_WriteConfigs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	notifier := &fakeStorageChangeNotifier{}
+	s := &SQLStorage{
+		SQLStore:       db.InitTestDB(t),
+		SecretsService: fakes.NewFakeSecretsService(),
+		ChangeNotifier: notifier,
+	}
+	settings := WriteSettings{Endpoint: "http://localhost:9090/api/v1/write", BasicAuth: &BasicAuth{User: "user"}}
+
+	writeConfig, err := s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+		Settings:       settings,
+		SecureSettings: map[string]string{"password": "secret"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, writeConfig.UID)
+	require.Equal(t, int64(1), writeConfig.Version)
+
+	_, err = s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{UID: writeConfig.UID, Settings: settings})
+	require.ErrorContains(t, err, "already exists")
+
+	stored, ok, err := s.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: writeConfig.UID})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, settings, stored.Settings)
+	require.Equal(t, map[string][]byte{"password": []byte("secret")}, stored.SecureSettings)
+
+	_, ok, err = s.GetWriteConfig(ctx, 2, WriteConfigGetCmd{UID: writeConfig.UID})
+	require.NoError(t, err)
+	require.False(t, ok, "write configs are scoped by organization")
+
+	settings.BasicAuth = &BasicAuth{User: "other"}
+	updated, err := s.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{UID: writeConfig.UID, Settings: settings, Version: 1})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), updated.Version)
+
+	_, err = s.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{UID: writeConfig.UID, Settings: settings, Version: 1})
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	writeConfigs, err := s.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, writeConfigs, 1)
+	require.Equal(t, "other", writeConfigs[0].Settings.BasicAuth.User)
+	require.Empty(t, writeConfigs[0].SecureSettings)
+
+	require.NoError(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: writeConfig.UID}))
+	require.Error(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: writeConfig.UID}))
+	writeConfigs, err = s.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Empty(t, writeConfigs)
+
+	require.Equal(t, []int64{1, 1, 1}, notifier.orgIDs)
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/livecontext"
+	"github.com/grafana/grafana/pkg/services/live/model"
+)
+
+type HistorySubscriberConfig struct {
+	// MaxFrames limits the number of frames a subscriber can request.
+	MaxFrames int `json:"maxFrames"`
+	// DefaultFrames is the number of frames sent when subscriber does not request a number.
+	DefaultFrames int `json:"defaultFrames,omitempty"`
+}
+
+// historySubscribeRequest is the data a client can send on subscribe.
+type historySubscribeRequest struct {
+	History *int `json:"history"`
+}
+
+// HistorySubscriber sends last frames kept in channel history by HistoryFrameOutput
+// to a client on subscribe. Frames with the same schema as the last one are merged
+// into a single frame which becomes the subscription initial data.
+type HistorySubscriber struct {
+	frameStorage FrameStorage
+	config       HistorySubscriberConfig
+}
+
+const SubscriberTypeHistory = "history"
+
+func NewHistorySubscriber(frameStorage FrameStorage, config HistorySubscriberConfig) *HistorySubscriber {
+	return &HistorySubscriber{frameStorage: frameStorage, config: config}
+}
+
+func (s *HistorySubscriber) Type() string {
+	return SubscriberTypeHistory
+}
+
+func (s *HistorySubscriber) Subscribe(ctx context.Context, vars Vars, subscribeData []byte) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if _, ok := livecontext.GetContextSignedUser(ctx); !ok {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+	n := s.config.DefaultFrames
+	if len(subscribeData) > 0 {
+		var req historySubscribeRequest
+		if err := json.Unmarshal(subscribeData, &req); err == nil && req.History != nil {
+			n = *req.History
+		}
+	}
+	if n > s.config.MaxFrames {
+		n = s.config.MaxFrames
+	}
+	if n <= 0 {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+	}
+	frames, err := s.frameStorage.History(vars.OrgID, vars.Channel, n)
+	if err != nil {
+		return model.SubscribeReply{}, 0, err
+	}
+	frame := mergeHistoryFrames(frames)
+	if frame == nil {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+	}
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return model.SubscribeReply{}, 0, err
+	}
+	return model.SubscribeReply{Data: frameJSON}, backend.SubscribeStreamStatusOK, nil
+}
+
+// mergeHistoryFrames merges the rows of frames (oldest first) into a single frame.
+// Only the most recent frames sharing the schema of the last frame are merged.
+func mergeHistoryFrames(frames []*data.Frame) *data.Frame {
+	if len(frames) == 0 {
+		return nil
+	}
+	last := frames[len(frames)-1]
+	start := len(frames) - 1
+	for start > 0 && sameFrameSchema(frames[start-1], last) {
+		start--
+	}
+	merged := last.EmptyCopy()
+	for _, frame := range frames[start:] {
+		for i := 0; i < frame.Rows(); i++ {
+			merged.AppendRow(frame.RowCopy(i)...)
+		}
+	}
+	return merged
+}
+
+func sameFrameSchema(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/livecontext"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestHistorySubscriber_Subscribe(t *testing.T) {
+	storage := NewMemoryFrameStorage()
+	out := NewHistoryFrameOutput(storage, HistoryOutputConfig{Size: 3})
+	vars := Vars{OrgID: 1, Channel: "stream/test/history"}
+
+	// Frame with a different schema must not be merged with the following ones.
+	_, err := out.OutputFrame(context.Background(), vars, data.NewFrame("test", data.NewField("other", nil, []string{"a"})))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := out.OutputFrame(context.Background(), vars, data.NewFrame("test", data.NewField("value", nil, []float64{float64(i)})))
+		require.NoError(t, err)
+	}
+
+	ctx := livecontext.SetContextSignedUser(context.Background(), &user.SignedInUser{OrgID: 1})
+	sub := NewHistorySubscriber(storage, HistorySubscriberConfig{MaxFrames: 2, DefaultFrames: 1})
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected []float64
+	}{
+		{name: "default number of frames", data: nil, expected: []float64{2}},
+		{name: "requested number of frames", data: []byte(`{"history": 2}`), expected: []float64{1, 2}},
+		{name: "requested number limited by max frames", data: []byte(`{"history": 10}`), expected: []float64{1, 2}},
+		{name: "no frames requested", data: []byte(`{"history": 0}`), expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reply, status, err := sub.Subscribe(ctx, vars, tc.data)
+			require.NoError(t, err)
+			require.Equal(t, backend.SubscribeStreamStatusOK, status)
+			if tc.expected == nil {
+				require.Nil(t, reply.Data)
+				return
+			}
+			var frame data.Frame
+			require.NoError(t, frame.UnmarshalJSON(reply.Data))
+			require.Len(t, frame.Fields, 1)
+			require.Equal(t, "value", frame.Fields[0].Name)
+			require.Equal(t, len(tc.expected), frame.Rows())
+			for i, v := range tc.expected {
+				require.Equal(t, v, frame.Fields[0].At(i))
+			}
+		})
+	}
+}
+
+func TestHistorySubscriber_Subscribe_NoUser(t *testing.T) {
+	sub := NewHistorySubscriber(NewMemoryFrameStorage(), HistorySubscriberConfig{MaxFrames: 2})
+	_, status, err := sub.Subscribe(context.Background(), Vars{OrgID: 1, Channel: "stream/test/history"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusPermissionDenied, status)
+}
+
+func TestMergeHistoryFrames(t *testing.T) {
+	require.Nil(t, mergeHistoryFrames(nil))
+
+	frame := mergeHistoryFrames([]*data.Frame{
+		data.NewFrame("test", data.NewField("value", nil, []float64{1})),
+		data.NewFrame("test", data.NewField("value", nil, []int64{2})),
+		data.NewFrame("test", data.NewField("value", nil, []int64{3, 4})),
+	})
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, int64(2), frame.Fields[0].At(0))
+	require.Equal(t, int64(4), frame.Fields[0].At(2))
+}
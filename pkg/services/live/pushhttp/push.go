@@ -58,6 +58,7 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 	// TODO Grafana 8: decide which formats to use or keep all.
 	urlValues := ctx.Req.URL.Query()
 	frameFormat := pushurl.FrameFormatFromValues(urlValues)
+	inputFormat := pushurl.InputFormatFromValues(urlValues)
 
 	body, err := io.ReadAll(ctx.Req.Body)
 	if err != nil {
@@ -70,12 +71,13 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 		"streamId", streamID,
 		"bodyLength", len(body),
 		"frameFormat", frameFormat,
+		"inputFormat", inputFormat,
 	)
 
-	metricFrames, err := g.converter.Convert(body, frameFormat)
+	metricFrames, err := g.converter.Convert(body, inputFormat, frameFormat)
 	if err != nil {
-		logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat)
-		if errors.Is(err, convert.ErrUnsupportedFrameFormat) {
+		logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat, "inputFormat", inputFormat)
+		if errors.Is(err, convert.ErrUnsupportedFrameFormat) || errors.Is(err, convert.ErrUnsupportedInputFormat) {
 			ctx.Resp.WriteHeader(http.StatusBadRequest)
 		} else {
 			ctx.Resp.WriteHeader(http.StatusInternalServerError)
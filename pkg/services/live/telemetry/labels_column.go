@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+var _ FrameWrapper = (*LabelsColumnFrame)(nil)
+
+// LabelsColumnFrame builds a frame with labels and time columns followed by
+// nullable float64 value columns. Values with the same labels and time are
+// put into the same row.
+type LabelsColumnFrame struct {
+	key        string
+	labels     []string
+	times      []time.Time
+	rowIndex   map[string]int
+	fields     []*data.Field
+	fieldIndex map[string]int
+}
+
+// NewLabelsColumnFrame creates new LabelsColumnFrame.
+func NewLabelsColumnFrame(key string) *LabelsColumnFrame {
+	return &LabelsColumnFrame{
+		key:        key,
+		rowIndex:   map[string]int{},
+		fieldIndex: map[string]int{},
+	}
+}
+
+// Add sets value of the field in a row identified by labels and time.
+func (f *LabelsColumnFrame) Add(labels data.Labels, t time.Time, fieldName string, value float64) {
+	labelsString := labels.String()
+	rowKey := labelsString + "_" + strconv.FormatInt(t.UnixNano(), 10)
+	row, ok := f.rowIndex[rowKey]
+	if !ok {
+		row = len(f.labels)
+		f.rowIndex[rowKey] = row
+		f.labels = append(f.labels, labelsString)
+		f.times = append(f.times, t)
+		for _, field := range f.fields {
+			field.Append(nil)
+		}
+	}
+	index, ok := f.fieldIndex[fieldName]
+	if !ok {
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(f.labels))
+		field.Name = fieldName
+		f.fields = append(f.fields, field)
+		index = len(f.fields) - 1
+		f.fieldIndex[fieldName] = index
+	}
+	f.fields[index].Set(row, &value)
+}
+
+// Key returns a key which describes Frame metrics.
+func (f *LabelsColumnFrame) Key() string {
+	return f.key
+}
+
+// Frame transforms LabelsColumnFrame to Grafana data.Frame.
+func (f *LabelsColumnFrame) Frame() *data.Frame {
+	fields := make([]*data.Field, 0, len(f.fields)+2)
+	fields = append(fields,
+		data.NewField("labels", nil, f.labels),
+		data.NewField("time", nil, f.times),
+	)
+	fields = append(fields, f.fields...)
+	return data.NewFrame(f.key, fields...)
+}
+
+// LabelsColumnFrames keeps LabelsColumnFrame objects in order of their creation.
+type LabelsColumnFrames struct {
+	frames []*LabelsColumnFrame
+	byKey  map[string]*LabelsColumnFrame
+}
+
+// NewLabelsColumnFrames creates new LabelsColumnFrames.
+func NewLabelsColumnFrames() *LabelsColumnFrames {
+	return &LabelsColumnFrames{byKey: map[string]*LabelsColumnFrame{}}
+}
+
+// Get returns frame for the key, creating it if needed.
+func (f *LabelsColumnFrames) Get(key string) *LabelsColumnFrame {
+	frame, ok := f.byKey[key]
+	if !ok {
+		frame = NewLabelsColumnFrame(key)
+		f.byKey[key] = frame
+		f.frames = append(f.frames, frame)
+	}
+	return frame
+}
+
+// FrameWrappers returns all frames.
+func (f *LabelsColumnFrames) FrameWrappers() []FrameWrapper {
+	frameWrappers := make([]FrameWrapper, 0, len(f.frames))
+	for _, frame := range f.frames {
+		frameWrappers = append(frameWrappers, frame)
+	}
+	return frameWrappers
+}
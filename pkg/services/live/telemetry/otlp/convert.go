@@ -0,0 +1,205 @@
+package otlp
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts OTLP metrics export requests to Grafana frames.
+type Converter struct {
+	useJSON bool
+	now     func() time.Time
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithJSONEncoding makes Converter expect OTLP/JSON encoded requests instead of protobuf.
+func WithJSONEncoding(enabled bool) ConverterOption {
+	return func(c *Converter) {
+		c.useJSON = enabled
+	}
+}
+
+// NewConverter creates new Converter from OTLP metrics to Grafana Data Frames.
+// This converter generates one frame in labels column format for each metric name.
+// Data point attributes become labels, together with job and instance labels built
+// from service resource attributes the same way Prometheus exporters do. Gauge and
+// sum points and summary quantiles are put into the value column, cumulative histogram
+// buckets into the bucket column, and sums and counts of histograms and summaries
+// into the sum and count columns.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var req colmetricspb.ExportMetricsServiceRequest
+	var err error
+	if c.useJSON {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, &req)
+	} else {
+		err = proto.Unmarshal(body, &req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	now := c.now()
+	frames := telemetry.NewLabelsColumnFrames()
+	for _, rm := range req.GetResourceMetrics() {
+		resourceLabels := getResourceLabels(rm.GetResource().GetAttributes())
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				c.convertMetric(frames.Get(m.GetName()), m, resourceLabels, now)
+			}
+		}
+	}
+	return frames.FrameWrappers(), nil
+}
+
+func (c *Converter) convertMetric(frame *telemetry.LabelsColumnFrame, m *metricspb.Metric, resourceLabels data.Labels, now time.Time) {
+	switch {
+	case m.GetGauge() != nil:
+		addNumberDataPoints(frame, m.GetGauge().GetDataPoints(), resourceLabels, now)
+	case m.GetSum() != nil:
+		addNumberDataPoints(frame, m.GetSum().GetDataPoints(), resourceLabels, now)
+	case m.GetHistogram() != nil:
+		for _, p := range m.GetHistogram().GetDataPoints() {
+			labels := getLabels(resourceLabels, p.GetAttributes())
+			t := getTime(p.GetTimeUnixNano(), now)
+			var cumulativeCount uint64
+			for i, count := range p.GetBucketCounts() {
+				cumulativeCount += count
+				le := "+Inf"
+				if i < len(p.GetExplicitBounds()) {
+					le = formatFloat(p.GetExplicitBounds()[i])
+				}
+				frame.Add(withLabel(labels, "le", le), t, "bucket", float64(cumulativeCount))
+			}
+			frame.Add(labels, t, "sum", p.GetSum())
+			frame.Add(labels, t, "count", float64(p.GetCount()))
+		}
+	case m.GetExponentialHistogram() != nil:
+		for _, p := range m.GetExponentialHistogram().GetDataPoints() {
+			labels := getLabels(resourceLabels, p.GetAttributes())
+			t := getTime(p.GetTimeUnixNano(), now)
+			frame.Add(labels, t, "sum", p.GetSum())
+			frame.Add(labels, t, "count", float64(p.GetCount()))
+		}
+	case m.GetSummary() != nil:
+		for _, p := range m.GetSummary().GetDataPoints() {
+			labels := getLabels(resourceLabels, p.GetAttributes())
+			t := getTime(p.GetTimeUnixNano(), now)
+			for _, q := range p.GetQuantileValues() {
+				frame.Add(withLabel(labels, "quantile", formatFloat(q.GetQuantile())), t, "value", q.GetValue())
+			}
+			frame.Add(labels, t, "sum", p.GetSum())
+			frame.Add(labels, t, "count", float64(p.GetCount()))
+		}
+	}
+}
+
+func addNumberDataPoints(frame *telemetry.LabelsColumnFrame, points []*metricspb.NumberDataPoint, resourceLabels data.Labels, now time.Time) {
+	for _, p := range points {
+		var value float64
+		switch v := p.GetValue().(type) {
+		case *metricspb.NumberDataPoint_AsDouble:
+			value = v.AsDouble
+		case *metricspb.NumberDataPoint_AsInt:
+			value = float64(v.AsInt)
+		default:
+			continue
+		}
+		frame.Add(getLabels(resourceLabels, p.GetAttributes()), getTime(p.GetTimeUnixNano(), now), "value", value)
+	}
+}
+
+// getResourceLabels returns job and instance labels built from service resource attributes.
+func getResourceLabels(attributes []*commonpb.KeyValue) data.Labels {
+	var serviceName, serviceNamespace, serviceInstanceID string
+	for _, kv := range attributes {
+		switch kv.GetKey() {
+		case "service.name":
+			serviceName = anyValueToString(kv.GetValue())
+		case "service.namespace":
+			serviceNamespace = anyValueToString(kv.GetValue())
+		case "service.instance.id":
+			serviceInstanceID = anyValueToString(kv.GetValue())
+		}
+	}
+	labels := data.Labels{}
+	if serviceName != "" {
+		job := serviceName
+		if serviceNamespace != "" {
+			job = serviceNamespace + "/" + serviceName
+		}
+		labels["job"] = job
+	}
+	if serviceInstanceID != "" {
+		labels["instance"] = serviceInstanceID
+	}
+	return labels
+}
+
+func getLabels(resourceLabels data.Labels, attributes []*commonpb.KeyValue) data.Labels {
+	labels := resourceLabels.Copy()
+	for _, kv := range attributes {
+		labels[kv.GetKey()] = anyValueToString(kv.GetValue())
+	}
+	return labels
+}
+
+func anyValueToString(v *commonpb.AnyValue) string {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return value.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(value.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(value.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return formatFloat(value.DoubleValue)
+	case nil:
+		return ""
+	default:
+		b, err := protojson.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+func getTime(unixNano uint64, now time.Time) time.Time {
+	if unixNano == 0 {
+		return now
+	}
+	return time.Unix(0, int64(unixNano))
+}
+
+func withLabel(labels data.Labels, name, value string) data.Labels {
+	l := labels.Copy()
+	l[name] = value
+	return l
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
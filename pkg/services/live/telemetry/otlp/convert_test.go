@@ -0,0 +1,134 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func testRequest(ts time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	timeUnixNano := uint64(ts.UnixNano())
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				stringAttribute("service.name", "checkout"),
+				stringAttribute("service.instance.id", "host-1"),
+				stringAttribute("host.arch", "amd64"),
+			}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Metrics: []*metricspb.Metric{
+					{
+						Name: "queue_size",
+						Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{
+							{
+								Attributes:   []*commonpb.KeyValue{stringAttribute("queue", "orders")},
+								TimeUnixNano: timeUnixNano,
+								Value:        &metricspb.NumberDataPoint_AsInt{AsInt: 12},
+							},
+							{
+								Attributes:   []*commonpb.KeyValue{stringAttribute("queue", "payments")},
+								TimeUnixNano: timeUnixNano,
+								Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: 1.5},
+							},
+						}}},
+					},
+					{
+						Name: "request_duration",
+						Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{DataPoints: []*metricspb.HistogramDataPoint{{
+							TimeUnixNano:   timeUnixNano,
+							Count:          6,
+							Sum:            proto.Float64(1.7),
+							BucketCounts:   []uint64{2, 3, 1},
+							ExplicitBounds: []float64{0.1, 0.5},
+						}}}},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func TestConverter_Convert(t *testing.T) {
+	ts := time.Unix(1640995200, 0)
+	body, err := proto.Marshal(testRequest(ts))
+	require.NoError(t, err)
+
+	frameWrappers, err := NewConverter().Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 2)
+
+	gauge := frameWrappers[0].Frame()
+	require.Equal(t, "queue_size", frameWrappers[0].Key())
+	require.Len(t, gauge.Fields, 3)
+	require.Equal(t, "value", gauge.Fields[2].Name)
+	require.Equal(t, 2, gauge.Rows())
+	require.Equal(t, "instance=host-1, job=checkout, queue=orders", gauge.Fields[0].At(0))
+	require.Equal(t, ts, gauge.Fields[1].At(0))
+	require.Equal(t, 12.0, *gauge.Fields[2].At(0).(*float64))
+	require.Equal(t, 1.5, *gauge.Fields[2].At(1).(*float64))
+
+	histogram := frameWrappers[1].Frame()
+	require.Equal(t, "request_duration", frameWrappers[1].Key())
+	require.Equal(t, 4, histogram.Rows())
+	// Bucket counts are cumulative.
+	require.Equal(t, "instance=host-1, job=checkout, le=0.5", histogram.Fields[0].At(1))
+	require.Equal(t, 5.0, *histogram.Fields[2].At(1).(*float64))
+	require.Equal(t, "instance=host-1, job=checkout, le=+Inf", histogram.Fields[0].At(2))
+	require.Equal(t, 6.0, *histogram.Fields[2].At(2).(*float64))
+	require.Equal(t, 1.7, *histogram.Fields[3].At(3).(*float64))
+	require.Equal(t, 6.0, *histogram.Fields[4].At(3).(*float64))
+
+	for _, fw := range frameWrappers {
+		_, err := data.FrameToJSON(fw.Frame(), data.IncludeAll)
+		require.NoError(t, err)
+	}
+}
+
+func TestConverter_Convert_JSON(t *testing.T) {
+	body := []byte(`{
+  "resourceMetrics": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "checkout"}}]},
+    "scopeMetrics": [{
+      "scope": {"name": "test"},
+      "metrics": [{
+        "name": "requests",
+        "unit": "1",
+        "sum": {
+          "aggregationTemporality": 2,
+          "isMonotonic": true,
+          "dataPoints": [{
+            "attributes": [{"key": "code", "value": {"intValue": "200"}}],
+            "timeUnixNano": "1640995200000000000",
+            "asInt": "42"
+          }]
+        }
+      }]
+    }]
+  }]
+}`)
+	frameWrappers, err := NewConverter(WithJSONEncoding(true)).Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1)
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, "requests", frameWrappers[0].Key())
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, "code=200, job=checkout", frame.Fields[0].At(0))
+	require.Equal(t, time.Unix(1640995200, 0), frame.Fields[1].At(0))
+	require.Equal(t, 42.0, *frame.Fields[2].At(0).(*float64))
+}
+
+func TestConverter_Convert_Error(t *testing.T) {
+	_, err := NewConverter(WithJSONEncoding(true)).Convert([]byte("{"))
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts metrics in Prometheus text exposition format to Grafana frames.
+type Converter struct {
+	now func() time.Time
+}
+
+// NewConverter creates new Converter from Prometheus text exposition format to Grafana
+// Data Frames. This converter generates one frame in labels column format for each
+// metric family. Counter, gauge and untyped samples and summary quantiles are put into
+// the value column, histogram buckets into the bucket column, and sums and counts of
+// histograms and summaries into the sum and count columns.
+func NewConverter() *Converter {
+	return &Converter{now: time.Now}
+}
+
+// Convert metrics.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := c.now()
+	frames := telemetry.NewLabelsColumnFrames()
+	for _, name := range names {
+		family := families[name]
+		frame := frames.Get(name)
+		for _, m := range family.GetMetric() {
+			labels := data.Labels{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			t := now
+			if m.TimestampMs != nil {
+				t = time.UnixMilli(m.GetTimestampMs())
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				frame.Add(labels, t, "value", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				frame.Add(labels, t, "value", m.GetGauge().GetValue())
+			case dto.MetricType_SUMMARY:
+				summary := m.GetSummary()
+				for _, q := range summary.GetQuantile() {
+					frame.Add(withLabel(labels, "quantile", formatFloat(q.GetQuantile())), t, "value", q.GetValue())
+				}
+				frame.Add(labels, t, "sum", summary.GetSampleSum())
+				frame.Add(labels, t, "count", float64(summary.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				histogram := m.GetHistogram()
+				for _, b := range histogram.GetBucket() {
+					frame.Add(withLabel(labels, "le", formatFloat(b.GetUpperBound())), t, "bucket", float64(b.GetCumulativeCount()))
+				}
+				frame.Add(labels, t, "sum", histogram.GetSampleSum())
+				frame.Add(labels, t, "count", float64(histogram.GetSampleCount()))
+			default:
+				frame.Add(labels, t, "value", m.GetUntyped().GetValue())
+			}
+		}
+	}
+	return frames.FrameWrappers(), nil
+}
+
+func withLabel(labels data.Labels, name, value string) data.Labels {
+	l := labels.Copy()
+	l[name] = value
+	return l
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
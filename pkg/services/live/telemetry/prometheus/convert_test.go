@@ -0,0 +1,95 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+const testMetrics = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"} 3 1395066363000
+# HELP temperature Current temperature.
+# TYPE temperature gauge
+temperature{room="kitchen"} 21.5
+# HELP request_duration_seconds A histogram of the request duration.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 2
+request_duration_seconds_bucket{le="0.5"} 5
+request_duration_seconds_bucket{le="+Inf"} 6
+request_duration_seconds_sum 1.7
+request_duration_seconds_count 6
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds{quantile="0.9"} 0.08
+rpc_duration_seconds_sum 17.5
+rpc_duration_seconds_count 250
+`
+
+func TestConverter_Convert(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewConverter()
+	c.now = func() time.Time { return now }
+
+	frameWrappers, err := c.Convert([]byte(testMetrics))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 4)
+
+	frames := map[string]*data.Frame{}
+	for _, fw := range frameWrappers {
+		frames[fw.Key()] = fw.Frame()
+		_, err := data.FrameToJSON(fw.Frame(), data.IncludeAll)
+		require.NoError(t, err)
+	}
+
+	counter := frames["http_requests_total"]
+	require.Len(t, counter.Fields, 3)
+	require.Equal(t, "labels", counter.Fields[0].Name)
+	require.Equal(t, "time", counter.Fields[1].Name)
+	require.Equal(t, "value", counter.Fields[2].Name)
+	require.Equal(t, 2, counter.Rows())
+	require.Equal(t, "code=200, method=post", counter.Fields[0].At(0))
+	require.Equal(t, time.UnixMilli(1395066363000), counter.Fields[1].At(0))
+	require.Equal(t, 1027.0, *counter.Fields[2].At(0).(*float64))
+
+	gauge := frames["temperature"]
+	require.Equal(t, 1, gauge.Rows())
+	require.Equal(t, now, gauge.Fields[1].At(0))
+	require.Equal(t, 21.5, *gauge.Fields[2].At(0).(*float64))
+
+	histogram := frames["request_duration_seconds"]
+	require.Len(t, histogram.Fields, 5)
+	require.Equal(t, []string{"labels", "time", "bucket", "sum", "count"}, fieldNames(histogram))
+	// One row per bucket and one row for sum and count.
+	require.Equal(t, 4, histogram.Rows())
+	require.Equal(t, "le=+Inf", histogram.Fields[0].At(2))
+	require.Equal(t, 6.0, *histogram.Fields[2].At(2).(*float64))
+	require.Nil(t, histogram.Fields[3].At(2))
+	require.Equal(t, "", histogram.Fields[0].At(3))
+	require.Nil(t, histogram.Fields[2].At(3))
+	require.Equal(t, 1.7, *histogram.Fields[3].At(3).(*float64))
+	require.Equal(t, 6.0, *histogram.Fields[4].At(3).(*float64))
+
+	summary := frames["rpc_duration_seconds"]
+	require.Equal(t, []string{"labels", "time", "value", "sum", "count"}, fieldNames(summary))
+	require.Equal(t, 3, summary.Rows())
+	require.Equal(t, "quantile=0.9", summary.Fields[0].At(1))
+	require.Equal(t, 0.08, *summary.Fields[2].At(1).(*float64))
+	require.Equal(t, 250.0, *summary.Fields[4].At(2).(*float64))
+}
+
+func TestConverter_Convert_Error(t *testing.T) {
+	_, err := NewConverter().Convert([]byte("invalid metric{"))
+	require.Error(t, err)
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
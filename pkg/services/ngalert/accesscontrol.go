@@ -164,6 +164,9 @@ var (
 				{
 					Action: accesscontrol.ActionAlertingProvisioningRead, // organization scope
 				},
+				{
+					Action: accesscontrol.ActionAlertingProvisioningReadSecrets, // organization scope
+				},
 				{
 					Action: accesscontrol.ActionAlertingProvisioningWrite, // organization scope
 				},
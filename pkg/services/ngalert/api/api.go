@@ -85,6 +85,7 @@ type API struct {
 	EvaluatorFactory     eval.EvaluatorFactory
 	FeatureManager       featuremgmt.FeatureToggles
 	Historian            Historian
+	NotificationLog      NotificationLogStore
 
 	AppUrl *url.URL
 }
@@ -118,6 +119,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			QuotaService:       api.QuotaService,
 			scheduleService:    api.Schedule,
 			store:              api.RuleStore,
+			amConfigStore:      api.AlertingStore,
 			provenanceStore:    api.ProvenanceStore,
 			xactManager:        api.TransactionManager,
 			log:                logger,
@@ -133,8 +135,9 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			accessControl:   api.AccessControl,
 			evaluator:       api.EvaluatorFactory,
 			cfg:             &api.Cfg.UnifiedAlerting,
-			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory),
+			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory, api.Policies, api.MuteTimings, api.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel)),
 			featureManager:  api.FeatureManager,
+			ruleStore:       api.RuleStore,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
@@ -147,6 +150,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 
 	api.RegisterProvisioningApiEndpoints(NewProvisioningApi(&ProvisioningSrv{
 		log:                 logger,
+		ac:                  api.AccessControl,
 		policies:            api.Policies,
 		contactPointService: api.ContactPointService,
 		templates:           api.Templates,
@@ -158,6 +162,12 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		logger: logger,
 		hist:   api.Historian,
 	}), m)
+
+	api.RegisterNotificationsApiEndpoints(NewNotificationsApi(&NotificationLogSrv{
+		logger: logger,
+		store:  api.NotificationLog,
+		mam:    api.MultiOrgAlertmanager,
+	}), m)
 }
 
 func (api *API) Usage(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
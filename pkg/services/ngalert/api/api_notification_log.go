@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultNotificationLogLimit = 100
+	maxNotificationLogLimit     = 1000
+)
+
+type NotificationLogStore interface {
+	GetNotificationLog(ctx context.Context, query models.GetNotificationLogQuery) ([]models.NotificationLogEntry, error)
+	GetNotificationLogEntry(ctx context.Context, orgID, id int64) (*models.NotificationLogEntry, error)
+}
+
+type NotificationLogSrv struct {
+	logger log.Logger
+	store  NotificationLogStore
+	mam    *notifier.MultiOrgAlertmanager
+}
+
+func (srv *NotificationLogSrv) RouteGetNotificationLog(c *contextmodel.ReqContext) response.Response {
+	query, err := parseNotificationLogQuery(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	entries, err := srv.store.GetNotificationLog(c.Req.Context(), query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification log")
+	}
+
+	result := make(apimodels.NotificationLog, 0, len(entries))
+	for _, entry := range entries {
+		gettable, err := notificationLogEntryToGettable(entry)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		result = append(result, gettable)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *NotificationLogSrv) RoutePostNotificationLogResend(c *contextmodel.ReqContext, idParam string) response.Response {
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid notification log entry ID %q", idParam), "")
+	}
+	entry, err := srv.store.GetNotificationLogEntry(c.Req.Context(), c.OrgID, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotificationLogEntryNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification log entry")
+	}
+
+	am, err := srv.mam.AlertmanagerFor(c.OrgID)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		if errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "unable to obtain org's Alertmanager")
+	}
+
+	if err := am.ResendNotification(c.Req.Context(), entry); err != nil {
+		if errors.Is(err, notifier.ErrNotificationIntegrationNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		srv.logger.Error("Failed to resend notification", "id", id, "receiver", entry.Receiver, "error", err)
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "notification resent"})
+}
+
+func parseNotificationLogQuery(c *contextmodel.ReqContext) (models.GetNotificationLogQuery, error) {
+	query := models.GetNotificationLogQuery{
+		OrgID:    c.OrgID,
+		Receiver: c.Query("receiver"),
+		Status:   c.Query("status"),
+		Limit:    defaultNotificationLogLimit,
+	}
+	switch query.Status {
+	case "", models.NotificationStatusSuccess, models.NotificationStatusFailed:
+	default:
+		return models.GetNotificationLogQuery{}, fmt.Errorf("unknown status %q", query.Status)
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(from, 0)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(to, 0)
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxNotificationLogLimit {
+			return models.GetNotificationLogQuery{}, fmt.Errorf("limit must be a number between 1 and %d", maxNotificationLogLimit)
+		}
+		query.Limit = n
+	}
+	return query, nil
+}
+
+func notificationLogEntryToGettable(entry models.NotificationLogEntry) (apimodels.GettableNotificationLogEntry, error) {
+	result := apimodels.GettableNotificationLogEntry{
+		ID:               entry.ID,
+		Receiver:         entry.Receiver,
+		IntegrationUID:   entry.IntegrationUID,
+		IntegrationType:  entry.IntegrationType,
+		IntegrationIndex: entry.IntegrationIndex,
+		GroupKey:         entry.GroupKey,
+		Status:           entry.Status,
+		Error:            entry.Error,
+		DurationMs:       entry.Duration,
+		Attempt:          entry.Attempt,
+		SentAt:           time.Unix(0, entry.SentAt).UTC(),
+	}
+	if entry.StatusCode != 0 {
+		statusCode := entry.StatusCode
+		result.StatusCode = &statusCode
+	}
+	if err := json.Unmarshal([]byte(entry.GroupLabels), &result.GroupLabels); err != nil {
+		return apimodels.GettableNotificationLogEntry{}, fmt.Errorf("failed to parse the group labels of notification log entry %d: %w", entry.ID, err)
+	}
+	if err := json.Unmarshal([]byte(entry.Alerts), &result.Alerts); err != nil {
+		return apimodels.GettableNotificationLogEntry{}, fmt.Errorf("failed to parse the alerts of notification log entry %d: %w", entry.ID, err)
+	}
+	return result, nil
+}
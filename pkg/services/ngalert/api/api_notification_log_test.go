@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestRouteGetNotificationLog(t *testing.T) {
+	t.Run("should pass filters to the store and return the entries", func(t *testing.T) {
+		store := &fakeNotificationLogStore{entries: []models.NotificationLogEntry{{
+			ID:              1,
+			OrgID:           1,
+			Receiver:        "team",
+			IntegrationUID:  "uid",
+			IntegrationType: "webhook",
+			GroupKey:        `{}:{alertname="test"}`,
+			GroupLabels:     `{"alertname":"test"}`,
+			Alerts:          `[{"labels":{"alertname":"test"},"annotations":{},"startsAt":"2023-03-01T10:00:00Z","endsAt":"0001-01-01T00:00:00Z","generatorURL":""}]`,
+			Status:          models.NotificationStatusFailed,
+			Error:           "webhook response status 503",
+			StatusCode:      503,
+			Duration:        120,
+			Attempt:         2,
+			SentAt:          time.Unix(1677664800, 0).UnixNano(),
+		}}}
+		srv := &NotificationLogSrv{logger: log.NewNopLogger(), store: store}
+
+		resp := srv.RouteGetNotificationLog(createNotificationLogRequestContext(t, url.Values{
+			"receiver": {"team"},
+			"status":   {"failed"},
+			"from":     {"1677664800"},
+			"limit":    {"10"},
+		}))
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, models.GetNotificationLogQuery{
+			OrgID:    1,
+			Receiver: "team",
+			Status:   models.NotificationStatusFailed,
+			From:     time.Unix(1677664800, 0),
+			Limit:    10,
+		}, store.query)
+		require.JSONEq(t, `[{
+			"id": 1,
+			"receiver": "team",
+			"integrationUid": "uid",
+			"integrationType": "webhook",
+			"integrationIndex": 0,
+			"groupKey": "{}:{alertname=\"test\"}",
+			"groupLabels": {"alertname": "test"},
+			"alerts": [{"labels": {"alertname": "test"}, "annotations": {}, "startsAt": "2023-03-01T10:00:00Z", "endsAt": "0001-01-01T00:00:00Z"}],
+			"status": "failed",
+			"error": "webhook response status 503",
+			"statusCode": 503,
+			"durationMs": 120,
+			"attempt": 2,
+			"sentAt": "2023-03-01T10:00:00Z"
+		}]`, string(resp.Body()))
+	})
+
+	t.Run("should return a null status code if it is not known", func(t *testing.T) {
+		store := &fakeNotificationLogStore{entries: []models.NotificationLogEntry{{
+			ID:              1,
+			OrgID:           1,
+			Receiver:        "team",
+			IntegrationType: "email",
+			GroupLabels:     `{}`,
+			Alerts:          `[]`,
+			Status:          models.NotificationStatusSuccess,
+			Attempt:         1,
+		}}}
+		srv := &NotificationLogSrv{logger: log.NewNopLogger(), store: store}
+
+		resp := srv.RouteGetNotificationLog(createNotificationLogRequestContext(t, url.Values{}))
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		var result []map[string]interface{}
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Len(t, result, 1)
+		require.Contains(t, result[0], "statusCode")
+		require.Nil(t, result[0]["statusCode"])
+	})
+
+	t.Run("should use the default limit", func(t *testing.T) {
+		store := &fakeNotificationLogStore{}
+		srv := &NotificationLogSrv{logger: log.NewNopLogger(), store: store}
+
+		resp := srv.RouteGetNotificationLog(createNotificationLogRequestContext(t, url.Values{}))
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, defaultNotificationLogLimit, store.query.Limit)
+		require.JSONEq(t, `[]`, string(resp.Body()))
+	})
+
+	t.Run("should return 400 on invalid parameters", func(t *testing.T) {
+		testCases := map[string]url.Values{
+			"unknown status":    {"status": {"pending"}},
+			"zero limit":        {"limit": {"0"}},
+			"too large limit":   {"limit": {"1001"}},
+			"non-numeric limit": {"limit": {"ten"}},
+		}
+		for name, params := range testCases {
+			t.Run(name, func(t *testing.T) {
+				store := &fakeNotificationLogStore{}
+				srv := &NotificationLogSrv{logger: log.NewNopLogger(), store: store}
+
+				resp := srv.RouteGetNotificationLog(createNotificationLogRequestContext(t, params))
+
+				require.Equal(t, http.StatusBadRequest, resp.Status())
+				require.False(t, store.called)
+			})
+		}
+	})
+}
+
+func TestRoutePostNotificationLogResend(t *testing.T) {
+	t.Run("should return 400 on invalid ID", func(t *testing.T) {
+		srv := &NotificationLogSrv{logger: log.NewNopLogger(), store: &fakeNotificationLogStore{}}
+
+		resp := srv.RoutePostNotificationLogResend(createNotificationLogRequestContext(t, url.Values{}), "abc")
+
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should return 404 if the entry does not exist in the organization", func(t *testing.T) {
+		store := &fakeNotificationLogStore{entries: []models.NotificationLogEntry{{ID: 1, OrgID: 2}}}
+		srv := &NotificationLogSrv{logger: log.NewNopLogger(), store: store}
+
+		resp := srv.RoutePostNotificationLogResend(createNotificationLogRequestContext(t, url.Values{}), "1")
+
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+}
+
+func createNotificationLogRequestContext(t *testing.T, params url.Values) *contextmodel.ReqContext {
+	t.Helper()
+	req := createRequestContext(1, org.RoleEditor, nil)
+	u, err := url.Parse("http://localhost/api/v1/notifications/log?" + params.Encode())
+	require.NoError(t, err)
+	req.Req.URL = u
+	return req
+}
+
+type fakeNotificationLogStore struct {
+	called  bool
+	query   models.GetNotificationLogQuery
+	entries []models.NotificationLogEntry
+}
+
+func (f *fakeNotificationLogStore) GetNotificationLog(_ context.Context, query models.GetNotificationLogQuery) ([]models.NotificationLogEntry, error) {
+	f.called, f.query = true, query
+	return f.entries, nil
+}
+
+func (f *fakeNotificationLogStore) GetNotificationLogEntry(_ context.Context, orgID, id int64) (*models.NotificationLogEntry, error) {
+	for _, e := range f.entries {
+		if e.OrgID == orgID && e.ID == id {
+			return &e, nil
+		}
+	}
+	return nil, models.ErrNotificationLogEntryNotFound
+}
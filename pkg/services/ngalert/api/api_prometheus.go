@@ -190,11 +190,12 @@ func (srv PrometheusSrv) toRuleGroup(groupName string, folder *folder.Folder, ru
 	ngmodels.RulesGroup(rules).SortByGroupIndex()
 	for _, rule := range rules {
 		alertingRule := apimodels.AlertingRule{
-			State:       "inactive",
-			Name:        rule.Title,
-			Query:       ruleToQuery(srv.log, rule),
-			Duration:    rule.For.Seconds(),
-			Annotations: rule.Annotations,
+			State:         "inactive",
+			Name:          rule.Title,
+			Query:         ruleToQuery(srv.log, rule),
+			Duration:      rule.For.Seconds(),
+			KeepFiringFor: rule.KeepFiringFor.Seconds(),
+			Annotations:   rule.Annotations,
 		}
 
 		newRule := apimodels.Rule{
@@ -205,6 +206,16 @@ func (srv PrometheusSrv) toRuleGroup(groupName string, folder *folder.Folder, ru
 			LastEvaluation: time.Time{},
 		}
 
+		if rule.IsRecordingRule() {
+			// recording rules do not have alerts
+			alertingRule.State = ""
+			newRule.Type = apiv1.RuleTypeRecording
+			alertingRule.Rule = newRule
+			newGroup.Rules = append(newGroup.Rules, alertingRule)
+			newGroup.Interval = float64(rule.IntervalSeconds)
+			continue
+		}
+
 		for _, alertState := range srv.manager.GetStatesForRuleUID(rule.OrgID, rule.UID) {
 			activeAt := alertState.StartsAt
 			valString := ""
@@ -222,6 +233,10 @@ func (srv PrometheusSrv) toRuleGroup(groupName string, folder *folder.Folder, ru
 				ActiveAt: &activeAt,
 				Value:    valString,
 			}
+			if !alertState.KeepFiringSince.IsZero() {
+				keepFiringSince := alertState.KeepFiringSince
+				alert.KeepFiringSince = &keepFiringSince
+			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
 				newRule.LastEvaluation = alertState.LastEvaluationTime
@@ -9,7 +9,9 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
@@ -22,6 +24,7 @@ const disableProvenanceHeaderName = "X-Disable-Provenance"
 
 type ProvisioningSrv struct {
 	log                 log.Logger
+	ac                  accesscontrol.AccessControl
 	policies            NotificationPolicyService
 	contactPointService ContactPointService
 	templates           TemplateService
@@ -80,6 +83,22 @@ func (srv *ProvisioningSrv) RouteGetPolicyTree(c *contextmodel.ReqContext) respo
 	return response.JSON(http.StatusOK, policies)
 }
 
+// RouteGetPolicyTreeExport retrieves the notification policy tree in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetPolicyTreeExport(c *contextmodel.ReqContext) response.Response {
+	policies, err := srv.policies.GetPolicyTree(c.Req.Context(), c.OrgID)
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return exportResponse(c, definitions.AlertingFileExport{
+		APIVersion: 1,
+		Policies:   []definitions.NotificationPolicyExport{PolicyTreeToExport(c.OrgID, policies)},
+	})
+}
+
 func (srv *ProvisioningSrv) RoutePutPolicyTree(c *contextmodel.ReqContext, tree definitions.Route) response.Response {
 	err := srv.policies.UpdatePolicyTree(c.Req.Context(), c.OrgID, tree, alerting_models.ProvenanceAPI)
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -115,6 +134,32 @@ func (srv *ProvisioningSrv) RouteGetContactPoints(c *contextmodel.ReqContext) re
 	return response.JSON(http.StatusOK, cps)
 }
 
+// RouteGetContactPointsExport retrieves all contact points in a format compatible with file provisioning.
+// The secure settings are redacted, unless they are decrypted on request of a user that is allowed to read them.
+func (srv *ProvisioningSrv) RouteGetContactPointsExport(c *contextmodel.ReqContext) response.Response {
+	decrypt := c.QueryBoolWithDefault("decrypt", false)
+	if decrypt && !accesscontrol.HasAccess(srv.ac, c)(accesscontrol.ReqOrgAdmin, accesscontrol.EvalPermission(accesscontrol.ActionAlertingProvisioningReadSecrets)) {
+		return ErrResp(http.StatusForbidden, errors.New("user is not allowed to read the secure settings of contact points"), "")
+	}
+
+	q := provisioning.ContactPointQuery{
+		Name:    c.Query("name"),
+		OrgID:   c.OrgID,
+		Decrypt: decrypt,
+	}
+	cps, err := srv.contactPointService.GetContactPoints(c.Req.Context(), q)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	e, err := ContactPointsToExport(c.OrgID, cps)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
+	}
+
+	return exportResponse(c, definitions.AlertingFileExport{APIVersion: 1, ContactPoints: e})
+}
+
 func (srv *ProvisioningSrv) RoutePostContactPoint(c *contextmodel.ReqContext, cp definitions.EmbeddedContactPoint) response.Response {
 	// TODO: provenance is hardcoded for now, change it later to make it more flexible
 	contactPoint, err := srv.contactPointService.CreateContactPoint(c.Req.Context(), c.OrgID, cp, alerting_models.ProvenanceAPI)
@@ -145,6 +190,9 @@ func (srv *ProvisioningSrv) RoutePutContactPoint(c *contextmodel.ReqContext, cp
 func (srv *ProvisioningSrv) RouteDeleteContactPoint(c *contextmodel.ReqContext, UID string) response.Response {
 	err := srv.contactPointService.DeleteContactPoint(c.Req.Context(), c.OrgID, UID)
 	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "contactpoint deleted"})
@@ -162,6 +210,19 @@ func (srv *ProvisioningSrv) RouteGetTemplates(c *contextmodel.ReqContext) respon
 	return response.JSON(http.StatusOK, result)
 }
 
+// RouteGetTemplatesExport retrieves all notification templates in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetTemplatesExport(c *contextmodel.ReqContext) response.Response {
+	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return exportResponse(c, definitions.AlertingFileExport{
+		APIVersion: 1,
+		Templates:  TemplatesToExport(c.OrgID, templates),
+	})
+}
+
 func (srv *ProvisioningSrv) RouteGetTemplate(c *contextmodel.ReqContext, name string) response.Response {
 	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgID)
 	if err != nil {
@@ -218,6 +279,36 @@ func (srv *ProvisioningSrv) RouteGetMuteTimings(c *contextmodel.ReqContext) resp
 	return response.JSON(http.StatusOK, timings)
 }
 
+// RouteGetMuteTimingsExport retrieves all mute timings in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetMuteTimingsExport(c *contextmodel.ReqContext) response.Response {
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return exportResponse(c, definitions.AlertingFileExport{
+		APIVersion:  1,
+		MuteTimings: MuteTimingsToExport(c.OrgID, timings),
+	})
+}
+
+// RouteGetMuteTimingExport retrieves the given mute timing in a format compatible with file provisioning.
+func (srv *ProvisioningSrv) RouteGetMuteTimingExport(c *contextmodel.ReqContext, name string) response.Response {
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	for _, timing := range timings {
+		if name == timing.Name {
+			return exportResponse(c, definitions.AlertingFileExport{
+				APIVersion:  1,
+				MuteTimings: MuteTimingsToExport(c.OrgID, []definitions.MuteTimeInterval{timing}),
+			})
+		}
+	}
+	return response.Empty(http.StatusNotFound)
+}
+
 func (srv *ProvisioningSrv) RoutePostMuteTiming(c *contextmodel.ReqContext, mt definitions.MuteTimeInterval) response.Response {
 	mt.Provenance = definitions.Provenance(alerting_models.ProvenanceAPI)
 	created, err := srv.muteTimings.CreateMuteTiming(c.Req.Context(), mt, c.OrgID)
@@ -249,6 +340,9 @@ func (srv *ProvisioningSrv) RoutePutMuteTiming(c *contextmodel.ReqContext, mt de
 func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, name string) response.Response {
 	err := srv.muteTimings.DeleteMuteTiming(c.Req.Context(), name, c.OrgID)
 	if err != nil {
+		if errors.Is(err, provisioning.ErrValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 	return response.JSON(http.StatusNoContent, nil)
@@ -347,12 +441,12 @@ func (srv *ProvisioningSrv) RouteGetAlertRulesExport(c *contextmodel.ReqContext)
 		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
 	}
 
-	e, err := file.NewAlertingFileExport(groupsWithTitle)
+	e, err := file.NewAlertRuleGroupExports(groupsWithTitle)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
 	}
 
-	return exportResponse(c, e)
+	return exportResponse(c, definitions.AlertingFileExport{APIVersion: 1, Groups: e})
 }
 
 // RouteGetAlertRuleGroupExport retrieves the given alert rule group in a format compatible with file provisioning.
@@ -365,12 +459,12 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleGroupExport(c *contextmodel.ReqCont
 		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule group")
 	}
 
-	e, err := file.NewAlertingFileExport([]file.AlertRuleGroupWithFolderTitle{g})
+	e, err := file.NewAlertRuleGroupExports([]file.AlertRuleGroupWithFolderTitle{g})
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
 	}
 
-	return exportResponse(c, e)
+	return exportResponse(c, definitions.AlertingFileExport{APIVersion: 1, Groups: e})
 }
 
 // RouteGetAlertRuleExport retrieves the given alert rule in a format compatible with file provisioning.
@@ -383,7 +477,7 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleExport(c *contextmodel.ReqContext,
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 
-	e, err := file.NewAlertingFileExport([]file.AlertRuleGroupWithFolderTitle{{
+	e, err := file.NewAlertRuleGroupExports([]file.AlertRuleGroupWithFolderTitle{{
 		AlertRuleGroup: &alerting_models.AlertRuleGroup{
 			Title:     rule.AlertRule.RuleGroup,
 			FolderUID: rule.AlertRule.NamespaceUID,
@@ -397,7 +491,7 @@ func (srv *ProvisioningSrv) RouteGetAlertRuleExport(c *contextmodel.ReqContext,
 		return ErrResp(http.StatusInternalServerError, err, "failed to create alerting file export")
 	}
 
-	return exportResponse(c, e)
+	return exportResponse(c, definitions.AlertingFileExport{APIVersion: 1, Groups: e})
 }
 
 func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, ag definitions.AlertRuleGroup, folderUID string, group string) response.Response {
@@ -427,7 +521,7 @@ func determineProvenance(ctx *contextmodel.ReqContext) alerting_models.Provenanc
 	return alerting_models.ProvenanceAPI
 }
 
-func exportResponse(c *contextmodel.ReqContext, body any) response.Response {
+func exportResponse(c *contextmodel.ReqContext, body definitions.AlertingFileExport) response.Response {
 	var format = "yaml"
 
 	acceptHeader := c.Req.Header.Get("Accept")
@@ -439,12 +533,19 @@ func exportResponse(c *contextmodel.ReqContext, body any) response.Response {
 		format = "json"
 	}
 
+	if strings.Contains(acceptHeader, "hcl") {
+		format = "hcl"
+	}
+
 	queryFormat := c.Query("format")
-	if queryFormat == "yaml" || queryFormat == "json" {
+	if queryFormat == "yaml" || queryFormat == "json" || queryFormat == "hcl" {
 		format = queryFormat
 	}
 
 	download := c.QueryBoolWithDefault("download", false)
+	if format == "hcl" {
+		return exportHcl(download, body)
+	}
 	if download {
 		r := response.JSONDownload
 		if format == "yaml" {
@@ -459,3 +560,16 @@ func exportResponse(c *contextmodel.ReqContext, body any) response.Response {
 	}
 	return r(http.StatusOK, body)
 }
+
+// exportHcl returns the export as Terraform resources. Downloaded files have the extension of Terraform files.
+func exportHcl(download bool, body definitions.AlertingFileExport) response.Response {
+	b, err := hcl.Encode(body)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to encode alerting file export in HCL")
+	}
+	r := response.Respond(http.StatusOK, b).SetHeader("Content-Type", "text/hcl")
+	if download {
+		r.SetHeader("Content-Disposition", `attachment;filename="export.tf"`)
+	}
+	return r
+}
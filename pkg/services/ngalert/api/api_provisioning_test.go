@@ -11,20 +11,26 @@ import (
 	"time"
 
 	prometheus "github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	secrets_fakes "github.com/grafana/grafana/pkg/services/secrets/fakes"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -802,11 +808,248 @@ func TestProvisioningApi(t *testing.T) {
 				require.Equal(t, expectedResponse, string(response.Body()))
 			})
 		})
+
+		t.Run("contact points", func(t *testing.T) {
+			t.Run("secure settings are redacted by default", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Contains(t, string(response.Body()), definitions.RedactedValue)
+				require.NotContains(t, string(response.Body()), "secret-token")
+			})
+
+			t.Run("decrypt without permission, GET returns 403", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Form.Set("decrypt", "true")
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 403, response.Status())
+			})
+
+			t.Run("decrypt with permission, GET returns decrypted secure settings", func(t *testing.T) {
+				env := createTestEnv(t)
+				env.ac = acmock.New().WithPermissions([]accesscontrol.Permission{
+					{Action: accesscontrol.ActionAlertingProvisioningReadSecrets},
+				})
+				sut := createProvisioningSrvSutFromEnv(t, &env)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Form.Set("decrypt", "true")
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Contains(t, string(response.Body()), "secret-token")
+				require.NotContains(t, string(response.Body()), definitions.RedactedValue)
+			})
+
+			t.Run("query param name filters contact points", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Form.Set("name", "email receiver")
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Contains(t, string(response.Body()), "email receiver")
+				require.NotContains(t, string(response.Body()), "slack receiver")
+			})
+
+			t.Run("yaml body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Header.Add("Accept", "application/yaml")
+				expectedResponse := "apiVersion: 1\ncontactPoints:\n    - orgId: 1\n      name: email receiver\n      receivers:\n        - uid: email-uid\n          type: email\n          settings:\n            addresses: <example@email.com>\n          disableResolveMessage: false\n    - orgId: 1\n      name: slack receiver\n      receivers:\n        - uid: slack-uid\n          type: slack\n          settings:\n            recipient: '#alerts'\n            token: '[REDACTED]'\n          disableResolveMessage: false\n"
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("json body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Header.Add("Accept", "application/json")
+				expectedResponse := `{"apiVersion":1,"contactPoints":[{"orgId":1,"name":"email receiver","receivers":[{"uid":"email-uid","type":"email","settings":{"addresses":"\u003cexample@email.com\u003e"},"disableResolveMessage":false}]},{"orgId":1,"name":"slack receiver","receivers":[{"uid":"slack-uid","type":"slack","settings":{"recipient":"#alerts","token":"[REDACTED]"},"disableResolveMessage":false}]}]}`
+
+				response := sut.RouteGetContactPointsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("hcl body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Form.Set("format", "hcl")
+				expectedResponse := `resource "grafana_contact_point" "email_receiver" {
+  org_id = "1"
+  name   = "email receiver"
+  email {
+    uid                     = "email-uid"
+    disable_resolve_message = false
+    addresses               = ["<example@email.com>"]
+  }
+}
+
+resource "grafana_contact_point" "slack_receiver" {
+  org_id = "1"
+  name   = "slack receiver"
+  slack {
+    uid                     = "slack-uid"
+    disable_resolve_message = false
+    recipient               = "#alerts"
+    token                   = "[REDACTED]"
+  }
+}
+`
+
+				response := sut.RouteGetContactPointsExport(&rc)
+				response.WriteTo(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "text/hcl", rc.Context.Resp.Header().Get("Content-Type"))
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+		})
+
+		t.Run("notification policies", func(t *testing.T) {
+			t.Run("yaml body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				sut.policies = &fakeNotificationPolicyService{tree: createTestPolicyTree()}
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Header.Add("Accept", "application/yaml")
+				expectedResponse := "apiVersion: 1\npolicies:\n    - orgId: 1\n      receiver: email receiver\n      group_by:\n        - alertname\n      routes:\n        - receiver: slack receiver\n          object_matchers:\n            - - team\n              - =\n              - alerting\n          mute_time_intervals:\n            - interval\n          continue: true\n      group_wait: 30s\n"
+
+				response := sut.RouteGetPolicyTreeExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("json body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				sut.policies = &fakeNotificationPolicyService{tree: createTestPolicyTree()}
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Header.Add("Accept", "application/json")
+				expectedResponse := `{"apiVersion":1,"policies":[{"orgId":1,"receiver":"email receiver","group_by":["alertname"],"routes":[{"receiver":"slack receiver","object_matchers":[["team","=","alerting"]],"mute_time_intervals":["interval"],"continue":true}],"group_wait":"30s"}]}`
+
+				response := sut.RouteGetPolicyTreeExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("when org has no AM config, GET returns 404", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				rc.SignedInUser.OrgID = 2
+
+				response := sut.RouteGetPolicyTreeExport(&rc)
+
+				require.Equal(t, 404, response.Status())
+			})
+		})
+
+		t.Run("mute timings", func(t *testing.T) {
+			t.Run("yaml body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Header.Add("Accept", "application/yaml")
+				expectedResponse := "apiVersion: 1\nmuteTimes:\n    - orgId: 1\n      name: interval\n      time_intervals: []\n"
+
+				response := sut.RouteGetMuteTimingsExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("are present, GET by name returns 200", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetMuteTimingExport(&rc, "interval")
+
+				require.Equal(t, 200, response.Status())
+			})
+
+			t.Run("are missing, GET by name returns 404", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				response := sut.RouteGetMuteTimingExport(&rc, "does not exist")
+
+				require.Equal(t, 404, response.Status())
+			})
+		})
+
+		t.Run("templates", func(t *testing.T) {
+			t.Run("yaml body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Header.Add("Accept", "application/yaml")
+				expectedResponse := "apiVersion: 1\ntemplates:\n    - orgId: 1\n      name: a\n      template: template\n"
+
+				response := sut.RouteGetTemplatesExport(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+		})
+
+		t.Run("exported files can be provisioned", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			sut.policies = &fakeNotificationPolicyService{tree: createTestPolicyTree()}
+			rc := createTestRequestCtx()
+			rc.Context.Req.Header.Add("Accept", "application/yaml")
+
+			parse := func(t *testing.T, r response.Response) alerting.AlertingFile {
+				t.Helper()
+				require.Equal(t, 200, r.Status())
+				var fileV1 alerting.AlertingFileV1
+				require.NoError(t, yaml.Unmarshal(r.Body(), &fileV1))
+				file, err := fileV1.MapToModel()
+				require.NoError(t, err)
+				return file
+			}
+
+			file := parse(t, sut.RouteGetContactPointsExport(&rc))
+			require.Len(t, file.ContactPoints, 2)
+			require.Equal(t, "slack-uid", file.ContactPoints[1].ContactPoints[0].UID)
+
+			file = parse(t, sut.RouteGetPolicyTreeExport(&rc))
+			require.Len(t, file.Policies, 1)
+			expected := createTestPolicyTree()
+			expected.Provenance = ""
+			expected.Routes[0].Provenance = ""
+			require.Equal(t, expected, file.Policies[0].Policy)
+
+			file = parse(t, sut.RouteGetMuteTimingsExport(&rc))
+			require.Len(t, file.MuteTimes, 1)
+			require.Equal(t, "interval", file.MuteTimes[0].MuteTime.Name)
+
+			file = parse(t, sut.RouteGetTemplatesExport(&rc))
+			require.Len(t, file.Templates, 1)
+			require.Equal(t, definitions.NotificationTemplate{Name: "a", Template: "template"}, file.Templates[0].Data)
+		})
 	})
 }
 
 // testEnvironment binds together common dependencies for testing alerting APIs.
 type testEnvironment struct {
+	ac               *acmock.Mock
 	secrets          secrets.Service
 	log              log.Logger
 	store            store.DBstore
@@ -840,6 +1083,7 @@ func createTestEnv(t *testing.T) testEnvironment {
 	prov := &provisioning.MockProvisioningStore{}
 	prov.EXPECT().SaveSucceeds()
 	prov.EXPECT().GetReturns(models.ProvenanceNone)
+	prov.EXPECT().GetProvenances(mock.Anything, mock.Anything, mock.Anything).Return(map[string]models.Provenance{}, nil)
 
 	dashboardService := dashboards.NewFakeDashboardService(t)
 	dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(&dashboards.Dashboard{
@@ -856,6 +1100,7 @@ func createTestEnv(t *testing.T) testEnvironment {
 		}}, nil).Maybe()
 
 	return testEnvironment{
+		ac:               acmock.New(),
 		secrets:          secrets,
 		log:              log,
 		configs:          configs,
@@ -879,11 +1124,12 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 
 	return ProvisioningSrv{
 		log:                 env.log,
+		ac:                  env.ac,
 		policies:            newFakeNotificationPolicyService(),
-		contactPointService: provisioning.NewContactPointService(env.configs, env.secrets, env.prov, env.xact, env.log),
+		contactPointService: provisioning.NewContactPointService(env.configs, env.store, env.secrets, env.prov, env.xact, env.log),
 		templates:           provisioning.NewTemplateService(env.configs, env.prov, env.xact, env.log),
-		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
-		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.dashboardService, env.quotas, env.xact, 60, 10, env.log),
+		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.store, env.prov, env.xact, env.log),
+		alertRules:          provisioning.NewAlertRuleService(env.store, env.configs, env.prov, env.dashboardService, env.quotas, env.xact, 60, 10, env.log),
 	}
 }
 
@@ -967,6 +1213,25 @@ func (f *fakeRejectingNotificationPolicyService) ResetPolicyTree(ctx context.Con
 	return definitions.Route{}, nil
 }
 
+func createTestPolicyTree() definitions.Route {
+	groupWait := model.Duration(30 * time.Second)
+	return definitions.Route{
+		Receiver:   "email receiver",
+		GroupByStr: []string{"alertname"},
+		GroupWait:  &groupWait,
+		Routes: []*definitions.Route{{
+			Receiver: "slack receiver",
+			ObjectMatchers: definitions.ObjectMatchers{
+				{Name: "team", Type: labels.MatchEqual, Value: "alerting"},
+			},
+			MuteTimeIntervals: []string{"interval"},
+			Continue:          true,
+			Provenance:        definitions.Provenance(models.ProvenanceAPI),
+		}},
+		Provenance: definitions.Provenance(models.ProvenanceAPI),
+	}
+}
+
 func createInvalidContactPoint() definitions.EmbeddedContactPoint {
 	settings, _ := simplejson.NewJson([]byte(`{}`))
 	return definitions.EmbeddedContactPoint{
@@ -1117,6 +1382,20 @@ var testConfig = `
 					"addresses": "<example@email.com>"
 				}
 			}]
+		},
+		{
+			"name": "slack",
+			"grafana_managed_receiver_configs": [{
+				"uid": "slack-uid",
+				"name": "slack receiver",
+				"type": "slack",
+				"settings": {
+					"recipient": "#alerts"
+				},
+				"secureSettings": {
+					"token": "c2VjcmV0LXRva2Vu"
+				}
+			}]
 		}],
 		"mute_time_intervals": [{
 			"name": "interval",
@@ -36,6 +36,7 @@ type RulerSrv struct {
 	xactManager        provisioning.TransactionManager
 	provenanceStore    provisioning.ProvisioningStore
 	store              RuleStore
+	amConfigStore      AlertingStore
 	QuotaService       quota.Service
 	scheduleService    schedule.ScheduleService
 	log                log.Logger
@@ -354,6 +355,10 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return err
 		}
 
+		if err := validateNotificationSettings(tranCtx, srv.amConfigStore, c.OrgID, groupChanges); err != nil {
+			return err
+		}
+
 		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
 		logger.Debug("updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
 
@@ -467,22 +472,24 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 	}
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
-			ID:              r.ID,
-			OrgID:           r.OrgID,
-			Title:           r.Title,
-			Condition:       r.Condition,
-			Data:            r.Data,
-			Updated:         r.Updated,
-			IntervalSeconds: r.IntervalSeconds,
-			Version:         r.Version,
-			UID:             r.UID,
-			NamespaceUID:    r.NamespaceUID,
-			NamespaceID:     namespaceID,
-			RuleGroup:       r.RuleGroup,
-			NoDataState:     apimodels.NoDataState(r.NoDataState),
-			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
-			Provenance:      apimodels.Provenance(provenance),
-			IsPaused:        r.IsPaused,
+			ID:                   r.ID,
+			OrgID:                r.OrgID,
+			Title:                r.Title,
+			Condition:            r.Condition,
+			Data:                 r.Data,
+			Updated:              r.Updated,
+			IntervalSeconds:      r.IntervalSeconds,
+			Version:              r.Version,
+			UID:                  r.UID,
+			NamespaceUID:         r.NamespaceUID,
+			NamespaceID:          namespaceID,
+			RuleGroup:            r.RuleGroup,
+			NoDataState:          apimodels.NoDataState(r.NoDataState),
+			ExecErrState:         apimodels.ExecutionErrorState(r.ExecErrState),
+			Provenance:           apimodels.Provenance(provenance),
+			IsPaused:             r.IsPaused,
+			Record:               ApiRecordFromModel(r.Record),
+			NotificationSettings: ApiNotificationSettingsFromModel(r.NotificationSettings),
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -491,6 +498,10 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provena
 		Annotations: r.Annotations,
 		Labels:      r.Labels,
 	}
+	if r.KeepFiringFor > 0 {
+		keepFiringFor := model.Duration(r.KeepFiringFor)
+		gettableExtendedRuleNode.ApiRuleNode.KeepFiringFor = &keepFiringFor
+	}
 	return gettableExtendedRuleNode
 }
 
@@ -506,6 +517,19 @@ func toNamespaceErrorResponse(err error) response.Response {
 
 // verifyProvisionedRulesNotAffected check that neither of provisioned alerts are affected by changes.
 // Returns errProvisionedResource if there is at least one rule in groups affected by changes that was provisioned.
+// validateNotificationSettings checks that the contact points and mute timings of the notification settings of the
+// new and updated rules exist.
+func validateNotificationSettings(ctx context.Context, amConfigStore AlertingStore, orgID int64, ch *store.GroupDelta) error {
+	rules := make([]ngmodels.AlertRule, 0, len(ch.New)+len(ch.Update))
+	for _, rule := range ch.New {
+		rules = append(rules, *rule)
+	}
+	for _, update := range ch.Update {
+		rules = append(rules, *update.New)
+	}
+	return provisioning.ValidateNotificationSettings(ctx, amConfigStore, orgID, rules...)
+}
+
 func verifyProvisionedRulesNotAffected(ctx context.Context, provenanceStore provisioning.ProvisioningStore, orgID int64, ch *store.GroupDelta) error {
 	provenances, err := provenanceStore.GetProvenances(ctx, orgID, (&ngmodels.AlertRule{}).ResourceType())
 	if err != nil {
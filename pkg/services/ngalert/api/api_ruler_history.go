@@ -2,19 +2,26 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 type Historian interface {
 	QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
+	QueryTransitionCounts(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
 }
 
 type HistorySrv struct {
@@ -24,29 +31,120 @@ type HistorySrv struct {
 
 const labelQueryPrefix = "labels_"
 
+// maxHistoryQueryEntries is the maximum number of state transitions a page of state history can reach, including the
+// ones skipped by the offset.
+const maxHistoryQueryEntries = 5000
+
 func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) response.Response {
-	from := c.QueryInt64("from")
-	to := c.QueryInt64("to")
-	ruleUID := c.Query("ruleUID")
+	query, err := parseHistoryQuery(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	frame, err := srv.hist.QueryStates(c.Req.Context(), query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, frame)
+}
+
+func (srv *HistorySrv) RouteQueryStateTransitions(c *contextmodel.ReqContext) response.Response {
+	query, err := parseHistoryQuery(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	frame, err := srv.hist.QueryTransitionCounts(c.Req.Context(), query)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, frame)
+}
+
+func parseHistoryQuery(c *contextmodel.ReqContext) (models.HistoryQuery, error) {
+	params := c.Req.URL.Query()
 
 	labels := make(map[string]string)
-	for k, v := range c.Req.URL.Query() {
+	for k, v := range params {
 		if strings.HasPrefix(k, labelQueryPrefix) {
 			labels[k[len(labelQueryPrefix):]] = v[0]
 		}
 	}
 
 	query := models.HistoryQuery{
-		RuleUID:      ruleUID,
-		OrgID:        c.OrgID,
-		SignedInUser: c.SignedInUser,
-		From:         time.Unix(from, 0),
-		To:           time.Unix(to, 0),
-		Labels:       labels,
+		RuleUID:       c.Query("ruleUID"),
+		OrgID:         c.OrgID,
+		SignedInUser:  c.SignedInUser,
+		Labels:        labels,
+		PreviousState: c.Query("previous"),
+		CurrentState:  c.Query("current"),
 	}
-	frame, err := srv.hist.QueryStates(c.Req.Context(), query)
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(from, 0)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(to, 0)
+	}
+
+	matchers, err := parseHistoryMatchers(params["matcher"])
 	if err != nil {
-		return ErrResp(http.StatusInternalServerError, err, "")
+		return models.HistoryQuery{}, err
 	}
-	return response.JSON(http.StatusOK, frame)
+	query.Matchers = matchers
+
+	if err := validateHistoryState(query.PreviousState); err != nil {
+		return models.HistoryQuery{}, fmt.Errorf("invalid previous state: %w", err)
+	}
+	if err := validateHistoryState(query.CurrentState); err != nil {
+		return models.HistoryQuery{}, fmt.Errorf("invalid current state: %w", err)
+	}
+
+	if query.Limit, err = parseHistoryPageParam(params.Get("limit")); err != nil {
+		return models.HistoryQuery{}, fmt.Errorf("invalid limit: %w", err)
+	}
+	if query.Offset, err = parseHistoryPageParam(params.Get("offset")); err != nil {
+		return models.HistoryQuery{}, fmt.Errorf("invalid offset: %w", err)
+	}
+	if query.Limit+query.Offset > maxHistoryQueryEntries {
+		return models.HistoryQuery{}, fmt.Errorf("the sum of limit and offset must not be greater than %d", maxHistoryQueryEntries)
+	}
+	return query, nil
+}
+
+// parseHistoryMatchers parses label matchers such as `severity=~"critical|warning"`. Each value can have a single
+// matcher or a list of matchers in curly braces.
+func parseHistoryMatchers(values []string) (labels.Matchers, error) {
+	var result labels.Matchers
+	for _, v := range values {
+		matchers, err := labels.ParseMatchers(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", v, err)
+		}
+		result = append(result, matchers...)
+	}
+	return result, nil
+}
+
+func validateHistoryState(state string) error {
+	if state == "" {
+		return nil
+	}
+	for _, s := range []eval.State{eval.Normal, eval.Alerting, eval.Pending, eval.NoData, eval.Error} {
+		if state == s.String() {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown state %q", state)
+}
+
+func parseHistoryPageParam(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return n, nil
 }
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestRouteQueryStateHistory(t *testing.T) {
+	t.Run("should pass filters and pagination to the historian", func(t *testing.T) {
+		hist := &fakeHistorian{}
+		srv := &HistorySrv{logger: log.NewNopLogger(), hist: hist}
+		req := createHistoryRequestContext(t, url.Values{
+			"ruleUID":      {"rule"},
+			"from":         {"1677664800"},
+			"labels_team":  {"a"},
+			"matcher":      {`severity=~"critical|warning"`, `{pod!="x",env!~"dev.*"}`},
+			"previous":     {"Normal"},
+			"current":      {"Alerting"},
+			"limit":        {"10"},
+			"offset":       {"20"},
+			"unrelatedKey": {"value"},
+		})
+
+		resp := srv.RouteQueryStateHistory(req)
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Equal(t, "rule", hist.query.RuleUID)
+		require.Equal(t, int64(1), hist.query.OrgID)
+		require.Equal(t, time.Unix(1677664800, 0), hist.query.From)
+		require.True(t, hist.query.To.IsZero())
+		require.Equal(t, map[string]string{"team": "a"}, hist.query.Labels)
+		require.Len(t, hist.query.Matchers, 3)
+		require.Equal(t, labels.MatchRegexp, hist.query.Matchers[0].Type)
+		require.Equal(t, labels.MatchNotEqual, hist.query.Matchers[1].Type)
+		require.Equal(t, labels.MatchNotRegexp, hist.query.Matchers[2].Type)
+		require.Equal(t, "Normal", hist.query.PreviousState)
+		require.Equal(t, "Alerting", hist.query.CurrentState)
+		require.Equal(t, 10, hist.query.Limit)
+		require.Equal(t, 20, hist.query.Offset)
+	})
+
+	t.Run("should return 400 on invalid parameters", func(t *testing.T) {
+		testCases := map[string]url.Values{
+			"invalid matcher":      {"matcher": {`severity=~"("`}},
+			"unknown state":        {"current": {"Firing"}},
+			"negative limit":       {"limit": {"-1"}},
+			"non-numeric offset":   {"offset": {"ten"}},
+			"too deep pagination":  {"limit": {"100"}, "offset": {"4950"}},
+			"unknown state reason": {"previous": {"Normal (NoData)"}},
+		}
+		for name, params := range testCases {
+			t.Run(name, func(t *testing.T) {
+				hist := &fakeHistorian{}
+				srv := &HistorySrv{logger: log.NewNopLogger(), hist: hist}
+
+				resp := srv.RouteQueryStateHistory(createHistoryRequestContext(t, params))
+
+				require.Equal(t, http.StatusBadRequest, resp.Status())
+				require.False(t, hist.called)
+			})
+		}
+	})
+}
+
+func TestRouteQueryStateTransitions(t *testing.T) {
+	hist := &fakeHistorian{}
+	srv := &HistorySrv{logger: log.NewNopLogger(), hist: hist}
+
+	resp := srv.RouteQueryStateTransitions(createHistoryRequestContext(t, url.Values{"current": {"Alerting"}}))
+
+	require.Equal(t, http.StatusOK, resp.Status())
+	require.True(t, hist.called)
+	require.Equal(t, "Alerting", hist.query.CurrentState)
+}
+
+func createHistoryRequestContext(t *testing.T, params url.Values) *contextmodel.ReqContext {
+	t.Helper()
+	req := createRequestContext(1, org.RoleViewer, nil)
+	u, err := url.Parse("http://localhost/api/v1/rules/history?" + params.Encode())
+	require.NoError(t, err)
+	req.Req.URL = u
+	return req
+}
+
+type fakeHistorian struct {
+	called bool
+	query  models.HistoryQuery
+}
+
+func (f *fakeHistorian) QueryStates(_ context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	f.called, f.query = true, query
+	return data.NewFrame("states"), nil
+}
+
+func (f *fakeHistorian) QueryTransitionCounts(_ context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	f.called, f.query = true, query
+	return data.NewFrame("transitions"), nil
+}
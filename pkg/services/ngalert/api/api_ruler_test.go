@@ -638,6 +638,46 @@ func TestVerifyProvisionedRulesNotAffected(t *testing.T) {
 	})
 }
 
+func TestValidateNotificationSettings(t *testing.T) {
+	orgID := rand.Int63()
+	amConfigStore := &provisioning.MockAMConfigStore{}
+	amConfigStore.EXPECT().GetsConfig(models.AlertConfiguration{
+		AlertmanagerConfiguration: `{
+			"alertmanager_config": {
+				"route": {"receiver": "default"},
+				"receivers": [{"name": "default"}, {"name": "team"}],
+				"mute_time_intervals": [{"name": "weekends"}]
+			}
+		}`,
+	})
+	delta := func(settings models.NotificationSettings) *store.GroupDelta {
+		rule := models.AlertRuleGen(withOrgID(orgID), models.WithNotificationSettings(settings))()
+		return &store.GroupDelta{New: []*models.AlertRule{rule}}
+	}
+
+	t.Run("should accept existing contact points and mute timings", func(t *testing.T) {
+		err := validateNotificationSettings(context.Background(), amConfigStore, orgID, delta(models.NotificationSettings{Receiver: "team", MuteTimeIntervals: []string{"weekends"}}))
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject missing contact points", func(t *testing.T) {
+		err := validateNotificationSettings(context.Background(), amConfigStore, orgID, delta(models.NotificationSettings{Receiver: "missing"}))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should reject missing mute timings", func(t *testing.T) {
+		err := validateNotificationSettings(context.Background(), amConfigStore, orgID, delta(models.NotificationSettings{Receiver: "team", MuteTimeIntervals: []string{"missing"}}))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should not read the configuration if rules have no notification settings", func(t *testing.T) {
+		rule := models.AlertRuleGen(withOrgID(orgID))()
+		rule.NotificationSettings = nil
+		err := validateNotificationSettings(context.Background(), nil, orgID, &store.GroupDelta{New: []*models.AlertRule{rule}})
+		require.NoError(t, err)
+	})
+}
+
 func createServiceWithProvenanceStore(ac *acMock.Mock, store *fakes.RuleStore, scheduler schedule.ScheduleService, provenanceStore provisioning.ProvisioningStore) *RulerSrv {
 	svc := createService(ac, store, scheduler)
 	svc.provenanceStore = provenanceStore
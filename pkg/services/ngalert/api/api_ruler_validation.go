@@ -76,9 +76,38 @@ func validateRuleNode(
 		}
 	}
 
+	condition := ruleNode.GrafanaManagedAlert.Condition
+	record := ModelRecordFromApi(ruleNode.GrafanaManagedAlert.Record)
+	if record != nil {
+		if !cfg.RecordingRules.Enabled {
+			return nil, fmt.Errorf("%w: recording rules are not enabled", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if len(ruleNode.GrafanaManagedAlert.Data) == 0 {
+			return nil, fmt.Errorf("%w: queries are not specified but record is. You must specify both queries and record to update an existing recording rule", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if err := record.Validate(ruleNode.GrafanaManagedAlert.Data); err != nil {
+			return nil, err
+		}
+		// the condition of a recording rule is the query or expression to record
+		if condition != "" && condition != record.From {
+			return nil, fmt.Errorf("%w: the condition %s of a recording rule must be the same as the query or expression to record %s", ngmodels.ErrAlertRuleFailedValidation, condition, record.From)
+		}
+		condition = record.From
+	}
+
+	notificationSettings := ModelNotificationSettingsFromApi(ruleNode.GrafanaManagedAlert.NotificationSettings)
+	if notificationSettings != nil {
+		if record != nil {
+			return nil, fmt.Errorf("%w: recording rules cannot have notification settings", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if err := notificationSettings.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(ruleNode.GrafanaManagedAlert.Data) != 0 {
 		cond := ngmodels.Condition{
-			Condition: ruleNode.GrafanaManagedAlert.Condition,
+			Condition: condition,
 			Data:      ruleNode.GrafanaManagedAlert.Data,
 		}
 		if err = conditionValidator(cond); err != nil {
@@ -87,16 +116,18 @@ func validateRuleNode(
 	}
 
 	newAlertRule := ngmodels.AlertRule{
-		OrgID:           orgId,
-		Title:           ruleNode.GrafanaManagedAlert.Title,
-		Condition:       ruleNode.GrafanaManagedAlert.Condition,
-		Data:            ruleNode.GrafanaManagedAlert.Data,
-		UID:             ruleNode.GrafanaManagedAlert.UID,
-		IntervalSeconds: intervalSeconds,
-		NamespaceUID:    namespace.UID,
-		RuleGroup:       groupName,
-		NoDataState:     noDataState,
-		ExecErrState:    errorState,
+		OrgID:                orgId,
+		Title:                ruleNode.GrafanaManagedAlert.Title,
+		Condition:            condition,
+		Data:                 ruleNode.GrafanaManagedAlert.Data,
+		UID:                  ruleNode.GrafanaManagedAlert.UID,
+		IntervalSeconds:      intervalSeconds,
+		NamespaceUID:         namespace.UID,
+		RuleGroup:            groupName,
+		NoDataState:          noDataState,
+		ExecErrState:         errorState,
+		Record:               record,
+		NotificationSettings: notificationSettings,
 	}
 
 	newAlertRule.For, err = validateForInterval(ruleNode)
@@ -104,6 +135,11 @@ func validateRuleNode(
 		return nil, err
 	}
 
+	newAlertRule.KeepFiringFor, err = validateKeepFiringForInterval(ruleNode)
+	if err != nil {
+		return nil, err
+	}
+
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
 		newAlertRule.Labels = ruleNode.ApiRuleNode.Labels
@@ -147,6 +183,21 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringForInterval validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil // if it's a new rule, do not keep firing
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
 // validateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
@@ -208,5 +259,16 @@ func validateRuleGroup(
 
 		result = append(result, &ruleWithOptionals)
 	}
+
+	group := make(ngmodels.RulesGroup, 0, len(result))
+	for _, rule := range result {
+		group = append(group, &rule.AlertRule)
+	}
+	if group.HasRuleReferences() {
+		// sort a copy because the rules must stay in the order of the request
+		if err := append(ngmodels.RulesGroup{}, group...).SortByDependencies(); err != nil {
+			return nil, fmt.Errorf("invalid rule references: %w", err)
+		}
+	}
 	return result, nil
 }
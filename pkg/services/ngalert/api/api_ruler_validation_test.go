@@ -133,6 +133,19 @@ func TestValidateRuleGroup(t *testing.T) {
 		}
 	})
 
+	t.Run("should accept references to rules of the group", func(t *testing.T) {
+		r1 := validRule()
+		r2 := validRule()
+		r2.GrafanaManagedAlert.Data = append(r2.GrafanaManagedAlert.Data, ruleReferenceQuery(r1.GrafanaManagedAlert.UID))
+		g := validGroup(cfg, r2, r1)
+		alerts, err := validateRuleGroup(&g, orgId, folder, func(condition models.Condition) error {
+			return nil
+		}, cfg)
+		require.NoError(t, err)
+		require.Len(t, alerts, 2)
+		require.Equal(t, r2.GrafanaManagedAlert.UID, alerts[0].UID)
+	})
+
 	t.Run("should show the payload has isPaused field", func(t *testing.T) {
 		for _, rule := range rules {
 			isPaused := true
@@ -150,6 +163,14 @@ func TestValidateRuleGroup(t *testing.T) {
 	})
 }
 
+func ruleReferenceQuery(ruleUID string) models.AlertQuery {
+	return models.AlertQuery{
+		RefID:         "REF",
+		DatasourceUID: models.RuleReferenceDatasourceUID,
+		Model:         []byte(fmt.Sprintf(`{"ruleUid": %q}`, ruleUID)),
+	}
+}
+
 func TestValidateRuleGroupFailures(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
@@ -209,6 +230,32 @@ func TestValidateRuleGroupFailures(t *testing.T) {
 				require.Contains(t, err.Error(), apiModel.Rules[0].GrafanaManagedAlert.UID)
 			},
 		},
+		{
+			name: "fail if rule references form a cycle",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r2 := validRule()
+				r1.GrafanaManagedAlert.Data = append(r1.GrafanaManagedAlert.Data, ruleReferenceQuery(r2.GrafanaManagedAlert.UID))
+				r2.GrafanaManagedAlert.Data = append(r2.GrafanaManagedAlert.Data, ruleReferenceQuery(r1.GrafanaManagedAlert.UID))
+				g := validGroup(cfg, r1, r2)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorContains(t, err, "invalid rule references")
+			},
+		},
+		{
+			name: "fail if rule references a rule of another group",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r1.GrafanaManagedAlert.Data = append(r1.GrafanaManagedAlert.Data, ruleReferenceQuery(util.GenerateShortUID()))
+				g := validGroup(cfg, r1)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorContains(t, err, "not in the same group")
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -264,6 +311,18 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Equal(t, api.ApiRuleNode.Labels, alert.Labels)
 			},
 		},
+		{
+			name: "coverts keep_firing_for",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(5 * time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, alert.KeepFiringFor)
+			},
+		},
 		{
 			name: "coverts api without ApiRuleNode",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -365,6 +424,15 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if keep_firing_for is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				keepFiringFor := model.Duration(-time.Minute)
+				r.ApiRuleNode.KeepFiringFor = &keepFiringFor
+				return &r
+			},
+		},
 		{
 			name: "fail if title is empty",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -483,6 +551,195 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 	}
 }
 
+func TestValidateRuleNode_Record(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+	cfg.RecordingRules.Enabled = true
+	successValidation := func(condition models.Condition) error {
+		return nil
+	}
+
+	t.Run("converts record and uses the recorded query as condition", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+
+		var validated models.Condition
+		alert, err := validateRuleNode(&r, "", cfg.BaseInterval, orgId, folder, func(condition models.Condition) error {
+			validated = condition
+			return nil
+		}, cfg)
+		require.NoError(t, err)
+		require.Equal(t, &models.Record{Metric: "test_metric", From: "A"}, alert.Record)
+		require.Equal(t, "A", alert.Condition)
+		require.Equal(t, "A", validated.Condition)
+	})
+
+	testCases := []struct {
+		name   string
+		cfg    func() *setting.UnifiedAlertingSettings
+		rule   func() *apimodels.PostableExtendedRuleNode
+		errMsg string
+	}{
+		{
+			name: "fail if recording rules are not enabled",
+			cfg: func() *setting.UnifiedAlertingSettings {
+				c := *cfg
+				c.RecordingRules.Enabled = false
+				return &c
+			},
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+				return &r
+			},
+			errMsg: "recording rules are not enabled",
+		},
+		{
+			name: "fail if metric name is not valid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test-metric", From: "A"}
+				return &r
+			},
+			errMsg: "\"test-metric\" is not a valid metric name",
+		},
+		{
+			name: "fail if recorded query does not exist",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "B"}
+				return &r
+			},
+			errMsg: "the query or expression B to record does not exist",
+		},
+		{
+			name: "fail if condition is not the recorded query",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Data = append(r.GrafanaManagedAlert.Data, models.AlertQuery{RefID: "B", DatasourceUID: "DATASOURCE_TEST"})
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "B"}
+				return &r
+			},
+			errMsg: "the condition A of a recording rule must be the same as the query or expression to record B",
+		},
+		{
+			name: "fail if there are no data",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Data = nil
+				r.GrafanaManagedAlert.Condition = ""
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+				return &r
+			},
+			errMsg: "queries are not specified but record is",
+		},
+		{
+			name: "fail if recording rule has notification settings",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "test_metric", From: "A"}
+				r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{Receiver: "test"}
+				return &r
+			},
+			errMsg: "recording rules cannot have notification settings",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := cfg
+			if testCase.cfg != nil {
+				c = testCase.cfg()
+			}
+			_, err := validateRuleNode(testCase.rule(), "", cfg.BaseInterval, orgId, folder, successValidation, c)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, testCase.errMsg)
+		})
+	}
+}
+
+func TestValidateRuleNode_NotificationSettings(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+	successValidation := func(condition models.Condition) error {
+		return nil
+	}
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+
+	t.Run("converts notification settings", func(t *testing.T) {
+		r := validRule()
+		r.GrafanaManagedAlert.NotificationSettings = &apimodels.AlertRuleNotificationSettings{
+			Receiver:          "test",
+			GroupBy:           []string{"pod"},
+			GroupWait:         duration(time.Second),
+			MuteTimeIntervals: []string{"weekends"},
+		}
+
+		alert, err := validateRuleNode(&r, "", cfg.BaseInterval, orgId, folder, successValidation, cfg)
+		require.NoError(t, err)
+		require.Equal(t, &models.NotificationSettings{
+			Receiver:          "test",
+			GroupBy:           []string{"pod"},
+			GroupWait:         duration(time.Second),
+			MuteTimeIntervals: []string{"weekends"},
+		}, alert.NotificationSettings)
+	})
+
+	testCases := []struct {
+		name     string
+		settings apimodels.AlertRuleNotificationSettings
+		errMsg   string
+	}{
+		{
+			name:     "fail if contact point is empty",
+			settings: apimodels.AlertRuleNotificationSettings{},
+			errMsg:   "the contact point of the notification settings must be set",
+		},
+		{
+			name:     "fail if group by mixes '...' with other labels",
+			settings: apimodels.AlertRuleNotificationSettings{Receiver: "test", GroupBy: []string{"...", "pod"}},
+			errMsg:   "cannot contain other labels with '...'",
+		},
+		{
+			name:     "fail if group by has invalid labels",
+			settings: apimodels.AlertRuleNotificationSettings{Receiver: "test", GroupBy: []string{"invalid-label"}},
+			errMsg:   "contains invalid label \"invalid-label\"",
+		},
+		{
+			name:     "fail if group wait is negative",
+			settings: apimodels.AlertRuleNotificationSettings{Receiver: "test", GroupWait: duration(-time.Second)},
+			errMsg:   "group_wait of the notification settings cannot be negative",
+		},
+		{
+			name:     "fail if group interval is zero",
+			settings: apimodels.AlertRuleNotificationSettings{Receiver: "test", GroupInterval: duration(0)},
+			errMsg:   "group_interval of the notification settings must be positive",
+		},
+		{
+			name:     "fail if repeat interval is zero",
+			settings: apimodels.AlertRuleNotificationSettings{Receiver: "test", RepeatInterval: duration(0)},
+			errMsg:   "repeat_interval of the notification settings must be positive",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := validRule()
+			settings := testCase.settings
+			r.GrafanaManagedAlert.NotificationSettings = &settings
+			_, err := validateRuleNode(&r, "", cfg.BaseInterval, orgId, folder, successValidation, cfg)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			require.ErrorContains(t, err, testCase.errMsg)
+		})
+	}
+}
+
 func TestValidateRuleNode_UID(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
@@ -528,6 +785,17 @@ func TestValidateRuleNode_UID(t *testing.T) {
 				require.Equal(t, models.ExecutionErrorState(""), alert.ExecErrState)
 			},
 		},
+		{
+			name: "use -1 KeepFiringFor if it is not specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.ApiRuleNode.KeepFiringFor = nil
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, time.Duration(-1), alert.KeepFiringFor)
+			},
+		},
 		{
 			name: "use empty Condition and Data if they are empty",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -33,6 +33,7 @@ type TestingApiSrv struct {
 	cfg             *setting.UnifiedAlertingSettings
 	backtesting     *backtesting.Engine
 	featureManager  featuremgmt.FeatureToggles
+	ruleStore       RuleStore
 }
 
 func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *contextmodel.ReqContext, body apimodels.TestRulePayload) response.Response {
@@ -159,6 +160,14 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	if err != nil {
 		return ErrResp(400, err, "")
 	}
+	execErrState := ngmodels.ErrorErrState
+	if cmd.ExecErrState != "" {
+		execErrState, err = ngmodels.ErrStateFromString(string(cmd.ExecErrState))
+		if err != nil {
+			return ErrResp(400, err, "")
+		}
+	}
+
 	forInterval := time.Duration(cmd.For)
 	if forInterval < 0 {
 		return ErrResp(400, nil, "Bad For interval")
@@ -175,16 +184,28 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		return errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization))
 	}
 
+	folderTitle := ""
+	if cmd.NamespaceUID != "" {
+		namespaces, err := srv.ruleStore.GetUserVisibleNamespaces(c.Req.Context(), c.OrgID, c.SignedInUser)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+		}
+		namespace, ok := namespaces[cmd.NamespaceUID]
+		if !ok {
+			return ErrResp(http.StatusBadRequest, nil, "folder %s does not exist or the user does not have access to it", cmd.NamespaceUID)
+		}
+		folderTitle = namespace.Title
+	}
+
 	rule := &ngmodels.AlertRule{
 		// ID:             0,
 		// Updated:        time.Time{},
 		// Version:        0,
-		// NamespaceUID:   "",
+		NamespaceUID: cmd.NamespaceUID,
 		// DashboardUID:   nil,
 		// PanelID:        nil,
 		// RuleGroup:      "",
 		// RuleGroupIndex: 0,
-		// ExecErrState:   "",
 		Title: cmd.Title,
 		// prefix backtesting- is to distinguish between executions of regular rule and backtesting in logs (like expression engine, evaluator, state manager etc)
 		UID:             "backtesting-" + util.GenerateShortUID(),
@@ -193,12 +214,13 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Data:            cmd.Data,
 		IntervalSeconds: intervalSeconds,
 		NoDataState:     noDataState,
+		ExecErrState:    execErrState,
 		For:             forInterval,
 		Annotations:     cmd.Annotations,
 		Labels:          cmd.Labels,
 	}
 
-	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
+	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, folderTitle, cmd.From, cmd.To)
 	if err != nil {
 		if errors.Is(err, backtesting.ErrInvalidInputData) {
 			return ErrResp(400, err, "Failed to evaluate")
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+	"sort"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -12,43 +14,101 @@ import (
 // AlertRuleFromProvisionedAlertRule converts definitions.ProvisionedAlertRule to models.AlertRule
 func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (models.AlertRule, error) {
 	return models.AlertRule{
-		ID:           a.ID,
-		UID:          a.UID,
-		OrgID:        a.OrgID,
-		NamespaceUID: a.FolderUID,
-		RuleGroup:    a.RuleGroup,
-		Title:        a.Title,
-		Condition:    a.Condition,
-		Data:         a.Data,
-		Updated:      a.Updated,
-		NoDataState:  models.NoDataState(a.NoDataState),          // TODO there must be a validation
-		ExecErrState: models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
-		For:          time.Duration(a.For),
-		Annotations:  a.Annotations,
-		Labels:       a.Labels,
-		IsPaused:     a.IsPaused,
+		ID:                   a.ID,
+		UID:                  a.UID,
+		OrgID:                a.OrgID,
+		NamespaceUID:         a.FolderUID,
+		RuleGroup:            a.RuleGroup,
+		Title:                a.Title,
+		Condition:            a.Condition,
+		Data:                 a.Data,
+		Updated:              a.Updated,
+		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
+		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
+		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
+		Annotations:          a.Annotations,
+		Labels:               a.Labels,
+		IsPaused:             a.IsPaused,
+		Record:               ModelRecordFromApi(a.Record),
+		NotificationSettings: ModelNotificationSettingsFromApi(a.NotificationSettings),
 	}, nil
 }
 
 // ProvisionedAlertRuleFromAlertRule converts models.AlertRule to definitions.ProvisionedAlertRule and sets provided provenance status
 func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.Provenance) definitions.ProvisionedAlertRule {
 	return definitions.ProvisionedAlertRule{
-		ID:           rule.ID,
-		UID:          rule.UID,
-		OrgID:        rule.OrgID,
-		FolderUID:    rule.NamespaceUID,
-		RuleGroup:    rule.RuleGroup,
-		Title:        rule.Title,
-		For:          model.Duration(rule.For),
-		Condition:    rule.Condition,
-		Data:         rule.Data,
-		Updated:      rule.Updated,
-		NoDataState:  definitions.NoDataState(rule.NoDataState),          // TODO there may be a validation
-		ExecErrState: definitions.ExecutionErrorState(rule.ExecErrState), // TODO there may be a validation
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		Provenance:   definitions.Provenance(provenance), // TODO validate enum conversion?
-		IsPaused:     rule.IsPaused,
+		ID:                   rule.ID,
+		UID:                  rule.UID,
+		OrgID:                rule.OrgID,
+		FolderUID:            rule.NamespaceUID,
+		RuleGroup:            rule.RuleGroup,
+		Title:                rule.Title,
+		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
+		Condition:            rule.Condition,
+		Data:                 rule.Data,
+		Updated:              rule.Updated,
+		NoDataState:          definitions.NoDataState(rule.NoDataState),          // TODO there may be a validation
+		ExecErrState:         definitions.ExecutionErrorState(rule.ExecErrState), // TODO there may be a validation
+		Annotations:          rule.Annotations,
+		Labels:               rule.Labels,
+		Provenance:           definitions.Provenance(provenance), // TODO validate enum conversion?
+		IsPaused:             rule.IsPaused,
+		Record:               ApiRecordFromModel(rule.Record),
+		NotificationSettings: ApiNotificationSettingsFromModel(rule.NotificationSettings),
+	}
+}
+
+// ModelRecordFromApi converts definitions.Record to models.Record
+func ModelRecordFromApi(r *definitions.Record) *models.Record {
+	if r == nil {
+		return nil
+	}
+	return &models.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ApiRecordFromModel converts models.Record to definitions.Record
+func ApiRecordFromModel(r *models.Record) *definitions.Record {
+	if r == nil {
+		return nil
+	}
+	return &definitions.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ModelNotificationSettingsFromApi converts definitions.AlertRuleNotificationSettings to models.NotificationSettings
+func ModelNotificationSettingsFromApi(s *definitions.AlertRuleNotificationSettings) *models.NotificationSettings {
+	if s == nil {
+		return nil
+	}
+	return &models.NotificationSettings{
+		Receiver:          s.Receiver,
+		GroupBy:           s.GroupBy,
+		GroupWait:         s.GroupWait,
+		GroupInterval:     s.GroupInterval,
+		RepeatInterval:    s.RepeatInterval,
+		MuteTimeIntervals: s.MuteTimeIntervals,
+	}
+}
+
+// ApiNotificationSettingsFromModel converts models.NotificationSettings to definitions.AlertRuleNotificationSettings
+func ApiNotificationSettingsFromModel(s *models.NotificationSettings) *definitions.AlertRuleNotificationSettings {
+	if s == nil {
+		return nil
+	}
+	return &definitions.AlertRuleNotificationSettings{
+		Receiver:          s.Receiver,
+		GroupBy:           s.GroupBy,
+		GroupWait:         s.GroupWait,
+		GroupInterval:     s.GroupInterval,
+		RepeatInterval:    s.RepeatInterval,
+		MuteTimeIntervals: s.MuteTimeIntervals,
 	}
 }
 
@@ -89,3 +149,107 @@ func AlertRuleGroupToApi(d models.AlertRuleGroup) definitions.AlertRuleGroup {
 		Rules:     rules,
 	}
 }
+
+// ContactPointsToExport converts the contact points of an organization to the provisioned file export. Integrations
+// with the same name are exported as the receivers of a single contact point.
+func ContactPointsToExport(orgID int64, cps []definitions.EmbeddedContactPoint) ([]definitions.ContactPointExport, error) {
+	byName := make(map[string]int)
+	result := make([]definitions.ContactPointExport, 0, len(cps))
+	for _, cp := range cps {
+		receiver, err := receiverToExport(cp)
+		if err != nil {
+			return nil, err
+		}
+		idx, ok := byName[cp.Name]
+		if !ok {
+			idx = len(result)
+			byName[cp.Name] = idx
+			result = append(result, definitions.ContactPointExport{
+				OrgID: orgID,
+				Name:  cp.Name,
+			})
+		}
+		result[idx].Receivers = append(result[idx].Receivers, receiver)
+	}
+	return result, nil
+}
+
+func receiverToExport(cp definitions.EmbeddedContactPoint) (definitions.ReceiverExport, error) {
+	// We unmarshal the settings into a map in order to facilitate yaml marshalling.
+	var settings map[string]interface{}
+	if cp.Settings != nil {
+		data, err := cp.Settings.MarshalJSON()
+		if err != nil {
+			return definitions.ReceiverExport{}, err
+		}
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return definitions.ReceiverExport{}, err
+		}
+	}
+	return definitions.ReceiverExport{
+		UID:                   cp.UID,
+		Type:                  cp.Type,
+		Settings:              settings,
+		DisableResolveMessage: cp.DisableResolveMessage,
+	}, nil
+}
+
+// PolicyTreeToExport converts the notification policy tree of an organization to the provisioned file export.
+func PolicyTreeToExport(orgID int64, tree definitions.Route) definitions.NotificationPolicyExport {
+	return definitions.NotificationPolicyExport{
+		OrgID:       orgID,
+		RouteExport: routeToExport(&tree),
+	}
+}
+
+func routeToExport(r *definitions.Route) *definitions.RouteExport {
+	if r == nil {
+		return nil
+	}
+	export := &definitions.RouteExport{
+		Receiver:          r.Receiver,
+		GroupByStr:        r.GroupByStr,
+		Match:             r.Match,
+		MatchRE:           r.MatchRE,
+		Matchers:          r.Matchers,
+		ObjectMatchers:    r.ObjectMatchers,
+		MuteTimeIntervals: r.MuteTimeIntervals,
+		Continue:          r.Continue,
+		GroupWait:         r.GroupWait,
+		GroupInterval:     r.GroupInterval,
+		RepeatInterval:    r.RepeatInterval,
+	}
+	for _, child := range r.Routes {
+		export.Routes = append(export.Routes, routeToExport(child))
+	}
+	return export
+}
+
+// MuteTimingsToExport converts the mute timings of an organization to the provisioned file export.
+func MuteTimingsToExport(orgID int64, mts []definitions.MuteTimeInterval) []definitions.MuteTimeIntervalExport {
+	result := make([]definitions.MuteTimeIntervalExport, 0, len(mts))
+	for _, mt := range mts {
+		result = append(result, definitions.MuteTimeIntervalExport{
+			OrgID:            orgID,
+			MuteTimeInterval: mt.MuteTimeInterval,
+		})
+	}
+	return result
+}
+
+// TemplatesToExport converts the notification templates of an organization to the provisioned file export,
+// sorted by name.
+func TemplatesToExport(orgID int64, templates map[string]string) []definitions.NotificationTemplateExport {
+	result := make([]definitions.NotificationTemplateExport, 0, len(templates))
+	for name, tmpl := range templates {
+		result = append(result, definitions.NotificationTemplateExport{
+			OrgID:    orgID,
+			Name:     name,
+			Template: tmpl,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -2,10 +2,13 @@ package api
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestToModel(t *testing.T) {
@@ -35,3 +38,14 @@ func TestToModel(t *testing.T) {
 		require.Len(t, tm.Rules, 1)
 	})
 }
+
+func TestProvisionedAlertRuleRoundTrip(t *testing.T) {
+	rule := models.AlertRuleGen(models.WithKeepFiringFor(5 * time.Minute))()
+	provisioned := ProvisionedAlertRuleFromAlertRule(*rule, models.ProvenanceAPI)
+	require.Equal(t, model.Duration(5*time.Minute), provisioned.KeepFiringFor)
+
+	converted, err := AlertRuleFromProvisionedAlertRule(provisioned)
+	require.NoError(t, err)
+	require.Equal(t, rule.For, converted.For)
+	require.Equal(t, rule.KeepFiringFor, converted.KeepFiringFor)
+}
@@ -18,11 +18,15 @@ import (
 
 type HistoryApi interface {
 	RouteGetStateHistory(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistoryTransitions(*contextmodel.ReqContext) response.Response
 }
 
 func (f *HistoryApiHandler) RouteGetStateHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetStateHistory(ctx)
 }
+func (f *HistoryApiHandler) RouteGetStateHistoryTransitions(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistoryTransitions(ctx)
+}
 
 func (api *API) RegisterHistoryApiEndpoints(srv HistoryApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -36,5 +40,15 @@ func (api *API) RegisterHistoryApiEndpoints(srv HistoryApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/transitions"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/transitions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/transitions",
+				srv.RouteGetStateHistoryTransitions,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -30,11 +30,16 @@ type ProvisioningApi interface {
 	RouteGetAlertRules(*contextmodel.ReqContext) response.Response
 	RouteGetAlertRulesExport(*contextmodel.ReqContext) response.Response
 	RouteGetContactpoints(*contextmodel.ReqContext) response.Response
+	RouteGetContactpointsExport(*contextmodel.ReqContext) response.Response
 	RouteGetMuteTiming(*contextmodel.ReqContext) response.Response
+	RouteGetMuteTimingExport(*contextmodel.ReqContext) response.Response
 	RouteGetMuteTimings(*contextmodel.ReqContext) response.Response
+	RouteGetMuteTimingsExport(*contextmodel.ReqContext) response.Response
 	RouteGetPolicyTree(*contextmodel.ReqContext) response.Response
+	RouteGetPolicyTreeExport(*contextmodel.ReqContext) response.Response
 	RouteGetTemplate(*contextmodel.ReqContext) response.Response
 	RouteGetTemplates(*contextmodel.ReqContext) response.Response
+	RouteGetTemplatesExport(*contextmodel.ReqContext) response.Response
 	RoutePostAlertRule(*contextmodel.ReqContext) response.Response
 	RoutePostContactpoints(*contextmodel.ReqContext) response.Response
 	RoutePostMuteTiming(*contextmodel.ReqContext) response.Response
@@ -98,17 +103,31 @@ func (f *ProvisioningApiHandler) RouteGetAlertRulesExport(ctx *contextmodel.ReqC
 func (f *ProvisioningApiHandler) RouteGetContactpoints(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetContactpoints(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetContactpointsExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetContactpointsExport(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetMuteTiming(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
 	return f.handleRouteGetMuteTiming(ctx, nameParam)
 }
+func (f *ProvisioningApiHandler) RouteGetMuteTimingExport(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":name"]
+	return f.handleRouteGetMuteTimingExport(ctx, nameParam)
+}
 func (f *ProvisioningApiHandler) RouteGetMuteTimings(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetMuteTimings(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetMuteTimingsExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetMuteTimingsExport(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetPolicyTree(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetPolicyTree(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetPolicyTreeExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetPolicyTreeExport(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetTemplate(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -117,6 +136,9 @@ func (f *ProvisioningApiHandler) RouteGetTemplate(ctx *contextmodel.ReqContext)
 func (f *ProvisioningApiHandler) RouteGetTemplates(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetTemplates(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetTemplatesExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetTemplatesExport(ctx)
+}
 func (f *ProvisioningApiHandler) RoutePostAlertRule(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.ProvisionedAlertRule{}
@@ -316,6 +338,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/contact-points/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/contact-points/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/contact-points/export",
+				srv.RouteGetContactpointsExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/mute-timings/{name}"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/{name}"),
@@ -326,6 +358,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{name}/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/{name}/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings/{name}/export",
+				srv.RouteGetMuteTimingExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/mute-timings"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings"),
@@ -336,6 +378,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings/export",
+				srv.RouteGetMuteTimingsExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/policies"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/policies"),
@@ -346,6 +398,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/policies/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/policies/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/policies/export",
+				srv.RouteGetPolicyTreeExport,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/templates/{name}"),
 			api.authorize(http.MethodGet, "/api/v1/provisioning/templates/{name}"),
@@ -366,6 +428,16 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/templates/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/templates/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/templates/export",
+				srv.RouteGetTemplatesExport,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/provisioning/alert-rules"),
 			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rules"),
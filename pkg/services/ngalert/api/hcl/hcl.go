@@ -0,0 +1,388 @@
+// Package hcl encodes the provisioned file export of alerting resources as Terraform resources of the Grafana
+// provider.
+package hcl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting/file"
+)
+
+const (
+	ruleGroupResource       = "grafana_rule_group"
+	contactPointResource    = "grafana_contact_point"
+	policyResource          = "grafana_notification_policy"
+	muteTimingResource      = "grafana_mute_timing"
+	messageTemplateResource = "grafana_message_template"
+)
+
+// Encode returns the resources of the export in HCL, one resource per rule group, contact point, notification
+// policy tree, mute timing and message template. Queries and expressions of alert rules are encoded with
+// jsonencode, and the settings of contact points are mapped to the attributes of the block of the integration in the
+// grafana_contact_point resource.
+func Encode(export definitions.AlertingFileExport) ([]byte, error) {
+	e := &encoder{
+		file:  hclwrite.NewEmptyFile(),
+		names: make(map[string]int),
+	}
+	for _, group := range export.Groups {
+		if err := e.ruleGroup(group); err != nil {
+			return nil, fmt.Errorf("failed to encode rule group %s: %w", group.Name, err)
+		}
+	}
+	for _, cp := range export.ContactPoints {
+		if err := e.contactPoint(cp); err != nil {
+			return nil, fmt.Errorf("failed to encode contact point %s: %w", cp.Name, err)
+		}
+	}
+	for _, policy := range export.Policies {
+		e.policy(policy)
+	}
+	for _, mt := range export.MuteTimings {
+		if err := e.muteTiming(mt); err != nil {
+			return nil, fmt.Errorf("failed to encode mute timing %s: %w", mt.Name, err)
+		}
+	}
+	for _, tmpl := range export.Templates {
+		e.template(tmpl)
+	}
+	return hclwrite.Format(e.file.Bytes()), nil
+}
+
+type encoder struct {
+	file *hclwrite.File
+	// names counts the resources of each type and name, so that every resource gets a unique name.
+	names map[string]int
+}
+
+// resource appends a resource block of the given type, named after the given name, and returns its body.
+func (e *encoder) resource(resourceType, name string, orgID int64) *hclwrite.Body {
+	label := resourceName(name)
+	key := resourceType + "." + label
+	e.names[key]++
+	if n := e.names[key]; n > 1 {
+		label = fmt.Sprintf("%s_%d", label, n)
+	}
+	if len(e.file.Body().Blocks()) > 0 {
+		e.file.Body().AppendNewline()
+	}
+	body := e.file.Body().AppendNewBlock("resource", []string{resourceType, label}).Body()
+	body.SetAttributeValue("org_id", cty.StringVal(strconv.FormatInt(orgID, 10)))
+	return body
+}
+
+func (e *encoder) ruleGroup(group file.AlertRuleGroupExport) error {
+	body := e.resource(ruleGroupResource, group.Name, group.OrgID)
+	body.SetAttributeValue("name", cty.StringVal(group.Name))
+	body.SetAttributeValue("folder_uid", cty.StringVal(group.FolderUID))
+	body.SetAttributeValue("interval_seconds", cty.NumberIntVal(int64(time.Duration(group.Interval).Seconds())))
+	for _, rule := range group.Rules {
+		r := body.AppendNewBlock("rule", nil).Body()
+		r.SetAttributeValue("name", cty.StringVal(rule.Title))
+		r.SetAttributeValue("uid", cty.StringVal(rule.UID))
+		r.SetAttributeValue("condition", cty.StringVal(rule.Condition))
+		r.SetAttributeValue("for", cty.StringVal(rule.For.String()))
+		if rule.KeepFiringFor > 0 {
+			r.SetAttributeValue("keep_firing_for", cty.StringVal(rule.KeepFiringFor.String()))
+		}
+		r.SetAttributeValue("no_data_state", cty.StringVal(string(rule.NoDataState)))
+		r.SetAttributeValue("exec_err_state", cty.StringVal(string(rule.ExecErrState)))
+		if len(rule.Annotations) > 0 {
+			r.SetAttributeValue("annotations", stringMap(rule.Annotations))
+		}
+		if len(rule.Labels) > 0 {
+			r.SetAttributeValue("labels", stringMap(rule.Labels))
+		}
+		r.SetAttributeValue("is_paused", cty.BoolVal(rule.IsPaused))
+		if rule.Record != nil {
+			record := r.AppendNewBlock("record", nil).Body()
+			record.SetAttributeValue("metric", cty.StringVal(rule.Record.Metric))
+			record.SetAttributeValue("from", cty.StringVal(rule.Record.From))
+		}
+		if ns := rule.NotificationSettings; ns != nil {
+			settings := r.AppendNewBlock("notification_settings", nil).Body()
+			settings.SetAttributeValue("contact_point", cty.StringVal(ns.Receiver))
+			if len(ns.GroupBy) > 0 {
+				settings.SetAttributeValue("group_by", stringList(ns.GroupBy))
+			}
+			setDuration(settings, "group_wait", ns.GroupWait)
+			setDuration(settings, "group_interval", ns.GroupInterval)
+			setDuration(settings, "repeat_interval", ns.RepeatInterval)
+			if len(ns.MuteTimeIntervals) > 0 {
+				settings.SetAttributeValue("mute_timings", stringList(ns.MuteTimeIntervals))
+			}
+		}
+		for _, query := range rule.Data {
+			d := r.AppendNewBlock("data", nil).Body()
+			d.SetAttributeValue("ref_id", cty.StringVal(query.RefID))
+			if query.QueryType != "" {
+				d.SetAttributeValue("query_type", cty.StringVal(query.QueryType))
+			}
+			d.SetAttributeValue("datasource_uid", cty.StringVal(query.DatasourceUID))
+			tr := d.AppendNewBlock("relative_time_range", nil).Body()
+			tr.SetAttributeValue("from", cty.NumberIntVal(int64(time.Duration(query.RelativeTimeRange.From).Seconds())))
+			tr.SetAttributeValue("to", cty.NumberIntVal(int64(time.Duration(query.RelativeTimeRange.To).Seconds())))
+			model, err := jsonValue(query.Model)
+			if err != nil {
+				return fmt.Errorf("invalid model of query %s: %w", query.RefID, err)
+			}
+			d.SetAttributeRaw("model", hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForValue(model)))
+		}
+	}
+	return nil
+}
+
+func (e *encoder) contactPoint(cp definitions.ContactPointExport) error {
+	body := e.resource(contactPointResource, cp.Name, cp.OrgID)
+	body.SetAttributeValue("name", cty.StringVal(cp.Name))
+	for _, receiver := range cp.Receivers {
+		schema, ok := integrationSchemas[receiver.Type]
+		if !ok {
+			return fmt.Errorf("integration %s has type %s that is not supported by the Terraform provider", receiver.UID, receiver.Type)
+		}
+		r := body.AppendNewBlock(schema.block, nil).Body()
+		r.SetAttributeValue("uid", cty.StringVal(receiver.UID))
+		r.SetAttributeValue("disable_resolve_message", cty.BoolVal(receiver.DisableResolveMessage))
+		keys := make([]string, 0, len(receiver.Settings))
+		for k := range receiver.Settings {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			attr, ok := schema.settings[k]
+			if !ok {
+				// Settings that the provider does not know about, such as the settings of legacy notification
+				// channels, are left out so that the resource can still be applied.
+				r.AppendUnstructuredTokens(hclwrite.Tokens{{
+					Type:  hclsyntax.TokenComment,
+					Bytes: []byte(fmt.Sprintf("# setting %s is not supported by the Terraform provider\n", k)),
+				}})
+				continue
+			}
+			v, err := attr.value(receiver.Settings[k])
+			if err != nil {
+				return fmt.Errorf("invalid setting %s of integration %s: %w", k, receiver.UID, err)
+			}
+			r.SetAttributeValue(attr.name, v)
+		}
+	}
+	return nil
+}
+
+func (e *encoder) policy(policy definitions.NotificationPolicyExport) {
+	if policy.RouteExport == nil {
+		return
+	}
+	body := e.resource(policyResource, "notification_policy", policy.OrgID)
+	body.SetAttributeValue("contact_point", cty.StringVal(policy.Receiver))
+	body.SetAttributeValue("group_by", stringList(policy.GroupByStr))
+	setDuration(body, "group_wait", policy.GroupWait)
+	setDuration(body, "group_interval", policy.GroupInterval)
+	setDuration(body, "repeat_interval", policy.RepeatInterval)
+	for _, child := range policy.Routes {
+		childPolicy(body, child)
+	}
+}
+
+func childPolicy(parent *hclwrite.Body, route *definitions.RouteExport) {
+	if route == nil {
+		return
+	}
+	body := parent.AppendNewBlock("policy", nil).Body()
+	for _, m := range routeMatchers(route) {
+		matcher := body.AppendNewBlock("matcher", nil).Body()
+		matcher.SetAttributeValue("label", cty.StringVal(m.Name))
+		matcher.SetAttributeValue("match", cty.StringVal(m.Type.String()))
+		matcher.SetAttributeValue("value", cty.StringVal(m.Value))
+	}
+	if route.Receiver != "" {
+		body.SetAttributeValue("contact_point", cty.StringVal(route.Receiver))
+	}
+	if len(route.GroupByStr) > 0 {
+		body.SetAttributeValue("group_by", stringList(route.GroupByStr))
+	}
+	if route.Continue {
+		body.SetAttributeValue("continue", cty.True)
+	}
+	if len(route.MuteTimeIntervals) > 0 {
+		body.SetAttributeValue("mute_timings", stringList(route.MuteTimeIntervals))
+	}
+	setDuration(body, "group_wait", route.GroupWait)
+	setDuration(body, "group_interval", route.GroupInterval)
+	setDuration(body, "repeat_interval", route.RepeatInterval)
+	for _, child := range route.Routes {
+		childPolicy(body, child)
+	}
+}
+
+// routeMatchers returns all the matchers of the route, including the deprecated match and match_re.
+func routeMatchers(route *definitions.RouteExport) []*labels.Matcher {
+	var result []*labels.Matcher
+	for _, name := range sortedKeys(route.Match) {
+		result = append(result, &labels.Matcher{Name: name, Type: labels.MatchEqual, Value: route.Match[name]})
+	}
+	names := make([]string, 0, len(route.MatchRE))
+	for name := range route.MatchRE {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, &labels.Matcher{Name: name, Type: labels.MatchRegexp, Value: regexpString(route.MatchRE[name])})
+	}
+	result = append(result, route.Matchers...)
+	result = append(result, route.ObjectMatchers...)
+	return result
+}
+
+func regexpString(re config.Regexp) string {
+	// MarshalYAML returns the original expression, without the anchors that are added when it is parsed.
+	v, err := re.MarshalYAML()
+	if err != nil {
+		return ""
+	}
+	s, _ := v.(string)
+	return s
+}
+
+func (e *encoder) muteTiming(mt definitions.MuteTimeIntervalExport) error {
+	body := e.resource(muteTimingResource, mt.Name, mt.OrgID)
+	body.SetAttributeValue("name", cty.StringVal(mt.Name))
+	for _, interval := range mt.TimeIntervals {
+		i := body.AppendNewBlock("intervals", nil).Body()
+		for _, tr := range interval.Times {
+			times := i.AppendNewBlock("times", nil).Body()
+			times.SetAttributeValue("start", cty.StringVal(minutesOfDay(tr.StartMinute)))
+			times.SetAttributeValue("end", cty.StringVal(minutesOfDay(tr.EndMinute)))
+		}
+		if err := setRanges(i, "weekdays", interval.Weekdays); err != nil {
+			return err
+		}
+		if err := setRanges(i, "days_of_month", interval.DaysOfMonth); err != nil {
+			return err
+		}
+		if err := setRanges(i, "months", interval.Months); err != nil {
+			return err
+		}
+		if err := setRanges(i, "years", interval.Years); err != nil {
+			return err
+		}
+		if interval.Location != nil && interval.Location.Location != nil {
+			i.SetAttributeValue("location", cty.StringVal(interval.Location.String()))
+		}
+	}
+	return nil
+}
+
+func (e *encoder) template(tmpl definitions.NotificationTemplateExport) {
+	body := e.resource(messageTemplateResource, tmpl.Name, tmpl.OrgID)
+	body.SetAttributeValue("name", cty.StringVal(tmpl.Name))
+	body.SetAttributeValue("template", cty.StringVal(tmpl.Template))
+}
+
+// setRanges sets the attribute to the ranges in their text form, for example monday:friday. It does nothing if
+// there are no ranges.
+func setRanges[T interface {
+	MarshalText() ([]byte, error)
+}](body *hclwrite.Body, name string, ranges []T) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		text, err := r.MarshalText()
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		values = append(values, string(text))
+	}
+	body.SetAttributeValue(name, stringList(values))
+	return nil
+}
+
+func minutesOfDay(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func setDuration(body *hclwrite.Body, name string, d *model.Duration) {
+	if d == nil {
+		return
+	}
+	body.SetAttributeValue(name, cty.StringVal(d.String()))
+}
+
+// jsonValue converts a value that can be marshalled to JSON into a cty value.
+func jsonValue(v interface{}) (cty.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	ty, err := ctyjson.ImpliedType(data)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(data, ty)
+}
+
+func stringMap(m map[string]string) cty.Value {
+	values := make(map[string]cty.Value, len(m))
+	for k, v := range m {
+		values[k] = cty.StringVal(v)
+	}
+	return cty.MapVal(values)
+}
+
+func stringList(values []string) cty.Value {
+	if len(values) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	list := make([]cty.Value, 0, len(values))
+	for _, v := range values {
+		list = append(list, cty.StringVal(v))
+	}
+	return cty.ListVal(list)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resourceName returns a valid Terraform resource name for the name, by replacing the characters that are not
+// allowed with underscores.
+func resourceName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-') {
+			sb.WriteRune(r)
+			continue
+		}
+		sb.WriteRune('_')
+	}
+	result := sb.String()
+	if result == "" {
+		return "_"
+	}
+	if first := rune(result[0]); !unicode.IsLetter(first) && first != '_' {
+		return "_" + result
+	}
+	return result
+}
@@ -0,0 +1,308 @@
+package hcl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting/file"
+)
+
+func TestEncode(t *testing.T) {
+	groupWait := model.Duration(30 * time.Second)
+	export := definitions.AlertingFileExport{
+		APIVersion: 1,
+		Groups: []file.AlertRuleGroupExport{{
+			OrgID:     1,
+			Name:      "my cool group",
+			Folder:    "Folder Title",
+			FolderUID: "folder-uid",
+			Interval:  model.Duration(time.Minute),
+			Rules: []file.AlertRuleExport{{
+				UID:       "rule1",
+				Title:     "rule1",
+				Condition: "A",
+				Data: []file.AlertQueryExport{{
+					RefID:             "A",
+					RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(10 * time.Minute)},
+					DatasourceUID:     "__expr__",
+					Model: map[string]interface{}{
+						"expression": "1==0",
+						"type":       "math",
+					},
+				}},
+				NoDataState:  models.OK,
+				ExecErrState: models.OkErrState,
+				For:          model.Duration(5 * time.Minute),
+				Labels:       map[string]string{"team": "alerting"},
+			}},
+		}},
+		ContactPoints: []definitions.ContactPointExport{{
+			OrgID: 1,
+			Name:  "slack receiver",
+			Receivers: []definitions.ReceiverExport{{
+				UID:  "slack-uid",
+				Type: "slack",
+				Settings: map[string]interface{}{
+					"recipient":    "#alerts",
+					"mentionUsers": "me",
+				},
+			}},
+		}},
+		Policies: []definitions.NotificationPolicyExport{{
+			OrgID: 1,
+			RouteExport: &definitions.RouteExport{
+				Receiver:   "slack receiver",
+				GroupByStr: []string{"alertname"},
+				GroupWait:  &groupWait,
+				Routes: []*definitions.RouteExport{{
+					Receiver:          "slack receiver",
+					ObjectMatchers:    definitions.ObjectMatchers{{Name: "team", Type: labels.MatchEqual, Value: "alerting"}},
+					MuteTimeIntervals: []string{"weekends"},
+					Continue:          true,
+				}},
+			},
+		}},
+		MuteTimings: []definitions.MuteTimeIntervalExport{{
+			OrgID: 1,
+			MuteTimeInterval: config.MuteTimeInterval{
+				Name: "weekends",
+				TimeIntervals: []timeinterval.TimeInterval{{
+					Times:    []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 9 * 60}},
+					Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}, {InclusiveRange: timeinterval.InclusiveRange{Begin: 6, End: 6}}},
+				}},
+			},
+		}},
+		Templates: []definitions.NotificationTemplateExport{{
+			OrgID:    1,
+			Name:     "my template",
+			Template: `{{ define "my template" }}firing{{ end }}`,
+		}},
+	}
+
+	expected := `resource "grafana_rule_group" "my_cool_group" {
+  org_id           = "1"
+  name             = "my cool group"
+  folder_uid       = "folder-uid"
+  interval_seconds = 60
+  rule {
+    name           = "rule1"
+    uid            = "rule1"
+    condition      = "A"
+    for            = "5m"
+    no_data_state  = "OK"
+    exec_err_state = "OK"
+    labels = {
+      team = "alerting"
+    }
+    is_paused = false
+    data {
+      ref_id         = "A"
+      datasource_uid = "__expr__"
+      relative_time_range {
+        from = 600
+        to   = 0
+      }
+      model = jsonencode({
+        expression = "1==0"
+        type       = "math"
+      })
+    }
+  }
+}
+
+resource "grafana_contact_point" "slack_receiver" {
+  org_id = "1"
+  name   = "slack receiver"
+  slack {
+    uid                     = "slack-uid"
+    disable_resolve_message = false
+    mention_users           = "me"
+    recipient               = "#alerts"
+  }
+}
+
+resource "grafana_notification_policy" "notification_policy" {
+  org_id        = "1"
+  contact_point = "slack receiver"
+  group_by      = ["alertname"]
+  group_wait    = "30s"
+  policy {
+    matcher {
+      label = "team"
+      match = "="
+      value = "alerting"
+    }
+    contact_point = "slack receiver"
+    continue      = true
+    mute_timings  = ["weekends"]
+  }
+}
+
+resource "grafana_mute_timing" "weekends" {
+  org_id = "1"
+  name   = "weekends"
+  intervals {
+    times {
+      start = "00:00"
+      end   = "09:00"
+    }
+    weekdays = ["sunday", "saturday"]
+  }
+}
+
+resource "grafana_message_template" "my_template" {
+  org_id   = "1"
+  name     = "my template"
+  template = "{{ define \"my template\" }}firing{{ end }}"
+}
+`
+	actual, err := Encode(export)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(actual))
+}
+
+func TestEncode_UniqueResourceNames(t *testing.T) {
+	export := definitions.AlertingFileExport{
+		Templates: []definitions.NotificationTemplateExport{
+			{OrgID: 1, Name: "template"},
+			{OrgID: 2, Name: "template"},
+		},
+	}
+	actual, err := Encode(export)
+	require.NoError(t, err)
+	require.Contains(t, string(actual), `resource "grafana_message_template" "template" {`)
+	require.Contains(t, string(actual), `resource "grafana_message_template" "template_2" {`)
+}
+
+func TestResourceName(t *testing.T) {
+	testCases := map[string]string{
+		"my-group":      "my-group",
+		"My Group":      "my_group",
+		"1st group":     "_1st_group",
+		"group/with:ch": "group_with_ch",
+		"":              "_",
+	}
+	for name, expected := range testCases {
+		require.Equal(t, expected, resourceName(name), name)
+	}
+}
+
+func TestEncode_ContactPoints(t *testing.T) {
+	export := func(receivers ...definitions.ReceiverExport) definitions.AlertingFileExport {
+		return definitions.AlertingFileExport{
+			ContactPoints: []definitions.ContactPointExport{{OrgID: 1, Name: "cp", Receivers: receivers}},
+		}
+	}
+
+	t.Run("should map the settings to the attributes of the provider", func(t *testing.T) {
+		actual, err := Encode(export(
+			definitions.ReceiverExport{
+				UID:  "am",
+				Type: "prometheus-alertmanager",
+				Settings: map[string]interface{}{
+					"url":               "http://alertmanager",
+					"basicAuthUser":     "user",
+					"basicAuthPassword": "[REDACTED]",
+				},
+			},
+			definitions.ReceiverExport{
+				UID:  "email",
+				Type: "email",
+				Settings: map[string]interface{}{
+					"addresses":   "a@example.com; b@example.com,c@example.com",
+					"singleEmail": true,
+				},
+			},
+			definitions.ReceiverExport{
+				UID:  "telegram",
+				Type: "telegram",
+				Settings: map[string]interface{}{
+					"bottoken":             "token",
+					"chatid":               "-1234",
+					"disable_notification": true,
+				},
+			},
+			definitions.ReceiverExport{
+				UID:  "pushover",
+				Type: "pushover",
+				Settings: map[string]interface{}{
+					"userKey":     "user",
+					"priority":    "1",
+					"retry":       float64(30),
+					"uploadImage": true,
+				},
+			},
+		))
+		require.NoError(t, err)
+
+		expected := `resource "grafana_contact_point" "cp" {
+  org_id = "1"
+  name   = "cp"
+  alertmanager {
+    uid                     = "am"
+    disable_resolve_message = false
+    basic_auth_password     = "[REDACTED]"
+    basic_auth_user         = "user"
+    url                     = "http://alertmanager"
+  }
+  email {
+    uid                     = "email"
+    disable_resolve_message = false
+    addresses               = ["a@example.com", "b@example.com", "c@example.com"]
+    single_email            = true
+  }
+  telegram {
+    uid                     = "telegram"
+    disable_resolve_message = false
+    token                   = "token"
+    chat_id                 = "-1234"
+    disable_notifications   = true
+  }
+  pushover {
+    uid                     = "pushover"
+    disable_resolve_message = false
+    priority                = 1
+    retry                   = 30
+    # setting uploadImage is not supported by the Terraform provider
+    user_key = "user"
+  }
+}
+`
+		require.Equal(t, expected, string(actual))
+	})
+
+	t.Run("should fail if the setting has the wrong type", func(t *testing.T) {
+		_, err := Encode(export(definitions.ReceiverExport{
+			UID:      "pushover",
+			Type:     "pushover",
+			Settings: map[string]interface{}{"priority": "high"},
+		}))
+		require.ErrorContains(t, err, "invalid setting priority of integration pushover")
+	})
+
+	t.Run("should fail if the provider does not support the integration", func(t *testing.T) {
+		_, err := Encode(export(definitions.ReceiverExport{UID: "custom", Type: "custom"}))
+		require.ErrorContains(t, err, "not supported by the Terraform provider")
+	})
+}
+
+func TestIntegrationSchemas(t *testing.T) {
+	// every setting of every integration must be mapped to an attribute of the provider
+	for _, n := range channels_config.GetAvailableNotifiers() {
+		schema, ok := integrationSchemas[n.Type]
+		require.Truef(t, ok, "integration %s is not mapped", n.Type)
+		for _, option := range n.Options {
+			_, ok := schema.settings[option.PropertyName]
+			require.Truef(t, ok, "setting %s of integration %s is not mapped", option.PropertyName, n.Type)
+		}
+	}
+}
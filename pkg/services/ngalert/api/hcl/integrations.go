@@ -0,0 +1,247 @@
+package hcl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+type attributeKind int
+
+const (
+	stringAttribute attributeKind = iota
+	boolAttribute
+	numberAttribute
+	// listAttribute is a list of strings that the integration stores as a single string separated by commas,
+	// semicolons or newlines.
+	listAttribute
+)
+
+type attribute struct {
+	name string
+	kind attributeKind
+}
+
+// integrationSchema describes the block of an integration in the grafana_contact_point resource.
+type integrationSchema struct {
+	block string
+	// settings maps the settings of the integration to the attributes of the block.
+	settings map[string]attribute
+}
+
+// integrationSchemas are the blocks of the grafana_contact_point resource of the Terraform provider, by type of the
+// integration. The provider does not use the names of the settings, so every setting is mapped explicitly.
+var integrationSchemas = map[string]integrationSchema{
+	"prometheus-alertmanager": {block: "alertmanager", settings: map[string]attribute{
+		"url":               {"url", stringAttribute},
+		"basicAuthUser":     {"basic_auth_user", stringAttribute},
+		"basicAuthPassword": {"basic_auth_password", stringAttribute},
+	}},
+	"dingding": {block: "dingding", settings: map[string]attribute{
+		"url":     {"url", stringAttribute},
+		"msgType": {"message_type", stringAttribute},
+		"title":   {"title", stringAttribute},
+		"message": {"message", stringAttribute},
+	}},
+	"discord": {block: "discord", settings: map[string]attribute{
+		"url":                  {"url", stringAttribute},
+		"title":                {"title", stringAttribute},
+		"message":              {"message", stringAttribute},
+		"avatar_url":           {"avatar_url", stringAttribute},
+		"use_discord_username": {"use_discord_username", boolAttribute},
+	}},
+	"email": {block: "email", settings: map[string]attribute{
+		"addresses":   {"addresses", listAttribute},
+		"singleEmail": {"single_email", boolAttribute},
+		"message":     {"message", stringAttribute},
+		"subject":     {"subject", stringAttribute},
+	}},
+	"googlechat": {block: "googlechat", settings: map[string]attribute{
+		"url":     {"url", stringAttribute},
+		"title":   {"title", stringAttribute},
+		"message": {"message", stringAttribute},
+	}},
+	"kafka": {block: "kafka", settings: map[string]attribute{
+		"kafkaRestProxy": {"rest_proxy_url", stringAttribute},
+		"kafkaTopic":     {"topic", stringAttribute},
+		"username":       {"username", stringAttribute},
+		"password":       {"password", stringAttribute},
+		"apiVersion":     {"api_version", stringAttribute},
+		"kafkaClusterId": {"cluster_id", stringAttribute},
+		"description":    {"description", stringAttribute},
+		"details":        {"details", stringAttribute},
+	}},
+	"LINE": {block: "line", settings: map[string]attribute{
+		"token":       {"token", stringAttribute},
+		"title":       {"title", stringAttribute},
+		"description": {"description", stringAttribute},
+	}},
+	"opsgenie": {block: "opsgenie", settings: map[string]attribute{
+		"apiKey":           {"api_key", stringAttribute},
+		"apiUrl":           {"url", stringAttribute},
+		"message":          {"message", stringAttribute},
+		"description":      {"description", stringAttribute},
+		"autoClose":        {"auto_close", boolAttribute},
+		"overridePriority": {"override_priority", boolAttribute},
+		"sendTagsAs":       {"send_tags_as", stringAttribute},
+	}},
+	"pagerduty": {block: "pagerduty", settings: map[string]attribute{
+		"integrationKey": {"integration_key", stringAttribute},
+		"severity":       {"severity", stringAttribute},
+		"class":          {"class", stringAttribute},
+		"component":      {"component", stringAttribute},
+		"group":          {"group", stringAttribute},
+		"summary":        {"summary", stringAttribute},
+		"source":         {"source", stringAttribute},
+		"client":         {"client", stringAttribute},
+		"client_url":     {"client_url", stringAttribute},
+	}},
+	"pushover": {block: "pushover", settings: map[string]attribute{
+		"apiToken":   {"api_token", stringAttribute},
+		"userKey":    {"user_key", stringAttribute},
+		"device":     {"device", stringAttribute},
+		"priority":   {"priority", numberAttribute},
+		"okPriority": {"ok_priority", numberAttribute},
+		"retry":      {"retry", numberAttribute},
+		"expire":     {"expire", numberAttribute},
+		"sound":      {"sound", stringAttribute},
+		"okSound":    {"ok_sound", stringAttribute},
+		"title":      {"title", stringAttribute},
+		"message":    {"message", stringAttribute},
+	}},
+	"sensugo": {block: "sensugo", settings: map[string]attribute{
+		"url":       {"url", stringAttribute},
+		"apikey":    {"api_key", stringAttribute},
+		"entity":    {"entity", stringAttribute},
+		"check":     {"check", stringAttribute},
+		"handler":   {"handler", stringAttribute},
+		"namespace": {"namespace", stringAttribute},
+		"message":   {"message", stringAttribute},
+	}},
+	"slack": {block: "slack", settings: map[string]attribute{
+		"recipient":      {"recipient", stringAttribute},
+		"token":          {"token", stringAttribute},
+		"username":       {"username", stringAttribute},
+		"icon_emoji":     {"icon_emoji", stringAttribute},
+		"icon_url":       {"icon_url", stringAttribute},
+		"mentionUsers":   {"mention_users", stringAttribute},
+		"mentionGroups":  {"mention_groups", stringAttribute},
+		"mentionChannel": {"mention_channel", stringAttribute},
+		"url":            {"url", stringAttribute},
+		"endpointUrl":    {"endpoint_url", stringAttribute},
+		"title":          {"title", stringAttribute},
+		"text":           {"text", stringAttribute},
+	}},
+	"teams": {block: "teams", settings: map[string]attribute{
+		"url":          {"url", stringAttribute},
+		"title":        {"title", stringAttribute},
+		"sectiontitle": {"section_title", stringAttribute},
+		"message":      {"message", stringAttribute},
+	}},
+	"telegram": {block: "telegram", settings: map[string]attribute{
+		"bottoken":             {"token", stringAttribute},
+		"chatid":               {"chat_id", stringAttribute},
+		"message":              {"message", stringAttribute},
+		"parse_mode":           {"parse_mode", stringAttribute},
+		"disable_notification": {"disable_notifications", boolAttribute},
+	}},
+	"threema": {block: "threema", settings: map[string]attribute{
+		"gateway_id":   {"gateway_id", stringAttribute},
+		"recipient_id": {"recipient_id", stringAttribute},
+		"api_secret":   {"api_secret", stringAttribute},
+		"title":        {"title", stringAttribute},
+		"description":  {"description", stringAttribute},
+	}},
+	"victorops": {block: "victorops", settings: map[string]attribute{
+		"url":         {"url", stringAttribute},
+		"messageType": {"message_type", stringAttribute},
+		"title":       {"title", stringAttribute},
+		"description": {"description", stringAttribute},
+	}},
+	"webex": {block: "webex", settings: map[string]attribute{
+		"api_url":   {"api_url", stringAttribute},
+		"room_id":   {"room_id", stringAttribute},
+		"bot_token": {"token", stringAttribute},
+		"message":   {"message", stringAttribute},
+	}},
+	"webhook": {block: "webhook", settings: map[string]attribute{
+		"url":                       {"url", stringAttribute},
+		"httpMethod":                {"http_method", stringAttribute},
+		"username":                  {"basic_auth_user", stringAttribute},
+		"password":                  {"basic_auth_password", stringAttribute},
+		"authorization_scheme":      {"authorization_scheme", stringAttribute},
+		"authorization_credentials": {"authorization_credentials", stringAttribute},
+		"maxAlerts":                 {"max_alerts", numberAttribute},
+		"title":                     {"title", stringAttribute},
+		"message":                   {"message", stringAttribute},
+	}},
+	"wecom": {block: "wecom", settings: map[string]attribute{
+		"url":      {"url", stringAttribute},
+		"agent_id": {"agent_id", stringAttribute},
+		"corp_id":  {"corp_id", stringAttribute},
+		"secret":   {"secret", stringAttribute},
+		"msgtype":  {"msg_type", stringAttribute},
+		"message":  {"message", stringAttribute},
+		"title":    {"title", stringAttribute},
+		"touser":   {"to_user", stringAttribute},
+	}},
+}
+
+// value converts the value of a setting to the type of the attribute.
+func (a attribute) value(v interface{}) (cty.Value, error) {
+	switch a.kind {
+	case boolAttribute:
+		switch b := v.(type) {
+		case bool:
+			return cty.BoolVal(b), nil
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return cty.NilVal, fmt.Errorf("expected a boolean but got %q", b)
+			}
+			return cty.BoolVal(parsed), nil
+		}
+		return cty.NilVal, fmt.Errorf("expected a boolean but got %v", v)
+	case numberAttribute:
+		switch n := v.(type) {
+		case float64:
+			return cty.NumberFloatVal(n), nil
+		case int64:
+			return cty.NumberIntVal(n), nil
+		case int:
+			return cty.NumberIntVal(int64(n)), nil
+		case string:
+			parsed, err := strconv.ParseInt(strings.TrimSpace(n), 10, 64)
+			if err != nil {
+				return cty.NilVal, fmt.Errorf("expected a number but got %q", n)
+			}
+			return cty.NumberIntVal(parsed), nil
+		}
+		return cty.NilVal, fmt.Errorf("expected a number but got %v", v)
+	case listAttribute:
+		s, ok := v.(string)
+		if !ok {
+			return cty.NilVal, fmt.Errorf("expected a string but got %v", v)
+		}
+		items := strings.FieldsFunc(s, func(r rune) bool {
+			return r == ',' || r == ';' || r == '\n'
+		})
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		return stringList(values), nil
+	default:
+		switch s := v.(type) {
+		case string:
+			return cty.StringVal(s), nil
+		case bool, float64, int, int64:
+			return cty.StringVal(fmt.Sprint(s)), nil
+		}
+		return cty.NilVal, fmt.Errorf("expected a string but got %v", v)
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+type NotificationsApiHandler struct {
+	svc *NotificationLogSrv
+}
+
+func NewNotificationsApi(svc *NotificationLogSrv) *NotificationsApiHandler {
+	return &NotificationsApiHandler{
+		svc: svc,
+	}
+}
+
+func (f *NotificationsApiHandler) handleRouteGetNotificationLog(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetNotificationLog(ctx)
+}
+
+func (f *NotificationsApiHandler) handleRoutePostNotificationLogResend(ctx *contextmodel.ReqContext, id string) response.Response {
+	return f.svc.RoutePostNotificationLogResend(ctx, id)
+}
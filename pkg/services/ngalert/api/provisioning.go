@@ -20,6 +20,10 @@ func (f *ProvisioningApiHandler) handleRouteGetPolicyTree(ctx *contextmodel.ReqC
 	return f.svc.RouteGetPolicyTree(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetPolicyTreeExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetPolicyTreeExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePutPolicyTree(ctx *contextmodel.ReqContext, route apimodels.Route) response.Response {
 	return f.svc.RoutePutPolicyTree(ctx, route)
 }
@@ -28,6 +32,10 @@ func (f *ProvisioningApiHandler) handleRouteGetContactpoints(ctx *contextmodel.R
 	return f.svc.RouteGetContactPoints(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetContactpointsExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetContactPointsExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePostContactpoints(ctx *contextmodel.ReqContext, cp apimodels.EmbeddedContactPoint) response.Response {
 	return f.svc.RoutePostContactPoint(ctx, cp)
 }
@@ -44,6 +52,10 @@ func (f *ProvisioningApiHandler) handleRouteGetTemplates(ctx *contextmodel.ReqCo
 	return f.svc.RouteGetTemplates(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetTemplatesExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetTemplatesExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetTemplate(ctx *contextmodel.ReqContext, name string) response.Response {
 	return f.svc.RouteGetTemplate(ctx, name)
 }
@@ -56,6 +68,14 @@ func (f *ProvisioningApiHandler) handleRouteDeleteTemplate(ctx *contextmodel.Req
 	return f.svc.RouteDeleteTemplate(ctx, name)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetMuteTimingExport(ctx *contextmodel.ReqContext, name string) response.Response {
+	return f.svc.RouteGetMuteTimingExport(ctx, name)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetMuteTimingsExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetMuteTimingsExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetMuteTiming(ctx *contextmodel.ReqContext, name string) response.Response {
 	return f.svc.RouteGetMuteTiming(ctx, name)
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -523,6 +524,20 @@ func (c *PostableUserConfig) GetGrafanaReceiverMap() map[string]*PostableGrafana
 	return UIDs
 }
 
+// NotificationSettingsValidator returns a validator of the notification settings of alert rules for the contact points
+// and mute timings of the configuration.
+func (c *PostableUserConfig) NotificationSettingsValidator() models.NotificationSettingsValidator {
+	receivers := make([]string, 0, len(c.AlertmanagerConfig.Receivers))
+	for _, r := range c.AlertmanagerConfig.Receivers {
+		receivers = append(receivers, r.Name)
+	}
+	muteTimings := make([]string, 0, len(c.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range c.AlertmanagerConfig.MuteTimeIntervals {
+		muteTimings = append(muteTimings, mt.Name)
+	}
+	return models.NewNotificationSettingsValidator(receivers, muteTimings)
+}
+
 // ProcessConfig parses grafana receivers, encrypts secrets and assigns UUIDs (if they are missing)
 func (c *PostableUserConfig) ProcessConfig(encrypt EncryptFn) error {
 	return processReceiverConfigs(c.AlertmanagerConfig.Receivers, encrypt)
@@ -260,12 +260,13 @@ func (c *GettableRuleGroupConfig) validate() error {
 }
 
 type ApiRuleNode struct {
-	Record      string            `yaml:"record,omitempty" json:"record,omitempty"`
-	Alert       string            `yaml:"alert,omitempty" json:"alert,omitempty"`
-	Expr        string            `yaml:"expr" json:"expr"`
-	For         *model.Duration   `yaml:"for,omitempty" json:"for,omitempty"`
-	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
-	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Record        string            `yaml:"record,omitempty" json:"record,omitempty"`
+	Alert         string            `yaml:"alert,omitempty" json:"alert,omitempty"`
+	Expr          string            `yaml:"expr" json:"expr"`
+	For           *model.Duration   `yaml:"for,omitempty" json:"for,omitempty"`
+	KeepFiringFor *model.Duration   `yaml:"keep_firing_for,omitempty" json:"keep_firing_for,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 }
 
 type RuleType int
@@ -375,6 +376,9 @@ type PostableGrafanaRule struct {
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused     *bool               `json:"is_paused" yaml:"is_paused"`
+	Record       *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+	// NotificationSettings send the alerts of the rule to a contact point directly instead of via the notification policies.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 }
 
 // swagger:model
@@ -395,4 +399,43 @@ type GettableGrafanaRule struct {
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	Provenance      Provenance          `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`
+	Record          *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+	// NotificationSettings send the alerts of the rule to a contact point directly instead of via the notification policies.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+}
+
+// Record makes a Grafana rule a recording rule. Recording rules do not produce alerts,
+// but write the result of the query or expression From as series of the metric.
+// swagger:model
+type Record struct {
+	// required: true
+	// example: grafana_slo_error_ratio
+	Metric string `json:"metric" yaml:"metric"`
+	// required: true
+	// example: A
+	From string `json:"from" yaml:"from"`
+}
+
+// AlertRuleNotificationSettings send the alerts of a rule to a contact point directly, bypassing the notification
+// policy tree. The alerts are always grouped by the folder and the alert name. Timings that are not set are
+// inherited from the default notification policy.
+// swagger:model
+type AlertRuleNotificationSettings struct {
+	// Name of the contact point the alerts of the rule are sent to.
+	// required: true
+	// example: grafana-default-email
+	Receiver string `json:"receiver" yaml:"receiver"`
+	// Labels the alerts are grouped by, in addition to grafana_folder and alertname. Use the special label '...' to
+	// group by all labels.
+	// example: ["grafana_folder", "alertname"]
+	GroupBy []string `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	// example: 30s
+	GroupWait *model.Duration `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	// example: 5m
+	GroupInterval *model.Duration `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	// example: 4h
+	RepeatInterval *model.Duration `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+	// Names of the mute timings of the alerts of the rule.
+	// example: ["maintenance"]
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty"`
 }
@@ -0,0 +1,99 @@
+package definitions
+
+import "time"
+
+// swagger:route GET /api/v1/notifications/log notifications RouteGetNotificationLog
+//
+// Get the log of the notifications sent by the contact points of the Grafana Alertmanager, newest first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: NotificationLog
+//       400: ValidationError
+
+// swagger:route POST /api/v1/notifications/log/{ID}/resend notifications RoutePostNotificationLogResend
+//
+// Send the alerts of a notification again with the integration that sent it.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: Ack
+//       404: NotFound
+//       409: AlertManagerNotReady
+//       500: Failure
+
+// swagger:parameters RouteGetNotificationLog
+type NotificationLogParams struct {
+	// Filter the notifications to the contact point with this name.
+	// in: query
+	// required: false
+	Receiver string `json:"receiver"`
+
+	// Filter the notifications by their status.
+	// in: query
+	// required: false
+	// enum: success,failed
+	Status string `json:"status"`
+
+	// The start of the time range, in seconds since the Unix epoch.
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// The end of the time range, in seconds since the Unix epoch.
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// The maximum number of notifications to return.
+	// in: query
+	// required: false
+	// default: 100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters RoutePostNotificationLogResend
+type NotificationLogEntryParams struct {
+	// in: path
+	// required: true
+	ID int64 `json:"ID"`
+}
+
+// swagger:model
+type NotificationLog []GettableNotificationLogEntry
+
+// swagger:model
+type GettableNotificationLogEntry struct {
+	ID int64 `json:"id"`
+	// The name of the contact point.
+	Receiver         string            `json:"receiver"`
+	IntegrationUID   string            `json:"integrationUid"`
+	IntegrationType  string            `json:"integrationType"`
+	IntegrationIndex int               `json:"integrationIndex"`
+	GroupKey         string            `json:"groupKey"`
+	GroupLabels      map[string]string `json:"groupLabels"`
+	Alerts           []NotifiedAlert   `json:"alerts"`
+	// enum: success,failed
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// The HTTP status code of the last response to the integration. It is null for integrations that do not send
+	// their requests with the webhook sender of Grafana, such as email or Slack, and if no response was received.
+	StatusCode *int `json:"statusCode"`
+	// How long the integration took to send the notification, in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+	// The number of the attempt to send the notification, starting at 1.
+	Attempt int       `json:"attempt"`
+	SentAt  time.Time `json:"sentAt"`
+}
+
+// NotifiedAlert is an alert of a notification.
+type NotifiedAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
@@ -101,8 +101,9 @@ type AlertingRule struct {
 	// required: true
 	Name string `json:"name,omitempty"`
 	// required: true
-	Query    string  `json:"query,omitempty"`
-	Duration float64 `json:"duration,omitempty"`
+	Query         string  `json:"query,omitempty"`
+	Duration      float64 `json:"duration,omitempty"`
+	KeepFiringFor float64 `json:"keepFiringFor,omitempty"`
 	// required: true
 	Annotations overrideLabels `json:"annotations,omitempty"`
 	// required: true
@@ -137,6 +138,8 @@ type Alert struct {
 	// required: true
 	State    string     `json:"state"`
 	ActiveAt *time.Time `json:"activeAt"`
+	// KeepFiringSince is set if the alert keeps firing although the condition of the rule is no longer met.
+	KeepFiringSince *time.Time `json:"keepFiringSince,omitempty"`
 	// required: true
 	Value string `json:"value"`
 }
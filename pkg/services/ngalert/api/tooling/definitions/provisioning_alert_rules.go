@@ -40,6 +40,7 @@ import (
 //     - application/json
 //     - application/yaml
 //     - text/yaml
+//     - text/hcl
 //
 //     Responses:
 //       200: AlertingFileExport
@@ -128,6 +129,8 @@ type ProvisionedAlertRule struct {
 	ExecErrState ExecutionErrorState `json:"execErrState"`
 	// required: true
 	For model.Duration `json:"for"`
+	// example: 5m
+	KeepFiringFor model.Duration `json:"keepFiringFor,omitempty"`
 	// example: {"runbook_url": "https://supercoolrunbook.com/page/13"}
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
@@ -135,7 +138,9 @@ type ProvisionedAlertRule struct {
 	// readonly: true
 	Provenance Provenance `json:"provenance,omitempty"`
 	// example: false
-	IsPaused bool `json:"isPaused"`
+	IsPaused             bool                           `json:"isPaused"`
+	Record               *Record                        `json:"record,omitempty"`
+	NotificationSettings *AlertRuleNotificationSettings `json:"notificationSettings,omitempty"`
 }
 
 // swagger:route GET /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -154,6 +159,7 @@ type ProvisionedAlertRule struct {
 //     - application/json
 //     - application/yaml
 //     - text/yaml
+//     - text/hcl
 //
 //     Responses:
 //       200: AlertingFileExport
@@ -193,7 +199,7 @@ type AlertRuleGroupMetadata struct {
 	Interval int64 `json:"interval"`
 }
 
-// swagger:parameters RouteGetAlertRuleGroupExport RouteGetAlertRuleExport RouteGetAlertRulesExport
+// swagger:parameters RouteGetAlertRuleGroupExport RouteGetAlertRuleExport RouteGetAlertRulesExport RouteGetContactpointsExport RouteGetPolicyTreeExport RouteGetMuteTimingsExport RouteGetMuteTimingExport RouteGetTemplatesExport
 type ExportQueryParams struct {
 	// Whether to initiate a download of the file or not.
 	// in: query
@@ -201,7 +207,7 @@ type ExportQueryParams struct {
 	// default: false
 	Download bool `json:"download"`
 
-	// Format of the downloaded file, either yaml or json. Accept header can also be used, but the query parameter will take precedence.
+	// Format of the downloaded file, either yaml, json or hcl. Accept header can also be used, but the query parameter will take precedence.
 	// in: query
 	// required: false
 	// default: yaml
@@ -218,4 +224,11 @@ type AlertRuleGroup struct {
 
 // AlertingFileExport is the full provisioned file export.
 // swagger:model
-type AlertingFileExport = file.AlertingFileExport
+type AlertingFileExport struct {
+	APIVersion    int64                        `json:"apiVersion" yaml:"apiVersion"`
+	Groups        []file.AlertRuleGroupExport  `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ContactPoints []ContactPointExport         `json:"contactPoints,omitempty" yaml:"contactPoints,omitempty"`
+	Policies      []NotificationPolicyExport   `json:"policies,omitempty" yaml:"policies,omitempty"`
+	MuteTimings   []MuteTimeIntervalExport     `json:"muteTimes,omitempty" yaml:"muteTimes,omitempty"`
+	Templates     []NotificationTemplateExport `json:"templates,omitempty" yaml:"templates,omitempty"`
+}
@@ -19,6 +19,14 @@ import (
 //     Responses:
 //       200: ContactPoints
 
+// swagger:route GET /api/v1/provisioning/contact-points/export provisioning stable RouteGetContactpointsExport
+//
+// Export all contact points in provisioning file format.
+//
+//     Responses:
+//       200: AlertingFileExport
+//       403: PermissionDenied
+
 // swagger:route POST /api/v1/provisioning/contact-points provisioning stable RoutePostContactpoints
 //
 // Create a contact point.
@@ -66,6 +74,19 @@ type ContactPointParams struct {
 	Name string `json:"name"`
 }
 
+// swagger:parameters RouteGetContactpointsExport
+type ContactPointExportParams struct {
+	// Whether the secure settings of the contact points are decrypted. If false, they are redacted.
+	// in: query
+	// required: false
+	// default: false
+	Decrypt bool `json:"decrypt"`
+	// Filter by name
+	// in: query
+	// required: false
+	Name string `json:"name"`
+}
+
 // swagger:parameters RoutePostContactpoints RoutePutContactpoint
 type ContactPointPayload struct {
 	// in:body
@@ -101,6 +122,21 @@ type EmbeddedContactPoint struct {
 
 const RedactedValue = "[REDACTED]"
 
+// ContactPointExport is the provisioned file export of alerting.ContactPointV1.
+type ContactPointExport struct {
+	OrgID     int64            `json:"orgId" yaml:"orgId"`
+	Name      string           `json:"name" yaml:"name"`
+	Receivers []ReceiverExport `json:"receivers" yaml:"receivers"`
+}
+
+// ReceiverExport is the provisioned file export of alerting.ReceiverV1.
+type ReceiverExport struct {
+	UID                   string                 `json:"uid" yaml:"uid"`
+	Type                  string                 `json:"type" yaml:"type"`
+	Settings              map[string]interface{} `json:"settings" yaml:"settings"`
+	DisableResolveMessage bool                   `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+}
+
 func (e *EmbeddedContactPoint) Valid(decryptFunc receivers.GetDecryptedValueFn) error {
 	if e.Type == "" {
 		return fmt.Errorf("type should not be an empty string")
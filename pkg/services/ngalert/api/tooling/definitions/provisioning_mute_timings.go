@@ -11,6 +11,13 @@ import (
 //     Responses:
 //       200: MuteTimings
 
+// swagger:route GET /api/v1/provisioning/mute-timings/export provisioning stable RouteGetMuteTimingsExport
+//
+// Export all mute timings in provisioning file format.
+//
+//     Responses:
+//       200: AlertingFileExport
+
 // swagger:route GET /api/v1/provisioning/mute-timings/{name} provisioning stable RouteGetMuteTiming
 //
 // Get a mute timing.
@@ -19,6 +26,14 @@ import (
 //       200: MuteTimeInterval
 //       404: description: Not found.
 
+// swagger:route GET /api/v1/provisioning/mute-timings/{name}/export provisioning stable RouteGetMuteTimingExport
+//
+// Export a mute timing in provisioning file format.
+//
+//     Responses:
+//       200: AlertingFileExport
+//       404: description: Not found.
+
 // swagger:route POST /api/v1/provisioning/mute-timings provisioning stable RoutePostMuteTiming
 //
 // Create a new mute timing.
@@ -53,7 +68,7 @@ import (
 // swagger:model
 type MuteTimings []MuteTimeInterval
 
-// swagger:parameters RouteGetTemplate RouteGetMuteTiming RoutePutMuteTiming stable RouteDeleteMuteTiming
+// swagger:parameters RouteGetTemplate RouteGetMuteTiming RouteGetMuteTimingExport RoutePutMuteTiming stable RouteDeleteMuteTiming
 type RouteGetMuteTimingParam struct {
 	// Mute timing name
 	// in:path
@@ -79,3 +94,9 @@ func (mt *MuteTimeInterval) ResourceType() string {
 func (mt *MuteTimeInterval) ResourceID() string {
 	return mt.MuteTimeInterval.Name
 }
+
+// MuteTimeIntervalExport is the provisioned file export of alerting.MuteTimeV1.
+type MuteTimeIntervalExport struct {
+	OrgID                   int64 `json:"orgId" yaml:"orgId"`
+	config.MuteTimeInterval `json:",inline" yaml:",inline"`
+}
@@ -8,6 +8,13 @@ package definitions
 //       200: NotificationTemplates
 //       404: description: Not found.
 
+// swagger:route GET /api/v1/provisioning/templates/export provisioning stable RouteGetTemplatesExport
+//
+// Export all notification templates in provisioning file format.
+//
+//     Responses:
+//       200: AlertingFileExport
+
 // swagger:route GET /api/v1/provisioning/templates/{name} provisioning stable RouteGetTemplate
 //
 // Get a notification template.
@@ -51,6 +58,13 @@ type NotificationTemplate struct {
 // swagger:model
 type NotificationTemplates []NotificationTemplate
 
+// NotificationTemplateExport is the provisioned file export of alerting.TemplateV1.
+type NotificationTemplateExport struct {
+	OrgID    int64  `json:"orgId" yaml:"orgId"`
+	Name     string `json:"name" yaml:"name"`
+	Template string `json:"template" yaml:"template"`
+}
+
 type NotificationTemplateContent struct {
 	Template string `json:"template"`
 }
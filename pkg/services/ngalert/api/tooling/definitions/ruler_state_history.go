@@ -12,6 +12,61 @@ import "github.com/grafana/grafana-plugin-sdk-go/data"
 //     Responses:
 //       200: StateHistory
 
+// swagger:route GET /api/v1/rules/history/transitions history RouteGetStateHistoryTransitions
+//
+// Count the state transitions of alert rules per hour.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistory
+
+// swagger:parameters RouteGetStateHistory RouteGetStateHistoryTransitions
+type StateHistoryParams struct {
+	// The start of the time range, in seconds since the Unix epoch.
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// The end of the time range, in seconds since the Unix epoch.
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// Filter the state history to the alert rule with this UID.
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+
+	// Filter the state history by label matchers, such as `severity=~"critical|warning"`.
+	// in: query
+	// required: false
+	Matcher []string `json:"matcher"`
+
+	// Filter the state history to transitions from this state.
+	// in: query
+	// required: false
+	// enum: Normal,Alerting,Pending,NoData,Error
+	Previous string `json:"previous"`
+
+	// Filter the state history to transitions to this state.
+	// in: query
+	// required: false
+	// enum: Normal,Alerting,Pending,NoData,Error
+	Current string `json:"current"`
+
+	// The maximum number of state transitions to return.
+	// in: query
+	// required: false
+	Limit int `json:"limit"`
+
+	// The number of state transitions to skip.
+	// in: query
+	// required: false
+	Offset int `json:"offset"`
+}
+
 type StateHistory struct {
 	Results *data.Frame `json:"results"`
 }
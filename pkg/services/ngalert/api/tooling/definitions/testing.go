@@ -191,11 +191,14 @@ type BacktestConfig struct {
 	Data      []models.AlertQuery `json:"data"` // TODO yuri. Create API model for AlertQuery
 	For       model.Duration      `json:"for,omitempty"`
 
-	Title       string            `json:"title"`
-	Labels      map[string]string `json:"labels,omitempty"`
-	Annotations map[string]string `json:"annotations,omitempty"`
-
-	NoDataState NoDataState `json:"no_data_state"`
+	Title string `json:"title"`
+	// NamespaceUID is the UID of the folder of the rule. It is used to add the folder labels to the simulated alerts.
+	NamespaceUID string            `json:"namespace_uid,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+
+	NoDataState  NoDataState         `json:"no_data_state"`
+	ExecErrState ExecutionErrorState `json:"exec_err_state,omitempty"`
 }
 
 // swagger:model
@@ -2,7 +2,6 @@ package backtesting
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -13,8 +12,10 @@ import (
 	"github.com/hashicorp/go-multierror"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/user"
 )
@@ -34,16 +35,24 @@ type backtestingEvaluator interface {
 
 type stateManager interface {
 	ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *models.AlertRule, results eval.Results, extraLabels data.Labels) []state.StateTransition
+	Put(states []*state.State)
 }
 
 type Engine struct {
 	evalFactory        eval.EvaluatorFactory
 	createStateManager func() stateManager
+	policies           notificationPolicyProvider
+	muteTimings        muteTimingProvider
+	// disableGrafanaFolder is true if the folder title label must not be added to the alerts.
+	disableGrafanaFolder bool
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, policies notificationPolicyProvider, muteTimings muteTimingProvider, disableGrafanaFolder bool) *Engine {
 	return &Engine{
-		evalFactory: evalFactory,
+		evalFactory:          evalFactory,
+		policies:             policies,
+		muteTimings:          muteTimings,
+		disableGrafanaFolder: disableGrafanaFolder,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:       nil,
@@ -58,7 +67,12 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
 	}
 }
 
-func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+// Test evaluates the rule at every evaluation interval between from and to, and returns a frame with the state of
+// each alert instance at each evaluation. The alerts that would have been sent are routed through the notification
+// policy tree of the organization, and the number of notifications that each contact point would have received
+// is returned as NotificationsResult in the custom metadata of the frame. The alerts get the same extra labels
+// as the alerts of the scheduler, with folderTitle as the title of the folder of the rule.
+func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, folderTitle string, from, to time.Time) (*data.Frame, error) {
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
@@ -77,6 +91,12 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 
 	stateManager := e.createStateManager()
 
+	notifications, err := e.newNotificationSimulator(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	extraLabels := rule.GetRuleExtraLabels(folderTitle, !e.disableGrafanaFolder)
+
 	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
 
 	start := time.Now()
@@ -86,7 +106,7 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 
 	err = evaluator.Eval(ruleCtx, from, to, time.Duration(rule.IntervalSeconds)*time.Second, func(currentTime time.Time, results eval.Results) error {
 		idx := int(currentTime.Sub(from).Seconds()) / int(rule.IntervalSeconds)
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, extraLabels)
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -103,6 +123,22 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 				continue
 			}
 		}
+
+		var sent []state.StateTransition
+		var sentStates []*state.State
+		for _, s := range states {
+			if !s.NeedsSending(state.ResendDelay) {
+				continue
+			}
+			sent = append(sent, s)
+			if s.StateReason == models.StateReasonMissingSeries { // do not put stale state back to state manager
+				continue
+			}
+			s.LastSentAt = currentTime
+			sentStates = append(sentStates, s.State)
+		}
+		stateManager.Put(sentStates)
+		notifications.process(currentTime, sent)
 		return nil
 	})
 	fields := make([]*data.Field, 0, len(valueFields)+1)
@@ -115,34 +151,71 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 	if err != nil {
 		return nil, err
 	}
+	notifications.flush(to)
+	result.SetMeta(&data.FrameMeta{Custom: notifications.result})
 	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
 	return result, nil
 }
 
+// newNotificationSimulator returns a simulator of the notification policy tree of the organization of the rule. If the
+// rule has notification settings, its alerts are routed through the autogenerated route of these settings, the same
+// way the Alertmanager does.
+func (e *Engine) newNotificationSimulator(ctx context.Context, rule *models.AlertRule) (*notificationSimulator, error) {
+	tree, err := e.policies.GetPolicyTree(ctx, rule.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification policy tree: %w", err)
+	}
+	muteTimings, err := e.muteTimings.GetMuteTimings(ctx, rule.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mute timings: %w", err)
+	}
+	if rule.NotificationSettings != nil {
+		settings := *rule.NotificationSettings
+		if err := settings.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidInputData, err)
+		}
+		for _, name := range settings.MuteTimeIntervals {
+			if !containsMuteTiming(muteTimings, name) {
+				return nil, fmt.Errorf("%w: mute timing '%s' of the notification settings does not exist", ErrInvalidInputData, name)
+			}
+		}
+		tree = *notifier.AutogeneratedRoute(&tree, []models.NotificationSettings{settings})
+	}
+	return newNotificationSimulator(tree, muteTimings)
+}
+
+func containsMuteTiming(muteTimings []definitions.MuteTimeInterval, name string) bool {
+	for _, mt := range muteTimings {
+		if mt.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
+	dataQueries := 0
 	for _, q := range condition.Data {
-		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
-			if len(condition.Data) != 1 {
-				return nil, errors.New("data queries are not supported with other expressions or data queries")
-			}
-			if condition.Condition == "" {
-				return nil, fmt.Errorf("condition must not be empty and be set to the data query %s", q.RefID)
-			}
-			if condition.Condition != q.RefID {
-				return nil, fmt.Errorf("condition must be set to the data query %s", q.RefID)
-			}
-			model := struct {
-				DataFrame *data.Frame `json:"data"`
-			}{}
-			err := json.Unmarshal(q.Model, &model)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse data frame: %w", err)
-			}
-			if model.DataFrame == nil {
-				return nil, errors.New("the data field must not be empty")
-			}
-			return newDataEvaluator(condition.Condition, model.DataFrame)
+		if isDataQuery(q) {
+			dataQueries++
+		}
+	}
+	if dataQueries > 0 && len(condition.Data) > 1 {
+		return newPipelineEvaluator(ctx, user, condition)
+	}
+	if dataQueries == 1 {
+		q := condition.Data[0]
+		if condition.Condition == "" {
+			return nil, fmt.Errorf("condition must not be empty and be set to the data query %s", q.RefID)
+		}
+		if condition.Condition != q.RefID {
+			return nil, fmt.Errorf("condition must be set to the data query %s", q.RefID)
+		}
+		frame, err := parseDataQuery(q)
+		if err != nil {
+			return nil, err
 		}
+		return newDataEvaluator(condition.Condition, frame)
 	}
 
 	evaluator, err := evalFactory.Create(eval.EvaluationContext{Ctx: ctx,
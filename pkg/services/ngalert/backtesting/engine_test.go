@@ -9,9 +9,14 @@ import (
 	"testing"
 	"time"
 
+	alertingModels "github.com/grafana/alerting/models"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -187,6 +192,8 @@ func TestEvaluatorTest(t *testing.T) {
 		createStateManager: func() stateManager {
 			return manager
 		},
+		policies:    &fakePolicyProvider{tree: definitions.Route{Receiver: "default"}},
+		muteTimings: &fakeMuteTimingProvider{},
 	}
 	rule := models.AlertRuleGen(models.WithInterval(time.Second))()
 	ruleInterval := time.Duration(rule.IntervalSeconds) * time.Second
@@ -213,7 +220,7 @@ func TestEvaluatorTest(t *testing.T) {
 			return states
 		}
 
-		frame, err := engine.Test(context.Background(), nil, rule, from, to)
+		frame, err := engine.Test(context.Background(), nil, rule, "", from, to)
 
 		require.NoError(t, err)
 		require.Len(t, frame.Fields, len(states)+1) // +1 - timestamp
@@ -288,7 +295,7 @@ func TestEvaluatorTest(t *testing.T) {
 			return stateByTime[now]
 		}
 
-		frame, err := engine.Test(context.Background(), nil, rule, from, to)
+		frame, err := engine.Test(context.Background(), nil, rule, "", from, to)
 		require.NoError(t, err)
 
 		var field3 *data.Field
@@ -310,6 +317,18 @@ func TestEvaluatorTest(t *testing.T) {
 		}
 	})
 
+	t.Run("should add the extra labels of the rule to the alerts", func(t *testing.T) {
+		manager.stateCallback = func(now time.Time) []state.StateTransition {
+			return nil
+		}
+		from := time.Unix(0, 0)
+		_, err := engine.Test(context.Background(), nil, rule, "test-folder", from, from.Add(ruleInterval))
+		require.NoError(t, err)
+		require.Equal(t, data.Labels(rule.GetRuleExtraLabels("test-folder", true)), manager.extraLabels)
+		require.Equal(t, "test-folder", manager.extraLabels[models.FolderTitleLabel])
+		require.Equal(t, rule.UID, manager.extraLabels[alertingModels.RuleUIDLabel])
+	})
+
 	t.Run("should fail", func(t *testing.T) {
 		manager.stateCallback = func(now time.Time) []state.StateTransition {
 			return nil
@@ -319,17 +338,17 @@ func TestEvaluatorTest(t *testing.T) {
 			from := time.Now()
 			t.Run("when from=to", func(t *testing.T) {
 				to := from
-				_, err := engine.Test(context.Background(), nil, rule, from, to)
+				_, err := engine.Test(context.Background(), nil, rule, "", from, to)
 				require.ErrorIs(t, err, ErrInvalidInputData)
 			})
 			t.Run("when from > to", func(t *testing.T) {
 				to := from.Add(-ruleInterval)
-				_, err := engine.Test(context.Background(), nil, rule, from, to)
+				_, err := engine.Test(context.Background(), nil, rule, "", from, to)
 				require.ErrorIs(t, err, ErrInvalidInputData)
 			})
 			t.Run("when to-from < interval", func(t *testing.T) {
 				to := from.Add(ruleInterval).Add(-time.Millisecond)
-				_, err := engine.Test(context.Background(), nil, rule, from, to)
+				_, err := engine.Test(context.Background(), nil, rule, "", from, to)
 				require.ErrorIs(t, err, ErrInvalidInputData)
 			})
 		})
@@ -341,20 +360,144 @@ func TestEvaluatorTest(t *testing.T) {
 			}
 			from := time.Now()
 			to := from.Add(ruleInterval)
-			_, err := engine.Test(context.Background(), nil, rule, from, to)
+			_, err := engine.Test(context.Background(), nil, rule, "", from, to)
+			require.ErrorIs(t, err, expectedError)
+		})
+
+		t.Run("when notification policy tree cannot be read", func(t *testing.T) {
+			expectedError := errors.New("test-error")
+			engine := &Engine{
+				createStateManager: func() stateManager {
+					return manager
+				},
+				policies:    &fakePolicyProvider{err: expectedError},
+				muteTimings: &fakeMuteTimingProvider{},
+			}
+			from := time.Now()
+			to := from.Add(ruleInterval)
+			_, err := engine.Test(context.Background(), nil, rule, "", from, to)
 			require.ErrorIs(t, err, expectedError)
 		})
 	})
 }
 
+func TestEngineNotificationSettings(t *testing.T) {
+	groupWait := model.Duration(30 * time.Second)
+	groupInterval := model.Duration(5 * time.Minute)
+	repeatInterval := model.Duration(4 * time.Hour)
+	settingsRepeatInterval := model.Duration(time.Hour)
+
+	engine := &Engine{
+		policies: &fakePolicyProvider{tree: definitions.Route{
+			Receiver:       "default",
+			GroupByStr:     []string{"alertname"},
+			GroupWait:      &groupWait,
+			GroupInterval:  &groupInterval,
+			RepeatInterval: &repeatInterval,
+		}},
+		muteTimings: &fakeMuteTimingProvider{muteTimings: []definitions.MuteTimeInterval{
+			{
+				MuteTimeInterval: config.MuteTimeInterval{
+					Name: "always",
+					TimeIntervals: []timeinterval.TimeInterval{
+						{Times: []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 24 * 60}}},
+					},
+				},
+			},
+		}},
+	}
+
+	// simulate sends an alert of the rule that fires for 2h30m to the simulator of the engine every minute
+	// for 3 hours, and returns the notifications that would have been sent.
+	simulate := func(t *testing.T, rule *models.AlertRule) NotificationsResult {
+		t.Helper()
+		sim, err := engine.newNotificationSimulator(context.Background(), rule)
+		require.NoError(t, err)
+		start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := &state.State{Labels: data.Labels(rule.GetRuleExtraLabels("folder", true))}
+		for ts := time.Duration(0); ts <= 3*time.Hour; ts += time.Minute {
+			now := start.Add(ts)
+			if ts < 150*time.Minute {
+				s.State = eval.Alerting
+				s.EndsAt = now.Add(4 * time.Minute)
+			} else {
+				if s.State != eval.Alerting {
+					sim.process(now, nil)
+					continue
+				}
+				s.State = eval.Normal
+				s.EndsAt = now
+			}
+			sim.process(now, []state.StateTransition{{State: s}})
+		}
+		sim.flush(start.Add(3 * time.Hour))
+		return sim.result
+	}
+
+	t.Run("should use the notification policy tree if rule has no notification settings", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithOrgID(1))()
+		rule.NotificationSettings = nil
+		result := simulate(t, rule)
+		require.Equal(t, map[string]int{"default": 2}, result.ContactPoints)
+	})
+
+	t.Run("should use the autogenerated route if rule has notification settings", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithNotificationSettings(models.NotificationSettings{
+			Receiver:       "team-a",
+			RepeatInterval: &settingsRepeatInterval,
+		}))()
+		result := simulate(t, rule)
+		require.Equal(t, map[string]int{"team-a": 4}, result.ContactPoints)
+	})
+
+	t.Run("should apply mute timings of notification settings", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithNotificationSettings(models.NotificationSettings{
+			Receiver:          "team-a",
+			MuteTimeIntervals: []string{"always"},
+		}))()
+		result := simulate(t, rule)
+		require.Empty(t, result.ContactPoints)
+	})
+
+	t.Run("should fail if mute timing of notification settings does not exist", func(t *testing.T) {
+		rule := models.AlertRuleGen(models.WithOrgID(1), models.WithNotificationSettings(models.NotificationSettings{
+			Receiver:          "team-a",
+			MuteTimeIntervals: []string{"unknown"},
+		}))()
+		_, err := engine.newNotificationSimulator(context.Background(), rule)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
 type fakeStateManager struct {
 	stateCallback func(now time.Time) []state.StateTransition
+	extraLabels   data.Labels
 }
 
-func (f *fakeStateManager) ProcessEvalResults(_ context.Context, evaluatedAt time.Time, _ *models.AlertRule, _ eval.Results, _ data.Labels) []state.StateTransition {
+func (f *fakeStateManager) ProcessEvalResults(_ context.Context, evaluatedAt time.Time, _ *models.AlertRule, _ eval.Results, extraLabels data.Labels) []state.StateTransition {
+	f.extraLabels = extraLabels
 	return f.stateCallback(evaluatedAt)
 }
 
+func (f *fakeStateManager) Put(_ []*state.State) {}
+
+type fakePolicyProvider struct {
+	tree definitions.Route
+	err  error
+}
+
+func (f *fakePolicyProvider) GetPolicyTree(_ context.Context, _ int64) (definitions.Route, error) {
+	return f.tree, f.err
+}
+
+type fakeMuteTimingProvider struct {
+	muteTimings []definitions.MuteTimeInterval
+}
+
+func (f *fakeMuteTimingProvider) GetMuteTimings(_ context.Context, _ int64) ([]definitions.MuteTimeInterval, error) {
+	return f.muteTimings, nil
+}
+
 type fakeBacktestingEvaluator struct {
 	evalCallback func(now time.Time) (eval.Results, error)
 }
@@ -0,0 +1,160 @@
+package backtesting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	dataQueryType = "__data__"
+	// pipelineEvaluationTimeout is the timeout of a single evaluation of a pipeline with inline data.
+	pipelineEvaluationTimeout = 30 * time.Second
+)
+
+func isDataQuery(q models.AlertQuery) bool {
+	return q.DatasourceUID == dataQueryType || q.QueryType == dataQueryType
+}
+
+// parseDataQuery returns the data frame that is set inline in the model of the data query.
+func parseDataQuery(q models.AlertQuery) (*data.Frame, error) {
+	model := struct {
+		DataFrame *data.Frame `json:"data"`
+	}{}
+	err := json.Unmarshal(q.Model, &model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data frame of query %s: %w", q.RefID, err)
+	}
+	if model.DataFrame == nil {
+		return nil, fmt.Errorf("the data field of query %s must not be empty", q.RefID)
+	}
+	return model.DataFrame, nil
+}
+
+// newPipelineEvaluator creates an evaluator that evaluates the queries and expressions of the condition,
+// where the data queries return the part of their inline data frame that is in the time range of the query.
+// The condition must not contain queries to data sources.
+func newPipelineEvaluator(ctx context.Context, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
+	frames := make(map[string]*data.Frame)
+	queries := make([]models.AlertQuery, 0, len(condition.Data))
+	for _, q := range condition.Data {
+		switch {
+		case isDataQuery(q):
+			frame, err := parseDataQuery(q)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := timeFieldIndex(frame); err != nil {
+				return nil, fmt.Errorf("invalid data frame of query %s: %w", q.RefID, err)
+			}
+			frames[q.RefID] = frame
+			q.DatasourceUID = dataQueryType
+		case expr.IsDataSource(q.DatasourceUID):
+		default:
+			return nil, fmt.Errorf("data queries can only be combined with expressions, but query %s uses data source %s", q.RefID, q.DatasourceUID)
+		}
+		queries = append(queries, q)
+	}
+	condition.Data = queries
+
+	factory := eval.NewEvaluatorFactory(
+		setting.UnifiedAlertingSettings{EvaluationTimeout: pipelineEvaluationTimeout},
+		dataSourceCache{},
+		expr.NewQueryDataService(&inlineDataHandler{frames: frames}),
+		nil,
+	)
+	evaluator, err := factory.Create(eval.EvaluationContext{Ctx: ctx, User: user}, condition)
+	if err != nil {
+		return nil, err
+	}
+	return &queryEvaluator{
+		eval: evaluator,
+	}, nil
+}
+
+// dataSourceCache resolves the data source of data queries.
+type dataSourceCache struct{}
+
+func (dataSourceCache) GetDatasource(_ context.Context, _ int64, _ *user.SignedInUser, _ bool) (*datasources.DataSource, error) {
+	return nil, datasources.ErrDataSourceNotFound
+}
+
+func (dataSourceCache) GetDatasourceByUID(_ context.Context, uid string, _ *user.SignedInUser, _ bool) (*datasources.DataSource, error) {
+	if uid != dataQueryType {
+		return nil, datasources.ErrDataSourceNotFound
+	}
+	return &datasources.DataSource{
+		UID:            dataQueryType,
+		Name:           dataQueryType,
+		Type:           dataQueryType,
+		JsonData:       simplejson.New(),
+		SecureJsonData: make(map[string][]byte),
+	}, nil
+}
+
+// inlineDataHandler responds to the queries of data source nodes with the rows of the inline data frame
+// of the query that are in the time range of the query.
+type inlineDataHandler struct {
+	frames map[string]*data.Frame
+}
+
+func (h *inlineDataHandler) QueryData(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		frame, ok := h.frames[q.RefID]
+		if !ok {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: fmt.Errorf("no data for query %s", q.RefID)}
+			continue
+		}
+		filtered, err := filterFrameByTime(frame, q.TimeRange.From, q.TimeRange.To)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{filtered}}
+	}
+	return resp, nil
+}
+
+func timeFieldIndex(frame *data.Frame) (int, error) {
+	idx := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	if len(idx) != 1 {
+		return 0, errors.New("data frame must have exactly one time field")
+	}
+	return idx[0], nil
+}
+
+// filterFrameByTime returns a copy of the frame with the rows whose time is in [from, to].
+func filterFrameByTime(frame *data.Frame, from, to time.Time) (*data.Frame, error) {
+	tIdx, err := timeFieldIndex(frame)
+	if err != nil {
+		return nil, err
+	}
+	timeField := frame.Fields[tIdx]
+	filtered := frame.EmptyCopy()
+	for i := 0; i < timeField.Len(); i++ {
+		t, ok := timeField.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		ts := t.(time.Time)
+		if ts.Before(from) || ts.After(to) {
+			continue
+		}
+		filtered.AppendRow(frame.RowCopy(i)...)
+	}
+	return filtered, nil
+}
@@ -0,0 +1,120 @@
+package backtesting
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestPipelineEvaluator(t *testing.T) {
+	from := time.Unix(0, 0).UTC()
+	interval := time.Minute
+
+	newDataQuery := func(t *testing.T, refID string, values []float64) models.AlertQuery {
+		t.Helper()
+		times := make([]time.Time, 0, len(values))
+		for i := range values {
+			times = append(times, from.Add(time.Duration(i)*interval))
+		}
+		frame := data.NewFrame("",
+			data.NewField("time", nil, times),
+			data.NewField("value", data.Labels{"query": refID}, values),
+		)
+		model, err := json.Marshal(struct {
+			Data *data.Frame `json:"data"`
+		}{Data: frame})
+		require.NoError(t, err)
+		return models.AlertQuery{
+			RefID:             refID,
+			QueryType:         "__data__",
+			RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(interval)},
+			Model:             model,
+		}
+	}
+	newExpression := func(refID string, model string) models.AlertQuery {
+		return models.AlertQuery{
+			RefID:         refID,
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(model),
+		}
+	}
+
+	t.Run("evaluates expressions of multiple data queries", func(t *testing.T) {
+		condition := models.Condition{
+			Condition: "E",
+			Data: []models.AlertQuery{
+				newDataQuery(t, "A", []float64{1, 2, 3, 4, 5}),
+				newDataQuery(t, "B", []float64{5, 5, 5, 5, 5}),
+				newExpression("C", `{"type": "reduce", "reducer": "last", "expression": "A"}`),
+				newExpression("D", `{"type": "reduce", "reducer": "last", "expression": "B"}`),
+				newExpression("E", `{"type": "math", "expression": "$C + $D > 8"}`),
+			},
+		}
+		evaluator, err := newBacktestingEvaluator(context.Background(), nil, &user.SignedInUser{}, condition)
+		require.NoError(t, err)
+		require.IsType(t, &queryEvaluator{}, evaluator)
+
+		var states []eval.State
+		err = evaluator.Eval(context.Background(), from, from.Add(5*interval), interval, func(now time.Time, results eval.Results) error {
+			require.Len(t, results, 1)
+			require.NoError(t, results[0].Error)
+			states = append(states, results[0].State)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []eval.State{eval.Normal, eval.Normal, eval.Normal, eval.Alerting, eval.Alerting}, states)
+	})
+
+	t.Run("fails if data queries are combined with data source queries", func(t *testing.T) {
+		condition := models.Condition{
+			Condition: "C",
+			Data: []models.AlertQuery{
+				newDataQuery(t, "A", []float64{1}),
+				{RefID: "B", DatasourceUID: "prometheus", Model: json.RawMessage(`{}`)},
+				newExpression("C", `{"type": "math", "expression": "$A + $B"}`),
+			},
+		}
+		_, err := newBacktestingEvaluator(context.Background(), nil, &user.SignedInUser{}, condition)
+		require.ErrorContains(t, err, "data queries can only be combined with expressions")
+	})
+
+	t.Run("fails if data frame does not have a time field", func(t *testing.T) {
+		model, err := json.Marshal(struct {
+			Data *data.Frame `json:"data"`
+		}{Data: data.NewFrame("", data.NewField("value", nil, []float64{1}))})
+		require.NoError(t, err)
+		condition := models.Condition{
+			Condition: "B",
+			Data: []models.AlertQuery{
+				{RefID: "A", QueryType: "__data__", Model: model},
+				newExpression("B", `{"type": "reduce", "reducer": "last", "expression": "A"}`),
+			},
+		}
+		_, err = newBacktestingEvaluator(context.Background(), nil, &user.SignedInUser{}, condition)
+		require.ErrorContains(t, err, "time field")
+	})
+}
+
+func TestFilterFrameByTime(t *testing.T) {
+	from := time.Unix(0, 0).UTC()
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{from, from.Add(time.Minute), from.Add(2 * time.Minute), from.Add(3 * time.Minute)}),
+		data.NewField("value", nil, []float64{1, 2, 3, 4}),
+	)
+	filtered, err := filterFrameByTime(frame, from.Add(time.Minute), from.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 2, filtered.Rows())
+	require.Equal(t, 2.0, filtered.Fields[1].At(0))
+	require.Equal(t, 3.0, filtered.Fields[1].At(1))
+	// the original frame is not modified
+	require.Equal(t, 4, frame.Rows())
+}
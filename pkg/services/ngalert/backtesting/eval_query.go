@@ -16,7 +16,8 @@ func (d *queryEvaluator) Eval(ctx context.Context, from, to time.Time, interval
 	for now := from; now.Before(to); now = now.Add(interval) {
 		results, err := d.eval.Evaluate(ctx, now)
 		if err != nil {
-			return err
+			// handle the failure the same way as the scheduler does, so the state reflects the execution error state of the rule
+			results = eval.Results{eval.NewResultFromError(err, now, 0)}
 		}
 		err = callback(now, results)
 		if err != nil {
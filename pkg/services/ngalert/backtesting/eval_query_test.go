@@ -51,18 +51,26 @@ func TestQueryEvaluator_Eval(t *testing.T) {
 			expectedError := errors.New("test")
 			m.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(expectedResults, nil).Times(3)
 			m.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(nil, expectedError).Once()
+			m.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(expectedResults, nil)
 			evaluator := queryEvaluator{
 				eval: m,
 			}
 
 			intervals := make([]time.Time, 0, times)
+			var errorResults eval.Results
 
 			err := evaluator.Eval(ctx, from, to, interval, func(now time.Time, results eval.Results) error {
 				intervals = append(intervals, now)
+				if len(results) == 1 && results[0].State == eval.Error {
+					errorResults = results
+				}
 				return nil
 			})
-			require.ErrorIs(t, err, expectedError)
-			require.Len(t, intervals, 3)
+			require.NoError(t, err)
+			require.Len(t, intervals, times)
+			require.Len(t, errorResults, 1)
+			require.ErrorIs(t, errorResults[0].Error, expectedError)
+			require.Equal(t, intervals[3], errorResults[0].EvaluatedAt)
 		})
 
 		t.Run("when callback fails", func(t *testing.T) {
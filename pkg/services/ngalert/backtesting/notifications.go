@@ -0,0 +1,197 @@
+package backtesting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+type notificationPolicyProvider interface {
+	GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, error)
+}
+
+type muteTimingProvider interface {
+	GetMuteTimings(ctx context.Context, orgID int64) ([]definitions.MuteTimeInterval, error)
+}
+
+// NotificationsResult describes the notifications that would have been sent during backtesting.
+type NotificationsResult struct {
+	// Alerts is the number of times alerts would have been sent to the Alertmanager.
+	Alerts int `json:"alerts"`
+	// ContactPoints is the number of notifications that each contact point would have received.
+	ContactPoints map[string]int `json:"contactPoints"`
+}
+
+type simulatedAlert struct {
+	labels model.LabelSet
+	endsAt time.Time
+}
+
+func (a *simulatedAlert) resolvedAt(t time.Time) bool {
+	return !a.endsAt.After(t)
+}
+
+// aggregationGroup simulates an aggregation group of the Alertmanager, that batches the alerts that match
+// a route and have the same values of the group_by labels into a single notification.
+type aggregationGroup struct {
+	route     *dispatch.Route
+	alerts    map[model.Fingerprint]*simulatedAlert
+	nextFlush time.Time
+	// lastNotify is the time of the last notification, and lastFiring the alerts that were firing in it.
+	lastNotify time.Time
+	lastFiring map[model.Fingerprint]struct{}
+}
+
+// groupKey identifies an aggregation group. Sibling routes can have the same Key(), so the route itself is used.
+type groupKey struct {
+	route  *dispatch.Route
+	labels string
+}
+
+// notificationSimulator simulates how the Alertmanager would route, group and time the alerts
+// sent during backtesting, and counts the notifications that each contact point would have received.
+type notificationSimulator struct {
+	route       *dispatch.Route
+	muteTimings map[string][]timeinterval.TimeInterval
+	groups      map[groupKey]*aggregationGroup
+	result      NotificationsResult
+}
+
+func newNotificationSimulator(tree definitions.Route, muteTimings []definitions.MuteTimeInterval) (*notificationSimulator, error) {
+	if err := tree.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid notification policy tree: %w", err)
+	}
+	mt := make(map[string][]timeinterval.TimeInterval, len(muteTimings))
+	for _, m := range muteTimings {
+		mt[m.Name] = m.TimeIntervals
+	}
+	return &notificationSimulator{
+		route:       dispatch.NewRoute(tree.AsAMRoute(), nil),
+		muteTimings: mt,
+		groups:      make(map[groupKey]*aggregationGroup),
+		result: NotificationsResult{
+			ContactPoints: make(map[string]int),
+		},
+	}, nil
+}
+
+// process flushes the aggregation groups that are due at now, and then routes the states that
+// would have been sent to the Alertmanager at now. The labels of the states must include the extra labels of the rule.
+func (n *notificationSimulator) process(now time.Time, states []state.StateTransition) {
+	n.flush(now)
+	for _, s := range states {
+		lbls := make(model.LabelSet, len(s.Labels))
+		for k, v := range s.Labels {
+			lbls[model.LabelName(k)] = model.LabelValue(v)
+		}
+		endsAt := s.EndsAt
+		if s.State.State == eval.Normal {
+			endsAt = now
+		}
+		n.result.Alerts++
+		n.add(now, &simulatedAlert{labels: lbls, endsAt: endsAt})
+	}
+}
+
+func (n *notificationSimulator) add(now time.Time, alert *simulatedAlert) {
+	fp := alert.labels.Fingerprint()
+	for _, r := range n.route.Match(alert.labels) {
+		groupLabels := model.LabelSet{}
+		for k, v := range alert.labels {
+			if _, ok := r.RouteOpts.GroupBy[k]; ok || r.RouteOpts.GroupByAll {
+				groupLabels[k] = v
+			}
+		}
+		key := groupKey{route: r, labels: groupLabels.String()}
+		g, ok := n.groups[key]
+		if !ok {
+			if alert.resolvedAt(now) {
+				// The Alertmanager does not create a group for a resolved alert.
+				continue
+			}
+			g = &aggregationGroup{
+				route:      r,
+				alerts:     make(map[model.Fingerprint]*simulatedAlert),
+				nextFlush:  now.Add(r.RouteOpts.GroupWait),
+				lastFiring: make(map[model.Fingerprint]struct{}),
+			}
+			n.groups[key] = g
+		}
+		g.alerts[fp] = alert
+	}
+}
+
+// flush flushes, in order of time, all aggregation groups that are due at or before now.
+func (n *notificationSimulator) flush(now time.Time) {
+	for {
+		var next *aggregationGroup
+		var nextKey groupKey
+		for key, g := range n.groups {
+			if g.nextFlush.After(now) {
+				continue
+			}
+			if next == nil || g.nextFlush.Before(next.nextFlush) || (g.nextFlush.Equal(next.nextFlush) && key.labels < nextKey.labels) {
+				next, nextKey = g, key
+			}
+		}
+		if next == nil {
+			return
+		}
+		if n.flushGroup(next) {
+			delete(n.groups, nextKey)
+		}
+	}
+}
+
+// flushGroup sends a notification for the group if its alerts changed since the last notification,
+// or if the repeat interval elapsed. It returns true if the group has no alerts left.
+func (n *notificationSimulator) flushGroup(g *aggregationGroup) bool {
+	at := g.nextFlush
+	firing := make(map[model.Fingerprint]struct{})
+	changed := false
+	for fp, a := range g.alerts {
+		if a.resolvedAt(at) {
+			if _, ok := g.lastFiring[fp]; ok {
+				changed = true
+			}
+			continue
+		}
+		firing[fp] = struct{}{}
+		if _, ok := g.lastFiring[fp]; !ok {
+			changed = true
+		}
+	}
+	repeat := len(firing) > 0 && !g.lastNotify.IsZero() && !at.Before(g.lastNotify.Add(g.route.RouteOpts.RepeatInterval))
+	if (changed || repeat) && !n.isMuted(g.route, at) {
+		n.result.ContactPoints[g.route.RouteOpts.Receiver]++
+		g.lastNotify = at
+		g.lastFiring = firing
+	}
+
+	for fp, a := range g.alerts {
+		if a.resolvedAt(at) {
+			delete(g.alerts, fp)
+		}
+	}
+	g.nextFlush = at.Add(g.route.RouteOpts.GroupInterval)
+	return len(g.alerts) == 0
+}
+
+func (n *notificationSimulator) isMuted(r *dispatch.Route, t time.Time) bool {
+	for _, name := range r.RouteOpts.MuteTimeIntervals {
+		for _, ti := range n.muteTimings[name] {
+			if ti.ContainsTime(t.UTC()) {
+				return true
+			}
+		}
+	}
+	return false
+}
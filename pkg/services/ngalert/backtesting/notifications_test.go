@@ -0,0 +1,112 @@
+package backtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	groupWait := model.Duration(30 * time.Second)
+	groupInterval := model.Duration(5 * time.Minute)
+	repeatInterval := model.Duration(4 * time.Hour)
+
+	teamMatcher := func(team string) definitions.ObjectMatchers {
+		m, err := labels.NewMatcher(labels.MatchEqual, "team", team)
+		require.NoError(t, err)
+		return definitions.ObjectMatchers{m}
+	}
+
+	tree := definitions.Route{
+		Receiver:       "default",
+		GroupByStr:     []string{"alertname"},
+		GroupWait:      &groupWait,
+		GroupInterval:  &groupInterval,
+		RepeatInterval: &repeatInterval,
+		Routes: []*definitions.Route{
+			{
+				Receiver:       "team-a",
+				ObjectMatchers: teamMatcher("a"),
+				Continue:       true,
+			},
+			{
+				Receiver:       "team-a-escalation",
+				ObjectMatchers: teamMatcher("a"),
+			},
+			{
+				Receiver:          "team-muted",
+				ObjectMatchers:    teamMatcher("muted"),
+				MuteTimeIntervals: []string{"always"},
+			},
+		},
+	}
+	muteTimings := []definitions.MuteTimeInterval{
+		{
+			MuteTimeInterval: config.MuteTimeInterval{
+				Name: "always",
+				TimeIntervals: []timeinterval.TimeInterval{
+					{Times: []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 24 * 60}}},
+				},
+			},
+		},
+	}
+
+	// simulate evaluates a rule every minute from start to end, where the alert with the given labels
+	// is firing until resolveAt, and sends states to the simulator the same way the engine does.
+	simulate := func(t *testing.T, lbls data.Labels, resolveAt time.Duration, end time.Duration) NotificationsResult {
+		t.Helper()
+		sim, err := newNotificationSimulator(tree, muteTimings)
+		require.NoError(t, err)
+		start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := &state.State{Labels: lbls.Copy()}
+		s.Labels["alertname"] = "test"
+		for ts := time.Duration(0); ts <= end; ts += time.Minute {
+			now := start.Add(ts)
+			if ts < resolveAt {
+				s.State = eval.Alerting
+				s.EndsAt = now.Add(4 * time.Minute)
+			} else {
+				if s.State != eval.Alerting {
+					sim.process(now, nil)
+					continue
+				}
+				s.State = eval.Normal
+				s.EndsAt = now
+			}
+			sim.process(now, []state.StateTransition{{State: s}})
+		}
+		sim.flush(start.Add(end))
+		return sim.result
+	}
+
+	t.Run("should notify after group wait and resolve", func(t *testing.T) {
+		result := simulate(t, data.Labels{"team": "b"}, 2*time.Hour, 3*time.Hour)
+		require.Equal(t, map[string]int{"default": 2}, result.ContactPoints)
+		require.Equal(t, 2*60+1, result.Alerts)
+	})
+
+	t.Run("should repeat notifications after repeat interval", func(t *testing.T) {
+		result := simulate(t, data.Labels{"team": "b"}, 10*time.Hour, 9*time.Hour)
+		require.Equal(t, map[string]int{"default": 3}, result.ContactPoints)
+	})
+
+	t.Run("should notify all matching routes when continue is set", func(t *testing.T) {
+		result := simulate(t, data.Labels{"team": "a"}, 2*time.Hour, 3*time.Hour)
+		require.Equal(t, map[string]int{"team-a": 2, "team-a-escalation": 2}, result.ContactPoints)
+	})
+
+	t.Run("should not notify when muted", func(t *testing.T) {
+		result := simulate(t, data.Labels{"team": "muted"}, 2*time.Hour, 3*time.Hour)
+		require.Empty(t, result.ContactPoints)
+	})
+}
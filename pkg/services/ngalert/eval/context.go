@@ -3,13 +3,26 @@ package eval
 import (
 	"context"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+// AlertingResultsReader provides the labels of the results that were alerting at the previous evaluation of a rule.
+type AlertingResultsReader interface {
+	Read() []data.Labels
+}
+
 // EvaluationContext represents the context in which a condition is evaluated.
 type EvaluationContext struct {
 	Ctx  context.Context
 	User *user.SignedInUser
+	// AlertingResultsReader is used by threshold expressions with a resolving threshold
+	// to find out which results were alerting at the previous evaluation. It is optional.
+	AlertingResultsReader AlertingResultsReader
+	// RuleReferenceReader is used by queries that reference other alert rules of the same group
+	// to read the current results of these rules. It is optional.
+	RuleReferenceReader RuleReferenceReader
 }
 
 func Context(ctx context.Context, user *user.SignedInUser) EvaluationContext {
@@ -18,3 +31,12 @@ func Context(ctx context.Context, user *user.SignedInUser) EvaluationContext {
 		User: user,
 	}
 }
+
+// NewContextWithPreviousResults creates an EvaluationContext that uses the results of the previous evaluation.
+func NewContextWithPreviousResults(ctx context.Context, user *user.SignedInUser, reader AlertingResultsReader) EvaluationContext {
+	return EvaluationContext{
+		Ctx:                   ctx,
+		User:                  user,
+		AlertingResultsReader: reader,
+	}
+}
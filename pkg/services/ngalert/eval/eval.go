@@ -4,6 +4,7 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"runtime/debug"
@@ -256,6 +257,12 @@ func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheServ
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model from '%s': %w", q.RefID, err)
 		}
+		if ctx.AlertingResultsReader != nil && expr.IsDataSource(q.DatasourceUID) {
+			model, err = patchHysteresisExpression(model, ctx.AlertingResultsReader)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set previous results to query model from '%s': %w", q.RefID, err)
+			}
+		}
 		interval, err := q.GetIntervalDuration()
 		if err != nil {
 			return nil, fmt.Errorf("failed to retrieve intervalMs from '%s': %w", q.RefID, err)
@@ -270,6 +277,11 @@ func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheServ
 		if !ok {
 			if expr.IsDataSource(q.DatasourceUID) {
 				ds = expr.DataSourceModel()
+			} else if q.IsRuleReference() {
+				if _, err := q.GetRuleReference(); err != nil {
+					return nil, err
+				}
+				ds = ruleReferenceDataSourceModel()
 			} else {
 				ds, err = dsCacheService.GetDatasourceByUID(ctx.Ctx, q.DatasourceUID, ctx.User, false /*skipCache*/)
 				if err != nil {
@@ -292,6 +304,22 @@ func getExprRequest(ctx EvaluationContext, data []models.AlertQuery, dsCacheServ
 	return req, nil
 }
 
+// patchHysteresisExpression sets the labels of the results that were alerting at the previous evaluation
+// in the model if it is a threshold expression with a resolving threshold. Other models are returned unchanged.
+func patchHysteresisExpression(model []byte, reader AlertingResultsReader) ([]byte, error) {
+	var query map[string]interface{}
+	if err := json.Unmarshal(model, &query); err != nil {
+		return nil, err
+	}
+	if !expr.IsHysteresisExpression(query) {
+		return model, nil
+	}
+	if err := expr.SetLoadedDimensionsToHysteresisCommand(query, reader.Read()); err != nil {
+		return nil, err
+	}
+	return json.Marshal(query)
+}
+
 type NumberValueCapture struct {
 	Var    string // RefID
 	Labels data.Labels
@@ -606,7 +634,7 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 		return err
 	}
 	for _, query := range req.Queries {
-		if query.DataSource == nil || expr.IsDataSource(query.DataSource.UID) {
+		if query.DataSource == nil || expr.IsDataSource(query.DataSource.UID) || query.DataSource.UID == models.RuleReferenceDatasourceUID {
 			continue
 		}
 		p, found := e.pluginsStore.Plugin(ctx.Ctx, query.DataSource.Type)
@@ -617,7 +645,7 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 			return fmt.Errorf("datasource refID %s is not a backend datasource", query.RefID)
 		}
 	}
-	_, err = e.create(condition, req)
+	_, err = e.create(condition, req, e.expressionServiceFor(ctx, condition))
 	return err
 }
 
@@ -632,11 +660,22 @@ func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition
 	if err != nil {
 		return nil, err
 	}
-	return e.create(condition, req)
+	return e.create(condition, req, e.expressionServiceFor(ctx, condition))
+}
+
+// expressionServiceFor returns the expression service that executes the condition. If the condition has queries
+// that reference other alert rules, they are answered by the RuleReferenceReader of the context.
+func (e *evaluatorImpl) expressionServiceFor(ctx EvaluationContext, condition models.Condition) *expr.Service {
+	if !hasRuleReferences(condition.Data) {
+		return e.expressionService
+	}
+	return e.expressionService.WithQueryDataMiddleware(func(next backend.QueryDataHandler) backend.QueryDataHandler {
+		return &ruleReferenceHandler{next: next, reader: ctx.RuleReferenceReader}
+	})
 }
 
-func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (ConditionEvaluator, error) {
-	pipeline, err := e.expressionService.BuildPipeline(req)
+func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request, expressionService *expr.Service) (ConditionEvaluator, error) {
+	pipeline, err := expressionService.BuildPipeline(req)
 	if err != nil {
 		return nil, err
 	}
@@ -645,7 +684,7 @@ func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (C
 		if node.RefID() == condition.Condition {
 			return &conditionEvaluator{
 				pipeline:          pipeline,
-				expressionService: e.expressionService,
+				expressionService: expressionService,
 				condition:         condition,
 				evalTimeout:       e.evaluationTimeout,
 			}, nil
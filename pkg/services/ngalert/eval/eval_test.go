@@ -573,6 +573,46 @@ func TestEvaluateRaw(t *testing.T) {
 	})
 }
 
+func TestGetExprRequestWithPreviousResults(t *testing.T) {
+	thresholdModel := `{
+		"type": "threshold",
+		"expression": "A",
+		"conditions": [{
+			"evaluator": {"type": "gt", "params": [90]},
+			"unloadEvaluator": {"type": "lt", "params": [80]}
+		}]
+	}`
+	data := []models.AlertQuery{
+		{
+			RefID:         "A",
+			DatasourceUID: "test",
+			Model:         []byte(`{"expr": "up"}`),
+		},
+		{
+			RefID:         "B",
+			DatasourceUID: expr.DatasourceUID,
+			Model:         []byte(thresholdModel),
+		},
+	}
+	cacheService := &fakes.FakeCacheService{
+		DataSources: []*datasources.DataSource{{UID: "test"}},
+	}
+	reader := fakeAlertingResultsReader{{"instance": "a"}}
+	ctx := NewContextWithPreviousResults(context.Background(), &user.SignedInUser{}, reader)
+
+	req, err := getExprRequest(ctx, data, cacheService)
+	require.NoError(t, err)
+	require.Len(t, req.Queries, 2)
+	require.NotContains(t, string(req.Queries[0].JSON), "loadedDimensions")
+	require.Contains(t, string(req.Queries[1].JSON), `"loadedDimensions":[{"instance":"a"}]`)
+}
+
+type fakeAlertingResultsReader []data.Labels
+
+func (f fakeAlertingResultsReader) Read() []data.Labels {
+	return f
+}
+
 type fakeExpressionService struct {
 	hook func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error)
 }
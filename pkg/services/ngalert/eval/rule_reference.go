@@ -0,0 +1,75 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RuleReferenceReader provides the current results of the alert rules that are referenced by the queries of a rule.
+type RuleReferenceReader interface {
+	// Read returns a frame with a row for each alert instance of the referenced rule. The frame has a nullable string field
+	// for each label of the instances and a single number field with the value described by the mode of the reference.
+	Read(ref models.RuleReference) (*data.Frame, error)
+}
+
+var errNoRuleReferenceReader = errors.New("queries that reference other alert rules can only be evaluated by the scheduler")
+
+// ruleReferenceDataSourceModel returns the data source of queries that reference other alert rules.
+func ruleReferenceDataSourceModel() *datasources.DataSource {
+	return &datasources.DataSource{
+		UID:            models.RuleReferenceDatasourceUID,
+		Name:           models.RuleReferenceDatasourceUID,
+		Type:           models.RuleReferenceDatasourceUID,
+		JsonData:       simplejson.New(),
+		SecureJsonData: make(map[string][]byte),
+	}
+}
+
+func hasRuleReferences(data []models.AlertQuery) bool {
+	for _, q := range data {
+		if q.IsRuleReference() {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleReferenceHandler responds to the queries that reference other alert rules with the frames of the reader,
+// and passes on all other queries to the next handler.
+type ruleReferenceHandler struct {
+	next   backend.QueryDataHandler
+	reader RuleReferenceReader
+}
+
+func (h *ruleReferenceHandler) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req.PluginContext.PluginID != models.RuleReferenceDatasourceUID {
+		return h.next.QueryData(ctx, req)
+	}
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		if h.reader == nil {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: errNoRuleReferenceReader}
+			continue
+		}
+		ref, err := models.ParseRuleReference(q.JSON)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: fmt.Errorf("invalid rule reference: %w", err)}
+			continue
+		}
+		frame, err := h.reader.Read(ref)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return resp, nil
+}
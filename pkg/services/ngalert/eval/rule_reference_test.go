@@ -0,0 +1,87 @@
+package eval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/plugins"
+	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestEvaluateRuleReference(t *testing.T) {
+	condition := models.Condition{
+		Condition: "B",
+		Data: []models.AlertQuery{
+			{
+				RefID:         "A",
+				DatasourceUID: models.RuleReferenceDatasourceUID,
+				Model:         []byte(`{"ruleUid": "other", "mode": "value", "refId": "C"}`),
+			},
+			{
+				RefID:         "B",
+				DatasourceUID: expr.DatasourceUID,
+				Model:         []byte(`{"type": "math", "expression": "$A > 10"}`),
+			},
+		},
+	}
+	factory := NewEvaluatorFactory(setting.UnifiedAlertingSettings{}, &fakes.FakeCacheService{}, expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil), &plugins.FakePluginStore{})
+
+	t.Run("should evaluate the frame of the referenced rule", func(t *testing.T) {
+		reader := &fakeRuleReferenceReader{frame: data.NewFrame("",
+			data.NewField("instance", nil, []string{"a", "b"}),
+			data.NewField("value", nil, []float64{5, 15}),
+		)}
+		ctx := Context(context.Background(), &user.SignedInUser{})
+		ctx.RuleReferenceReader = reader
+
+		require.NoError(t, factory.Validate(ctx, condition))
+		evaluator, err := factory.Create(ctx, condition)
+		require.NoError(t, err)
+		results, err := evaluator.Evaluate(context.Background(), time.Now())
+		require.NoError(t, err)
+
+		require.Equal(t, []models.RuleReference{{RuleUID: "other", Mode: models.RuleReferenceModeValue, RefID: "C"}}, reader.refs)
+		states := map[string]State{}
+		for _, r := range results {
+			states[r.Instance["instance"]] = r.State
+		}
+		require.Equal(t, map[string]State{"a": Normal, "b": Alerting}, states)
+	})
+
+	t.Run("should fail if there is no reader", func(t *testing.T) {
+		evaluator, err := factory.Create(Context(context.Background(), &user.SignedInUser{}), condition)
+		require.NoError(t, err)
+		_, err = evaluator.Evaluate(context.Background(), time.Now())
+		require.ErrorIs(t, err, errNoRuleReferenceReader)
+	})
+
+	t.Run("should fail to validate an invalid reference", func(t *testing.T) {
+		invalid := models.Condition{
+			Condition: "A",
+			Data: []models.AlertQuery{{
+				RefID:         "A",
+				DatasourceUID: models.RuleReferenceDatasourceUID,
+				Model:         []byte(`{"mode": "value"}`),
+			}},
+		}
+		require.ErrorContains(t, factory.Validate(Context(context.Background(), &user.SignedInUser{}), invalid), "ruleUid must not be empty")
+	})
+}
+
+type fakeRuleReferenceReader struct {
+	frame *data.Frame
+	refs  []models.RuleReference
+}
+
+func (f *fakeRuleReferenceReader) Read(ref models.RuleReference) (*data.Frame, error) {
+	f.refs = append(f.refs, ref)
+	return f.frame, nil
+}
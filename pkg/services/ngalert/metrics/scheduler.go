@@ -25,6 +25,13 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	ShardReplicas                       prometheus.Gauge
+	ShardOwnedAlertRules                prometheus.Gauge
+	ShardRebalances                     prometheus.Counter
+	TickDispatchedRules                 prometheus.Gauge
+	TickDispatchedBatches               prometheus.Gauge
+	EvaluationsInFlight                 prometheus.Gauge
+	EvalLag                             *prometheus.HistogramVec
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -121,5 +128,63 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		ShardReplicas: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_replicas",
+				Help:      "The number of instances that share the evaluation of alert rules.",
+			},
+		),
+		ShardOwnedAlertRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_alert_rules",
+				Help:      "The number of alert rules that are assigned to this instance for evaluation.",
+			},
+		),
+		ShardRebalances: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_shard_rebalances_total",
+				Help:      "The total number of times the alert rules were rebalanced because an instance joined or left.",
+			},
+		),
+		TickDispatchedRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_tick_dispatched_rules",
+				Help:      "The number of alert rules that were dispatched for evaluation at the last tick.",
+			},
+		),
+		TickDispatchedBatches: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_tick_dispatched_batches",
+				Help:      "The number of batches the alert rules of the last tick were spread across. The rules of a group that is evaluated in order are one batch.",
+			},
+		),
+		EvaluationsInFlight: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_evaluations_in_flight",
+				Help:      "The number of alert rule evaluations that are running.",
+			},
+		),
+		EvalLag: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_rule_evaluation_lag_seconds",
+				Help:      "The time between when an evaluation was planned to be dispatched, after the delay that spreads the evaluations of a tick, and the start of the evaluation.",
+				Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 15, 30, 60, 120},
+			},
+			[]string{"org"},
+		),
 	}
 }
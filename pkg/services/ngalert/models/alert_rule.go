@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	alertingModels "github.com/grafana/alerting/models"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/util/cmputil"
@@ -112,13 +113,19 @@ const (
 	StateReasonPaused        = "Paused"
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
+	// StateReasonKeepFiring explains that an alert is firing although the condition of the rule is no longer met,
+	// because the rule keeps it firing for a while.
+	StateReasonKeepFiring = "KeepFiring"
 )
 
 var (
 	// InternalLabelNameSet are labels that grafana automatically include as part of the labelset.
 	InternalLabelNameSet = map[string]struct{}{
-		alertingModels.RuleUIDLabel:      {},
-		alertingModels.NamespaceUIDLabel: {},
+		alertingModels.RuleUIDLabel:         {},
+		alertingModels.NamespaceUIDLabel:    {},
+		AutogeneratedRouteLabel:             {},
+		AutogeneratedRouteReceiverNameLabel: {},
+		AutogeneratedRouteSettingsHashLabel: {},
 	}
 	InternalAnnotationNameSet = map[string]struct{}{
 		DashboardUIDAnnotation:              {},
@@ -156,10 +163,43 @@ type AlertRule struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For time.Duration
+	// KeepFiringFor is how long an alert keeps firing after the condition of the rule is no longer met.
+	KeepFiringFor time.Duration `xorm:"keep_firing_for"`
+	Annotations   map[string]string
+	Labels        map[string]string
+	IsPaused      bool
+	// Record is set if the rule is a recording rule. Recording rules do not produce alerts,
+	// but write the result of their condition as series of a new metric.
+	Record *Record `xorm:"json 'record'"`
+	// NotificationSettings is set if the alerts of the rule are sent to a contact point directly,
+	// instead of being routed by the notification policy tree.
+	NotificationSettings *NotificationSettings `xorm:"json 'notification_settings'"`
+}
+
+// Record describes the series written by a recording rule.
+type Record struct {
+	// Metric is the name of the metric the series are written to.
+	Metric string `json:"metric"`
+	// From is the refID of the query or expression whose result is recorded.
+	From string `json:"from"`
+}
+
+// Validate checks that the metric name is a valid Prometheus metric name and that From refers to
+// one of the queries or expressions of the rule.
+func (r *Record) Validate(data []AlertQuery) error {
+	if !model.IsValidMetricName(model.LabelValue(r.Metric)) {
+		return fmt.Errorf("%w: %q is not a valid metric name", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	if r.From == "" {
+		return fmt.Errorf("%w: the query or expression to record must be set", ErrAlertRuleFailedValidation)
+	}
+	for _, q := range data {
+		if q.RefID == r.From {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: the query or expression %s to record does not exist", ErrAlertRuleFailedValidation, r.From)
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -212,6 +252,11 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// IsRecordingRule returns true if the rule is a recording rule.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return alertRule.Record != nil
+}
+
 func (alertRule *AlertRule) GetEvalCondition() Condition {
 	return Condition{
 		Condition: alertRule.Condition,
@@ -237,6 +282,75 @@ func (alertRule *AlertRule) Diff(rule *AlertRule, ignore ...string) cmputil.Diff
 	return reporter.Diffs
 }
 
+// Copy creates a deep copy of the alert rule.
+func (alertRule *AlertRule) Copy() *AlertRule {
+	result := AlertRule{
+		ID:              alertRule.ID,
+		OrgID:           alertRule.OrgID,
+		Title:           alertRule.Title,
+		Condition:       alertRule.Condition,
+		Updated:         alertRule.Updated,
+		IntervalSeconds: alertRule.IntervalSeconds,
+		Version:         alertRule.Version,
+		UID:             alertRule.UID,
+		NamespaceUID:    alertRule.NamespaceUID,
+		RuleGroup:       alertRule.RuleGroup,
+		RuleGroupIndex:  alertRule.RuleGroupIndex,
+		NoDataState:     alertRule.NoDataState,
+		ExecErrState:    alertRule.ExecErrState,
+		For:             alertRule.For,
+		KeepFiringFor:   alertRule.KeepFiringFor,
+		IsPaused:        alertRule.IsPaused,
+	}
+
+	if alertRule.DashboardUID != nil {
+		dash := *alertRule.DashboardUID
+		result.DashboardUID = &dash
+	}
+	if alertRule.PanelID != nil {
+		p := *alertRule.PanelID
+		result.PanelID = &p
+	}
+	if alertRule.Record != nil {
+		record := *alertRule.Record
+		result.Record = &record
+	}
+	if alertRule.NotificationSettings != nil {
+		settings := *alertRule.NotificationSettings
+		settings.GroupBy = append([]string(nil), alertRule.NotificationSettings.GroupBy...)
+		settings.MuteTimeIntervals = append([]string(nil), alertRule.NotificationSettings.MuteTimeIntervals...)
+		result.NotificationSettings = &settings
+	}
+
+	for _, d := range alertRule.Data {
+		q := AlertQuery{
+			RefID:             d.RefID,
+			QueryType:         d.QueryType,
+			RelativeTimeRange: d.RelativeTimeRange,
+			DatasourceUID:     d.DatasourceUID,
+		}
+		q.Model = make([]byte, 0, cap(d.Model))
+		q.Model = append(q.Model, d.Model...)
+		result.Data = append(result.Data, q)
+	}
+
+	if alertRule.Annotations != nil {
+		result.Annotations = make(map[string]string, len(alertRule.Annotations))
+		for s, s2 := range alertRule.Annotations {
+			result.Annotations[s] = s2
+		}
+	}
+
+	if alertRule.Labels != nil {
+		result.Labels = make(map[string]string, len(alertRule.Labels))
+		for s, s2 := range alertRule.Labels {
+			result.Labels[s] = s2
+		}
+	}
+
+	return &result
+}
+
 // SetDashboardAndPanelFromAnnotations will set the DashboardUID and PanelID field by doing a lookup in the annotations.
 // Errors when the found annotations are not valid.
 func (alertRule *AlertRule) SetDashboardAndPanelFromAnnotations() error {
@@ -301,6 +415,26 @@ func (alertRule *AlertRule) GetKey() AlertRuleKey {
 	return AlertRuleKey{OrgID: alertRule.OrgID, UID: alertRule.UID}
 }
 
+// GetRuleExtraLabels returns the labels that Grafana adds to the alerts of the rule. The folder title label is only
+// added if includeFolder is true.
+func (alertRule *AlertRule) GetRuleExtraLabels(folderTitle string, includeFolder bool) map[string]string {
+	extraLabels := make(map[string]string, 4)
+
+	extraLabels[alertingModels.NamespaceUIDLabel] = alertRule.NamespaceUID
+	extraLabels[model.AlertNameLabel] = alertRule.Title
+	extraLabels[alertingModels.RuleUIDLabel] = alertRule.UID
+
+	if includeFolder {
+		extraLabels[FolderTitleLabel] = folderTitle
+	}
+	if alertRule.NotificationSettings != nil {
+		for k, v := range alertRule.NotificationSettings.ToLabels() {
+			extraLabels[k] = v
+		}
+	}
+	return extraLabels
+}
+
 // GetGroupKey returns the identifier of a group the rule belongs to
 func (alertRule *AlertRule) GetGroupKey() AlertRuleGroupKey {
 	return AlertRuleGroupKey{OrgID: alertRule.OrgID, NamespaceUID: alertRule.NamespaceUID, RuleGroup: alertRule.RuleGroup}
@@ -352,10 +486,13 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For                  time.Duration
+	KeepFiringFor        time.Duration `xorm:"keep_firing_for"`
+	Annotations          map[string]string
+	Labels               map[string]string
+	IsPaused             bool
+	Record               *Record               `xorm:"json 'record'"`
+	NotificationSettings *NotificationSettings `xorm:"json 'notification_settings'"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -451,9 +588,9 @@ func (c Condition) IsValid() bool {
 // There are several exceptions:
 // 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
 // 2. There are fields that are patched together:
-//   - AlertRule.Condition and AlertRule.Data
+//   - AlertRule.Condition, AlertRule.Data and AlertRule.Record
 //
-// If either of the pair is specified, neither is patched.
+// If either of the Condition and Data is not specified, all of them are patched.
 func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOptionals) {
 	if ruleToPatch.Title == "" {
 		ruleToPatch.Title = existingRule.Title
@@ -461,6 +598,7 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
 		ruleToPatch.Condition = existingRule.Condition
 		ruleToPatch.Data = existingRule.Data
+		ruleToPatch.Record = existingRule.Record
 	}
 	if ruleToPatch.IntervalSeconds == 0 {
 		ruleToPatch.IntervalSeconds = existingRule.IntervalSeconds
@@ -480,6 +618,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -199,6 +199,12 @@ func TestPatchPartialAlertRule(t *testing.T) {
 					r.For = -1
 				},
 			},
+			{
+				name: "KeepFiringFor is -1",
+				mutator: func(r *AlertRuleWithOptionals) {
+					r.KeepFiringFor = -1
+				},
+			},
 			{
 				name: "IsPaused did not come in request",
 				mutator: func(r *AlertRuleWithOptionals) {
@@ -213,6 +219,7 @@ func TestPatchPartialAlertRule(t *testing.T) {
 				for {
 					rule := AlertRuleGen(func(rule *AlertRule) {
 						rule.For = time.Duration(rand.Int63n(1000) + 1)
+						rule.KeepFiringFor = time.Duration(rand.Int63n(1000) + 1)
 					})()
 					existing = &AlertRuleWithOptionals{AlertRule: *rule}
 					cloned := *existing
@@ -635,6 +642,26 @@ func TestDiff(t *testing.T) {
 	})
 }
 
+func TestCopy(t *testing.T) {
+	rule := AlertRuleGen(WithNotificationSettings(NotificationSettings{
+		Receiver:          "receiver",
+		GroupBy:           []string{"alertname"},
+		MuteTimeIntervals: []string{"weekends"},
+	}))()
+	rule.IsPaused = true
+	result := rule.Copy()
+	require.Empty(t, rule.Diff(result))
+
+	result.Labels["new"] = "label"
+	result.Data[0].Model = append(result.Data[0].Model[:0], '{', '}')
+	result.NotificationSettings.GroupBy[0] = "changed"
+	result.NotificationSettings.MuteTimeIntervals[0] = "changed"
+	require.NotContains(t, rule.Labels, "new")
+	require.NotEqual(t, "{}", string(rule.Data[0].Model))
+	require.Equal(t, []string{"alertname"}, rule.NotificationSettings.GroupBy)
+	require.Equal(t, []string{"weekends"}, rule.NotificationSettings.MuteTimeIntervals)
+}
+
 func TestSortByGroupIndex(t *testing.T) {
 	ensureNotSorted := func(t *testing.T, rules []*AlertRule, less func(i, j int) bool) {
 		for i := 0; i < 5; i++ {
@@ -3,15 +3,88 @@ package models
 import (
 	"time"
 
+	"github.com/prometheus/alertmanager/pkg/labels"
+
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
 // HistoryQuery represents a query for alert state history.
 type HistoryQuery struct {
-	RuleUID      string
-	OrgID        int64
-	Labels       map[string]string
-	From         time.Time
-	To           time.Time
+	RuleUID string
+	OrgID   int64
+	Labels  map[string]string
+	// Matchers filter the state transitions by the labels of the alert instances. Unlike Labels, they support
+	// the !=, =~ and !~ operators.
+	Matchers labels.Matchers
+	// PreviousState and CurrentState filter the state transitions by the state before and after the transition,
+	// for example Alerting. The reason of the state, such as NoData in "Normal (NoData)", is ignored.
+	PreviousState string
+	CurrentState  string
+	From          time.Time
+	To            time.Time
+	// Limit is the maximum number of state transitions to return, in the order of time. Zero means that the
+	// default limit of the backend applies.
+	Limit int
+	// Offset is the number of state transitions to skip, so that the result can be paginated with Limit.
+	Offset       int
 	SignedInUser *user.SignedInUser
 }
+
+// HistoryAggregationInterval is the width of the time buckets that state transitions are counted in.
+const HistoryAggregationInterval = time.Hour
+
+// StateHistoryEntry is a single alert state transition, as recorded by the sql state history backend.
+type StateHistoryEntry struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	RuleGroup     string `xorm:"rule_group"`
+	NamespaceUID  string `xorm:"namespace_uid"`
+	Labels        string `xorm:"labels"`
+	LabelsHash    string `xorm:"labels_hash"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	Error         string `xorm:"error"`
+	Values        string `xorm:"state_values"`
+	DashboardUID  string `xorm:"dashboard_uid"`
+	PanelID       int64  `xorm:"panel_id"`
+	// EvaluatedAt is the Unix time in nanoseconds of the evaluation that caused the transition.
+	EvaluatedAt int64 `xorm:"evaluated_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (e *StateHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
+
+// GetStateHistoryQuery is the query for state transitions stored by the sql state history backend.
+type GetStateHistoryQuery struct {
+	OrgID   int64
+	RuleUID string
+	// From and To bound the evaluation time of the returned entries, both inclusive.
+	From time.Time
+	To   time.Time
+	// PreviousState and CurrentState filter the entries by the state before and after the transition, ignoring
+	// the reason of the state.
+	PreviousState string
+	CurrentState  string
+	// Before, if set, only returns the entries that were evaluated before it, so that the entries can be read in
+	// batches starting from the newest.
+	Before *StateHistoryEntry
+	// Limit is the maximum number of entries to return. If more entries match, the newest are returned.
+	// Zero means no limit.
+	Limit int
+	// Ascending returns the oldest entries within the limit instead of the newest, to page through the entries from
+	// the start of the time range.
+	Ascending bool
+	// Offset is the number of entries to skip, in the order of the query, before the limit is applied.
+	Offset int
+}
+
+// StateHistoryCount is the number of state transitions of an alert rule in a bucket of HistoryAggregationInterval.
+type StateHistoryCount struct {
+	RuleUID string `xorm:"rule_uid"`
+	// Bucket is the Unix time in nanoseconds of the start of the bucket.
+	Bucket      int64 `xorm:"bucket"`
+	Transitions int64 `xorm:"transitions"`
+}
@@ -7,8 +7,11 @@ import (
 
 // AlertInstance represents a single alert instance.
 type AlertInstance struct {
-	AlertInstanceKey  `xorm:"extends"`
-	Labels            InstanceLabels
+	AlertInstanceKey `xorm:"extends"`
+	Labels           InstanceLabels
+	// ResultLabels are the labels of the evaluation result of the instance, without the labels of the rule and the
+	// labels added by Grafana. They are nil for instances that were saved before they were stored.
+	ResultLabels      InstanceLabels
 	CurrentState      InstanceStateType
 	CurrentReason     string
 	CurrentStateSince time.Time
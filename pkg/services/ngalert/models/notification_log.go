@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotificationLogEntryNotFound is returned when the entry does not exist in the notification log.
+	ErrNotificationLogEntryNotFound = errors.New("notification log entry not found")
+)
+
+const (
+	// NotificationStatusSuccess is the status of a notification that the integration sent successfully.
+	NotificationStatusSuccess = "success"
+	// NotificationStatusFailed is the status of a notification that the integration failed to send.
+	NotificationStatusFailed = "failed"
+)
+
+// NotificationLogEntry is a single attempt of an integration of a contact point to send a notification for an alert group.
+type NotificationLogEntry struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// Receiver is the name of the contact point.
+	Receiver         string `xorm:"receiver"`
+	IntegrationUID   string `xorm:"integration_uid"`
+	IntegrationType  string `xorm:"integration_type"`
+	IntegrationIndex int    `xorm:"integration_index"`
+	GroupKey         string `xorm:"group_key"`
+	// GroupLabels are the labels of the alert group, serialized as JSON.
+	GroupLabels string `xorm:"group_labels"`
+	// Alerts are the alerts of the notification, serialized as JSON, so that the notification can be sent again.
+	Alerts string `xorm:"alerts"`
+	Status string `xorm:"status"`
+	Error  string `xorm:"error"`
+	// StatusCode is the HTTP status code of the last response to the integration. It is only known for integrations
+	// that send their requests with SendWebhook of the notification sender, such as webhook, Microsoft Teams or
+	// PagerDuty. It is zero for the others, such as email or Slack, and if no response was received.
+	StatusCode int `xorm:"status_code"`
+	// Duration is how long the integration took to send the notification, in milliseconds.
+	Duration int64 `xorm:"duration"`
+	// Attempt is the number of the attempt to send the notification, starting at 1, as failed notifications are retried.
+	Attempt int `xorm:"attempt"`
+	// SentAt is the Unix time in nanoseconds of the attempt.
+	SentAt int64 `xorm:"sent_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (e *NotificationLogEntry) TableName() string {
+	return "alert_notification_log"
+}
+
+// GetNotificationLogQuery is the query for the notification log of an organization.
+type GetNotificationLogQuery struct {
+	OrgID int64
+	// Receiver and Status filter the entries by contact point and status if they are set.
+	Receiver string
+	Status   string
+	// From and To bound the time of the returned entries, both inclusive.
+	From time.Time
+	To   time.Time
+	// Limit is the maximum number of entries to return, newest first. Zero means no limit.
+	Limit int
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// AutogeneratedRouteLabel is added to the alerts of rules with notification settings, so that they are routed by
+	// the autogenerated route of the Alertmanager configuration instead of the notification policy tree.
+	AutogeneratedRouteLabel = "__grafana_autogenerated__"
+	// AutogeneratedRouteReceiverNameLabel contains the name of the contact point of the notification settings.
+	AutogeneratedRouteReceiverNameLabel = "__grafana_receiver__"
+	// AutogeneratedRouteSettingsHashLabel contains the fingerprint of the notification settings, if they override
+	// any of the defaults of the contact point route.
+	AutogeneratedRouteSettingsHashLabel = "__grafana_route_settings_hash__"
+)
+
+// DefaultNotificationSettingsGroupBy are the labels the alerts of rules with notification settings are grouped by.
+// They are always part of the group by of the notification settings.
+var DefaultNotificationSettingsGroupBy = []string{FolderTitleLabel, model.AlertNameLabel}
+
+// NotificationSettings lets an alert rule send its alerts to a contact point directly, bypassing the notification
+// policy tree. They are compiled into a route of the autogenerated route of the Alertmanager configuration, so
+// the notification policy tree is not edited. Timings that are not set are inherited from the root route.
+type NotificationSettings struct {
+	Receiver          string          `json:"receiver"`
+	GroupBy           []string        `json:"group_by,omitempty"`
+	GroupWait         *model.Duration `json:"group_wait,omitempty"`
+	GroupInterval     *model.Duration `json:"group_interval,omitempty"`
+	RepeatInterval    *model.Duration `json:"repeat_interval,omitempty"`
+	MuteTimeIntervals []string        `json:"mute_time_intervals,omitempty"`
+}
+
+// Validate checks that the contact point is set, that the timings are valid and that the group by does not mix
+// the special label '...' with other labels.
+func (s *NotificationSettings) Validate() error {
+	if s.Receiver == "" {
+		return fmt.Errorf("%w: the contact point of the notification settings must be set", ErrAlertRuleFailedValidation)
+	}
+	if s.GroupWait != nil && *s.GroupWait < 0 {
+		return fmt.Errorf("%w: group_wait of the notification settings cannot be negative", ErrAlertRuleFailedValidation)
+	}
+	if s.GroupInterval != nil && *s.GroupInterval <= 0 {
+		return fmt.Errorf("%w: group_interval of the notification settings must be positive", ErrAlertRuleFailedValidation)
+	}
+	if s.RepeatInterval != nil && *s.RepeatInterval <= 0 {
+		return fmt.Errorf("%w: repeat_interval of the notification settings must be positive", ErrAlertRuleFailedValidation)
+	}
+	for _, l := range s.GroupBy {
+		if l == "..." {
+			if len(s.GroupBy) > 1 {
+				return fmt.Errorf("%w: group_by of the notification settings cannot contain other labels with '...'", ErrAlertRuleFailedValidation)
+			}
+			continue
+		}
+		if !model.LabelName(l).IsValid() {
+			return fmt.Errorf("%w: group_by of the notification settings contains invalid label %q", ErrAlertRuleFailedValidation, l)
+		}
+	}
+	for _, name := range s.MuteTimeIntervals {
+		if name == "" {
+			return fmt.Errorf("%w: mute timing names of the notification settings cannot be empty", ErrAlertRuleFailedValidation)
+		}
+	}
+	return nil
+}
+
+// NormalizedGroupBy returns the labels the alerts are grouped by. It is the group by of the settings, with the
+// default labels added if they are not there, unless the alerts are grouped by all labels.
+func (s *NotificationSettings) NormalizedGroupBy() []string {
+	if len(s.GroupBy) == 1 && s.GroupBy[0] == "..." {
+		return s.GroupBy
+	}
+	groupBy := make([]string, 0, len(s.GroupBy)+len(DefaultNotificationSettingsGroupBy))
+	seen := make(map[string]struct{}, cap(groupBy))
+	for _, l := range append(append([]string{}, DefaultNotificationSettingsGroupBy...), s.GroupBy...) {
+		if _, ok := seen[l]; ok {
+			continue
+		}
+		seen[l] = struct{}{}
+		groupBy = append(groupBy, l)
+	}
+	return groupBy
+}
+
+// IsAllDefault returns true if the settings only set the contact point, so the alerts are routed by the route
+// of the contact point.
+func (s *NotificationSettings) IsAllDefault() bool {
+	return len(s.GroupBy) == 0 && s.GroupWait == nil && s.GroupInterval == nil && s.RepeatInterval == nil && len(s.MuteTimeIntervals) == 0
+}
+
+// Fingerprint returns a fingerprint of the settings that is the same for equal settings. It does not depend on
+// the order of the group by labels and of the mute timings.
+func (s *NotificationSettings) Fingerprint() string {
+	h := fnv.New64()
+	tmp := make([]byte, 8)
+	writeString := func(s string) {
+		// We can ignore err as fnv64 does not return an error
+		// nolint:errcheck,gosec
+		h.Write([]byte(s))
+		// nolint:errcheck,gosec
+		h.Write([]byte{255})
+	}
+	writeDuration := func(d *model.Duration) {
+		if d == nil {
+			writeString("")
+			return
+		}
+		binary.LittleEndian.PutUint64(tmp, uint64(*d))
+		// nolint:errcheck,gosec
+		h.Write(tmp)
+	}
+	writeStrings := func(values []string) {
+		sorted := append([]string{}, values...)
+		sort.Strings(sorted)
+		for _, v := range sorted {
+			writeString(v)
+		}
+		writeString("")
+	}
+	writeString(s.Receiver)
+	writeStrings(s.NormalizedGroupBy())
+	writeDuration(s.GroupWait)
+	writeDuration(s.GroupInterval)
+	writeDuration(s.RepeatInterval)
+	writeStrings(s.MuteTimeIntervals)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// ToLabels returns the labels that route the alerts of the rule via the autogenerated route of the settings.
+func (s *NotificationSettings) ToLabels() map[string]string {
+	result := map[string]string{
+		AutogeneratedRouteLabel:             "true",
+		AutogeneratedRouteReceiverNameLabel: s.Receiver,
+	}
+	if !s.IsAllDefault() {
+		result[AutogeneratedRouteSettingsHashLabel] = s.Fingerprint()
+	}
+	return result
+}
+
+// NotificationSettingsValidator checks that the contact point and the mute timings of notification settings exist
+// in the Alertmanager configuration of the organization.
+type NotificationSettingsValidator struct {
+	receivers   map[string]struct{}
+	muteTimings map[string]struct{}
+}
+
+// NewNotificationSettingsValidator returns a validator for the given names of the contact points and mute timings.
+func NewNotificationSettingsValidator(receivers []string, muteTimings []string) NotificationSettingsValidator {
+	v := NotificationSettingsValidator{
+		receivers:   make(map[string]struct{}, len(receivers)),
+		muteTimings: make(map[string]struct{}, len(muteTimings)),
+	}
+	for _, name := range receivers {
+		v.receivers[name] = struct{}{}
+	}
+	for _, name := range muteTimings {
+		v.muteTimings[name] = struct{}{}
+	}
+	return v
+}
+
+// Validate returns an error that wraps ErrAlertRuleFailedValidation if the contact point or one of the mute timings
+// of the settings does not exist.
+func (v NotificationSettingsValidator) Validate(s NotificationSettings) error {
+	if _, ok := v.receivers[s.Receiver]; !ok {
+		return fmt.Errorf("%w: contact point '%s' of the notification settings does not exist", ErrAlertRuleFailedValidation, s.Receiver)
+	}
+	for _, name := range s.MuteTimeIntervals {
+		if _, ok := v.muteTimings[name]; !ok {
+			return fmt.Errorf("%w: mute timing '%s' of the notification settings does not exist", ErrAlertRuleFailedValidation, name)
+		}
+	}
+	return nil
+}
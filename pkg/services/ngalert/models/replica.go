@@ -0,0 +1,14 @@
+package models
+
+// ReplicaHeartbeat is the last heartbeat of an instance of Grafana that takes part in the evaluation of alert rules.
+type ReplicaHeartbeat struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	Replica string `xorm:"replica"`
+	// LastHeartbeat is the Unix time in seconds of the last heartbeat.
+	LastHeartbeat int64 `xorm:"last_heartbeat"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (h *ReplicaHeartbeat) TableName() string {
+	return "alert_scheduler_replica"
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// RuleReferenceDatasourceUID is the data source UID of a query that references another alert rule of the same group.
+// The query returns a number for each alert instance of the referenced rule, with the labels of the instance.
+const RuleReferenceDatasourceUID = "__alert_rule__"
+
+// RuleReferenceMode defines what the query that references another rule returns for each alert instance of that rule.
+type RuleReferenceMode string
+
+const (
+	// RuleReferenceModeState returns 1 if the alert instance is firing, and 0 otherwise.
+	RuleReferenceModeState RuleReferenceMode = "state"
+	// RuleReferenceModeValue returns the value of a query or expression at the latest evaluation of the referenced rule.
+	RuleReferenceModeValue RuleReferenceMode = "value"
+)
+
+// RuleReference is the model of a query that references another alert rule of the same group.
+type RuleReference struct {
+	// RuleUID is the UID of the referenced rule.
+	RuleUID string `json:"ruleUid"`
+	// Mode defines what is returned for each alert instance of the referenced rule. It defaults to RuleReferenceModeState.
+	Mode RuleReferenceMode `json:"mode,omitempty"`
+	// RefID is the query or expression of the referenced rule whose value is returned by RuleReferenceModeValue.
+	// It defaults to the condition of the referenced rule.
+	RefID string `json:"refId,omitempty"`
+}
+
+// IsRuleReference returns true if the alert query references another alert rule.
+func (aq *AlertQuery) IsRuleReference() bool {
+	return aq.DatasourceUID == RuleReferenceDatasourceUID
+}
+
+// GetRuleReference returns the reference of a query that references another alert rule.
+func (aq *AlertQuery) GetRuleReference() (RuleReference, error) {
+	if !aq.IsRuleReference() {
+		return RuleReference{}, fmt.Errorf("query %s does not reference an alert rule", aq.RefID)
+	}
+	ref, err := ParseRuleReference(aq.Model)
+	if err != nil {
+		return RuleReference{}, fmt.Errorf("invalid rule reference in query %s: %w", aq.RefID, err)
+	}
+	return ref, nil
+}
+
+// ParseRuleReference parses and validates the model of a query that references another alert rule.
+func ParseRuleReference(model json.RawMessage) (RuleReference, error) {
+	var ref RuleReference
+	if err := json.Unmarshal(model, &ref); err != nil {
+		return RuleReference{}, err
+	}
+	if ref.RuleUID == "" {
+		return RuleReference{}, errors.New("ruleUid must not be empty")
+	}
+	switch ref.Mode {
+	case "":
+		ref.Mode = RuleReferenceModeState
+	case RuleReferenceModeState, RuleReferenceModeValue:
+	default:
+		return RuleReference{}, fmt.Errorf("unknown mode %q, must be one of %q or %q", ref.Mode, RuleReferenceModeState, RuleReferenceModeValue)
+	}
+	return ref, nil
+}
+
+// GetReferencedRuleUIDs returns the UIDs of the rules that the queries of the rule reference.
+func (alertRule *AlertRule) GetReferencedRuleUIDs() ([]string, error) {
+	var uids []string
+	for _, q := range alertRule.Data {
+		if !q.IsRuleReference() {
+			continue
+		}
+		ref, err := q.GetRuleReference()
+		if err != nil {
+			return nil, err
+		}
+		uids = append(uids, ref.RuleUID)
+	}
+	return uids, nil
+}
+
+// HasRuleReferences returns true if any rule of the group references another rule.
+func (g RulesGroup) HasRuleReferences() bool {
+	for _, rule := range g {
+		for _, q := range rule.Data {
+			if q.IsRuleReference() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SortByDependencies sorts the rules of the group in the order in which they must be evaluated, so that each rule
+// comes after the rules that it references. Rules that do not depend on each other are sorted by group index.
+// It returns an error if a rule references a rule that is not in the group, or if the references form a cycle.
+func (g RulesGroup) SortByDependencies() error {
+	g.SortByGroupIndex()
+	byUID := make(map[string]int, len(g))
+	for i, rule := range g {
+		byUID[rule.UID] = i
+	}
+	dependencies := make([][]int, len(g))
+	for i, rule := range g {
+		uids, err := rule.GetReferencedRuleUIDs()
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", rule.UID, err)
+		}
+		for _, uid := range uids {
+			j, ok := byUID[uid]
+			if !ok {
+				return fmt.Errorf("rule %s references rule %s that is not in the same group", rule.UID, uid)
+			}
+			if i == j {
+				return fmt.Errorf("rule %s references itself", rule.UID)
+			}
+			dependencies[i] = append(dependencies[i], j)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(g))
+	order := make([]*AlertRule, 0, len(g))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch marks[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("rule %s is part of a cycle of rule references", g[i].UID)
+		}
+		marks[i] = visiting
+		deps := dependencies[i]
+		sort.Ints(deps)
+		for _, j := range deps {
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		marks[i] = visited
+		order = append(order, g[i])
+		return nil
+	}
+	for i := range g {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	copy(g, order)
+	return nil
+}
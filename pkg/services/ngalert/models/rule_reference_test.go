@@ -0,0 +1,106 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRuleReference(t *testing.T) {
+	testCases := []struct {
+		name     string
+		model    string
+		expected RuleReference
+		err      string
+	}{
+		{
+			name:     "mode defaults to state",
+			model:    `{"ruleUid": "a"}`,
+			expected: RuleReference{RuleUID: "a", Mode: RuleReferenceModeState},
+		},
+		{
+			name:     "value mode with refId",
+			model:    `{"ruleUid": "a", "mode": "value", "refId": "B"}`,
+			expected: RuleReference{RuleUID: "a", Mode: RuleReferenceModeValue, RefID: "B"},
+		},
+		{
+			name:  "empty rule UID",
+			model: `{"mode": "state"}`,
+			err:   "ruleUid must not be empty",
+		},
+		{
+			name:  "unknown mode",
+			model: `{"ruleUid": "a", "mode": "labels"}`,
+			err:   `unknown mode "labels"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := ParseRuleReference(json.RawMessage(tc.model))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ref)
+		})
+	}
+}
+
+func TestRulesGroup_SortByDependencies(t *testing.T) {
+	rule := func(uid string, groupIndex int, references ...string) *AlertRule {
+		r := AlertRuleGen(WithGroupIndex(groupIndex))()
+		r.UID = uid
+		for i, ref := range references {
+			r.Data = append(r.Data, AlertQuery{
+				RefID:         fmt.Sprintf("ref%d", i),
+				DatasourceUID: RuleReferenceDatasourceUID,
+				Model:         json.RawMessage(fmt.Sprintf(`{"ruleUid": %q}`, ref)),
+			})
+		}
+		return r
+	}
+	uids := func(g RulesGroup) []string {
+		result := make([]string, 0, len(g))
+		for _, r := range g {
+			result = append(result, r.UID)
+		}
+		return result
+	}
+
+	t.Run("should sort rules after the rules they reference", func(t *testing.T) {
+		g := RulesGroup{
+			rule("a", 1, "c"),
+			rule("b", 2),
+			rule("c", 3, "d"),
+			rule("d", 4),
+		}
+		require.True(t, g.HasRuleReferences())
+		require.NoError(t, g.SortByDependencies())
+		require.Equal(t, []string{"d", "c", "a", "b"}, uids(g))
+	})
+
+	t.Run("should sort by group index if there are no references", func(t *testing.T) {
+		g := RulesGroup{rule("b", 2), rule("a", 1)}
+		require.False(t, g.HasRuleReferences())
+		require.NoError(t, g.SortByDependencies())
+		require.Equal(t, []string{"a", "b"}, uids(g))
+	})
+
+	t.Run("should fail if references form a cycle", func(t *testing.T) {
+		g := RulesGroup{rule("a", 1, "b"), rule("b", 2, "c"), rule("c", 3, "a")}
+		require.ErrorContains(t, g.SortByDependencies(), "cycle")
+	})
+
+	t.Run("should fail if a rule references itself", func(t *testing.T) {
+		g := RulesGroup{rule("a", 1, "a")}
+		require.ErrorContains(t, g.SortByDependencies(), "references itself")
+	})
+
+	t.Run("should fail if a rule references a rule of another group", func(t *testing.T) {
+		g := RulesGroup{rule("a", 1, "x")}
+		require.ErrorContains(t, g.SortByDependencies(), "not in the same group")
+	})
+}
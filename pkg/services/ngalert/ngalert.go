@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -37,6 +38,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -125,6 +127,7 @@ type AlertNG struct {
 	imageService        image.ImageService
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	heartbeat           *schedule.HeartbeatMembership
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 
@@ -196,6 +199,10 @@ func (ng *AlertNG) init() error {
 	ng.AlertsRouter = alertsRouter
 
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
+	recordingWriter, err := configureRecordingWriter(ng.Cfg.UnifiedAlerting.RecordingRules, ng.Log)
+	if err != nil {
+		return err
+	}
 	schedCfg := schedule.SchedulerCfg{
 		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
 		C:                    clk,
@@ -208,9 +215,20 @@ func (ng *AlertNG) init() error {
 		Metrics:              ng.Metrics.GetSchedulerMetrics(),
 		AlertSender:          alertsRouter,
 		Tracer:               ng.tracer,
+		RecordingWriter:      recordingWriter,
+		SequentialRuleGroups: ng.Cfg.UnifiedAlerting.SequentialRuleGroupEvaluation,
+		JitterEvaluations:    schedule.JitterStrategyFromSetting(ng.Cfg.UnifiedAlerting.JitterEvaluations),
+	}
+	switch ng.Cfg.UnifiedAlerting.HASharding {
+	case setting.HAShardingGossip:
+		schedCfg.Membership = ng.MultiOrgAlertmanager.ClusterMembership()
+	case setting.HAShardingDatabase:
+		ng.heartbeat = schedule.NewHeartbeatMembership(replicaName(ng.Cfg.UnifiedAlerting, setting.InstanceName), store,
+			ng.Cfg.UnifiedAlerting.HAShardingHeartbeatInterval, ng.Cfg.UnifiedAlerting.HAShardingHeartbeatTimeout, clk)
+		schedCfg.Membership = ng.heartbeat
 	}
 
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics())
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.store, ng.Metrics.GetHistorianMetrics())
 	if err != nil {
 		return err
 	}
@@ -236,10 +254,10 @@ func (ng *AlertNG) init() error {
 
 	// Provisioning
 	policyService := provisioning.NewNotificationPolicyService(store, store, store, ng.Cfg.UnifiedAlerting, ng.Log)
-	contactPointService := provisioning.NewContactPointService(store, ng.SecretsService, store, store, ng.Log)
+	contactPointService := provisioning.NewContactPointService(store, store, ng.SecretsService, store, store, ng.Log)
 	templateService := provisioning.NewTemplateService(store, store, store, ng.Log)
-	muteTimingService := provisioning.NewMuteTimingService(store, store, store, ng.Log)
-	alertRuleService := provisioning.NewAlertRuleService(store, store, ng.dashboardService, ng.QuotaService, store,
+	muteTimingService := provisioning.NewMuteTimingService(store, store, store, store, ng.Log)
+	alertRuleService := provisioning.NewAlertRuleService(store, store, store, ng.dashboardService, ng.QuotaService, store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()), ng.Log)
 
@@ -269,6 +287,7 @@ func (ng *AlertNG) init() error {
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
+		NotificationLog:      store,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -340,6 +359,14 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	})
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
+		if ng.heartbeat != nil {
+			// Register this instance and load the other instances before the first tick of the scheduler,
+			// so that it does not start by evaluating all rules.
+			ng.heartbeat.Register(ctx)
+			children.Go(func() error {
+				return ng.heartbeat.Run(subCtx)
+			})
+		}
 		children.Go(func() error {
 			return ng.schedule.Run(subCtx)
 		})
@@ -389,7 +416,35 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian) (Historian, error) {
+// replicaName returns the name of this instance in the heartbeats of the database. It must be stable across restarts,
+// so that a restarted instance takes back its rules rather than appearing as a new instance. The advertised address is
+// used if it is configured, otherwise the instance name, which defaults to the hostname.
+func replicaName(cfg setting.UnifiedAlertingSettings, instanceName string) string {
+	if cfg.HAAdvertiseAddr != "" {
+		return cfg.HAAdvertiseAddr
+	}
+	if instanceName != "" {
+		return instanceName
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "grafana"
+	}
+	return hostname
+}
+
+func configureRecordingWriter(cfg setting.UnifiedAlertingRecordingRulesSettings, l log.Logger) (writer.Writer, error) {
+	if !cfg.Enabled || cfg.URL == "" {
+		return writer.NewNoopWriter(), nil
+	}
+	w, err := writer.NewPrometheusWriter(cfg, l.New("component", "recording-writer"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recording rules configuration: %w", err)
+	}
+	return w, nil
+}
+
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, hs store.StateHistoryStore, met *metrics.Historian) (Historian, error) {
 	if !cfg.Enabled {
 		return historian.NewNopHistorian(), nil
 	}
@@ -413,7 +468,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		return backend, nil
 	}
 	if cfg.Backend == "sql" {
-		return historian.NewSqlBackend(), nil
+		return historian.NewSqlBackend(hs), nil
 	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", cfg.Backend)
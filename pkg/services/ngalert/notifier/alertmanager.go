@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
@@ -43,6 +44,8 @@ var silenceMaintenanceInterval = 15 * time.Minute
 type AlertingStore interface {
 	store.AlertingStore
 	store.ImageStore
+	NotificationSettingsStore
+	store.NotificationLogStore
 }
 
 type Alertmanager struct {
@@ -56,6 +59,13 @@ type Alertmanager struct {
 
 	decryptFn receivers.GetDecryptedValueFn
 	orgID     int64
+
+	// notificationLog records the attempts to send notifications. It is nil if the notification log is disabled.
+	notificationLog *notificationLog
+
+	integrationsMtx sync.RWMutex
+	// integrations are the integrations of the applied configuration by contact point and UID.
+	integrations map[integrationKey]*alertingNotify.Integration
 }
 
 // maintenanceOptions represent the options for components that need maintenance on a frequency within the Alertmanager.
@@ -142,6 +152,9 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		fileStore:           fileStore,
 		logger:              l,
 	}
+	if cfg.UnifiedAlerting.NotificationLog.Enabled {
+		am.notificationLog = newNotificationLog(store, orgID, l)
+	}
 
 	return am, nil
 }
@@ -155,6 +168,9 @@ func (am *Alertmanager) Ready() bool {
 
 func (am *Alertmanager) StopAndWait() {
 	am.Base.StopAndWait()
+	if am.notificationLog != nil {
+		am.notificationLog.stop()
+	}
 }
 
 // SaveAndApplyDefaultConfig saves the default configuration to the database and applies it to the Alertmanager.
@@ -177,7 +193,7 @@ func (am *Alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
+			_, err := am.applyConfig(ctx, cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
 			return err
 		})
 		if err != nil {
@@ -207,7 +223,7 @@ func (am *Alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.P
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, rawConfig)
+			_, err := am.applyConfig(ctx, cfg, rawConfig)
 			return err
 		})
 		if err != nil {
@@ -240,11 +256,17 @@ func (am *Alertmanager) ApplyConfig(ctx context.Context, dbCfg *ngmodels.AlertCo
 
 // applyConfig applies a new configuration by re-initializing all components using the configuration provided.
 // It returns a boolean indicating whether the user config was changed and an error.
+// The autogenerated route of the notification settings of the alert rules is added to the configuration before it is applied.
 // It is not safe to call concurrently.
-func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig []byte) (bool, error) {
+func (am *Alertmanager) applyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig, rawConfig []byte) (bool, error) {
+	cfg, autogenerated, err := am.withAutogeneratedRoute(ctx, cfg)
+	if err != nil {
+		return false, err
+	}
+
 	// First, let's make sure this config is not already loaded
 	var amConfigChanged bool
-	if rawConfig == nil {
+	if rawConfig == nil || autogenerated {
 		enc, err := json.Marshal(cfg.AlertmanagerConfig)
 		if err != nil {
 			// In theory, this should never happen.
@@ -280,23 +302,29 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 		return false, err
 	}
 
+	var integrations map[string][]*alertingNotify.Integration
 	err = am.Base.ApplyConfig(AlertingConfiguration{
-		RawAlertmanagerConfig:    rawConfig,
-		AlertmanagerConfig:       cfg.AlertmanagerConfig,
-		AlertmanagerTemplates:    tmpl,
-		IntegrationsFunc:         am.buildIntegrationsMap,
+		RawAlertmanagerConfig: rawConfig,
+		AlertmanagerConfig:    cfg.AlertmanagerConfig,
+		AlertmanagerTemplates: tmpl,
+		IntegrationsFunc: func(receivers []*apimodels.PostableApiReceiver, templates *alertingNotify.Template) (map[string][]*alertingNotify.Integration, error) {
+			var err error
+			integrations, err = am.buildIntegrationsMap(receivers, templates)
+			return integrations, err
+		},
 		ReceiverIntegrationsFunc: am.buildReceiverIntegration,
 	})
 	if err != nil {
 		return false, err
 	}
+	am.setIntegrations(cfg.AlertmanagerConfig.Receivers, integrations)
 
 	return true, nil
 }
 
 // applyAndMarkConfig applies a configuration and marks it as applied if no errors occur.
 func (am *Alertmanager) applyAndMarkConfig(ctx context.Context, hash string, cfg *apimodels.PostableUserConfig, rawConfig []byte) error {
-	configChanged, err := am.applyConfig(cfg, rawConfig)
+	configChanged, err := am.applyConfig(ctx, cfg, rawConfig)
 	if err != nil {
 		return err
 	}
@@ -338,6 +366,9 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
+		if am.notificationLog != nil {
+			n = am.notificationLog.wrap(receiver.Name, r, i, n)
+		}
 		integrations = append(integrations, alertingNotify.NewIntegration(n, n, r.Type, i))
 	}
 	return integrations, nil
@@ -115,14 +115,116 @@ func (moa *MultiOrgAlertmanager) ApplyAlertmanagerConfiguration(ctx context.Cont
 		}
 	}
 
+	var previous *definitions.PostableUserConfig
+	if query.Result != nil {
+		// If the previous configuration is invalid, the references of the alert rules are not checked.
+		previous, _ = Load([]byte(query.Result.AlertmanagerConfiguration))
+	}
+	renames := receiverRenames(previous, &config)
+	if err := moa.checkNotificationSettings(ctx, org, previous, &config, renames); err != nil {
+		return AlertmanagerConfigRejectedError{err}
+	}
+	if err := moa.renameReceivers(ctx, org, renames); err != nil {
+		return err
+	}
+
 	if err := am.SaveAndApplyConfig(ctx, &config); err != nil {
 		moa.logger.Error("unable to save and apply alertmanager configuration", "error", err)
+		reverted := make(map[string]string, len(renames))
+		for oldName, newName := range renames {
+			reverted[newName] = oldName
+		}
+		if err := moa.renameReceivers(ctx, org, reverted); err != nil {
+			moa.logger.Error("unable to revert the contact points of the notification settings of alert rules", "error", err)
+		}
 		return AlertmanagerConfigRejectedError{err}
 	}
 
 	return nil
 }
 
+// checkNotificationSettings returns an error if the configuration removes a contact point or a mute timing that the
+// notification settings of an alert rule refer to. Contact points that are renamed are checked with their new name.
+// References that are already invalid in the previous configuration are ignored.
+func (moa *MultiOrgAlertmanager) checkNotificationSettings(ctx context.Context, org int64, previous, cfg *definitions.PostableUserConfig, renames map[string]string) error {
+	if previous == nil {
+		return nil
+	}
+	settings, err := moa.configStore.ListNotificationSettings(ctx, org)
+	if err != nil {
+		return fmt.Errorf("failed to list notification settings of alert rules: %w", err)
+	}
+	previousValidator := previous.NotificationSettingsValidator()
+	validator := cfg.NotificationSettingsValidator()
+	for key, s := range settings {
+		if previousValidator.Validate(s) != nil {
+			continue
+		}
+		if newName, ok := renames[s.Receiver]; ok {
+			s.Receiver = newName
+		}
+		if err := validator.Validate(s); err != nil {
+			return fmt.Errorf("alert rule %s refers to a contact point or mute timing that is removed: %w", key.UID, err)
+		}
+	}
+	return nil
+}
+
+func (moa *MultiOrgAlertmanager) renameReceivers(ctx context.Context, org int64, renames map[string]string) error {
+	for oldName, newName := range renames {
+		n, err := moa.configStore.RenameReceiverInNotificationSettings(ctx, org, oldName, newName)
+		if err != nil {
+			return fmt.Errorf("failed to rename contact point %s of the notification settings of alert rules: %w", oldName, err)
+		}
+		if n > 0 {
+			moa.logger.Info("Renamed the contact point of the notification settings of alert rules", "org", org, "old", oldName, "new", newName, "rules", n)
+		}
+	}
+	return nil
+}
+
+// receiverRenames returns the new names of the contact points of the previous configuration that are renamed by the
+// configuration, by the old name. A contact point is renamed if neither it exists in the configuration nor its new
+// name exists in the previous configuration, and all of its integrations are in the same contact point.
+func receiverRenames(previous, cfg *definitions.PostableUserConfig) map[string]string {
+	if previous == nil {
+		return nil
+	}
+	names := make(map[string]struct{}, len(cfg.AlertmanagerConfig.Receivers))
+	receiverByUID := make(map[string]string)
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		names[r.Name] = struct{}{}
+		for _, gr := range r.GrafanaManagedReceivers {
+			receiverByUID[gr.UID] = r.Name
+		}
+	}
+	previousNames := make(map[string]struct{}, len(previous.AlertmanagerConfig.Receivers))
+	for _, r := range previous.AlertmanagerConfig.Receivers {
+		previousNames[r.Name] = struct{}{}
+	}
+
+	renames := make(map[string]string)
+	for _, r := range previous.AlertmanagerConfig.Receivers {
+		if _, ok := names[r.Name]; ok {
+			continue
+		}
+		newName := ""
+		for _, gr := range r.GrafanaManagedReceivers {
+			name, ok := receiverByUID[gr.UID]
+			if !ok || (newName != "" && name != newName) {
+				newName = ""
+				break
+			}
+			newName = name
+		}
+		if _, ok := previousNames[newName]; newName == "" || ok {
+			continue
+		}
+		renames[r.Name] = newName
+	}
+	return renames
+}
+
 func (moa *MultiOrgAlertmanager) mergeProvenance(ctx context.Context, config definitions.GettableUserConfig, org int64) (definitions.GettableUserConfig, error) {
 	if config.AlertmanagerConfig.Route != nil {
 		provenance, err := moa.ProvStore.GetProvenance(ctx, config.AlertmanagerConfig.Route, org)
@@ -0,0 +1,96 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestReceiverRenames(t *testing.T) {
+	load := func(mutate func(cfg *definitions.PostableUserConfig)) *definitions.PostableUserConfig {
+		cfg, err := Load([]byte(autogenTestConfig))
+		require.NoError(t, err)
+		mutate(cfg)
+		return cfg
+	}
+	previous := load(func(*definitions.PostableUserConfig) {})
+
+	t.Run("should find contact points whose integrations moved to a new contact point", func(t *testing.T) {
+		cfg := load(func(cfg *definitions.PostableUserConfig) {
+			cfg.AlertmanagerConfig.Receivers[1].Name = "team-a"
+		})
+		require.Equal(t, map[string]string{"team": "team-a"}, receiverRenames(previous, cfg))
+	})
+
+	t.Run("should not consider removed contact points renamed", func(t *testing.T) {
+		cfg := load(func(cfg *definitions.PostableUserConfig) {
+			cfg.AlertmanagerConfig.Receivers = cfg.AlertmanagerConfig.Receivers[:2]
+		})
+		require.Empty(t, receiverRenames(previous, cfg))
+	})
+
+	t.Run("should not consider contact points merged into an existing one renamed", func(t *testing.T) {
+		cfg := load(func(cfg *definitions.PostableUserConfig) {
+			receivers := cfg.AlertmanagerConfig.Receivers
+			receivers[0].GrafanaManagedReceivers = append(receivers[0].GrafanaManagedReceivers, receivers[2].GrafanaManagedReceivers...)
+			cfg.AlertmanagerConfig.Receivers = receivers[:2]
+		})
+		require.Empty(t, receiverRenames(previous, cfg))
+	})
+
+	t.Run("should not find renames without a previous configuration", func(t *testing.T) {
+		require.Empty(t, receiverRenames(nil, previous))
+	})
+}
+
+func TestCheckNotificationSettings(t *testing.T) {
+	load := func(mutate func(cfg *definitions.PostableUserConfig)) *definitions.PostableUserConfig {
+		cfg, err := Load([]byte(autogenTestConfig))
+		require.NoError(t, err)
+		mutate(cfg)
+		return cfg
+	}
+	previous := load(func(*definitions.PostableUserConfig) {})
+	configStore := &fakeConfigStore{
+		notificationSettings: map[int64]map[models.AlertRuleKey]models.NotificationSettings{
+			1: {
+				{OrgID: 1, UID: "rule"}:    {Receiver: "oncall", MuteTimeIntervals: []string{"weekends"}},
+				{OrgID: 1, UID: "invalid"}: {Receiver: "missing"},
+			},
+		},
+	}
+	moa := &MultiOrgAlertmanager{configStore: configStore, logger: log.NewNopLogger()}
+
+	t.Run("should reject removing a contact point that alert rules use", func(t *testing.T) {
+		cfg := load(func(cfg *definitions.PostableUserConfig) {
+			cfg.AlertmanagerConfig.Receivers = cfg.AlertmanagerConfig.Receivers[:2]
+		})
+		err := moa.checkNotificationSettings(context.Background(), 1, previous, cfg, nil)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "alert rule rule")
+	})
+
+	t.Run("should reject removing a mute timing that alert rules use", func(t *testing.T) {
+		cfg := load(func(cfg *definitions.PostableUserConfig) {
+			cfg.AlertmanagerConfig.MuteTimeIntervals = nil
+		})
+		err := moa.checkNotificationSettings(context.Background(), 1, previous, cfg, nil)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should accept renaming a contact point that alert rules use", func(t *testing.T) {
+		cfg := load(func(cfg *definitions.PostableUserConfig) {
+			cfg.AlertmanagerConfig.Receivers[2].Name = "on-call"
+		})
+		renames := receiverRenames(previous, cfg)
+		require.NoError(t, moa.checkNotificationSettings(context.Background(), 1, previous, cfg, renames))
+
+		require.NoError(t, moa.renameReceivers(context.Background(), 1, renames))
+		require.Equal(t, "on-call", configStore.notificationSettings[1][models.AlertRuleKey{OrgID: 1, UID: "rule"}].Receiver)
+	})
+}
@@ -0,0 +1,142 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// NotificationSettingsStore lists the notification settings of the alert rules of an organization, and renames the
+// contact points they refer to.
+type NotificationSettingsStore interface {
+	ListNotificationSettings(ctx context.Context, orgID int64) (map[models.AlertRuleKey]models.NotificationSettings, error)
+	RenameReceiverInNotificationSettings(ctx context.Context, orgID int64, oldReceiver, newReceiver string) (int, error)
+}
+
+// withAutogeneratedRoute returns a copy of the configuration with the autogenerated route of the notification
+// settings of the alert rules of the organization. The configuration is returned as-is if no rule has
+// notification settings.
+func (am *Alertmanager) withAutogeneratedRoute(ctx context.Context, cfg *apimodels.PostableUserConfig) (*apimodels.PostableUserConfig, bool, error) {
+	settings, err := am.Store.ListNotificationSettings(ctx, am.orgID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list notification settings of alert rules: %w", err)
+	}
+	result, changed := addAutogeneratedRoute(am.logger, cfg, settings)
+	return result, changed, nil
+}
+
+// addAutogeneratedRoute returns a copy of the configuration where the autogenerated route is the first route of the
+// root route. The autogenerated route matches the alerts of rules with notification settings, so they never reach the
+// notification policy tree. It has a route for each contact point, which in turn has a route for each distinct
+// notification settings that override the defaults. Notification settings that refer to contact points or mute
+// timings that do not exist are skipped, so the configuration can always be applied. The alerts of these rules are
+// sent to the default contact point instead.
+func addAutogeneratedRoute(logger log.Logger, cfg *apimodels.PostableUserConfig, settings map[models.AlertRuleKey]models.NotificationSettings) (*apimodels.PostableUserConfig, bool) {
+	if len(settings) == 0 || cfg.AlertmanagerConfig.Route == nil {
+		return cfg, false
+	}
+
+	validator := cfg.NotificationSettingsValidator()
+	valid := make([]models.NotificationSettings, 0, len(settings))
+	for key, s := range settings {
+		if err := validator.Validate(s); err != nil {
+			logger.Warn("Skipping notification settings of alert rule", "rule_uid", key.UID, "error", err)
+			continue
+		}
+		valid = append(valid, s)
+	}
+	if len(valid) == 0 {
+		return cfg, false
+	}
+
+	amConfig := cfg.AlertmanagerConfig
+	amConfig.Route = AutogeneratedRoute(cfg.AlertmanagerConfig.Route, valid)
+	result := *cfg
+	result.AlertmanagerConfig = amConfig
+	return &result, true
+}
+
+// AutogeneratedRoute returns a copy of the root route where the autogenerated route of the given notification settings
+// is the first route. The settings are not validated, callers must make sure that their contact points and mute
+// timings exist.
+func AutogeneratedRoute(root *apimodels.Route, settings []models.NotificationSettings) *apimodels.Route {
+	// Settings by fingerprint by contact point.
+	byReceiver := make(map[string]map[string]models.NotificationSettings)
+	for _, s := range settings {
+		if _, ok := byReceiver[s.Receiver]; !ok {
+			byReceiver[s.Receiver] = make(map[string]models.NotificationSettings)
+		}
+		if s.IsAllDefault() {
+			continue
+		}
+		byReceiver[s.Receiver][s.Fingerprint()] = s
+	}
+
+	autogenerated := &apimodels.Route{
+		Receiver:       root.Receiver,
+		ObjectMatchers: apimodels.ObjectMatchers{mustNewMatcher(models.AutogeneratedRouteLabel, "true")},
+	}
+	for _, receiver := range sortedKeys(byReceiver) {
+		receiverRoute := &apimodels.Route{
+			Receiver:       receiver,
+			ObjectMatchers: apimodels.ObjectMatchers{mustNewMatcher(models.AutogeneratedRouteReceiverNameLabel, receiver)},
+		}
+		setGroupBy(receiverRoute, models.DefaultNotificationSettingsGroupBy)
+		fingerprints := byReceiver[receiver]
+		for _, fp := range sortedKeys(fingerprints) {
+			s := fingerprints[fp]
+			settingsRoute := &apimodels.Route{
+				Receiver:          receiver,
+				ObjectMatchers:    apimodels.ObjectMatchers{mustNewMatcher(models.AutogeneratedRouteSettingsHashLabel, fp)},
+				GroupWait:         s.GroupWait,
+				GroupInterval:     s.GroupInterval,
+				RepeatInterval:    s.RepeatInterval,
+				MuteTimeIntervals: s.MuteTimeIntervals,
+			}
+			setGroupBy(settingsRoute, s.NormalizedGroupBy())
+			receiverRoute.Routes = append(receiverRoute.Routes, settingsRoute)
+		}
+		autogenerated.Routes = append(autogenerated.Routes, receiverRoute)
+	}
+
+	newRoot := *root
+	newRoot.Routes = append([]*apimodels.Route{autogenerated}, root.Routes...)
+	return &newRoot
+}
+
+// setGroupBy sets the group by of a route, including the fields that are otherwise set when the route is parsed.
+func setGroupBy(r *apimodels.Route, groupBy []string) {
+	r.GroupByStr = groupBy
+	for _, l := range groupBy {
+		if l == "..." {
+			r.GroupByAll = true
+			continue
+		}
+		r.GroupBy = append(r.GroupBy, model.LabelName(l))
+	}
+}
+
+func mustNewMatcher(name, value string) *labels.Matcher {
+	m, err := labels.NewMatcher(labels.MatchEqual, name, value)
+	if err != nil {
+		// This should never happen, equality matchers are always valid.
+		panic(err)
+	}
+	return m
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const autogenTestConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"routes": [{
+				"receiver": "team",
+				"object_matchers": [["team", "=", "a"]]
+			}]
+		},
+		"mute_time_intervals": [{
+			"name": "weekends",
+			"time_intervals": [{"weekdays": ["saturday", "sunday"]}]
+		}],
+		"receivers": [
+			{"name": "default", "grafana_managed_receiver_configs": [{"uid": "1", "name": "default", "type": "email", "settings": {"addresses": "a@example.com"}}]},
+			{"name": "team", "grafana_managed_receiver_configs": [{"uid": "2", "name": "team", "type": "email", "settings": {"addresses": "b@example.com"}}]},
+			{"name": "oncall", "grafana_managed_receiver_configs": [{"uid": "3", "name": "oncall", "type": "email", "settings": {"addresses": "c@example.com"}}]}
+		]
+	}
+}`
+
+func TestAddAutogeneratedRoute(t *testing.T) {
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+
+	t.Run("should not change the configuration if there are no notification settings", func(t *testing.T) {
+		cfg, err := Load([]byte(autogenTestConfig))
+		require.NoError(t, err)
+
+		result, changed := addAutogeneratedRoute(log.NewNopLogger(), cfg, nil)
+		require.False(t, changed)
+		require.Same(t, cfg, result)
+	})
+
+	t.Run("should add the autogenerated route as the first route without changing the original configuration", func(t *testing.T) {
+		cfg, err := Load([]byte(autogenTestConfig))
+		require.NoError(t, err)
+
+		custom := models.NotificationSettings{
+			Receiver:          "oncall",
+			GroupBy:           []string{"pod"},
+			GroupWait:         duration(time.Second),
+			RepeatInterval:    duration(time.Hour),
+			MuteTimeIntervals: []string{"weekends"},
+		}
+		settings := map[models.AlertRuleKey]models.NotificationSettings{
+			{OrgID: 1, UID: "rule-1"}: {Receiver: "team"},
+			{OrgID: 1, UID: "rule-2"}: custom,
+			{OrgID: 1, UID: "rule-3"}: custom,
+		}
+
+		result, changed := addAutogeneratedRoute(log.NewNopLogger(), cfg, settings)
+		require.True(t, changed)
+		require.Len(t, cfg.AlertmanagerConfig.Route.Routes, 1)
+		require.Len(t, result.AlertmanagerConfig.Route.Routes, 2)
+		require.Equal(t, cfg.AlertmanagerConfig.Route.Routes[0], result.AlertmanagerConfig.Route.Routes[1])
+
+		autogenerated := result.AlertmanagerConfig.Route.Routes[0]
+		require.Equal(t, "default", autogenerated.Receiver)
+		require.Len(t, autogenerated.Routes, 2)
+		require.Equal(t, "oncall", autogenerated.Routes[0].Receiver)
+		require.Len(t, autogenerated.Routes[0].Routes, 1)
+		require.Equal(t, "team", autogenerated.Routes[1].Receiver)
+		require.Empty(t, autogenerated.Routes[1].Routes)
+
+		settingsRoute := autogenerated.Routes[0].Routes[0]
+		require.Equal(t, []string{models.FolderTitleLabel, model.AlertNameLabel, "pod"}, settingsRoute.GroupByStr)
+		require.Equal(t, custom.GroupWait, settingsRoute.GroupWait)
+		require.Nil(t, settingsRoute.GroupInterval)
+		require.Equal(t, custom.RepeatInterval, settingsRoute.RepeatInterval)
+		require.Equal(t, []string{"weekends"}, settingsRoute.MuteTimeIntervals)
+
+		route := dispatch.NewRoute(result.AlertmanagerConfig.Route.AsAMRoute(), nil)
+		match := func(ls map[string]string) []*dispatch.Route {
+			set := model.LabelSet{}
+			for k, v := range ls {
+				set[model.LabelName(k)] = model.LabelValue(v)
+			}
+			return route.Match(set)
+		}
+
+		matched := match((&models.NotificationSettings{Receiver: "team"}).ToLabels())
+		require.Len(t, matched, 1)
+		require.Equal(t, "team", matched[0].RouteOpts.Receiver)
+		require.Equal(t, map[model.LabelName]struct{}{models.FolderTitleLabel: {}, model.AlertNameLabel: {}}, matched[0].RouteOpts.GroupBy)
+
+		matched = match(custom.ToLabels())
+		require.Len(t, matched, 1)
+		require.Equal(t, "oncall", matched[0].RouteOpts.Receiver)
+		require.Equal(t, time.Second, matched[0].RouteOpts.GroupWait)
+		require.Equal(t, time.Hour, matched[0].RouteOpts.RepeatInterval)
+		require.Contains(t, matched[0].RouteOpts.GroupBy, model.LabelName("pod"))
+
+		// Alerts of rules without notification settings are routed by the notification policy tree.
+		matched = match(map[string]string{"team": "a"})
+		require.Len(t, matched, 1)
+		require.Equal(t, "team", matched[0].RouteOpts.Receiver)
+	})
+
+	t.Run("should skip notification settings with unknown contact points or mute timings", func(t *testing.T) {
+		cfg, err := Load([]byte(autogenTestConfig))
+		require.NoError(t, err)
+
+		settings := map[models.AlertRuleKey]models.NotificationSettings{
+			{OrgID: 1, UID: "rule-1"}: {Receiver: "unknown"},
+			{OrgID: 1, UID: "rule-2"}: {Receiver: "team", MuteTimeIntervals: []string{"unknown"}},
+		}
+		result, changed := addAutogeneratedRoute(log.NewNopLogger(), cfg, settings)
+		require.False(t, changed)
+		require.Same(t, cfg, result)
+
+		settings[models.AlertRuleKey{OrgID: 1, UID: "rule-3"}] = models.NotificationSettings{Receiver: "oncall"}
+		result, changed = addAutogeneratedRoute(log.NewNopLogger(), cfg, settings)
+		require.True(t, changed)
+		autogenerated := result.AlertmanagerConfig.Route.Routes[0]
+		require.Len(t, autogenerated.Routes, 1)
+		require.Equal(t, "oncall", autogenerated.Routes[0].Receiver)
+
+		// Alerts of skipped rules are sent to the default contact point.
+		route := dispatch.NewRoute(result.AlertmanagerConfig.Route.AsAMRoute(), nil)
+		matched := route.Match(model.LabelSet{
+			models.AutogeneratedRouteLabel:             "true",
+			models.AutogeneratedRouteReceiverNameLabel: "unknown",
+		})
+		require.Len(t, matched, 1)
+		require.Equal(t, "default", matched[0].RouteOpts.Receiver)
+	})
+}
@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"sort"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/cluster"
+)
+
+// ClusterMembership provides the members of the HA cluster of the Alertmanager, that is the Grafana instances
+// configured with ha_peers. It can be used to shard work across the instances.
+type ClusterMembership struct {
+	peer alertingNotify.ClusterPeer
+}
+
+// ClusterMembership returns the membership of the HA cluster of this Alertmanager.
+func (moa *MultiOrgAlertmanager) ClusterMembership() *ClusterMembership {
+	return &ClusterMembership{peer: moa.peer}
+}
+
+// Self returns the name of this instance in the cluster, or an empty string if HA is not configured.
+func (m *ClusterMembership) Self() string {
+	p, ok := m.peer.(*cluster.Peer)
+	if !ok {
+		return ""
+	}
+	return p.Name()
+}
+
+// Replicas returns the names of the members of the cluster that are alive, including this instance, in order.
+// It returns nil if HA is not configured.
+func (m *ClusterMembership) Replicas() []string {
+	p, ok := m.peer.(*cluster.Peer)
+	if !ok {
+		return nil
+	}
+	peers := p.Peers()
+	names := make([]string, 0, len(peers))
+	for _, member := range peers {
+		names = append(names, member.Name())
+	}
+	sort.Strings(names)
+	return names
+}
@@ -102,6 +102,19 @@ grafana_alerting_discovered_configurations 4
 		require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
 		require.Len(t, mam.alertmanagers, 4)
 	}
+	// if the notification settings of the alert rules change, the autogenerated route should be applied on the next sync.
+	{
+		hash := mam.alertmanagers[1].Base.ConfigHash()
+		configStore.notificationSettings = map[int64]map[models.AlertRuleKey]models.NotificationSettings{
+			1: {{OrgID: 1, UID: "rule"}: {Receiver: "grafana-default-email", GroupBy: []string{"pod"}}},
+		}
+		require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+		require.NotEqual(t, hash, mam.alertmanagers[1].Base.ConfigHash())
+
+		configStore.notificationSettings = nil
+		require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+		require.Equal(t, hash, mam.alertmanagers[1].Base.ConfigHash())
+	}
 
 	// Orphaned state should be removed.
 	{
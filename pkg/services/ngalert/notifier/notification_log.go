@@ -0,0 +1,323 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+var (
+	// ErrNotificationIntegrationNotFound is returned when the integration that sent a notification no longer exists
+	// in the configuration of the Alertmanager.
+	ErrNotificationIntegrationNotFound = errors.New("the integration of the notification no longer exists")
+)
+
+const (
+	// notificationLogBufferSize is the number of entries of the notification log that are buffered before they are
+	// saved. Entries are dropped when the buffer is full, so that a slow database does not delay notifications.
+	notificationLogBufferSize = 1000
+	// notificationLogBatchSize is the maximum number of entries of the notification log that are saved at once.
+	notificationLogBatchSize = 100
+	// notificationLogSaveTimeout is the timeout of saving a batch of entries of the notification log.
+	notificationLogSaveTimeout = 10 * time.Second
+)
+
+// notificationLogAttemptsRetention is how long the attempts of a failed notification are counted. After this time the
+// notification is not retried anymore.
+const notificationLogAttemptsRetention = time.Hour
+
+// DeleteExpiredNotificationLogService is a service to delete entries of the notification log that are past their retention.
+type DeleteExpiredNotificationLogService struct {
+	store store.NotificationLogAdminStore
+}
+
+func (s *DeleteExpiredNotificationLogService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.store.DeleteExpiredNotificationLog(ctx)
+}
+
+func ProvideDeleteExpiredNotificationLogService(store *store.DBstore) *DeleteExpiredNotificationLogService {
+	return &DeleteExpiredNotificationLogService{store: store}
+}
+
+// notificationLog records the attempts of the integrations of an Alertmanager to send notifications.
+type notificationLog struct {
+	store  store.NotificationLogStore
+	orgID  int64
+	logger log.Logger
+
+	mtx sync.Mutex
+	// attempts counts the consecutive failed attempts of each integration to send the notification of an alert group.
+	attempts map[attemptKey]attempt
+
+	// entries buffers the entries until they are saved by run.
+	entries chan ngmodels.NotificationLogEntry
+	// dropped counts the entries dropped since the last save because the buffer was full.
+	dropped atomic.Int64
+	stopMtx sync.RWMutex
+	stopped bool
+	done    chan struct{}
+}
+
+type attemptKey struct {
+	receiver string
+	index    int
+	groupKey string
+}
+
+type attempt struct {
+	// flushedAt is the time of the flush of the alert group that the notification is sent for. The retries of a
+	// notification share the time of their flush.
+	flushedAt time.Time
+	n         int
+}
+
+// newNotificationLog returns a notification log that saves its entries in the background until it is stopped.
+func newNotificationLog(store store.NotificationLogStore, orgID int64, logger log.Logger) *notificationLog {
+	l := &notificationLog{
+		store:    store,
+		orgID:    orgID,
+		logger:   logger,
+		attempts: make(map[attemptKey]attempt),
+		entries:  make(chan ngmodels.NotificationLogEntry, notificationLogBufferSize),
+		done:     make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// stop stops recording entries, and waits until the buffered entries are saved.
+func (l *notificationLog) stop() {
+	l.stopMtx.Lock()
+	if !l.stopped {
+		l.stopped = true
+		close(l.entries)
+	}
+	l.stopMtx.Unlock()
+	<-l.done
+}
+
+// run saves the buffered entries in batches until the notification log is stopped.
+func (l *notificationLog) run() {
+	defer close(l.done)
+	batch := make([]ngmodels.NotificationLogEntry, 0, notificationLogBatchSize)
+	for entry := range l.entries {
+		batch = append(batch[:0], entry)
+	collect:
+		for len(batch) < notificationLogBatchSize {
+			select {
+			case e, ok := <-l.entries:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, e)
+			default:
+				break collect
+			}
+		}
+		l.save(batch)
+	}
+}
+
+func (l *notificationLog) save(entries []ngmodels.NotificationLogEntry) {
+	if dropped := l.dropped.Swap(0); dropped > 0 {
+		l.logger.Warn("Dropped notification log entries because the buffer was full", "count", dropped)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notificationLogSaveTimeout)
+	defer cancel()
+	if err := l.store.SaveNotificationLog(ctx, entries); err != nil {
+		l.logger.Error("Failed to save notification log entries", "count", len(entries), "error", err)
+	}
+}
+
+// enqueue buffers an entry to be saved, or drops it if the buffer is full or the notification log is stopped.
+func (l *notificationLog) enqueue(entry ngmodels.NotificationLogEntry) {
+	l.stopMtx.RLock()
+	defer l.stopMtx.RUnlock()
+	if l.stopped {
+		return
+	}
+	select {
+	case l.entries <- entry:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+// wrap returns an integration that records its attempts to send notifications in the notification log.
+func (l *notificationLog) wrap(receiver string, r *apimodels.PostableGrafanaReceiver, index int, n alertingNotify.NotificationChannel) alertingNotify.NotificationChannel {
+	return &loggingNotifier{
+		NotificationChannel: n,
+		log:                 l,
+		receiver:            receiver,
+		uid:                 r.UID,
+		integrationType:     r.Type,
+		index:               index,
+	}
+}
+
+// attempt returns the number of the attempt to send a notification. The count is reset when the notification is sent
+// successfully, or when the alert group is flushed again.
+func (l *notificationLog) attempt(key attemptKey, flushedAt time.Time, failed bool) int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	// Forget notifications that are not retried anymore.
+	for k, a := range l.attempts {
+		if time.Since(a.flushedAt) > notificationLogAttemptsRetention {
+			delete(l.attempts, k)
+		}
+	}
+
+	a, ok := l.attempts[key]
+	if !ok || !a.flushedAt.Equal(flushedAt) {
+		a = attempt{flushedAt: flushedAt}
+	}
+	a.n++
+	if failed {
+		l.attempts[key] = a
+	} else {
+		delete(l.attempts, key)
+	}
+	return a.n
+}
+
+func (l *notificationLog) record(ctx context.Context, n *loggingNotifier, alerts []*types.Alert, sentAt time.Time, duration time.Duration, statusCode int, notifyErr error) {
+	groupKey, _ := notify.GroupKey(ctx)
+	groupLabels, _ := notify.GroupLabels(ctx)
+	flushedAt, ok := notify.Now(ctx)
+	if !ok {
+		flushedAt = sentAt
+	}
+
+	entry := ngmodels.NotificationLogEntry{
+		OrgID:            l.orgID,
+		Receiver:         n.receiver,
+		IntegrationUID:   n.uid,
+		IntegrationType:  n.integrationType,
+		IntegrationIndex: n.index,
+		GroupKey:         groupKey,
+		Status:           ngmodels.NotificationStatusSuccess,
+		StatusCode:       statusCode,
+		Duration:         duration.Milliseconds(),
+		Attempt:          l.attempt(attemptKey{receiver: n.receiver, index: n.index, groupKey: groupKey}, flushedAt, notifyErr != nil),
+		SentAt:           sentAt.UnixNano(),
+	}
+	if notifyErr != nil {
+		entry.Status = ngmodels.NotificationStatusFailed
+		entry.Error = notifyErr.Error()
+	}
+
+	lbls, err := json.Marshal(groupLabels)
+	if err != nil {
+		l.logger.Error("Failed to serialize group labels of notification", "receiver", n.receiver, "error", err)
+		return
+	}
+	entry.GroupLabels = string(lbls)
+	alertsJSON, err := json.Marshal(alerts)
+	if err != nil {
+		l.logger.Error("Failed to serialize alerts of notification", "receiver", n.receiver, "error", err)
+		return
+	}
+	entry.Alerts = string(alertsJSON)
+	l.enqueue(entry)
+}
+
+// loggingNotifier is an integration that records its attempts to send notifications in the notification log.
+type loggingNotifier struct {
+	alertingNotify.NotificationChannel
+	log             *notificationLog
+	receiver        string
+	uid             string
+	integrationType string
+	index           int
+}
+
+func (n *loggingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	ctx, resp := withWebhookResponse(ctx)
+	start := time.Now()
+	retry, err := n.NotificationChannel.Notify(ctx, alerts...)
+	n.log.record(ctx, n, alerts, start, time.Since(start), resp.statusCode, err)
+	return retry, err
+}
+
+type webhookResponseKey struct{}
+
+// webhookResponse is the response to the last webhook an integration sent.
+type webhookResponse struct {
+	statusCode int
+}
+
+func withWebhookResponse(ctx context.Context) (context.Context, *webhookResponse) {
+	resp := &webhookResponse{}
+	return context.WithValue(ctx, webhookResponseKey{}, resp), resp
+}
+
+func webhookResponseFromContext(ctx context.Context) *webhookResponse {
+	resp, _ := ctx.Value(webhookResponseKey{}).(*webhookResponse)
+	return resp
+}
+
+type integrationKey struct {
+	receiver string
+	uid      string
+}
+
+// setIntegrations keeps the integrations of the applied configuration, so that notifications can be sent again.
+func (am *Alertmanager) setIntegrations(receivers []*apimodels.PostableApiReceiver, integrations map[string][]*alertingNotify.Integration) {
+	byUID := make(map[integrationKey]*alertingNotify.Integration)
+	for _, receiver := range receivers {
+		built := integrations[receiver.Name]
+		for i, r := range receiver.GrafanaManagedReceivers {
+			if i < len(built) {
+				byUID[integrationKey{receiver: receiver.Name, uid: r.UID}] = built[i]
+			}
+		}
+	}
+
+	am.integrationsMtx.Lock()
+	defer am.integrationsMtx.Unlock()
+	am.integrations = byUID
+}
+
+// ResendNotification sends the alerts of an entry of the notification log again, with the integration of the current
+// configuration that has the UID of the integration that sent them. The new attempt is recorded in the notification log.
+func (am *Alertmanager) ResendNotification(ctx context.Context, entry *ngmodels.NotificationLogEntry) error {
+	am.integrationsMtx.RLock()
+	integration, ok := am.integrations[integrationKey{receiver: entry.Receiver, uid: entry.IntegrationUID}]
+	am.integrationsMtx.RUnlock()
+	if !ok {
+		return ErrNotificationIntegrationNotFound
+	}
+
+	var alerts []*types.Alert
+	if err := json.Unmarshal([]byte(entry.Alerts), &alerts); err != nil {
+		return fmt.Errorf("failed to parse the alerts of the notification: %w", err)
+	}
+	groupLabels := model.LabelSet{}
+	if err := json.Unmarshal([]byte(entry.GroupLabels), &groupLabels); err != nil {
+		return fmt.Errorf("failed to parse the group labels of the notification: %w", err)
+	}
+
+	ctx = notify.WithGroupKey(ctx, entry.GroupKey)
+	ctx = notify.WithReceiverName(ctx, entry.Receiver)
+	ctx = notify.WithGroupLabels(ctx, groupLabels)
+	ctx = notify.WithNow(ctx, time.Now())
+	if _, err := integration.Notify(ctx, alerts...); err != nil {
+		return fmt.Errorf("failed to resend the notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNotificationLog(t *testing.T) {
+	store := NewFakeConfigStore(t, nil)
+	ns := &notifications.NotificationServiceMock{}
+	channel := &fakeWebhookChannel{sender: NewNotificationSender(ns)}
+	l := newNotificationLog(store, 1, log.NewNopLogger())
+	n := l.wrap("team", &apimodels.PostableGrafanaReceiver{UID: "uid", Type: "webhook"}, 2, channel)
+
+	flush := func(at time.Time) context.Context {
+		ctx := notify.WithGroupKey(context.Background(), `{}:{alertname="test"}`)
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "test"})
+		return notify.WithNow(ctx, at)
+	}
+	respond := func(statusCode int, err error) {
+		ns.WebhookHandler = func(_ context.Context, cmd *notifications.SendWebhookSync) error {
+			require.NoError(t, cmd.Validation(nil, statusCode))
+			return err
+		}
+	}
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}
+
+	ctx := flush(time.Now())
+	respond(503, errors.New("webhook response status 503"))
+	_, err := n.Notify(ctx, alert)
+	require.Error(t, err)
+	_, err = n.Notify(ctx, alert)
+	require.Error(t, err)
+	respond(200, nil)
+	_, err = n.Notify(ctx, alert)
+	require.NoError(t, err)
+	_, err = n.Notify(flush(time.Now()), alert)
+	require.NoError(t, err)
+	l.stop()
+
+	entries, err := store.GetNotificationLog(context.Background(), models.GetNotificationLogQuery{OrgID: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	// Entries are returned newest first.
+	failed, retried, next := entries[3], entries[1], entries[0]
+	require.Equal(t, "team", failed.Receiver)
+	require.Equal(t, "uid", failed.IntegrationUID)
+	require.Equal(t, "webhook", failed.IntegrationType)
+	require.Equal(t, 2, failed.IntegrationIndex)
+	require.Equal(t, `{}:{alertname="test"}`, failed.GroupKey)
+	require.JSONEq(t, `{"alertname":"test"}`, failed.GroupLabels)
+	require.Contains(t, failed.Alerts, `"alertname":"test"`)
+	require.Equal(t, models.NotificationStatusFailed, failed.Status)
+	require.Equal(t, "webhook response status 503", failed.Error)
+	require.Equal(t, 503, failed.StatusCode)
+	require.Equal(t, 1, failed.Attempt)
+	require.Equal(t, 2, entries[2].Attempt)
+
+	require.Equal(t, models.NotificationStatusSuccess, retried.Status)
+	require.Empty(t, retried.Error)
+	require.Equal(t, 200, retried.StatusCode)
+	require.Equal(t, 3, retried.Attempt)
+
+	require.Equal(t, 1, next.Attempt)
+}
+
+func TestNotificationLog_Buffer(t *testing.T) {
+	store := &blockingNotificationLogStore{fakeConfigStore: NewFakeConfigStore(t, nil), release: make(chan struct{})}
+	l := newNotificationLog(store, 1, log.NewNopLogger())
+	n := l.wrap("team", &apimodels.PostableGrafanaReceiver{UID: "uid", Type: "webhook"}, 0, &fakeWebhookChannel{
+		sender: NewNotificationSender(&notifications.NotificationServiceMock{}),
+	})
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}
+
+	// Notifications are not delayed while the entries cannot be saved, and the entries that do not fit in the buffer
+	// are dropped.
+	sent := notificationLogBufferSize + notificationLogBatchSize + 10
+	for i := 0; i < sent; i++ {
+		_, err := n.Notify(context.Background(), alert)
+		require.NoError(t, err)
+	}
+	close(store.release)
+	l.stop()
+
+	entries, err := store.GetNotificationLog(context.Background(), models.GetNotificationLogQuery{OrgID: 1})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(entries), notificationLogBufferSize)
+	require.Less(t, len(entries), sent)
+}
+
+// blockingNotificationLogStore is a notification log store that saves entries only after it is released.
+type blockingNotificationLogStore struct {
+	*fakeConfigStore
+	release chan struct{}
+}
+
+func (s *blockingNotificationLogStore) SaveNotificationLog(ctx context.Context, entries []models.NotificationLogEntry) error {
+	<-s.release
+	return s.fakeConfigStore.SaveNotificationLog(ctx, entries)
+}
+
+func TestAlertmanager_ResendNotification(t *testing.T) {
+	store := NewFakeConfigStore(t, nil)
+	ns := &notifications.NotificationServiceMock{}
+	cfg := &setting.Cfg{
+		DataPath: t.TempDir(),
+		UnifiedAlerting: setting.UnifiedAlertingSettings{
+			NotificationLog: setting.UnifiedAlertingNotificationLogSettings{Enabled: true},
+		},
+	}
+	decryptFn := func(_ context.Context, _ map[string][]byte, _ string, fallback string) string { return fallback }
+	m := metrics.NewAlertmanagerMetrics(prometheus.NewRegistry())
+	am, err := newAlertmanager(context.Background(), 1, cfg, store, NewFakeKVStore(t), &NilPeer{}, decryptFn, ns, m)
+	require.NoError(t, err)
+
+	amCfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {"receiver": "team"},
+			"receivers": [{
+				"name": "team",
+				"grafana_managed_receiver_configs": [{"uid": "uid", "name": "team", "type": "webhook", "settings": {"url": "http://localhost/hook"}}]
+			}]
+		}
+	}`))
+	require.NoError(t, err)
+	_, err = am.applyConfig(context.Background(), amCfg, nil)
+	require.NoError(t, err)
+
+	entry := &models.NotificationLogEntry{
+		OrgID:           1,
+		Receiver:        "team",
+		IntegrationUID:  "uid",
+		IntegrationType: "webhook",
+		GroupKey:        `{}:{alertname="test"}`,
+		GroupLabels:     `{"alertname":"test"}`,
+		Alerts:          `[{"labels":{"alertname":"test"},"annotations":{},"startsAt":"2023-03-01T10:00:00Z","endsAt":"0001-01-01T00:00:00Z"}]`,
+	}
+
+	t.Run("should send the alerts with the integration of the notification", func(t *testing.T) {
+		require.NoError(t, am.ResendNotification(context.Background(), entry))
+
+		require.Equal(t, "http://localhost/hook", ns.Webhook.Url)
+		require.Contains(t, ns.Webhook.Body, `"alertname":"test"`)
+		var entries []models.NotificationLogEntry
+		require.Eventually(t, func() bool {
+			entries, err = store.GetNotificationLog(context.Background(), models.GetNotificationLogQuery{OrgID: 1})
+			return err == nil && len(entries) == 1
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, models.NotificationStatusSuccess, entries[0].Status)
+		require.Equal(t, 1, entries[0].Attempt)
+	})
+
+	t.Run("should fail if the integration no longer exists", func(t *testing.T) {
+		removed := *entry
+		removed.IntegrationUID = "removed"
+
+		err := am.ResendNotification(context.Background(), &removed)
+
+		require.ErrorIs(t, err, ErrNotificationIntegrationNotFound)
+	})
+}
+
+func TestNotificationLog_StatusCode(t *testing.T) {
+	store := NewFakeConfigStore(t, nil)
+	ns := &notifications.NotificationServiceMock{
+		WebhookHandler: func(_ context.Context, cmd *notifications.SendWebhookSync) error {
+			if cmd.Validation != nil {
+				// Microsoft Teams responds with "1" if the request succeeded.
+				return cmd.Validation([]byte("1"), 202)
+			}
+			return nil
+		},
+	}
+	cfg := &setting.Cfg{
+		DataPath: t.TempDir(),
+		UnifiedAlerting: setting.UnifiedAlertingSettings{
+			NotificationLog: setting.UnifiedAlertingNotificationLogSettings{Enabled: true},
+		},
+	}
+	decryptFn := func(_ context.Context, _ map[string][]byte, _ string, fallback string) string { return fallback }
+	m := metrics.NewAlertmanagerMetrics(prometheus.NewRegistry())
+	am, err := newAlertmanager(context.Background(), 1, cfg, store, NewFakeKVStore(t), &NilPeer{}, decryptFn, ns, m)
+	require.NoError(t, err)
+
+	amCfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {"receiver": "team"},
+			"receivers": [{
+				"name": "team",
+				"grafana_managed_receiver_configs": [
+					{"uid": "webhook", "name": "team", "type": "webhook", "settings": {"url": "http://localhost/hook"}},
+					{"uid": "pagerduty", "name": "team", "type": "pagerduty", "settings": {"integrationKey": "key"}},
+					{"uid": "teams", "name": "team", "type": "teams", "settings": {"url": "http://localhost/teams"}},
+					{"uid": "email", "name": "team", "type": "email", "settings": {"addresses": "team@example.com"}}
+				]
+			}]
+		}
+	}`))
+	require.NoError(t, err)
+	_, err = am.applyConfig(context.Background(), amCfg, nil)
+	require.NoError(t, err)
+
+	// Integrations that send webhooks with the notification service record the status code of the response.
+	expected := map[string]int{"webhook": 202, "pagerduty": 202, "teams": 202, "email": 0}
+	for uid := range expected {
+		require.NoError(t, am.ResendNotification(context.Background(), &models.NotificationLogEntry{
+			OrgID:           1,
+			Receiver:        "team",
+			IntegrationUID:  uid,
+			IntegrationType: uid,
+			GroupKey:        `{}:{alertname="test"}`,
+			GroupLabels:     `{"alertname":"test"}`,
+			Alerts:          `[{"labels":{"alertname":"test"},"annotations":{},"startsAt":"2023-03-01T10:00:00Z","endsAt":"0001-01-01T00:00:00Z"}]`,
+		}))
+	}
+	var entries []models.NotificationLogEntry
+	require.Eventually(t, func() bool {
+		entries, err = store.GetNotificationLog(context.Background(), models.GetNotificationLogQuery{OrgID: 1})
+		return err == nil && len(entries) == len(expected)
+	}, time.Second, 10*time.Millisecond)
+	actual := make(map[string]int, len(entries))
+	for _, e := range entries {
+		require.Equal(t, models.NotificationStatusSuccess, e.Status, e.Error)
+		actual[e.IntegrationUID] = e.StatusCode
+	}
+	require.Equal(t, expected, actual)
+}
+
+// fakeWebhookChannel is an integration that sends a webhook for every notification.
+type fakeWebhookChannel struct {
+	sender receivers.NotificationSender
+}
+
+func (c *fakeWebhookChannel) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	err := c.sender.SendWebhook(ctx, &receivers.SendWebhookSettings{URL: "http://localhost/hook"})
+	return err != nil, err
+}
+
+func (c *fakeWebhookChannel) SendResolved() bool {
+	return true
+}
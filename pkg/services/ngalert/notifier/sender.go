@@ -13,6 +13,17 @@ type sender struct {
 }
 
 func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSettings) error {
+	validation := cmd.Validation
+	// Record the status code of the response for the notification log.
+	if resp := webhookResponseFromContext(ctx); resp != nil {
+		validation = func(body []byte, statusCode int) error {
+			resp.statusCode = statusCode
+			if cmd.Validation != nil {
+				return cmd.Validation(body, statusCode)
+			}
+			return nil
+		}
+	}
 	return s.ns.SendWebhookSync(ctx, &notifications.SendWebhookSync{
 		Url:         cmd.URL,
 		User:        cmd.User,
@@ -21,7 +32,7 @@ func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSetti
 		HttpMethod:  cmd.HTTPMethod,
 		HttpHeader:  cmd.HTTPHeader,
 		ContentType: cmd.ContentType,
-		Validation:  cmd.Validation,
+		Validation:  validation,
 	})
 }
 
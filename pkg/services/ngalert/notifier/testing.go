@@ -19,6 +19,12 @@ type fakeConfigStore struct {
 
 	// appliedConfigs stores configs by orgID and config hash.
 	appliedConfigs map[int64]map[string]*models.AlertConfiguration
+
+	// notificationSettings stores the notification settings of alert rules by orgID.
+	notificationSettings map[int64]map[models.AlertRuleKey]models.NotificationSettings
+
+	notificationLogMtx sync.Mutex
+	notificationLog    []models.NotificationLogEntry
 }
 
 // Saves the image or returns an error.
@@ -34,6 +40,23 @@ func (f *fakeConfigStore) GetImages(ctx context.Context, tokens []string) ([]mod
 	return nil, nil, models.ErrImageNotFound
 }
 
+func (f *fakeConfigStore) ListNotificationSettings(_ context.Context, orgID int64) (map[models.AlertRuleKey]models.NotificationSettings, error) {
+	return f.notificationSettings[orgID], nil
+}
+
+func (f *fakeConfigStore) RenameReceiverInNotificationSettings(_ context.Context, orgID int64, oldReceiver, newReceiver string) (int, error) {
+	n := 0
+	for key, s := range f.notificationSettings[orgID] {
+		if s.Receiver != oldReceiver {
+			continue
+		}
+		s.Receiver = newReceiver
+		f.notificationSettings[orgID][key] = s
+		n++
+	}
+	return n, nil
+}
+
 func NewFakeConfigStore(t *testing.T, configs map[int64]*models.AlertConfiguration) *fakeConfigStore {
 	t.Helper()
 
@@ -43,6 +66,39 @@ func NewFakeConfigStore(t *testing.T, configs map[int64]*models.AlertConfigurati
 	}
 }
 
+func (f *fakeConfigStore) SaveNotificationLog(_ context.Context, entries []models.NotificationLogEntry) error {
+	f.notificationLogMtx.Lock()
+	defer f.notificationLogMtx.Unlock()
+	for _, entry := range entries {
+		entry.ID = int64(len(f.notificationLog) + 1)
+		f.notificationLog = append(f.notificationLog, entry)
+	}
+	return nil
+}
+
+func (f *fakeConfigStore) GetNotificationLog(_ context.Context, query models.GetNotificationLogQuery) ([]models.NotificationLogEntry, error) {
+	f.notificationLogMtx.Lock()
+	defer f.notificationLogMtx.Unlock()
+	var result []models.NotificationLogEntry
+	for i := len(f.notificationLog) - 1; i >= 0; i-- {
+		if e := f.notificationLog[i]; e.OrgID == query.OrgID {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeConfigStore) GetNotificationLogEntry(_ context.Context, orgID, id int64) (*models.NotificationLogEntry, error) {
+	f.notificationLogMtx.Lock()
+	defer f.notificationLogMtx.Unlock()
+	for _, e := range f.notificationLog {
+		if e.OrgID == orgID && e.ID == id {
+			return &e, nil
+		}
+	}
+	return nil, models.ErrNotificationLogEntryNotFound
+}
+
 func (f *fakeConfigStore) GetAllLatestAlertmanagerConfiguration(context.Context) ([]*models.AlertConfiguration, error) {
 	result := make([]*models.AlertConfiguration, 0, len(f.configs))
 	for _, configuration := range f.configs {
@@ -20,6 +20,7 @@ type AlertRuleService struct {
 	defaultIntervalSeconds int64
 	baseIntervalSeconds    int64
 	ruleStore              RuleStore
+	amConfigStore          AMConfigReader
 	provenanceStore        ProvisioningStore
 	dashboardService       dashboards.DashboardService
 	quotas                 QuotaChecker
@@ -28,6 +29,7 @@ type AlertRuleService struct {
 }
 
 func NewAlertRuleService(ruleStore RuleStore,
+	amConfigStore AMConfigReader,
 	provenanceStore ProvisioningStore,
 	dashboardService dashboards.DashboardService,
 	quotas QuotaChecker,
@@ -39,6 +41,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 		defaultIntervalSeconds: defaultIntervalSeconds,
 		baseIntervalSeconds:    baseIntervalSeconds,
 		ruleStore:              ruleStore,
+		amConfigStore:          amConfigStore,
 		provenanceStore:        provenanceStore,
 		dashboardService:       dashboardService,
 		quotas:                 quotas,
@@ -114,6 +117,9 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule model
 	if rule.UID == "" {
 		rule.UID = util.GenerateShortUID()
 	}
+	if err := ValidateNotificationSettings(ctx, service.amConfigStore, rule.OrgID, rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	interval, err := service.ruleStore.GetRuleGroupInterval(ctx, rule.OrgID, rule.NamespaceUID, rule.RuleGroup)
 	// if the alert group does not exists we just use the default interval
 	if err != nil && errors.Is(err, store.ErrAlertRuleGroupNotFound) {
@@ -214,6 +220,10 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 		return err
 	}
 
+	if err := ValidateNotificationSettings(ctx, service.amConfigStore, orgID, group.Rules...); err != nil {
+		return err
+	}
+
 	// If the provided request did not provide the rules list at all, treat it as though it does not wish to change rules.
 	// This is done for backwards compatibility. Requests which specify only the interval must update only the interval.
 	if group.Rules == nil {
@@ -326,6 +336,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
 		return models.AlertRule{}, fmt.Errorf("cannot changed provenance from '%s' to '%s'", storedProvenance, provenance)
 	}
+	if err := ValidateNotificationSettings(ctx, service.amConfigStore, rule.OrgID, rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	rule.Updated = time.Now()
 	rule.ID = storedRule.ID
 	rule.IntervalSeconds = storedRule.IntervalSeconds
@@ -332,6 +332,36 @@ func TestAlertRuleService(t *testing.T) {
 
 		require.ErrorIs(t, err, models.ErrQuotaReached)
 	})
+
+	t.Run("alert rules with notification settings of an existing contact point can be created", func(t *testing.T) {
+		rule := dummyRule("test#notification-settings", 1)
+		rule.NotificationSettings = &models.NotificationSettings{Receiver: "a new receiver"}
+
+		_, err := ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceAPI, 0)
+
+		require.NoError(t, err)
+	})
+
+	t.Run("alert rules with notification settings of a missing contact point are rejected", func(t *testing.T) {
+		rule := dummyRule("test#missing-receiver", 1)
+		rule.NotificationSettings = &models.NotificationSettings{Receiver: "missing"}
+
+		_, err := ruleService.CreateAlertRule(context.Background(), rule, models.ProvenanceAPI, 0)
+
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rule groups with notification settings of a missing mute timing are rejected", func(t *testing.T) {
+		group := createDummyGroup("missing-mute-timing", 1)
+		group.Rules[0].NotificationSettings = &models.NotificationSettings{
+			Receiver:          "a new receiver",
+			MuteTimeIntervals: []string{"missing"},
+		}
+
+		err := ruleService.ReplaceRuleGroup(context.Background(), 1, group, 0, models.ProvenanceAPI)
+
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
 }
 
 func createAlertRuleService(t *testing.T) AlertRuleService {
@@ -348,6 +378,7 @@ func createAlertRuleService(t *testing.T) AlertRuleService {
 	quotas.EXPECT().LimitOK()
 	return AlertRuleService{
 		ruleStore:              store,
+		amConfigStore:          newFakeAMConfigStore(),
 		provenanceStore:        store,
 		quotas:                 &quotas,
 		xact:                   sqlStore,
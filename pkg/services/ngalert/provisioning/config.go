@@ -27,7 +27,12 @@ type cfgRevision struct {
 	version          string
 }
 
-func getLastConfiguration(ctx context.Context, orgID int64, store AMConfigStore) (*cfgRevision, error) {
+// AMConfigReader reads the latest Alertmanager configuration of an organization.
+type AMConfigReader interface {
+	GetLatestAlertmanagerConfiguration(ctx context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) error
+}
+
+func getLastConfiguration(ctx context.Context, orgID int64, store AMConfigReader) (*cfgRevision, error) {
 	q := models.GetLatestAlertmanagerConfigurationQuery{
 		OrgID: orgID,
 	}
@@ -51,3 +56,42 @@ func getLastConfiguration(ctx context.Context, orgID int64, store AMConfigStore)
 		version:          q.Result.ConfigurationVersion,
 	}, nil
 }
+
+// ValidateNotificationSettings returns an error that wraps models.ErrAlertRuleFailedValidation if the notification
+// settings of one of the rules refer to a contact point or a mute timing that does not exist in the latest
+// Alertmanager configuration of the organization. The configuration is only read if a rule has notification settings.
+func ValidateNotificationSettings(ctx context.Context, store AMConfigReader, orgID int64, rules ...models.AlertRule) error {
+	var validator *models.NotificationSettingsValidator
+	for _, rule := range rules {
+		if rule.NotificationSettings == nil {
+			continue
+		}
+		if validator == nil {
+			rev, err := getLastConfiguration(ctx, orgID, store)
+			if err != nil {
+				return err
+			}
+			v := rev.cfg.NotificationSettingsValidator()
+			validator = &v
+		}
+		if err := validator.Validate(*rule.NotificationSettings); err != nil {
+			return fmt.Errorf("invalid alert rule '%s': %w", rule.Title, err)
+		}
+	}
+	return nil
+}
+
+// countNotificationSettings returns the number of alert rules of the organization whose notification settings match.
+func countNotificationSettings(ctx context.Context, store NotificationSettingsStore, orgID int64, match func(models.NotificationSettings) bool) (int, error) {
+	settings, err := store.ListNotificationSettings(ctx, orgID)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, s := range settings {
+		if match(s) {
+			n++
+		}
+	}
+	return n, nil
+}
@@ -18,21 +18,23 @@ import (
 )
 
 type ContactPointService struct {
-	amStore           AMConfigStore
-	encryptionService secrets.Service
-	provenanceStore   ProvisioningStore
-	xact              TransactionManager
-	log               log.Logger
+	amStore              AMConfigStore
+	notificationSettings NotificationSettingsStore
+	encryptionService    secrets.Service
+	provenanceStore      ProvisioningStore
+	xact                 TransactionManager
+	log                  log.Logger
 }
 
-func NewContactPointService(store AMConfigStore, encryptionService secrets.Service,
+func NewContactPointService(store AMConfigStore, notificationSettings NotificationSettingsStore, encryptionService secrets.Service,
 	provenanceStore ProvisioningStore, xact TransactionManager, log log.Logger) *ContactPointService {
 	return &ContactPointService{
-		amStore:           store,
-		encryptionService: encryptionService,
-		provenanceStore:   provenanceStore,
-		xact:              xact,
-		log:               log,
+		amStore:              store,
+		notificationSettings: notificationSettings,
+		encryptionService:    encryptionService,
+		provenanceStore:      provenanceStore,
+		xact:                 xact,
+		log:                  log,
 	}
 }
 
@@ -40,6 +42,8 @@ type ContactPointQuery struct {
 	// Optionally filter by name.
 	Name  string
 	OrgID int64
+	// Optionally decrypt the secure settings instead of redacting them.
+	Decrypt bool
 }
 
 func (ecp *ContactPointService) GetContactPoints(ctx context.Context, q ContactPointQuery) ([]apimodels.EmbeddedContactPoint, error) {
@@ -80,6 +84,10 @@ func (ecp *ContactPointService) GetContactPoints(ctx context.Context, q ContactP
 			if decryptedValue == "" {
 				continue
 			}
+			if q.Decrypt {
+				embeddedContactPoint.Settings.Set(k, decryptedValue)
+				continue
+			}
 			embeddedContactPoint.Settings.Set(k, apimodels.RedactedValue)
 		}
 
@@ -134,6 +142,9 @@ func (ecp *ContactPointService) CreateContactPoint(ctx context.Context, orgID in
 	if err := contactPoint.Valid(ecp.encryptionService.GetDecryptedValue); err != nil {
 		return apimodels.EmbeddedContactPoint{}, fmt.Errorf("%w: %s", ErrValidation, err.Error())
 	}
+	if err := checkNotRedacted(contactPoint); err != nil {
+		return apimodels.EmbeddedContactPoint{}, err
+	}
 
 	revision, err := getLastConfiguration(ctx, orgID, ecp.amStore)
 	if err != nil {
@@ -295,10 +306,13 @@ func (ecp *ContactPointService) UpdateContactPoint(ctx context.Context, orgID in
 		return err
 	}
 
+	oldName := receiverGroupName(revision.cfg, mergedReceiver.UID)
 	configModified := stitchReceiver(revision.cfg, mergedReceiver)
 	if !configModified {
 		return fmt.Errorf("contact point with uid '%s' not found", mergedReceiver.UID)
 	}
+	// The contact point is renamed if the old name is gone, so the alert rules that use it must be renamed as well.
+	renamed := oldName != mergedReceiver.Name && !receiverExists(revision.cfg, oldName)
 
 	data, err := json.Marshal(revision.cfg)
 	if err != nil {
@@ -315,6 +329,15 @@ func (ecp *ContactPointService) UpdateContactPoint(ctx context.Context, orgID in
 		if err != nil {
 			return err
 		}
+		if renamed {
+			n, err := ecp.notificationSettings.RenameReceiverInNotificationSettings(ctx, orgID, oldName, mergedReceiver.Name)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				ecp.log.Info("Renamed the contact point of alert rules", "old_name", oldName, "new_name", mergedReceiver.Name, "rules", n)
+			}
+		}
 		err = ecp.provenanceStore.SetProvenance(ctx, &contactPoint, orgID, provenance)
 		if err != nil {
 			return err
@@ -353,6 +376,17 @@ func (ecp *ContactPointService) DeleteContactPoint(ctx context.Context, orgID in
 	if fullRemoval && isContactPointInUse(name, []*apimodels.Route{revision.cfg.AlertmanagerConfig.Route}) {
 		return fmt.Errorf("contact point '%s' is currently used by a notification policy", name)
 	}
+	if fullRemoval {
+		n, err := countNotificationSettings(ctx, ecp.notificationSettings, orgID, func(s models.NotificationSettings) bool {
+			return s.Receiver == name
+		})
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return fmt.Errorf("%w: contact point '%s' is currently used by %d alert rules", ErrValidation, name, n)
+		}
+	}
 	data, err := json.Marshal(revision.cfg)
 	if err != nil {
 		return err
@@ -375,6 +409,27 @@ func (ecp *ContactPointService) DeleteContactPoint(ctx context.Context, orgID in
 	})
 }
 
+// receiverGroupName returns the name of the contact point that has the integration with the given UID.
+func receiverGroupName(cfg *apimodels.PostableUserConfig, uid string) string {
+	for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+		for _, grafanaReceiver := range receiver.GrafanaManagedReceivers {
+			if grafanaReceiver.UID == uid {
+				return receiver.Name
+			}
+		}
+	}
+	return ""
+}
+
+func receiverExists(cfg *apimodels.PostableUserConfig, name string) bool {
+	for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+		if receiver.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func isContactPointInUse(name string, routes []*apimodels.Route) bool {
 	if len(routes) == 0 {
 		return false
@@ -494,3 +549,19 @@ func replaceReferences(oldName, newName string, routes ...*apimodels.Route) {
 		replaceReferences(oldName, newName, route.Routes...)
 	}
 }
+
+// checkNotRedacted returns a validation error if a secure setting of the contact point has the redacted value.
+// Exports contain this value unless they are decrypted, and importing it would replace the secret with the
+// placeholder.
+func checkNotRedacted(contactPoint apimodels.EmbeddedContactPoint) error {
+	secretKeys, err := contactPoint.SecretKeys()
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	for _, secretKey := range secretKeys {
+		if contactPoint.Settings.Get(secretKey).MustString() == apimodels.RedactedValue {
+			return fmt.Errorf("%w: setting %s has the redacted value, provide the secret instead", ErrValidation, secretKey)
+		}
+	}
+	return nil
+}
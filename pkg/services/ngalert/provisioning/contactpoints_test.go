@@ -61,6 +61,41 @@ func TestContactPointService(t *testing.T) {
 		require.Equal(t, "slack", cps[1].Type)
 	})
 
+	t.Run("service redacts secure settings by default", func(t *testing.T) {
+		sut := createContactPointServiceSut(secretsService)
+		newCp := createTestContactPoint()
+		_, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		q := ContactPointQuery{
+			OrgID: 1,
+			Name:  "test-contact-point",
+		}
+		cps, err := sut.GetContactPoints(context.Background(), q)
+		require.NoError(t, err)
+
+		require.Len(t, cps, 1)
+		require.Equal(t, definitions.RedactedValue, cps[0].Settings.Get("token").MustString())
+	})
+
+	t.Run("service decrypts secure settings when requested", func(t *testing.T) {
+		sut := createContactPointServiceSut(secretsService)
+		newCp := createTestContactPoint()
+		_, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		q := ContactPointQuery{
+			OrgID:   1,
+			Name:    "test-contact-point",
+			Decrypt: true,
+		}
+		cps, err := sut.GetContactPoints(context.Background(), q)
+		require.NoError(t, err)
+
+		require.Len(t, cps, 1)
+		require.Equal(t, "value_token", cps[0].Settings.Get("token").MustString())
+	})
+
 	t.Run("it's possible to use a custom uid", func(t *testing.T) {
 		customUID := "1337"
 		sut := createContactPointServiceSut(secretsService)
@@ -99,6 +134,32 @@ func TestContactPointService(t *testing.T) {
 		require.ErrorIs(t, err, ErrValidation)
 	})
 
+	t.Run("create rejects contact points with redacted secure settings", func(t *testing.T) {
+		sut := createContactPointServiceSut(secretsService)
+		newCp := createTestContactPoint()
+		newCp.Settings.Set("token", definitions.RedactedValue)
+
+		_, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("update keeps the stored secure settings that are redacted", func(t *testing.T) {
+		sut := createContactPointServiceSut(secretsService)
+		newCp := createTestContactPoint()
+		newCp, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+		newCp.Settings.Set("recipient", "other_recipient")
+
+		err = sut.UpdateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		cps, err := sut.GetContactPoints(context.Background(), ContactPointQuery{OrgID: 1, Name: newCp.Name, Decrypt: true})
+		require.NoError(t, err)
+		require.Len(t, cps, 1)
+		require.Equal(t, "value_token", cps[0].Settings.Get("token").MustString())
+	})
+
 	t.Run("update rejects contact points with no settings", func(t *testing.T) {
 		sut := createContactPointServiceSut(secretsService)
 		newCp := createTestContactPoint()
@@ -135,6 +196,37 @@ func TestContactPointService(t *testing.T) {
 		require.ErrorIs(t, err, ErrValidation)
 	})
 
+	t.Run("update renames the contact point of the alert rules when it is renamed", func(t *testing.T) {
+		sut := createContactPointServiceSut(secretsService)
+		newCp := createTestContactPoint()
+		newCp, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+		settings := sut.notificationSettings.(*fakeNotificationSettingsStore).settings
+		settings[1] = map[models.AlertRuleKey]models.NotificationSettings{
+			{OrgID: 1, UID: "rule"}: {Receiver: newCp.Name},
+		}
+		newCp.Name = "renamed-contact-point"
+
+		err = sut.UpdateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		require.Equal(t, "renamed-contact-point", settings[1][models.AlertRuleKey{OrgID: 1, UID: "rule"}].Receiver)
+	})
+
+	t.Run("delete rejects contact points that alert rules use", func(t *testing.T) {
+		sut := createContactPointServiceSut(secretsService)
+		newCp := createTestContactPoint()
+		newCp, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+		sut.notificationSettings.(*fakeNotificationSettingsStore).settings[1] = map[models.AlertRuleKey]models.NotificationSettings{
+			{OrgID: 1, UID: "rule"}: {Receiver: newCp.Name},
+		}
+
+		err = sut.DeleteContactPoint(context.Background(), 1, newCp.UID)
+
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
 	t.Run("default provenance of contact points is none", func(t *testing.T) {
 		sut := createContactPointServiceSut(secretsService)
 
@@ -270,11 +362,12 @@ func TestContactPointInUse(t *testing.T) {
 
 func createContactPointServiceSut(secretService secrets.Service) *ContactPointService {
 	return &ContactPointService{
-		amStore:           newFakeAMConfigStore(),
-		provenanceStore:   NewFakeProvisioningStore(),
-		xact:              newNopTransactionManager(),
-		encryptionService: secretService,
-		log:               log.NewNopLogger(),
+		amStore:              newFakeAMConfigStore(),
+		notificationSettings: newFakeNotificationSettingsStore(),
+		provenanceStore:      NewFakeProvisioningStore(),
+		xact:                 newNopTransactionManager(),
+		encryptionService:    secretService,
+		log:                  log.NewNopLogger(),
 	}
 }
 
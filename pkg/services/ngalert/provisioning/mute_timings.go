@@ -12,18 +12,20 @@ import (
 )
 
 type MuteTimingService struct {
-	config AMConfigStore
-	prov   ProvisioningStore
-	xact   TransactionManager
-	log    log.Logger
+	config               AMConfigStore
+	notificationSettings NotificationSettingsStore
+	prov                 ProvisioningStore
+	xact                 TransactionManager
+	log                  log.Logger
 }
 
-func NewMuteTimingService(config AMConfigStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *MuteTimingService {
+func NewMuteTimingService(config AMConfigStore, notificationSettings NotificationSettingsStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *MuteTimingService {
 	return &MuteTimingService{
-		config: config,
-		prov:   prov,
-		xact:   xact,
-		log:    log,
+		config:               config,
+		notificationSettings: notificationSettings,
+		prov:                 prov,
+		xact:                 xact,
+		log:                  log,
 	}
 }
 
@@ -163,6 +165,20 @@ func (svc *MuteTimingService) DeleteMuteTiming(ctx context.Context, name string,
 	if isMuteTimeInUse(name, []*definitions.Route{revision.cfg.AlertmanagerConfig.Route}) {
 		return fmt.Errorf("mute time '%s' is currently used by a notification policy", name)
 	}
+	n, err := countNotificationSettings(ctx, svc.notificationSettings, orgID, func(s models.NotificationSettings) bool {
+		for _, mt := range s.MuteTimeIntervals {
+			if mt == name {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%w: mute time '%s' is currently used by %d alert rules", ErrValidation, name, n)
+	}
 	for i, existing := range revision.cfg.AlertmanagerConfig.MuteTimeIntervals {
 		if name == existing.Name {
 			intervals := revision.cfg.AlertmanagerConfig.MuteTimeIntervals
@@ -358,6 +358,21 @@ func TestMuteTimingService(t *testing.T) {
 				require.ErrorContains(t, err, "failed to save config")
 			})
 
+			t.Run("when mute timing is used by alert rules", func(t *testing.T) {
+				sut := createMuteTimingSvcSut()
+				sut.config.(*MockAMConfigStore).EXPECT().
+					GetsConfig(models.AlertConfiguration{
+						AlertmanagerConfiguration: configWithMuteTimings,
+					})
+				sut.notificationSettings.(*fakeNotificationSettingsStore).settings[1] = map[models.AlertRuleKey]models.NotificationSettings{
+					{OrgID: 1, UID: "rule"}: {Receiver: "grafana-default-email", MuteTimeIntervals: []string{"asdf"}},
+				}
+
+				err := sut.DeleteMuteTiming(context.Background(), "asdf", 1)
+
+				require.ErrorIs(t, err, ErrValidation)
+			})
+
 			t.Run("when mute timing is used in route", func(t *testing.T) {
 				sut := createMuteTimingSvcSut()
 				sut.config.(*MockAMConfigStore).EXPECT().
@@ -375,10 +390,11 @@ func TestMuteTimingService(t *testing.T) {
 
 func createMuteTimingSvcSut() *MuteTimingService {
 	return &MuteTimingService{
-		config: &MockAMConfigStore{},
-		prov:   &MockProvisioningStore{},
-		xact:   newNopTransactionManager(),
-		log:    log.NewNopLogger(),
+		config:               &MockAMConfigStore{},
+		notificationSettings: newFakeNotificationSettingsStore(),
+		prov:                 &MockProvisioningStore{},
+		xact:                 newNopTransactionManager(),
+		log:                  log.NewNopLogger(),
 	}
 }
 
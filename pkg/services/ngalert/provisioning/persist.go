@@ -44,6 +44,12 @@ type RuleStore interface {
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *models.GetAlertRulesGroupByRuleUIDQuery) error
 }
 
+// NotificationSettingsStore lists the notification settings of alert rules and renames the contact points they refer to.
+type NotificationSettingsStore interface {
+	ListNotificationSettings(ctx context.Context, orgID int64) (map[models.AlertRuleKey]models.NotificationSettings, error)
+	RenameReceiverInNotificationSettings(ctx context.Context, orgID int64, oldReceiver, newReceiver string) (int, error)
+}
+
 // QuotaChecker represents the ability to evaluate whether quotas are met.
 //
 //go:generate mockery --name QuotaChecker --structname MockQuotaChecker --inpackage --filename quota_checker_mock.go --with-expecter
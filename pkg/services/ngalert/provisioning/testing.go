@@ -137,6 +137,33 @@ func (f *fakeProvisioningStore) DeleteProvenance(ctx context.Context, o models.P
 	return nil
 }
 
+type fakeNotificationSettingsStore struct {
+	settings map[int64]map[models.AlertRuleKey]models.NotificationSettings
+}
+
+func newFakeNotificationSettingsStore() *fakeNotificationSettingsStore {
+	return &fakeNotificationSettingsStore{
+		settings: map[int64]map[models.AlertRuleKey]models.NotificationSettings{},
+	}
+}
+
+func (f *fakeNotificationSettingsStore) ListNotificationSettings(_ context.Context, orgID int64) (map[models.AlertRuleKey]models.NotificationSettings, error) {
+	return f.settings[orgID], nil
+}
+
+func (f *fakeNotificationSettingsStore) RenameReceiverInNotificationSettings(_ context.Context, orgID int64, oldReceiver, newReceiver string) (int, error) {
+	n := 0
+	for key, s := range f.settings[orgID] {
+		if s.Receiver != oldReceiver {
+			continue
+		}
+		s.Receiver = newReceiver
+		f.settings[orgID][key] = s
+		n++
+	}
+	return n, nil
+}
+
 type NopTransactionManager struct{}
 
 func newNopTransactionManager() *NopTransactionManager {
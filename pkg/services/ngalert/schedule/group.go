@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// batchReadyToRun returns the items in batches that are dispatched together. The rules of a group that is evaluated
+// sequentially are in one batch, in the order of evaluation. Every other rule is in a batch of its own.
+// A group is evaluated sequentially if sequential evaluation of rule groups is enabled, or if a rule of the group
+// references another rule.
+func (sch *schedule) batchReadyToRun(items []readyToRunItem) [][]readyToRunItem {
+	groups := make(map[ngmodels.AlertRuleGroupKey][]readyToRunItem)
+	order := make([]ngmodels.AlertRuleGroupKey, 0)
+	for _, item := range items {
+		key := item.rule.GetGroupKey()
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+
+	batches := make([][]readyToRunItem, 0, len(items))
+	for _, key := range order {
+		group := groups[key]
+		rules := make(ngmodels.RulesGroup, 0, len(group))
+		for _, item := range group {
+			rules = append(rules, item.rule)
+		}
+		if len(group) == 1 || (!sch.sequentialRuleGroups && !rules.HasRuleReferences()) {
+			for _, item := range group {
+				batches = append(batches, []readyToRunItem{item})
+			}
+			continue
+		}
+		if err := rules.SortByDependencies(); err != nil {
+			// this is expected to never happen given that we validate references during alert rule updates
+			sch.log.Warn("Rules of the group will be evaluated in the order of the group because of invalid rule references", "group", key.String(), "error", err)
+			rules.SortByGroupIndex()
+		}
+		byUID := make(map[string]readyToRunItem, len(group))
+		for _, item := range group {
+			byUID[item.rule.UID] = item
+		}
+		batch := make([]readyToRunItem, 0, len(group))
+		for _, rule := range rules {
+			batch = append(batch, byUID[rule.UID])
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// dispatch sends the evaluation to the routine of the rule. It returns false if the routine was stopped.
+func (sch *schedule) dispatch(item readyToRunItem, tick time.Time) bool {
+	key := item.rule.GetKey()
+	success, dropped := item.ruleInfo.eval(&item.evaluation)
+	if !success {
+		sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
+		return false
+	}
+	if dropped != nil {
+		sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
+		orgID := fmt.Sprint(key.OrgID)
+		sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
+		dropped.done()
+	}
+	return true
+}
+
+// dispatchSequentially sends the evaluations to the routines of the rules one after the other, and waits for each
+// evaluation to complete before sending the next one, so that a rule can use the results of the rules before it.
+func (sch *schedule) dispatchSequentially(batch []readyToRunItem, tick time.Time) {
+	for i, item := range batch {
+		if i > 0 {
+			// the evaluation is planned for when the evaluation of the previous rule completes.
+			item.plannedAt = sch.clock.Now()
+		}
+		finished := make(chan struct{})
+		var once sync.Once
+		item.afterEval = func() {
+			once.Do(func() { close(finished) })
+		}
+		if !sch.dispatch(item, tick) {
+			continue
+		}
+		select {
+		case <-finished:
+		case <-item.ruleInfo.ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestBatchReadyToRun(t *testing.T) {
+	gen := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Second))
+	groupKey := models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "ns", RuleGroup: "group"}
+	rule := func(uid string, groupIndex int, references ...string) *models.AlertRule {
+		r := gen()
+		r.UID = uid
+		r.NamespaceUID = groupKey.NamespaceUID
+		r.RuleGroup = groupKey.RuleGroup
+		r.RuleGroupIndex = groupIndex
+		for i, ref := range references {
+			r.Data = append(r.Data, models.AlertQuery{
+				RefID:         fmt.Sprintf("ref%d", i),
+				DatasourceUID: models.RuleReferenceDatasourceUID,
+				Model:         json.RawMessage(fmt.Sprintf(`{"ruleUid": %q}`, ref)),
+			})
+		}
+		return r
+	}
+	items := func(rules ...*models.AlertRule) []readyToRunItem {
+		result := make([]readyToRunItem, 0, len(rules))
+		for _, r := range rules {
+			result = append(result, readyToRunItem{evaluation: evaluation{rule: r}})
+		}
+		return result
+	}
+	uids := func(batches [][]readyToRunItem) [][]string {
+		result := make([][]string, 0, len(batches))
+		for _, batch := range batches {
+			b := make([]string, 0, len(batch))
+			for _, item := range batch {
+				b = append(b, item.rule.UID)
+			}
+			result = append(result, b)
+		}
+		return result
+	}
+
+	t.Run("should dispatch each rule independently if the group has no references", func(t *testing.T) {
+		sch := &schedule{log: log.NewNopLogger()}
+		batches := sch.batchReadyToRun(items(rule("b", 2), rule("a", 1), gen()))
+		require.Len(t, batches, 3)
+		for _, batch := range batches {
+			require.Len(t, batch, 1)
+		}
+	})
+
+	t.Run("should dispatch the group in order of group index if sequential evaluation is enabled", func(t *testing.T) {
+		sch := &schedule{log: log.NewNopLogger(), sequentialRuleGroups: true}
+		other := gen()
+		batches := sch.batchReadyToRun(items(rule("b", 2), other, rule("a", 1)))
+		require.Equal(t, [][]string{{"a", "b"}, {other.UID}}, uids(batches))
+	})
+
+	t.Run("should dispatch the group in order of references", func(t *testing.T) {
+		sch := &schedule{log: log.NewNopLogger()}
+		batches := sch.batchReadyToRun(items(rule("a", 1, "c"), rule("b", 2), rule("c", 3)))
+		require.Equal(t, [][]string{{"c", "a", "b"}}, uids(batches))
+	})
+
+	t.Run("should fall back to group index if references are invalid", func(t *testing.T) {
+		sch := &schedule{log: log.NewNopLogger()}
+		batches := sch.batchReadyToRun(items(rule("b", 2, "a"), rule("a", 1, "b")))
+		require.Equal(t, [][]string{{"a", "b"}}, uids(batches))
+	})
+}
+
+func TestDispatchSequentially(t *testing.T) {
+	sch := &schedule{log: log.NewNopLogger(), clock: clock.New()}
+	gen := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Second))
+
+	var mtx sync.Mutex
+	var events []string
+	record := func(event string) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		events = append(events, event)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	batch := make([]readyToRunItem, 0, 3)
+	for i := 0; i < 3; i++ {
+		info := newAlertRuleInfo(ctx)
+		r := gen()
+		r.UID = fmt.Sprintf("rule-%d", i)
+		batch = append(batch, readyToRunItem{ruleInfo: info, evaluation: evaluation{rule: r}})
+		go func() {
+			for {
+				select {
+				case e := <-info.evalCh:
+					record("start " + e.rule.UID)
+					// give the scheduler a chance to dispatch the next rule too early
+					time.Sleep(10 * time.Millisecond)
+					record("end " + e.rule.UID)
+					e.done()
+				case <-info.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	// a stopped routine must not block the other rules
+	batch[1].ruleInfo.stop(errRuleDeleted)
+
+	sch.dispatchSequentially(batch, time.Now())
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	require.Equal(t, []string{"start rule-0", "end rule-0", "start rule-2", "end rule-2"}, events)
+}
+
+func TestRuleReferenceFrame(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	referenced := models.AlertRuleGen(models.WithOrgID(1))()
+	referenced.Title = "errors"
+	referenced.Condition = "C"
+	referenced.Labels = map[string]string{"severity": "critical"}
+
+	stateWithLabels := func(s eval.State, values map[string]float64, labels ...string) *state.State {
+		l := data.Labels{
+			"severity":                       "critical",
+			alertingModels.RuleUIDLabel:      referenced.UID,
+			alertingModels.NamespaceUIDLabel: referenced.NamespaceUID,
+			"alertname":                      referenced.Title,
+			models.FolderTitleLabel:          "folder",
+		}
+		for i := 0; i < len(labels); i += 2 {
+			l[labels[i]] = labels[i+1]
+		}
+		return &state.State{State: s, Values: values, Labels: l}
+	}
+	states := []*state.State{
+		stateWithLabels(eval.Normal, map[string]float64{"B": 1, "C": 0}, "instance", "b"),
+		stateWithLabels(eval.Alerting, map[string]float64{"B": 20, "C": 1}, "instance", "a"),
+		stateWithLabels(eval.NoData, nil, "instance", "c"),
+	}
+
+	t.Run("state mode", func(t *testing.T) {
+		frame := ruleReferenceFrame(referenced, models.RuleReference{RuleUID: referenced.UID, Mode: models.RuleReferenceModeState}, states)
+		expected := data.NewFrame("",
+			data.NewField("instance", nil, []*string{ptr("a"), ptr("b"), ptr("c")}),
+			data.NewField("errors", nil, []float64{1, 0, 0}),
+		)
+		require.Equal(t, expected, frame)
+	})
+
+	t.Run("value mode of the condition", func(t *testing.T) {
+		frame := ruleReferenceFrame(referenced, models.RuleReference{RuleUID: referenced.UID, Mode: models.RuleReferenceModeValue}, states)
+		expected := data.NewFrame("",
+			data.NewField("instance", nil, []*string{ptr("a"), ptr("b")}),
+			data.NewField("errors", nil, []float64{1, 0}),
+		)
+		require.Equal(t, expected, frame)
+	})
+
+	t.Run("value mode of a query", func(t *testing.T) {
+		frame := ruleReferenceFrame(referenced, models.RuleReference{RuleUID: referenced.UID, Mode: models.RuleReferenceModeValue, RefID: "B"}, states)
+		expected := data.NewFrame("",
+			data.NewField("instance", nil, []*string{ptr("a"), ptr("b")}),
+			data.NewField("errors", nil, []float64{20, 1}),
+		)
+		require.Equal(t, expected, frame)
+	})
+
+	t.Run("result labels", func(t *testing.T) {
+		withResultLabels := func(s *state.State, labels data.Labels) *state.State {
+			s.ResultLabels = labels
+			return s
+		}
+		states := []*state.State{
+			// the label of the rule overrides the label of the result with the same name
+			withResultLabels(stateWithLabels(eval.Alerting, nil, "instance", "a"), data.Labels{"instance": "a", "severity": "warning"}),
+			withResultLabels(stateWithLabels(eval.Normal, nil, "instance", "b"), data.Labels{"instance": "b"}),
+		}
+		frame := ruleReferenceFrame(referenced, models.RuleReference{RuleUID: referenced.UID, Mode: models.RuleReferenceModeState}, states)
+		expected := data.NewFrame("",
+			data.NewField("instance", nil, []*string{ptr("a"), ptr("b")}),
+			data.NewField("severity", nil, []*string{ptr("warning"), nil}),
+			data.NewField("errors", nil, []float64{1, 0}),
+		)
+		require.Equal(t, expected, frame)
+	})
+}
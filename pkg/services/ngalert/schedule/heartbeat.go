@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// ReplicaHeartbeatStore stores the heartbeats of the instances that share the evaluation of alert rules.
+type ReplicaHeartbeatStore interface {
+	SaveReplicaHeartbeat(ctx context.Context, replica string, at time.Time) error
+	GetLiveReplicas(ctx context.Context, since time.Time) ([]string, error)
+	DeleteReplicaHeartbeats(ctx context.Context, before time.Time) (int64, error)
+	DeleteReplica(ctx context.Context, replica string) error
+}
+
+// HeartbeatMembership is a ReplicaMembership whose replicas are the instances that have written a heartbeat
+// to the database within the timeout. It can be used when the instances do not form an HA cluster.
+type HeartbeatMembership struct {
+	self     string
+	store    ReplicaHeartbeatStore
+	interval time.Duration
+	timeout  time.Duration
+	clock    clock.Clock
+	log      log.Logger
+
+	mtx      sync.RWMutex
+	replicas []string
+}
+
+func NewHeartbeatMembership(self string, store ReplicaHeartbeatStore, interval, timeout time.Duration, clk clock.Clock) *HeartbeatMembership {
+	return &HeartbeatMembership{
+		self:     self,
+		store:    store,
+		interval: interval,
+		timeout:  timeout,
+		clock:    clk,
+		log:      log.New("ngalert.scheduler.heartbeat"),
+	}
+}
+
+func (m *HeartbeatMembership) Self() string {
+	return m.self
+}
+
+func (m *HeartbeatMembership) Replicas() []string {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	return m.replicas
+}
+
+// Register writes the first heartbeat of this instance and loads the live replicas. It should be called before
+// the scheduler starts, so that the first tick already shards the rules across the replicas.
+func (m *HeartbeatMembership) Register(ctx context.Context) {
+	m.heartbeat(ctx)
+}
+
+// Run writes a heartbeat and refreshes the live replicas at every interval until the context is canceled.
+// It then deletes the heartbeat of this instance, so that the other instances take over its rules without
+// waiting for the timeout.
+func (m *HeartbeatMembership) Run(ctx context.Context) error {
+	m.log.Info("Starting heartbeat", "replica", m.self, "interval", m.interval, "timeout", m.timeout)
+	m.heartbeat(ctx)
+	ticker := m.clock.Ticker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.heartbeat(ctx)
+		case <-ctx.Done():
+			deleteCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := m.store.DeleteReplica(deleteCtx, m.self); err != nil {
+				m.log.Warn("Failed to delete the heartbeat of the replica", "replica", m.self, "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+func (m *HeartbeatMembership) heartbeat(ctx context.Context) {
+	now := m.clock.Now()
+	if err := m.store.SaveReplicaHeartbeat(ctx, m.self, now); err != nil {
+		m.log.Error("Failed to save heartbeat", "replica", m.self, "error", err)
+	}
+	since := now.Add(-m.timeout)
+	if deleted, err := m.store.DeleteReplicaHeartbeats(ctx, since); err != nil {
+		m.log.Warn("Failed to delete expired heartbeats", "error", err)
+	} else if deleted > 0 {
+		m.log.Info("Deleted expired heartbeats", "count", deleted)
+	}
+	replicas, err := m.store.GetLiveReplicas(ctx, since)
+	if err != nil {
+		// keep the last known replicas rather than evaluating all rules or none of them
+		m.log.Error("Failed to get live replicas", "error", err)
+		return
+	}
+	m.mtx.Lock()
+	m.replicas = replicas
+	m.mtx.Unlock()
+}
@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// JitterStrategy defines how the evaluations of alert rules are spread across their evaluation interval.
+type JitterStrategy int
+
+const (
+	// JitterNever evaluates every alert rule at the first tick of its evaluation interval.
+	JitterNever JitterStrategy = iota
+	// JitterByGroup evaluates the rules of a group at the same tick, with an offset derived from the group.
+	JitterByGroup
+	// JitterByRule evaluates each alert rule with an offset derived from the rule.
+	JitterByRule
+)
+
+// JitterStrategyFromSetting returns the strategy of the jitter_evaluations setting.
+func JitterStrategyFromSetting(value string) JitterStrategy {
+	switch value {
+	case setting.JitterEvaluationsByGroup:
+		return JitterByGroup
+	case setting.JitterEvaluationsByRule:
+		return JitterByRule
+	default:
+		return JitterNever
+	}
+}
+
+// jitterOffsetInTicks returns the number of ticks by which the evaluation of the rule is delayed within its evaluation
+// interval. The offset is derived from a hash of the rule group, or the rule, so it is the same on every tick and
+// every instance. The interval of the rule must be a multiple of the base interval.
+func jitterOffsetInTicks(rule *ngmodels.AlertRule, baseInterval time.Duration, strategy JitterStrategy) int64 {
+	if strategy == JitterNever {
+		return 0
+	}
+	itemFrequency := rule.IntervalSeconds / int64(baseInterval.Seconds())
+	if itemFrequency <= 1 {
+		return 0
+	}
+	return int64(jitterHash(rule, strategy) % uint64(itemFrequency))
+}
+
+func jitterHash(rule *ngmodels.AlertRule, strategy JitterStrategy) uint64 {
+	key := ruleGroupShardKey(rule.GetGroupKey())
+	if strategy == JitterByRule {
+		key += "/" + rule.UID
+	}
+	h := fnv.New64a()
+	// We can ignore err as fnv64 does not return an error
+	// nolint:errcheck,gosec
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// jitterStrategyFor returns the strategy of the rule. Groups that are evaluated in order are always spread by group,
+// so that their rules are evaluated at the same tick.
+func (sch *schedule) jitterStrategyFor(rule *ngmodels.AlertRule, groupsInOrder map[ngmodels.AlertRuleGroupKey]struct{}) JitterStrategy {
+	if sch.jitterEvaluations != JitterByRule {
+		return sch.jitterEvaluations
+	}
+	if sch.sequentialRuleGroups {
+		return JitterByGroup
+	}
+	if _, ok := groupsInOrder[rule.GetGroupKey()]; ok {
+		return JitterByGroup
+	}
+	return JitterByRule
+}
+
+// groupsWithRuleReferences returns the groups that have rules that reference other rules.
+func groupsWithRuleReferences(rules []*ngmodels.AlertRule) map[ngmodels.AlertRuleGroupKey]struct{} {
+	result := make(map[ngmodels.AlertRuleGroupKey]struct{})
+	for _, rule := range rules {
+		if (ngmodels.RulesGroup{rule}).HasRuleReferences() {
+			result[rule.GetGroupKey()] = struct{}{}
+		}
+	}
+	return result
+}
+
+// evaluationLag returns the time between when the evaluation was planned to be dispatched and now. It does not include
+// the delay that spreads the evaluations of a tick across the base interval, which is intended.
+func evaluationLag(now time.Time, e *evaluation) time.Duration {
+	if e.plannedAt.IsZero() {
+		return now.Sub(e.scheduledAt)
+	}
+	return now.Sub(e.plannedAt)
+}
@@ -0,0 +1,143 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestJitterOffsetInTicks(t *testing.T) {
+	baseInterval := 10 * time.Second
+	gen := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(10*time.Minute))
+
+	t.Run("should not delay rules if jitter is disabled", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			require.Zero(t, jitterOffsetInTicks(gen(), baseInterval, JitterNever))
+		}
+	})
+
+	t.Run("should not delay rules that are evaluated at every tick", func(t *testing.T) {
+		rule := gen()
+		rule.IntervalSeconds = int64(baseInterval.Seconds())
+		require.Zero(t, jitterOffsetInTicks(rule, baseInterval, JitterByRule))
+	})
+
+	t.Run("should spread rules across the interval", func(t *testing.T) {
+		const rules = 6000
+		itemFrequency := int64(60)
+		offsets := make(map[int64]int)
+		for i := 0; i < rules; i++ {
+			rule := gen()
+			offset := jitterOffsetInTicks(rule, baseInterval, JitterByRule)
+			require.GreaterOrEqual(t, offset, int64(0))
+			require.Less(t, offset, itemFrequency)
+			require.Equal(t, offset, jitterOffsetInTicks(rule, baseInterval, JitterByRule), "offset should be deterministic")
+			offsets[offset]++
+		}
+		require.Len(t, offsets, int(itemFrequency))
+		for offset, count := range offsets {
+			// 100 rules are expected at each offset
+			require.InDeltaf(t, 100, count, 50, "too many or too few rules at offset %d", offset)
+		}
+	})
+
+	t.Run("should evaluate the rules of a group at the same tick if jittered by group", func(t *testing.T) {
+		rule := gen()
+		other := models.CopyRule(rule)
+		other.UID = "other"
+		require.Equal(t, jitterOffsetInTicks(rule, baseInterval, JitterByGroup), jitterOffsetInTicks(other, baseInterval, JitterByGroup))
+	})
+}
+
+func TestJitterStrategyFor(t *testing.T) {
+	gen := models.AlertRuleGen(models.WithOrgID(1))
+	rule := gen()
+
+	testCases := []struct {
+		name          string
+		strategy      JitterStrategy
+		sequential    bool
+		groupsInOrder map[models.AlertRuleGroupKey]struct{}
+		expected      JitterStrategy
+	}{
+		{name: "never", strategy: JitterNever, sequential: true, expected: JitterNever},
+		{name: "by group", strategy: JitterByGroup, expected: JitterByGroup},
+		{name: "by rule", strategy: JitterByRule, expected: JitterByRule},
+		{name: "by rule with sequential groups", strategy: JitterByRule, sequential: true, expected: JitterByGroup},
+		{
+			name:          "by rule with group that has references",
+			strategy:      JitterByRule,
+			groupsInOrder: map[models.AlertRuleGroupKey]struct{}{rule.GetGroupKey(): {}},
+			expected:      JitterByGroup,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sch := &schedule{jitterEvaluations: tc.strategy, sequentialRuleGroups: tc.sequential}
+			require.Equal(t, tc.expected, sch.jitterStrategyFor(rule, tc.groupsInOrder))
+		})
+	}
+}
+
+func TestProcessTicks_Jitter(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	sched := setupScheduler(t, ruleStore, &state.FakeInstanceStore{}, nil, nil, nil)
+	sched.jitterEvaluations = JitterByRule
+
+	const itemFrequency = 10
+	interval := time.Duration(itemFrequency) * sched.baseInterval
+	gen := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(interval), withQueryForState(t, eval.Normal))
+	rules := make(map[string]*models.AlertRule)
+	for i := 0; i < 20; i++ {
+		rule := gen()
+		rule.UID = fmt.Sprintf("rule-%d", i)
+		rules[rule.UID] = rule
+		ruleStore.PutRule(ctx, rule)
+	}
+
+	tick := time.Unix(0, 0)
+	evaluated := make(map[string]int64)
+	for i := int64(0); i < itemFrequency; i++ {
+		scheduled, _ := sched.processTick(ctx, dispatcherGroup, tick)
+		require.Equal(t, float64(len(scheduled)), testutil.ToFloat64(sched.metrics.TickDispatchedRules))
+		for _, item := range scheduled {
+			require.NotContains(t, evaluated, item.rule.UID, "rule should be evaluated once per interval")
+			evaluated[item.rule.UID] = i
+		}
+		tick = tick.Add(sched.baseInterval)
+	}
+
+	require.Len(t, evaluated, len(rules))
+	offsets := make(map[int64]struct{})
+	for uid, i := range evaluated {
+		require.Equal(t, jitterOffsetInTicks(rules[uid], sched.baseInterval, JitterByRule), i)
+		offsets[i] = struct{}{}
+	}
+	require.Greater(t, len(offsets), 1, "rules should be spread across the interval")
+}
+
+func TestEvaluationLag(t *testing.T) {
+	tick := time.Unix(100, 0)
+	now := tick.Add(800 * time.Millisecond)
+
+	t.Run("should be measured from the planned dispatch time", func(t *testing.T) {
+		e := &evaluation{scheduledAt: tick, plannedAt: tick.Add(750 * time.Millisecond)}
+		require.Equal(t, 50*time.Millisecond, evaluationLag(now, e))
+	})
+
+	t.Run("should be measured from the tick if the planned dispatch time is unknown", func(t *testing.T) {
+		e := &evaluation{scheduledAt: tick}
+		require.Equal(t, 800*time.Millisecond, evaluationLag(now, e))
+	})
+}
@@ -0,0 +1,85 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// evaluateRecordingRule evaluates the queries and expressions of a recording rule and writes the result
+// of the recorded query or expression as series of the metric of the rule. Recording rules do not have state.
+func (sch *schedule) evaluateRecordingRule(ctx context.Context, logger log.Logger, e *evaluation, schedulerUser *user.SignedInUser, span tracing.Span) {
+	orgID := fmt.Sprint(e.rule.OrgID)
+	start := sch.clock.Now()
+
+	err := sch.recordRule(ctx, e, schedulerUser)
+	dur := sch.clock.Now().Sub(start)
+
+	sch.metrics.EvalTotal.WithLabelValues(orgID).Inc()
+	sch.metrics.EvalDuration.WithLabelValues(orgID).Observe(dur.Seconds())
+	if err != nil {
+		sch.metrics.EvalFailures.WithLabelValues(orgID).Inc()
+		logger.Error("Failed to evaluate recording rule", "error", err, "duration", dur)
+		span.RecordError(err)
+		span.AddEvents(
+			[]string{"error", "message"},
+			[]tracing.EventValue{
+				{Str: fmt.Sprintf("%v", err)},
+				{Str: "recording rule evaluation failed"},
+			})
+		return
+	}
+	logger.Debug("Recording rule evaluated", "duration", dur)
+	span.AddEvents([]string{"message"}, []tracing.EventValue{{Str: "recording rule evaluated"}})
+}
+
+func (sch *schedule) recordRule(ctx context.Context, e *evaluation, schedulerUser *user.SignedInUser) error {
+	ruleEval, err := sch.evaluatorFactory.Create(eval.Context(ctx, schedulerUser), e.rule.GetEvalCondition())
+	if err != nil {
+		return fmt.Errorf("failed to build rule evaluator: %w", err)
+	}
+	resp, err := ruleEval.EvaluateRaw(ctx, e.scheduledAt)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate rule: %w", err)
+	}
+	points, err := recordedPoints(resp, e.rule.Record.From, e.rule.Labels)
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return sch.recordingWriter.Write(ctx, e.rule.OrgID, e.rule.Record.Metric, e.scheduledAt, points)
+}
+
+// recordedPoints returns the points of the query or expression refID, with the labels of the rule added to the labels of each series.
+func recordedPoints(resp *backend.QueryDataResponse, refID string, ruleLabels map[string]string) ([]writer.Point, error) {
+	res, ok := resp.Responses[refID]
+	if !ok {
+		return nil, fmt.Errorf("no result for the recorded query or expression %s", refID)
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to evaluate the recorded query or expression %s: %w", refID, res.Error)
+	}
+	points, err := writer.PointsFromFrames(res.Frames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result of the recorded query or expression %s: %w", refID, err)
+	}
+	for i := range points {
+		if points[i].Labels == nil {
+			points[i].Labels = make(data.Labels, len(ruleLabels))
+		}
+		for k, v := range ruleLabels {
+			points[i].Labels[k] = v
+		}
+	}
+	return points, nil
+}
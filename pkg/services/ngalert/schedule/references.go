@@ -0,0 +1,123 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// ruleReferenceReader reads the current states of the rules that are referenced by the queries of a rule.
+// Only rules of the same group can be referenced, as the rules of a group are evaluated in the order of their references.
+type ruleReferenceReader struct {
+	manager *state.Manager
+	rules   *alertRulesRegistry
+	rule    *ngmodels.AlertRule
+}
+
+func (r *ruleReferenceReader) Read(ref ngmodels.RuleReference) (*data.Frame, error) {
+	referenced := r.rules.get(ngmodels.AlertRuleKey{OrgID: r.rule.OrgID, UID: ref.RuleUID})
+	if referenced == nil || referenced.GetGroupKey() != r.rule.GetGroupKey() {
+		return nil, fmt.Errorf("referenced rule %s is not in the group of the rule", ref.RuleUID)
+	}
+	return ruleReferenceFrame(referenced, ref, r.manager.GetStatesForRuleUID(referenced.OrgID, referenced.UID)), nil
+}
+
+// ruleReferenceFrame returns a frame with a row for each state of the referenced rule. The rows have the labels of
+// the results of the referenced rule, without the labels that Grafana and the referenced rule add, so that the rows can
+// be matched with the series of the rule that references it. A label that only some of the rows have is null in the
+// other rows. In RuleReferenceModeValue, states without a value are skipped.
+func ruleReferenceFrame(referenced *ngmodels.AlertRule, ref ngmodels.RuleReference, states []*state.State) *data.Frame {
+	refID := ref.RefID
+	if refID == "" {
+		refID = referenced.Condition
+	}
+
+	type row struct {
+		labels data.Labels
+		value  float64
+	}
+	rows := make([]row, 0, len(states))
+	keys := make(map[string]struct{})
+	for _, s := range states {
+		var value float64
+		switch ref.Mode {
+		case ngmodels.RuleReferenceModeValue:
+			v, ok := s.Values[refID]
+			if !ok {
+				continue
+			}
+			value = v
+		default:
+			if s.State == eval.Alerting {
+				value = 1
+			}
+		}
+		labels := resultLabels(referenced, s)
+		for k := range labels {
+			keys[k] = struct{}{}
+		}
+		rows = append(rows, row{labels: labels, value: value})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].labels.String() < rows[j].labels.String()
+	})
+
+	labelKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+
+	fields := make([]*data.Field, 0, len(labelKeys)+1)
+	for _, k := range labelKeys {
+		values := make([]*string, 0, len(rows))
+		for _, r := range rows {
+			var value *string
+			if v, ok := r.labels[k]; ok {
+				value = &v
+			}
+			values = append(values, value)
+		}
+		fields = append(fields, data.NewField(k, nil, values))
+	}
+	values := make([]float64, 0, len(rows))
+	for _, r := range rows {
+		values = append(values, r.value)
+	}
+	fields = append(fields, data.NewField(referenced.Title, nil, values))
+	return data.NewFrame("", fields...)
+}
+
+// resultLabels returns the labels of the result that the state was created from. States that were saved before
+// the labels of the results were stored only have the labels of the alert, so the labels that Grafana and the rule
+// add are removed from them instead. This loses the labels of the result that have the same name as the labels of
+// the rule.
+func resultLabels(rule *ngmodels.AlertRule, s *state.State) data.Labels {
+	if s.ResultLabels != nil {
+		return s.ResultLabels.Copy()
+	}
+	labels := make(data.Labels, len(s.Labels))
+	for k, v := range s.Labels {
+		if _, ok := rule.Labels[k]; ok || isExtraLabel(k) {
+			continue
+		}
+		labels[k] = v
+	}
+	return labels
+}
+
+// isExtraLabel returns true if the label is one of the labels that the scheduler adds to the labels of the results.
+func isExtraLabel(name string) bool {
+	switch name {
+	case alertingModels.NamespaceUIDLabel, alertingModels.RuleUIDLabel, prometheusModel.AlertNameLabel, ngmodels.FolderTitleLabel:
+		return true
+	}
+	return false
+}
@@ -13,6 +13,9 @@ import (
 
 var errRuleDeleted = errors.New("rule deleted")
 
+// errRuleMoved is the reason for stopping the evaluation of a rule that is now evaluated by another instance.
+var errRuleMoved = errors.New("rule moved to another instance")
+
 type alertRuleInfoRegistry struct {
 	mu            sync.Mutex
 	alertRuleInfo map[models.AlertRuleKey]*alertRuleInfo
@@ -142,8 +145,20 @@ func (a *alertRuleInfo) update(lastVersion ruleVersionAndPauseStatus) bool {
 
 type evaluation struct {
 	scheduledAt time.Time
+	// plannedAt is the time at which the evaluation was planned to be sent to the routine of the rule, that is the
+	// tick plus the delay that spreads the batches of the tick across the base interval. It is zero if not known.
+	plannedAt   time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// afterEval is called when the evaluation is completed or dropped. It is optional.
+	afterEval func()
+}
+
+// done calls afterEval if it is set.
+func (e *evaluation) done() {
+	if e.afterEval != nil {
+		e.afterEval()
+	}
 }
 
 type alertRulesRegistry struct {
@@ -8,9 +8,8 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
-	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/hashicorp/go-multierror"
-	prometheusModel "github.com/prometheus/common/model"
 	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 
@@ -22,6 +21,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/template"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util/ticker"
@@ -83,6 +84,9 @@ type schedule struct {
 
 	stateManager *state.Manager
 
+	// recordingWriter writes the series of recording rules.
+	recordingWriter writer.Writer
+
 	appURL               *url.URL
 	disableGrafanaFolder bool
 
@@ -97,6 +101,22 @@ type schedule struct {
 	// last evaluated.
 	schedulableAlertRules alertRulesRegistry
 
+	// sharder decides which rules are evaluated by this instance when the rules are sharded across instances.
+	// It is nil if every instance evaluates all rules.
+	sharder *ruleSharder
+	// unownedRules are the rules that were evaluated by other instances in the last tick.
+	unownedRules map[ngmodels.AlertRuleKey]struct{}
+	// handoffs are the rules that moved from this instance to other instances, whose firing alerts this instance
+	// keeps sending until the new owners have evaluated them.
+	handoffs map[ngmodels.AlertRuleKey]*ruleHandoff
+
+	// sequentialRuleGroups enables the evaluation of the rules of every group in order. Groups with rules
+	// that reference other rules are always evaluated in order.
+	sequentialRuleGroups bool
+
+	// jitterEvaluations defines how the evaluations of alert rules are spread across their evaluation interval.
+	jitterEvaluations JitterStrategy
+
 	tracer tracing.Tracer
 }
 
@@ -113,6 +133,17 @@ type SchedulerCfg struct {
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
 	Tracer               tracing.Tracer
+	// RecordingWriter writes the series of recording rules. If it is nil, the series are discarded.
+	RecordingWriter writer.Writer
+	// Membership provides the instances that share the evaluation of alert rules. If it is not nil, the rule groups
+	// are sharded across the instances with consistent hashing. If it is nil, every instance evaluates all rules.
+	Membership ReplicaMembership
+	// SequentialRuleGroups enables the evaluation of the rules of every group in order, each rule after the previous
+	// rule has been evaluated. Groups with rules that reference other rules are always evaluated in order.
+	SequentialRuleGroups bool
+	// JitterEvaluations defines how the evaluations of alert rules are spread across their evaluation interval.
+	// By default, every rule is evaluated at the first tick of its interval.
+	JitterEvaluations JitterStrategy
 }
 
 // NewScheduler returns a new schedule.
@@ -133,6 +164,15 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
 		tracer:                cfg.Tracer,
+		recordingWriter:       cfg.RecordingWriter,
+		sequentialRuleGroups:  cfg.SequentialRuleGroups,
+		jitterEvaluations:     cfg.JitterEvaluations,
+	}
+	if sch.recordingWriter == nil {
+		sch.recordingWriter = writer.NewNoopWriter()
+	}
+	if cfg.Membership != nil {
+		sch.sharder = newRuleSharder(cfg.Membership)
 	}
 
 	return &sch
@@ -245,11 +285,25 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	registeredDefinitions := sch.registry.keyMap()
 
 	sch.updateRulesMetrics(alertRules)
+	sch.updateShards()
+
+	var groupsInOrder map[ngmodels.AlertRuleGroupKey]struct{}
+	if sch.jitterEvaluations == JitterByRule && !sch.sequentialRuleGroups {
+		groupsInOrder = groupsWithRuleReferences(alertRules)
+	}
 
 	readyToRun := make([]readyToRunItem, 0)
 	missingFolder := make(map[string][]string)
+	unownedRules := make(map[ngmodels.AlertRuleKey]struct{})
+	ownedRules := 0
 	for _, item := range alertRules {
 		key := item.GetKey()
+		if !sch.ownsRule(ctx, item, tick, unownedRules) {
+			// the rule is evaluated by another instance, so it must not be considered deleted
+			delete(registeredDefinitions, key)
+			continue
+		}
+		ownedRules++
 		ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 		// enforce minimum evaluation interval
@@ -274,7 +328,8 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		}
 
 		itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-		if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
+		offset := jitterOffsetInTicks(item, sch.baseInterval, sch.jitterStrategyFor(item, groupsInOrder))
+		if item.IntervalSeconds != 0 && tickNum%itemFrequency == offset {
 			var folderTitle string
 			if !sch.disableGrafanaFolder {
 				title, ok := folderTitles[item.NamespaceUID]
@@ -295,30 +350,35 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		delete(registeredDefinitions, key)
 	}
 
+	sch.unownedRules = unownedRules
+	sch.processHandoffs(ctx, tick, unownedRules)
+	sch.metrics.ShardOwnedAlertRules.Set(float64(ownedRules))
+
 	if len(missingFolder) > 0 { // if this happens then there can be problems with fetching folders from the database.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
+	batches := sch.batchReadyToRun(readyToRun)
+	sch.metrics.TickDispatchedRules.Set(float64(len(readyToRun)))
+	sch.metrics.TickDispatchedBatches.Set(float64(len(batches)))
 	var step int64 = 0
-	if len(readyToRun) > 0 {
-		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
+	if len(batches) > 0 {
+		step = sch.baseInterval.Nanoseconds() / int64(len(batches))
 	}
 
-	for i := range readyToRun {
-		item := readyToRun[i]
+	for i := range batches {
+		batch := batches[i]
+		delay := time.Duration(int64(i) * step)
+		for j := range batch {
+			batch[j].plannedAt = tick.Add(delay)
+		}
 
-		time.AfterFunc(time.Duration(int64(i)*step), func() {
-			key := item.rule.GetKey()
-			success, dropped := item.ruleInfo.eval(&item.evaluation)
-			if !success {
-				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
+		time.AfterFunc(delay, func() {
+			if len(batch) == 1 {
+				sch.dispatch(batch[0], tick)
 				return
 			}
-			if dropped != nil {
-				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
-				orgID := fmt.Sprint(key.OrgID)
-				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
-			}
+			sch.dispatchSequentially(batch, tick)
 		})
 	}
 
@@ -340,6 +400,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 	evalTotal := sch.metrics.EvalTotal.WithLabelValues(orgID)
 	evalDuration := sch.metrics.EvalDuration.WithLabelValues(orgID)
 	evalTotalFailures := sch.metrics.EvalFailures.WithLabelValues(orgID)
+	evalLag := sch.metrics.EvalLag.WithLabelValues(orgID)
 
 	notify := func(states []state.StateTransition) {
 		expiredAlerts := FromAlertsStateToStoppedAlert(states, sch.appURL, sch.clock)
@@ -376,7 +437,20 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				},
 			},
 		}
-		evalCtx := eval.Context(ctx, schedulerUser)
+		if e.rule.IsRecordingRule() {
+			sch.evaluateRecordingRule(ctx, logger, e, schedulerUser, span)
+			return
+		}
+		extraLabels := sch.getRuleExtraLabels(e)
+		evalCtx := eval.NewContextWithPreviousResults(ctx, schedulerUser, &alertingResultsFromRuleState{
+			manager: sch.stateManager,
+			rule:    e.rule,
+		})
+		evalCtx.RuleReferenceReader = &ruleReferenceReader{
+			manager: sch.stateManager,
+			rules:   &sch.schedulableAlertRules,
+			rule:    e.rule,
+		}
 		ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
 		var results eval.Results
 		var dur time.Duration
@@ -425,7 +499,8 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 			logger.Debug("Skip updating the state because the context has been cancelled")
 			return
 		}
-		processedStates := sch.stateManager.ProcessEvalResults(ctx, e.scheduledAt, e.rule, results, sch.getRuleExtraLabels(e))
+		stateCtx := template.WithQueryFunc(ctx, sch.templateQueryFunc(schedulerUser, e.rule))
+		processedStates := sch.stateManager.ProcessEvalResults(stateCtx, e.scheduledAt, e.rule, results, extraLabels)
 		alerts := FromStateTransitionToPostableAlerts(processedStates, sch.stateManager, sch.appURL)
 		span.AddEvents(
 			[]string{"message", "state_transitions", "alerts_to_send"},
@@ -477,14 +552,19 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				return nil
 			}
 			if evalRunning {
+				ctx.done()
 				continue
 			}
 
 			func() {
 				evalRunning = true
+				evalLag.Observe(evaluationLag(sch.clock.Now(), ctx).Seconds())
+				sch.metrics.EvaluationsInFlight.Inc()
 				defer func() {
+					sch.metrics.EvaluationsInFlight.Dec()
 					evalRunning = false
 					sch.evalApplied(key, ctx.scheduledAt)
+					ctx.done()
 				}()
 
 				err := retryIfError(func(attempt int64) error {
@@ -538,6 +618,60 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 	}
 }
 
+// updateShards rebuilds the hash ring of the instances that share the evaluation of alert rules if they have changed.
+func (sch *schedule) updateShards() {
+	if sch.sharder == nil {
+		return
+	}
+	if sch.sharder.update() {
+		sch.log.Info("Instances that evaluate alert rules have changed, rebalancing alert rules", "replicas", sch.sharder.replicas())
+		sch.metrics.ShardRebalances.Inc()
+	}
+	sch.metrics.ShardReplicas.Set(float64(sch.sharder.replicas()))
+}
+
+// ownsRule reports whether this instance evaluates the rule, and adds the rule to unowned if it does not.
+// When a rule moves to another instance, its evaluation routine is stopped and its states are removed from the cache
+// without being resolved, as they are kept in the database for the new owner. The firing alerts of the rule are
+// handed off: this instance keeps sending them until the new owner has evaluated them, so that they do not expire
+// in the Alertmanager in the meantime. When a rule moves to this instance, its states are loaded from the database,
+// so that the evaluation continues where the previous owner stopped.
+func (sch *schedule) ownsRule(ctx context.Context, rule *ngmodels.AlertRule, tick time.Time, unowned map[ngmodels.AlertRuleKey]struct{}) bool {
+	if sch.sharder == nil {
+		return true
+	}
+	key := rule.GetKey()
+	_, wasUnowned := sch.unownedRules[key]
+	if sch.sharder.owns(rule.GetGroupKey()) {
+		if wasUnowned {
+			sch.log.Debug("Alert rule moved to this instance", key.LogContext()...)
+			delete(sch.handoffs, key)
+			if err := sch.stateManager.WarmRule(ctx, rule); err != nil {
+				sch.log.Error("Failed to load the state of the alert rule", append(key.LogContext(), "error", err)...)
+			}
+		}
+		return true
+	}
+	unowned[key] = struct{}{}
+	if !wasUnowned {
+		ruleInfo, wasOwned := sch.registry.del(key)
+		if wasOwned {
+			sch.log.Debug("Alert rule moved to another instance", key.LogContext()...)
+			ruleInfo.stop(errRuleMoved)
+		}
+		states := sch.stateManager.ForgetRule(key)
+		// only the alerts of rules that this instance evaluated were sent by it. On startup, the states of
+		// the rules of other instances are in the cache but are not handed off.
+		if h := newRuleHandoff(rule, states, tick); wasOwned && h != nil {
+			if sch.handoffs == nil {
+				sch.handoffs = make(map[ngmodels.AlertRuleKey]*ruleHandoff)
+			}
+			sch.handoffs[key] = h
+		}
+	}
+	return false
+}
+
 // evalApplied is only used on tests.
 func (sch *schedule) evalApplied(alertDefKey ngmodels.AlertRuleKey, now time.Time) {
 	if sch.evalAppliedFunc == nil {
@@ -556,15 +690,30 @@ func (sch *schedule) stopApplied(alertDefKey ngmodels.AlertRuleKey) {
 	sch.stopAppliedFunc(alertDefKey)
 }
 
-func (sch *schedule) getRuleExtraLabels(evalCtx *evaluation) map[string]string {
-	extraLabels := make(map[string]string, 4)
-
-	extraLabels[alertingModels.NamespaceUIDLabel] = evalCtx.rule.NamespaceUID
-	extraLabels[prometheusModel.AlertNameLabel] = evalCtx.rule.Title
-	extraLabels[alertingModels.RuleUIDLabel] = evalCtx.rule.UID
+// alertingResultsFromRuleState reads the labels of the results that were alerting at the previous
+// evaluation of a rule from the state of its alert instances.
+type alertingResultsFromRuleState struct {
+	manager *state.Manager
+	rule    *ngmodels.AlertRule
+}
 
-	if !sch.disableGrafanaFolder {
-		extraLabels[ngmodels.FolderTitleLabel] = evalCtx.folderTitle
+// Read returns the labels of the results of alert instances that are either pending or alerting.
+// States without result labels, which were restored from the database before the result labels were stored,
+// are skipped.
+func (r *alertingResultsFromRuleState) Read() []data.Labels {
+	var result []data.Labels
+	for _, s := range r.manager.GetStatesForRuleUID(r.rule.OrgID, r.rule.UID) {
+		if s.State != eval.Alerting && s.State != eval.Pending {
+			continue
+		}
+		if s.ResultLabels == nil {
+			continue
+		}
+		result = append(result, s.ResultLabels.Copy())
 	}
-	return extraLabels
+	return result
+}
+
+func (sch *schedule) getRuleExtraLabels(evalCtx *evaluation) map[string]string {
+	return evalCtx.rule.GetRuleExtraLabels(evalCtx.folderTitle, !sch.disableGrafanaFolder)
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -653,6 +654,134 @@ func TestSchedule_ruleRoutine(t *testing.T) {
 		})
 	})
 
+	t.Run("when a recording rule is evaluated", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting))()
+		rule.Data[0].Model = json.RawMessage(`{
+			"datasourceUid": "__expr__",
+			"type":"math",
+			"expression":"2 + 2"
+		}`)
+		rule.Record = &models.Record{Metric: "test_metric", From: "A"}
+		rule.Labels = map[string]string{"team": "a"}
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sender := AlertsSenderMock{}
+		sch, ruleStore, _, reg := createSchedule(evalAppliedChan, &sender)
+		recordingWriter := &fakeRecordingWriter{}
+		sch.recordingWriter = recordingWriter
+		ruleStore.PutRule(context.Background(), rule)
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		expectedTime := time.UnixMicro(rand.Int63())
+		evalChan <- &evaluation{
+			scheduledAt: expectedTime,
+			rule:        rule,
+		}
+
+		waitForTimeChannel(t, evalAppliedChan)
+
+		t.Run("it should write the result with the labels of the rule", func(t *testing.T) {
+			require.Len(t, recordingWriter.writes, 1)
+			w := recordingWriter.writes[0]
+			require.Equal(t, rule.OrgID, w.orgID)
+			require.Equal(t, "test_metric", w.name)
+			require.Equal(t, expectedTime, w.t)
+			require.Equal(t, []writer.Point{{Labels: data.Labels{"team": "a"}, Value: 4}}, w.points)
+		})
+
+		t.Run("it should not create states and not send alerts", func(t *testing.T) {
+			require.Empty(t, sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID))
+			sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+		})
+
+		t.Run("it reports metrics", func(t *testing.T) {
+			expectedMetric := fmt.Sprintf(
+				`# HELP grafana_alerting_rule_evaluation_failures_total The total number of rule evaluation failures.
+				# TYPE grafana_alerting_rule_evaluation_failures_total counter
+				grafana_alerting_rule_evaluation_failures_total{org="%[1]d"} 0
+				# HELP grafana_alerting_rule_evaluations_total The total number of rule evaluations.
+				# TYPE grafana_alerting_rule_evaluations_total counter
+				grafana_alerting_rule_evaluations_total{org="%[1]d"} 1
+				`, rule.OrgID)
+
+			err := testutil.GatherAndCompare(reg, bytes.NewBufferString(expectedMetric), "grafana_alerting_rule_evaluations_total", "grafana_alerting_rule_evaluation_failures_total")
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("when annotations use the query function", func(t *testing.T) {
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting))()
+		rule.Annotations = map[string]string{
+			"summary": `{{ query "{\"datasource\": \"__expr__\", \"model\": {\"type\": \"math\", \"expression\": \"2 * 3\"}}" | first | value }}`,
+		}
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sender := AlertsSenderMock{}
+		sender.EXPECT().Send(rule.GetKey(), mock.Anything).Return()
+
+		sch, ruleStore, _, _ := createSchedule(evalAppliedChan, &sender)
+		ruleStore.PutRule(context.Background(), rule)
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		evalChan <- &evaluation{
+			scheduledAt: sch.clock.Now(),
+			rule:        rule,
+		}
+
+		waitForTimeChannel(t, evalAppliedChan)
+
+		states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, "6", states[0].Annotations["summary"])
+	})
+
+	t.Run("when the rule has notification settings", func(t *testing.T) {
+		settings := models.NotificationSettings{Receiver: "test-receiver", GroupBy: []string{"pod"}}
+		rule := models.AlertRuleGen(withQueryForState(t, eval.Alerting), models.WithNotificationSettings(settings))()
+
+		evalChan := make(chan *evaluation)
+		evalAppliedChan := make(chan time.Time)
+
+		sender := AlertsSenderMock{}
+		sender.EXPECT().Send(rule.GetKey(), mock.Anything).Return()
+
+		sch, ruleStore, _, _ := createSchedule(evalAppliedChan, &sender)
+		ruleStore.PutRule(context.Background(), rule)
+
+		go func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			_ = sch.ruleRoutine(ctx, rule.GetKey(), evalChan, make(chan ruleVersionAndPauseStatus))
+		}()
+
+		evalChan <- &evaluation{
+			scheduledAt: sch.clock.Now(),
+			rule:        rule,
+		}
+
+		waitForTimeChannel(t, evalAppliedChan)
+
+		states := sch.stateManager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, "true", states[0].Labels[models.AutogeneratedRouteLabel])
+		require.Equal(t, "test-receiver", states[0].Labels[models.AutogeneratedRouteReceiverNameLabel])
+		require.Equal(t, settings.Fingerprint(), states[0].Labels[models.AutogeneratedRouteSettingsHashLabel])
+	})
+
 	t.Run("when there are alerts that should be firing", func(t *testing.T) {
 		t.Run("it should call sender", func(t *testing.T) {
 			// eval.Alerting makes state manager to create notifications for alertmanagers
@@ -914,6 +1043,40 @@ func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys
 	}
 }
 
+func TestAlertingResultsFromRuleState(t *testing.T) {
+	rule := models.AlertRuleGen()()
+	rule.Labels = map[string]string{"team": "a"}
+
+	st := state.NewManager(state.ManagerCfg{
+		Metrics:   metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		Images:    &state.NoopImageService{},
+		Clock:     clock.NewMock(),
+		Historian: &state.FakeHistorian{},
+	})
+	newState := func(s eval.State, instance string) *state.State {
+		return &state.State{
+			OrgID:        rule.OrgID,
+			AlertRuleUID: rule.UID,
+			CacheID:      instance,
+			State:        s,
+			Labels:       data.Labels{prometheusModel.AlertNameLabel: rule.Title, "team": "a", "instance": instance},
+			ResultLabels: data.Labels{"team": "b", "instance": instance},
+		}
+	}
+	restored := newState(eval.Alerting, "restored")
+	restored.ResultLabels = nil
+	st.Put([]*state.State{
+		newState(eval.Alerting, "alerting"),
+		newState(eval.Pending, "pending"),
+		newState(eval.Normal, "normal"),
+		newState(eval.NoData, "nodata"),
+		restored,
+	})
+
+	reader := &alertingResultsFromRuleState{manager: st, rule: rule}
+	require.ElementsMatch(t, []data.Labels{{"team": "b", "instance": "alerting"}, {"team": "b", "instance": "pending"}}, reader.Read())
+}
+
 func assertStopRun(t *testing.T, ch <-chan models.AlertRuleKey, keys ...models.AlertRuleKey) {
 	timeout := time.After(time.Second)
 
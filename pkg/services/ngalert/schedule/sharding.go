@@ -0,0 +1,252 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// ReplicaMembership provides the instances of Grafana that share the evaluation of alert rules.
+type ReplicaMembership interface {
+	// Self returns the name of this instance.
+	Self() string
+	// Replicas returns the names of the live instances, including this one.
+	Replicas() []string
+}
+
+// ringTokensPerReplica is the number of virtual nodes of each replica in the hash ring. More virtual nodes
+// spread the rule groups more evenly, and move fewer groups when a replica joins or leaves.
+const ringTokensPerReplica = 128
+
+// hashRing assigns keys to replicas with consistent hashing.
+type hashRing struct {
+	replicas []string
+	tokens   []uint64
+	owners   map[uint64]string
+}
+
+// newHashRing returns a ring of the given replicas. The replicas must be unique and sorted.
+func newHashRing(replicas []string) *hashRing {
+	r := &hashRing{
+		replicas: replicas,
+		tokens:   make([]uint64, 0, len(replicas)*ringTokensPerReplica),
+		owners:   make(map[uint64]string, len(replicas)*ringTokensPerReplica),
+	}
+	for _, replica := range replicas {
+		for i := 0; i < ringTokensPerReplica; i++ {
+			token := ringHash(replica + "#" + strconv.Itoa(i))
+			// Collisions are very unlikely, the replica that comes first in order keeps the token so that all
+			// the instances build the same ring.
+			if _, ok := r.owners[token]; ok {
+				continue
+			}
+			r.owners[token] = replica
+			r.tokens = append(r.tokens, token)
+		}
+	}
+	sort.Slice(r.tokens, func(i, j int) bool { return r.tokens[i] < r.tokens[j] })
+	return r
+}
+
+// owner returns the replica that owns the key, that is the replica of the first token clockwise from the hash of the key.
+func (r *hashRing) owner(key string) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := ringHash(key)
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= h })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.owners[r.tokens[i]]
+}
+
+func ringHash(key string) uint64 {
+	h := fnv.New64a()
+	// We can ignore err as fnv64 does not return an error
+	// nolint:errcheck,gosec
+	h.Write([]byte(key))
+	// fnv does not spread similar keys well, such as the tokens of a replica, so mix the bits of the sum.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// ruleSharder decides which rules are evaluated by this instance. Rules are sharded by rule group, so that all
+// the rules of a group are evaluated by the same instance.
+type ruleSharder struct {
+	membership ReplicaMembership
+
+	mtx  sync.Mutex
+	self string
+	ring *hashRing
+}
+
+func newRuleSharder(membership ReplicaMembership) *ruleSharder {
+	return &ruleSharder{membership: membership}
+}
+
+// update rebuilds the ring if the replicas have changed since the last update, and reports whether the rules
+// have to be rebalanced because the replicas have changed since the ring was first built. This instance is always
+// part of the ring, even if the membership does not know about it yet. If the membership has no replicas, the ring
+// only has this instance and it evaluates all rules.
+func (s *ruleSharder) update() bool {
+	self := s.membership.Self()
+	replicas := uniqueSorted(append([]string{self}, s.membership.Replicas()...))
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.ring != nil && s.self == self && equalStrings(s.ring.replicas, replicas) {
+		return false
+	}
+	rebalance := s.ring != nil
+	s.self = self
+	s.ring = newHashRing(replicas)
+	return rebalance
+}
+
+// owns reports whether this instance evaluates the rules of the group.
+func (s *ruleSharder) owns(key ngmodels.AlertRuleGroupKey) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.ring == nil || len(s.ring.replicas) <= 1 {
+		return true
+	}
+	return s.ring.owner(ruleGroupShardKey(key)) == s.self
+}
+
+// replicas returns the number of replicas in the ring.
+func (s *ruleSharder) replicas() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.ring == nil {
+		return 0
+	}
+	return len(s.ring.replicas)
+}
+
+func ruleGroupShardKey(key ngmodels.AlertRuleGroupKey) string {
+	return fmt.Sprintf("%d/%s/%s", key.OrgID, key.NamespaceUID, key.RuleGroup)
+}
+
+func uniqueSorted(values []string) []string {
+	sort.Strings(values)
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || (len(result) > 0 && v == result[len(result)-1]) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleHandoffTimeout is how long an instance keeps sending the firing alerts of a rule that moved to another
+// instance if the new owner does not evaluate them.
+const ruleHandoffTimeout = 5 * time.Minute
+
+// ruleHandoff is a rule that moved to another instance. The previous owner keeps sending the firing alerts of the rule
+// until the new owner has evaluated them, and therefore sent them itself, so that the Alertmanager does not resolve
+// the alerts while the new owner takes over.
+type ruleHandoff struct {
+	rule     *ngmodels.AlertRule
+	since    time.Time
+	lastSent time.Time
+	states   []*state.State
+}
+
+// newRuleHandoff returns the handoff of the firing states of the rule, or nil if none of the states is firing.
+func newRuleHandoff(rule *ngmodels.AlertRule, states []*state.State, now time.Time) *ruleHandoff {
+	var firing []*state.State
+	for _, s := range states {
+		if s.State == eval.Normal || s.State == eval.Pending {
+			continue
+		}
+		firing = append(firing, s)
+	}
+	if len(firing) == 0 {
+		return nil
+	}
+	return &ruleHandoff{rule: rule, since: now, lastSent: now, states: firing}
+}
+
+// processHandoffs sends the firing alerts of the rules that moved to other instances again, until the new owners
+// have evaluated them. The handoffs of rules that are not in unowned, because they were deleted or moved back to this
+// instance, are stopped.
+func (sch *schedule) processHandoffs(ctx context.Context, tick time.Time, unowned map[ngmodels.AlertRuleKey]struct{}) {
+	for key, h := range sch.handoffs {
+		if _, ok := unowned[key]; !ok {
+			delete(sch.handoffs, key)
+			continue
+		}
+		if tick.Sub(h.since) > ruleHandoffTimeout {
+			sch.log.Warn("The new owner of the alert rule did not evaluate it in time, no longer sending its alerts", append(key.LogContext(), "alerts", len(h.states))...)
+			delete(sch.handoffs, key)
+			continue
+		}
+		if tick.Sub(h.lastSent) < sch.stateManager.ResendDelay {
+			continue
+		}
+		stored, err := sch.stateManager.LoadRuleStates(ctx, h.rule)
+		if err != nil {
+			sch.log.Error("Failed to load the state of the alert rule that moved to another instance", append(key.LogContext(), "error", err)...)
+			continue
+		}
+		h.states = notTakenOver(h.states, stored, h.since)
+		if len(h.states) == 0 {
+			sch.log.Debug("The new owner of the alert rule has evaluated it", key.LogContext()...)
+			delete(sch.handoffs, key)
+			continue
+		}
+		alerts := apimodels.PostableAlerts{PostableAlerts: make([]models.PostableAlert, 0, len(h.states))}
+		for _, s := range h.states {
+			resent := *s
+			// keep the alert firing for as long after this send as after the last evaluation.
+			resent.EndsAt = tick.Add(s.EndsAt.Sub(s.LastEvaluationTime))
+			alerts.PostableAlerts = append(alerts.PostableAlerts, *stateToPostableAlert(&resent, sch.appURL))
+		}
+		sch.alertsSender.Send(key, alerts)
+		h.lastSent = tick
+	}
+}
+
+// notTakenOver returns the states that the new owner has not evaluated since the handoff. A state that is no longer
+// in the instance store has been resolved or deleted by the new owner.
+func notTakenOver(states []*state.State, stored map[string]*state.State, since time.Time) []*state.State {
+	result := states[:0]
+	for _, s := range states {
+		current, ok := stored[s.CacheID]
+		if !ok || current.LastEvaluationTime.After(since) {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
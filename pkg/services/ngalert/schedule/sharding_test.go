@@ -0,0 +1,218 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]string, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, fmt.Sprintf("1/folder/group-%d", i))
+	}
+	owners := func(r *hashRing) map[string]string {
+		result := make(map[string]string, len(keys))
+		for _, k := range keys {
+			result[k] = r.owner(k)
+		}
+		return result
+	}
+
+	ring := newHashRing([]string{"a", "b", "c"})
+	before := owners(ring)
+
+	t.Run("should spread keys across all replicas", func(t *testing.T) {
+		count := map[string]int{}
+		for _, o := range before {
+			count[o]++
+		}
+		require.Len(t, count, 3)
+		for replica, c := range count {
+			require.Greaterf(t, c, len(keys)/5, "replica %s owns too few keys", replica)
+		}
+	})
+
+	t.Run("should be the same on every instance", func(t *testing.T) {
+		require.Equal(t, before, owners(newHashRing([]string{"a", "b", "c"})))
+	})
+
+	t.Run("should only move keys to a replica that joins", func(t *testing.T) {
+		after := owners(newHashRing([]string{"a", "b", "c", "d"}))
+		moved := 0
+		for k, o := range after {
+			if o != before[k] {
+				require.Equal(t, "d", o)
+				moved++
+			}
+		}
+		require.Greater(t, moved, len(keys)/8)
+		require.Less(t, moved, len(keys)/2)
+	})
+
+	t.Run("should only move keys of a replica that leaves", func(t *testing.T) {
+		after := owners(newHashRing([]string{"a", "c"}))
+		for k, o := range after {
+			if before[k] != "b" {
+				require.Equal(t, before[k], o)
+			}
+		}
+	})
+
+	t.Run("should have no owner if there are no replicas", func(t *testing.T) {
+		require.Empty(t, newHashRing(nil).owner("key"))
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	membership := newFakeReplicaMembership("a", "a", "b")
+	sharder := newRuleSharder(membership)
+
+	require.False(t, sharder.update(), "building the first ring should not be a rebalance")
+	require.Equal(t, 2, sharder.replicas())
+	require.False(t, sharder.update(), "unchanged replicas should not be a rebalance")
+
+	membership.setReplicas("b", "c", "a")
+	require.True(t, sharder.update())
+	require.Equal(t, 3, sharder.replicas())
+
+	t.Run("should include self even if the membership does not know about it yet", func(t *testing.T) {
+		membership.setReplicas("b", "c")
+		require.False(t, sharder.update())
+		require.Equal(t, 3, sharder.replicas())
+	})
+
+	t.Run("should own all groups if it is the only replica", func(t *testing.T) {
+		membership.setReplicas()
+		require.True(t, sharder.update())
+		for i := 0; i < 100; i++ {
+			require.True(t, sharder.owns(models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: fmt.Sprint(i)}))
+		}
+	})
+}
+
+func TestProcessTicks_Sharding(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sent := map[models.AlertRuleKey][]definitions.PostableAlerts{}
+	sender := &AlertsSenderMock{}
+	sender.EXPECT().Send(mock.Anything, mock.Anything).Run(func(key models.AlertRuleKey, alerts definitions.PostableAlerts) {
+		sent[key] = append(sent[key], alerts)
+	}).Return()
+	sched := setupScheduler(t, ruleStore, instanceStore, nil, sender, nil)
+	membership := newFakeReplicaMembership("a", "a", "b")
+	sched.sharder = newRuleSharder(membership)
+
+	// find a group owned by each replica
+	ring := newHashRing([]string{"a", "b"})
+	groups := map[string]string{}
+	for i := 0; len(groups) < 2; i++ {
+		group := fmt.Sprintf("group-%d", i)
+		owner := ring.owner(ruleGroupShardKey(models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "folder", RuleGroup: group}))
+		if _, ok := groups[owner]; !ok {
+			groups[owner] = group
+		}
+	}
+	gen := models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Second), withQueryForState(t, eval.Normal))
+	owned, other := gen(), gen()
+	owned.NamespaceUID, owned.RuleGroup = "folder", groups["a"]
+	other.NamespaceUID, other.RuleGroup = "folder", groups["b"]
+	ruleStore.PutRule(ctx, owned, other)
+
+	// the states of all rules are loaded at startup
+	sched.stateManager.Put([]*state.State{
+		{OrgID: other.OrgID, AlertRuleUID: other.UID, CacheID: "1", State: eval.Alerting},
+	})
+
+	tick := time.Time{}
+
+	t.Run("should only evaluate the rules owned by this instance", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 1)
+		require.Equal(t, owned.UID, scheduled[0].rule.UID)
+		require.Empty(t, stopped)
+		require.False(t, sched.registry.exists(other.GetKey()))
+		require.Empty(t, sched.stateManager.GetStatesForRuleUID(other.OrgID, other.UID), "states of rules owned by other instances should be removed from the cache")
+		require.Empty(t, sched.handoffs, "alerts of rules that this instance did not evaluate should not be handed off")
+	})
+
+	t.Run("should take over the rules of a replica that leaves and load their states", func(t *testing.T) {
+		membership.setReplicas("a")
+		tick = tick.Add(time.Second)
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 2)
+		require.Empty(t, stopped)
+		require.Contains(t, instanceStore.RecordedOps, models.ListAlertInstancesQuery{RuleOrgID: other.OrgID, RuleUID: other.UID})
+	})
+
+	lbls := models.InstanceLabels{"alertname": other.Title}
+	cacheID, err := lbls.StringKey()
+	require.NoError(t, err)
+
+	t.Run("should stop the rules of a replica that joins without deleting their state", func(t *testing.T) {
+		instanceStore.RecordedOps = nil
+		sched.stateManager.Put([]*state.State{{
+			OrgID:              other.OrgID,
+			AlertRuleUID:       other.UID,
+			CacheID:            cacheID,
+			Labels:             map[string]string(lbls),
+			State:              eval.Alerting,
+			LastEvaluationTime: tick,
+			EndsAt:             tick.Add(time.Minute),
+		}})
+		membership.setReplicas("a", "b")
+		tick = tick.Add(time.Second)
+		scheduled, stopped := sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, scheduled, 1)
+		require.Equal(t, owned.UID, scheduled[0].rule.UID)
+		require.Empty(t, stopped, "rules owned by other instances should not be considered deleted")
+		require.False(t, sched.registry.exists(other.GetKey()))
+		for _, op := range instanceStore.RecordedOps {
+			require.IsType(t, models.ListAlertInstancesQuery{}, op, "the state of the moved rule should not be deleted")
+		}
+		require.Contains(t, sched.handoffs, other.GetKey(), "the firing alerts of the moved rule should be handed off")
+	})
+
+	handoffAt := tick
+
+	t.Run("should keep sending the firing alerts of a rule that moved until the new owner evaluates them", func(t *testing.T) {
+		instanceStore.Instances = []*models.AlertInstance{{
+			AlertInstanceKey: models.AlertInstanceKey{RuleOrgID: other.OrgID, RuleUID: other.UID},
+			Labels:           lbls,
+			CurrentState:     models.InstanceStateFiring,
+			LastEvalTime:     handoffAt.Add(-time.Second),
+		}}
+		tick = tick.Add(sched.stateManager.ResendDelay)
+		sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, sent[other.GetKey()], 1)
+		alerts := sent[other.GetKey()][0].PostableAlerts
+		require.Len(t, alerts, 1)
+		require.Equal(t, tick.Add(time.Minute), time.Time(alerts[0].EndsAt), "the alert should be kept firing after the send")
+	})
+
+	t.Run("should stop sending the alerts once the new owner has evaluated them", func(t *testing.T) {
+		instanceStore.Instances[0].LastEvalTime = tick
+		tick = tick.Add(sched.stateManager.ResendDelay)
+		sched.processTick(ctx, dispatcherGroup, tick)
+
+		require.Len(t, sent[other.GetKey()], 1)
+		require.NotContains(t, sched.handoffs, other.GetKey())
+	})
+}
@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/template"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// templateQueryKey identifies a query of the query function of the templates, and the time at which it is run.
+type templateQueryKey struct {
+	datasourceUID string
+	model         string
+	timeRange     ngmodels.RelativeTimeRange
+	ts            time.Time
+}
+
+type templateQueryResult struct {
+	values []template.Value
+	err    error
+}
+
+// templateQueryFunc returns the function that runs the queries of the query function in the templates of the labels
+// and annotations of a rule. The queries are run as the scheduler user with the same evaluator as the rule itself,
+// so they have the same timeout and data source headers.
+//
+// The scheduler user can query any data source, so only the data sources of the queries of the rule can be
+// queried, as the user that saved the rule was authorized to query them. Server-side expressions are allowed too.
+// The function is created for every evaluation, and the result of each query is reused for all the alerts of
+// the evaluation.
+func (sch *schedule) templateQueryFunc(schedulerUser *user.SignedInUser, rule *ngmodels.AlertRule) template.QueryFunc {
+	allowed := make(map[string]struct{}, len(rule.Data))
+	for _, q := range rule.Data {
+		allowed[q.DatasourceUID] = struct{}{}
+	}
+	var mtx sync.Mutex
+	results := make(map[templateQueryKey]templateQueryResult)
+	return func(ctx context.Context, q ngmodels.AlertQuery, ts time.Time) ([]template.Value, error) {
+		if _, ok := allowed[q.DatasourceUID]; !ok && !expr.IsDataSource(q.DatasourceUID) {
+			return nil, fmt.Errorf("data source %s is not queried by the alert rule", q.DatasourceUID)
+		}
+		key := templateQueryKey{
+			datasourceUID: q.DatasourceUID,
+			model:         string(q.Model),
+			timeRange:     q.RelativeTimeRange,
+			ts:            ts,
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		if res, ok := results[key]; ok {
+			return res.values, res.err
+		}
+		values, err := sch.runTemplateQuery(ctx, schedulerUser, q, ts)
+		results[key] = templateQueryResult{values: values, err: err}
+		return values, err
+	}
+}
+
+func (sch *schedule) runTemplateQuery(ctx context.Context, schedulerUser *user.SignedInUser, q ngmodels.AlertQuery, ts time.Time) ([]template.Value, error) {
+	condition := ngmodels.Condition{
+		Condition: q.RefID,
+		Data:      []ngmodels.AlertQuery{q},
+	}
+	queryEval, err := sch.evaluatorFactory.Create(eval.Context(ctx, schedulerUser), condition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+	resp, err := queryEval.EvaluateRaw(ctx, ts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	res, ok := resp.Responses[q.RefID]
+	if !ok {
+		return nil, nil
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("failed to run query: %w", res.Error)
+	}
+	points, err := writer.PointsFromFrames(res.Frames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result of query: %w", err)
+	}
+	values := make([]template.Value, 0, len(points))
+	for _, p := range points {
+		values = append(values, template.Value{Labels: template.Labels(p.Labels), Value: p.Value})
+	}
+	return values, nil
+}
@@ -0,0 +1,64 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/template"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestTemplateQueryFunc(t *testing.T) {
+	evaluator := eval_mocks.NewConditionEvaluatorMock(t)
+	evaluator.EXPECT().EvaluateRaw(mock.Anything, mock.Anything).Return(&backend.QueryDataResponse{
+		Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("", data.NewField("Value", data.Labels{"pod": "a"}, []float64{5}))}},
+		},
+	}, nil)
+	sch := &schedule{evaluatorFactory: eval_mocks.NewEvaluatorFactory(evaluator)}
+
+	rule := models.AlertRuleGen()()
+	rule.Data = []models.AlertQuery{{RefID: "A", DatasourceUID: "allowed"}}
+	query := func(datasourceUID string) models.AlertQuery {
+		return models.AlertQuery{RefID: "A", DatasourceUID: datasourceUID, Model: json.RawMessage(`{"expr": "up"}`)}
+	}
+	now := time.Now()
+
+	t.Run("should run queries of the data sources of the rule", func(t *testing.T) {
+		f := sch.templateQueryFunc(&user.SignedInUser{}, rule)
+		values, err := f(context.Background(), query("allowed"), now)
+		require.NoError(t, err)
+		require.Equal(t, []template.Value{{Labels: template.Labels{"pod": "a"}, Value: 5}}, values)
+	})
+
+	t.Run("should reject queries of other data sources", func(t *testing.T) {
+		evaluator.Calls = nil
+		f := sch.templateQueryFunc(&user.SignedInUser{}, rule)
+		_, err := f(context.Background(), query("other"), now)
+		require.ErrorContains(t, err, "data source other is not queried by the alert rule")
+		evaluator.AssertNotCalled(t, "EvaluateRaw", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should run each query once per evaluation time", func(t *testing.T) {
+		evaluator.Calls = nil
+		f := sch.templateQueryFunc(&user.SignedInUser{}, rule)
+		for i := 0; i < 3; i++ {
+			_, err := f(context.Background(), query("allowed"), now)
+			require.NoError(t, err)
+		}
+		evaluator.AssertNumberOfCalls(t, "EvaluateRaw", 1)
+
+		_, err := f(context.Background(), query("allowed"), now.Add(time.Second))
+		require.NoError(t, err)
+		evaluator.AssertNumberOfCalls(t, "EvaluateRaw", 2)
+	})
+}
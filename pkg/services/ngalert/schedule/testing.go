@@ -2,10 +2,12 @@ package schedule
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 )
 
 // waitForTimeChannel blocks the execution until either the channel ch has some data or a timeout of 10 second expires.
@@ -80,3 +82,49 @@ func (f *fakeRulesStore) DeleteRule(rules ...*models.AlertRule) {
 func (f *fakeRulesStore) getNamespaceTitle(uid string) string {
 	return "TEST-FOLDER-" + uid
 }
+
+type recordedWrite struct {
+	orgID  int64
+	name   string
+	t      time.Time
+	points []writer.Point
+}
+
+// fakeRecordingWriter records the writes of recording rules.
+type fakeRecordingWriter struct {
+	mtx    sync.Mutex
+	writes []recordedWrite
+}
+
+func (w *fakeRecordingWriter) Write(_ context.Context, orgID int64, name string, t time.Time, points []writer.Point) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.writes = append(w.writes, recordedWrite{orgID: orgID, name: name, t: t, points: points})
+	return nil
+}
+
+type fakeReplicaMembership struct {
+	mtx      sync.Mutex
+	self     string
+	replicas []string
+}
+
+func newFakeReplicaMembership(self string, replicas ...string) *fakeReplicaMembership {
+	return &fakeReplicaMembership{self: self, replicas: replicas}
+}
+
+func (f *fakeReplicaMembership) Self() string {
+	return f.self
+}
+
+func (f *fakeReplicaMembership) Replicas() []string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.replicas
+}
+
+func (f *fakeReplicaMembership) setReplicas(replicas ...string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.replicas = replicas
+}
@@ -113,6 +113,7 @@ func (rs *ruleStates) getOrCreate(ctx context.Context, log log.Logger, alertRule
 		}
 		state.Annotations = annotations
 		state.Values = values
+		state.ResultLabels = result.Instance.Copy()
 		rs.states[id] = state
 		return state
 	}
@@ -124,6 +125,7 @@ func (rs *ruleStates) getOrCreate(ctx context.Context, log log.Logger, alertRule
 		OrgID:              alertRule.OrgID,
 		CacheID:            id,
 		Labels:             lbs,
+		ResultLabels:       result.Instance.Copy(),
 		Annotations:        annotations,
 		EvaluationDuration: result.EvaluationDuration,
 		Values:             values,
@@ -183,6 +185,15 @@ func (c *cache) setAllStates(newStates map[int64]map[string]*ruleStates) {
 	c.states = newStates
 }
 
+func (c *cache) setRuleStates(orgID int64, alertRuleUID string, states *ruleStates) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[orgID]; !ok {
+		c.states[orgID] = make(map[string]*ruleStates)
+	}
+	c.states[orgID][alertRuleUID] = states
+}
+
 func (c *cache) set(entry *State) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
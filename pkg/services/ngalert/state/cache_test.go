@@ -48,6 +48,7 @@ func Test_getOrCreate(t *testing.T) {
 		for key, expected := range result.Instance {
 			assert.Equal(t, expected, state.Labels[key])
 		}
+		assert.Equal(t, result.Instance, state.ResultLabels)
 	})
 	t.Run("extra labels should take precedence over rule and result labels", func(t *testing.T) {
 		rule := generateRule()
@@ -82,6 +83,7 @@ func Test_getOrCreate(t *testing.T) {
 		for key, expected := range rule.Labels {
 			require.Equal(t, expected, state.Labels[key])
 		}
+		require.Equal(t, result.Instance, state.ResultLabels)
 	})
 	t.Run("rule labels should be able to be expanded with result and extra labels", func(t *testing.T) {
 		result := eval.Result{
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -31,8 +32,19 @@ type AnnotationBackend struct {
 
 type RuleStore interface {
 	GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAlertRuleByUIDQuery) error
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error
 }
 
+const (
+	// annotationQueryLimit is the maximum number of annotations returned by a query without a limit.
+	annotationQueryLimit = 5000
+	// annotationQueryBatchSize is the number of annotations read at once when the annotations are filtered by labels.
+	annotationQueryBatchSize = 1000
+	// annotationQueryScanLimit is the maximum number of annotations read by a single query, as the annotation store
+	// cannot filter annotations by labels.
+	annotationQueryScanLimit = 50000
+)
+
 func NewAnnotationBackend(annotations annotations.Repository, dashboards dashboards.DashboardService, rules RuleStore) *AnnotationBackend {
 	return &AnnotationBackend{
 		annotations: annotations,
@@ -62,32 +74,31 @@ func (h *AnnotationBackend) QueryStates(ctx context.Context, query ngmodels.Hist
 		return nil, fmt.Errorf("ruleUID is required to query annotations")
 	}
 
-	if query.Labels != nil {
-		logger.Warn("Annotation state history backend does not support label queries, ignoring that filter")
-	}
-
-	rq := ngmodels.GetAlertRuleByUIDQuery{
-		UID:   query.RuleUID,
-		OrgID: query.OrgID,
-	}
-	err := h.rules.GetAlertRuleByUID(ctx, &rq)
+	rule, err := h.getRule(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to look up the requested rule")
+		return nil, err
 	}
-	if rq.Result == nil {
-		return nil, fmt.Errorf("no such rule exists")
+	matchers, err := queryMatchers(query)
+	if err != nil {
+		return nil, err
 	}
 
 	q := annotations.ItemQuery{
-		AlertID:      rq.Result.ID,
+		AlertID:      rule.ID,
 		OrgID:        query.OrgID,
-		From:         query.From.Unix(),
-		To:           query.To.Unix(),
+		From:         unixMilli(query.From),
+		To:           unixMilli(query.To),
 		SignedInUser: query.SignedInUser,
 	}
-	items, err := h.annotations.Find(ctx, &q)
+	var match func(*annotations.ItemDTO) bool
+	if len(matchers) > 0 {
+		match = func(item *annotations.ItemDTO) bool {
+			return matchesLabels(annotationLabels(rule, item), matchers)
+		}
+	}
+	items, truncated, err := h.findAnnotations(ctx, q, query, annotationQueryLimit, match)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query annotations for state history: %w", err)
+		return nil, err
 	}
 
 	frame := data.NewFrame("states")
@@ -124,7 +135,7 @@ func (h *AnnotationBackend) QueryStates(ctx context.Context, query ngmodels.Hist
 			logger.Error("Annotation service gave an annotation with unparseable data, skipping", "id", item.ID, "err", err)
 			continue
 		}
-		times = append(times, time.Unix(item.Time, 0))
+		times = append(times, time.UnixMilli(item.Time))
 		texts = append(texts, item.Text)
 		prevStates = append(prevStates, item.PrevState)
 		nextStates = append(nextStates, item.NewState)
@@ -137,9 +148,250 @@ func (h *AnnotationBackend) QueryStates(ctx context.Context, query ngmodels.Hist
 	frame.Fields = append(frame.Fields, data.NewField("next", lbls, nextStates))
 	frame.Fields = append(frame.Fields, data.NewField("data", lbls, values))
 
+	if truncated {
+		return setTruncated(frame, fmt.Sprintf("The result is limited to the newest %d state transitions that match the query, and label filters are applied to at most the newest %d state transitions.", annotationQueryLimit, annotationQueryScanLimit)), nil
+	}
 	return frame, nil
 }
 
+// QueryTransitionCounts counts the state transitions that match the query per rule and hour. If the query has no rule,
+// the transitions of all rules of the organization are counted.
+func (h *AnnotationBackend) QueryTransitionCounts(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
+	matchers, err := queryMatchers(query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = withDefaultRange(query)
+	q := annotations.ItemQuery{
+		OrgID:        query.OrgID,
+		From:         query.From.UnixMilli(),
+		To:           query.To.UnixMilli(),
+		SignedInUser: query.SignedInUser,
+	}
+	rules := make(map[int64]*ngmodels.AlertRule)
+	if query.RuleUID != "" {
+		rule, err := h.getRule(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		rules[rule.ID] = rule
+		q.AlertID = rule.ID
+	} else {
+		rq := ngmodels.ListAlertRulesQuery{OrgID: query.OrgID}
+		if err := h.rules.ListAlertRules(ctx, &rq); err != nil {
+			return nil, fmt.Errorf("failed to look up the alert rules: %w", err)
+		}
+		for _, rule := range rq.Result {
+			rules[rule.ID] = rule
+		}
+		q.Type = "alert"
+	}
+
+	// The annotation store cannot count annotations, so all annotations in the time range are read.
+	query.Limit, query.Offset = 0, 0
+	items, truncated, err := h.findAnnotations(ctx, q, query, 0, func(item *annotations.ItemDTO) bool {
+		rule, ok := rules[item.AlertID]
+		return ok && (len(matchers) == 0 || matchesLabels(annotationLabels(rule, item), matchers))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counter := transitionCounter{}
+	for _, item := range items {
+		counter.add(rules[item.AlertID].UID, time.UnixMilli(item.Time), 1)
+	}
+	if truncated {
+		return setTruncated(counter.frame(), fmt.Sprintf("Only the newest %d state transitions are counted, as the annotation store cannot count state transitions.", annotationQueryScanLimit)), nil
+	}
+	return counter.frame(), nil
+}
+
+// findAnnotations returns the annotations that match the query, ordered by time, and whether the result is truncated.
+// If the history query has a limit, the page of the query is returned, counted from the oldest annotation in the time
+// range. Otherwise, the newest annotations are returned, up to the given limit if it is positive.
+//
+// The rule, time range and state filters are applied by the annotation store. If match is not nil, the annotations
+// are read in batches and filtered by match before the page or the limit is applied, and at most
+// annotationQueryScanLimit annotations are read.
+func (h *AnnotationBackend) findAnnotations(ctx context.Context, q annotations.ItemQuery, query ngmodels.HistoryQuery, limit int, match func(*annotations.ItemDTO) bool) ([]*annotations.ItemDTO, bool, error) {
+	logger := h.log.FromContext(ctx)
+	q.PrevState, q.NewState = query.PreviousState, query.CurrentState
+	paged := query.Limit > 0
+	if paged {
+		q.Ascending = true
+		limit = query.Offset + query.Limit
+	}
+
+	if match == nil && limit > 0 {
+		// All filters are applied by the annotation store, so the annotations are read at once. One more annotation
+		// than the limit is read to know whether the result is truncated.
+		q.Limit = int64(limit + 1)
+		if paged {
+			q.Offset, q.Limit = int64(query.Offset), int64(query.Limit)
+		}
+		items, err := h.annotations.Find(ctx, &q)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to query annotations for state history: %w", err)
+		}
+		sortAnnotations(items, true)
+		if !paged && len(items) > limit {
+			return items[len(items)-limit:], true, nil
+		}
+		return items, false, nil
+	}
+
+	// The annotations are collected from the start of the page, or from the newest.
+	var result []*annotations.ItemDTO
+	q.Limit = annotationQueryBatchSize
+	scanned := 0
+	truncated := false
+	for {
+		batch, err := h.annotations.Find(ctx, &q)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to query annotations for state history: %w", err)
+		}
+		sortAnnotations(batch, paged)
+		full := false
+		for i := 0; i < len(batch) && !full; i++ {
+			if match != nil && !match(batch[i]) {
+				continue
+			}
+			result = append(result, batch[i])
+			full = limit > 0 && len(result) > limit
+		}
+		if full {
+			result = result[:limit]
+			truncated = !paged
+			break
+		}
+		scanned += len(batch)
+		if len(batch) < int(q.Limit) {
+			break
+		}
+		if scanned >= annotationQueryScanLimit {
+			logger.Warn("State history query reached the maximum number of scanned annotations, the result is truncated", "limit", annotationQueryScanLimit)
+			truncated = true
+			break
+		}
+		q.Offset += int64(len(batch))
+	}
+
+	if paged {
+		if len(result) < query.Offset {
+			return nil, truncated, nil
+		}
+		return result[query.Offset:], truncated, nil
+	}
+	sortAnnotations(result, true)
+	return result, truncated, nil
+}
+
+// sortAnnotations sorts annotations by time, as the annotation store does not order the annotations it returns.
+func sortAnnotations(items []*annotations.ItemDTO, ascending bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if ascending {
+			return items[i].Time < items[j].Time
+		}
+		return items[i].Time > items[j].Time
+	})
+}
+
+func (h *AnnotationBackend) getRule(ctx context.Context, query ngmodels.HistoryQuery) (*ngmodels.AlertRule, error) {
+	rq := ngmodels.GetAlertRuleByUIDQuery{
+		UID:   query.RuleUID,
+		OrgID: query.OrgID,
+	}
+	err := h.rules.GetAlertRuleByUID(ctx, &rq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the requested rule")
+	}
+	if rq.Result == nil {
+		return nil, fmt.Errorf("no such rule exists")
+	}
+	return rq.Result, nil
+}
+
+// annotationLabelStart matches the start of a label in the text of an annotation.
+var annotationLabelStart = regexp.MustCompile(`(?:^|, )([a-zA-Z_][a-zA-Z0-9_]*)=`)
+
+// annotationLabels returns the labels of the rule and of the alert instance of an annotation. The labels of the
+// instance are stored in the data of the annotation. Older annotations only have them in the text, which is formatted
+// as "<title> {<labels>} - <value>", so they are parsed from it.
+func annotationLabels(rule *ngmodels.AlertRule, item *annotations.ItemDTO) map[string]string {
+	lbls := map[string]string{
+		StateHistoryLabelKey: StateHistoryLabelValue,
+		OrgIDLabel:           fmt.Sprint(rule.OrgID),
+		RuleUIDLabel:         rule.UID,
+		GroupLabel:           rule.RuleGroup,
+		FolderUIDLabel:       rule.NamespaceUID,
+	}
+	if item.Data != nil {
+		if stored, err := item.Data.Get("labels").Map(); err == nil {
+			for name, value := range stored {
+				if v, ok := value.(string); ok {
+					lbls[name] = v
+				}
+			}
+			return lbls
+		}
+	}
+	for name, value := range parseAnnotationLabels(rule.Title, item.Text) {
+		lbls[name] = value
+	}
+	return lbls
+}
+
+// parseAnnotationLabels parses the labels from the text of an annotation. Labels are formatted as "name=value" pairs
+// separated by ", ", in the order of their names, so a ", name=" in a value is only taken as the start of the next
+// label if the name comes after the name of the previous label.
+func parseAnnotationLabels(title, text string) map[string]string {
+	prefix := title + " {"
+	if !strings.HasPrefix(text, prefix) {
+		return nil
+	}
+	text = strings.TrimPrefix(text, prefix)
+	end := strings.LastIndex(text, "} - ")
+	if end < 0 {
+		return nil
+	}
+	text = text[:end]
+
+	type labelStart struct {
+		name         string
+		start, value int
+	}
+	var starts []labelStart
+	for _, m := range annotationLabelStart.FindAllStringSubmatchIndex(text, -1) {
+		name := text[m[2]:m[3]]
+		if len(starts) == 0 && m[0] != 0 {
+			return nil
+		}
+		if len(starts) > 0 && (name <= starts[len(starts)-1].name || m[0] < starts[len(starts)-1].value) {
+			continue
+		}
+		starts = append(starts, labelStart{name: name, start: m[0], value: m[1]})
+	}
+	lbls := make(map[string]string, len(starts))
+	for i, s := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1].start
+		}
+		lbls[s.name] = text[s.value:end]
+	}
+	return lbls
+}
+
+// unixMilli returns the time in milliseconds, or 0 if the time is not set.
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
 func buildAnnotations(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []annotations.Item {
 	items := make([]annotations.Item, 0, len(states))
 	for _, state := range states {
@@ -219,6 +471,9 @@ func buildAnnotationTextAndData(rule history_model.RuleMeta, currentState *state
 	}
 
 	labels := removePrivateLabels(currentState.Labels)
+	if len(labels) > 0 {
+		jsonData.Set("labels", labels)
+	}
 	return fmt.Sprintf("%s {%s} - %s", rule.Title, labels.String(), value), jsonData
 }
 
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"math"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -42,6 +45,272 @@ func TestAnnotationHistorian_Integration(t *testing.T) {
 	})
 }
 
+func TestAnnotationHistorian_Query(t *testing.T) {
+	rules := fakes.NewRuleStore(t)
+	ruleA := models.AlertRuleGen(withOrgID(1), withUID("rule-a"), models.WithTitle("Rule A"))()
+	ruleA.ID = 1
+	ruleB := models.AlertRuleGen(withOrgID(1), withUID("rule-b"), models.WithTitle("Rule B"))()
+	ruleB.ID = 2
+	rules.Rules[1] = []*models.AlertRule{ruleA, ruleB}
+
+	hour := time.Now().Add(-2 * time.Hour).Truncate(time.Hour)
+	repo := &fakeAnnotationsFinder{items: []*annotations.ItemDTO{
+		{ID: 1, AlertID: 1, Time: hour.Add(time.Minute).UnixMilli(), PrevState: "Normal", NewState: "Alerting", Text: "Rule A {pod=a, team=x} - A=1.000000"},
+		{ID: 2, AlertID: 1, Time: hour.Add(2 * time.Minute).UnixMilli(), PrevState: "Normal", NewState: "Alerting", Text: "Rule A {pod=b, team=y} - A=1.000000"},
+		{ID: 3, AlertID: 1, Time: hour.Add(3 * time.Minute).UnixMilli(), PrevState: "Alerting", NewState: "Normal (NoData)", Text: "Rule A {pod=a, team=x} - No data"},
+		{ID: 4, AlertID: 1, Time: hour.Add(time.Hour).UnixMilli(), PrevState: "Normal", NewState: "Alerting", Text: "Rule A {pod=a, team=x} - A=1.000000"},
+		{ID: 5, AlertID: 2, Time: hour.Add(time.Minute).UnixMilli(), PrevState: "Normal", NewState: "Alerting", Text: "Rule B {pod=a} - A=1.000000"},
+		{ID: 6, AlertID: 3, Time: hour.Add(time.Minute).UnixMilli(), PrevState: "Normal", NewState: "Alerting", Text: "Deleted {} - A=1.000000"},
+	}}
+	sut := NewAnnotationBackend(repo, &dashboards.FakeDashboardService{}, rules)
+
+	t.Run("states are filtered by labels and states, and paginated", func(t *testing.T) {
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{
+			OrgID:         1,
+			RuleUID:       "rule-a",
+			Matchers:      labels.Matchers{mustMatcher(t, labels.MatchEqual, "team", "x")},
+			PreviousState: "Normal",
+			Limit:         1,
+			Offset:        1,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, int64(1), repo.query.AlertID)
+		require.Equal(t, "Normal", repo.query.PrevState)
+		require.True(t, repo.query.Ascending)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, time.UnixMilli(hour.Add(time.Hour).UnixMilli()), frame.Fields[0].At(0))
+		require.Nil(t, frame.Meta)
+
+		frame, err = sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "rule-a", CurrentState: "Normal"})
+
+		require.NoError(t, err)
+		require.Equal(t, "Normal", repo.query.NewState)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "Normal (NoData)", frame.Fields[3].At(0))
+	})
+
+	t.Run("pages without label filters are queried from the annotation store", func(t *testing.T) {
+		repo.queries = 0
+
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "rule-a", PreviousState: "Normal", Limit: 1, Offset: 1})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, repo.queries)
+		require.Equal(t, int64(1), repo.query.Offset)
+		require.Equal(t, int64(1), repo.query.Limit)
+		require.True(t, repo.query.Ascending)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, time.UnixMilli(hour.Add(2*time.Minute).UnixMilli()), frame.Fields[0].At(0))
+	})
+
+	t.Run("transitions of all rules are counted per rule and hour", func(t *testing.T) {
+		frame, err := sut.QueryTransitionCounts(context.Background(), models.HistoryQuery{
+			OrgID:        1,
+			CurrentState: "Alerting",
+			Matchers:     labels.Matchers{mustMatcher(t, labels.MatchRegexp, "pod", "a|b")},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, "alert", repo.query.Type)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, hour.UTC(), frame.Fields[0].At(0))
+		require.Equal(t, "rule-a", frame.Fields[1].At(0))
+		require.Equal(t, int64(2), frame.Fields[2].At(0))
+		require.Equal(t, hour.UTC(), frame.Fields[0].At(1))
+		require.Equal(t, "rule-b", frame.Fields[1].At(1))
+		require.Equal(t, int64(1), frame.Fields[2].At(1))
+		require.Equal(t, hour.Add(time.Hour).UTC(), frame.Fields[0].At(2))
+		require.Equal(t, "rule-a", frame.Fields[1].At(2))
+		require.Equal(t, int64(1), frame.Fields[2].At(2))
+	})
+}
+
+func TestAnnotationHistorian_QueryTruncated(t *testing.T) {
+	rules := fakes.NewRuleStore(t)
+	rule := models.AlertRuleGen(withOrgID(1), withUID("my-rule"), models.WithTitle("My rule"))()
+	rule.ID = 1
+	rules.Rules[1] = []*models.AlertRule{rule}
+	start := time.Now().Add(-time.Hour)
+	repo := &fakeAnnotationsFinder{}
+	for i := 0; i <= annotationQueryLimit; i++ {
+		repo.items = append(repo.items, &annotations.ItemDTO{
+			ID:        int64(i + 1),
+			AlertID:   1,
+			Time:      start.Add(time.Duration(i) * time.Millisecond).UnixMilli(),
+			PrevState: "Normal",
+			NewState:  "Alerting",
+			Text:      "My rule {pod=a} - A=1.000000",
+		})
+	}
+	sut := NewAnnotationBackend(repo, &dashboards.FakeDashboardService{}, rules)
+
+	t.Run("without label filters", func(t *testing.T) {
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "my-rule"})
+
+		require.NoError(t, err)
+		require.Equal(t, annotationQueryLimit, frame.Rows())
+		require.Equal(t, time.UnixMilli(start.Add(time.Millisecond).UnixMilli()), frame.Fields[0].At(0))
+		require.Equal(t, true, frame.Meta.Custom.(map[string]interface{})["truncated"])
+	})
+
+	t.Run("with label filters", func(t *testing.T) {
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{
+			OrgID:    1,
+			RuleUID:  "my-rule",
+			Matchers: labels.Matchers{mustMatcher(t, labels.MatchEqual, "pod", "a")},
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, annotationQueryLimit, frame.Rows())
+		require.Equal(t, time.UnixMilli(start.Add(time.Millisecond).UnixMilli()), frame.Fields[0].At(0))
+		require.Equal(t, true, frame.Meta.Custom.(map[string]interface{})["truncated"])
+	})
+
+	t.Run("paged", func(t *testing.T) {
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: "my-rule", Limit: 10})
+
+		require.NoError(t, err)
+		require.Equal(t, 10, frame.Rows())
+		require.Equal(t, time.UnixMilli(start.UnixMilli()), frame.Fields[0].At(0))
+		require.Nil(t, frame.Meta)
+	})
+}
+
+func TestAnnotationLabels(t *testing.T) {
+	rule := models.AlertRuleGen(withOrgID(1), withUID("my-rule"), models.WithTitle("My {rule}"))()
+	ruleLabels := map[string]string{
+		StateHistoryLabelKey: StateHistoryLabelValue,
+		OrgIDLabel:           "1",
+		RuleUIDLabel:         "my-rule",
+		GroupLabel:           rule.RuleGroup,
+		FolderUIDLabel:       rule.NamespaceUID,
+	}
+	withLabels := func(lbls map[string]string) map[string]string {
+		result := make(map[string]string, len(ruleLabels)+len(lbls))
+		for k, v := range ruleLabels {
+			result[k] = v
+		}
+		for k, v := range lbls {
+			result[k] = v
+		}
+		return result
+	}
+
+	t.Run("labels are read from the data", func(t *testing.T) {
+		_, d := buildAnnotationTextAndData(history_model.RuleMeta{Title: rule.Title}, &state.State{
+			State:  eval.NoData,
+			Labels: data.Labels{"a": "b, c=d", "e": "f} - g", "__private__": "x"},
+		})
+		// The data is read back from the annotation store.
+		b, err := d.MarshalJSON()
+		require.NoError(t, err)
+		stored, err := simplejson.NewJson(b)
+		require.NoError(t, err)
+
+		lbls := annotationLabels(rule, &annotations.ItemDTO{Text: "ignored", Data: stored})
+
+		require.Equal(t, withLabels(map[string]string{"a": "b, c=d", "e": "f} - g"}), lbls)
+	})
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected map[string]string
+	}{
+		{
+			name:     "labels are parsed from the text",
+			text:     "My {rule} {a=b, c=d} - No data",
+			expected: map[string]string{"a": "b", "c": "d"},
+		},
+		{
+			name:     "values can contain equal signs",
+			text:     "My {rule} {a=b, c=d=e} - No data",
+			expected: map[string]string{"a": "b", "c": "d=e"},
+		},
+		{
+			name:     "values can contain separators",
+			text:     "My {rule} {a=b, c, d, e=f} - A=1.000000",
+			expected: map[string]string{"a": "b, c, d", "e": "f"},
+		},
+		{
+			name:     "values can contain separators that are not followed by the next label",
+			text:     "My {rule} {b=x, a=y, c=z} - A=1.000000",
+			expected: map[string]string{"b": "x, a=y", "c": "z"},
+		},
+		{
+			name:     "text without labels",
+			text:     "My {rule} {} - No data",
+			expected: map[string]string{},
+		},
+		{
+			name:     "text of another format",
+			text:     "Something else",
+			expected: map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lbls := annotationLabels(rule, &annotations.ItemDTO{Text: tc.text, Data: simplejson.New()})
+
+			require.Equal(t, withLabels(tc.expected), lbls)
+		})
+	}
+}
+
+// fakeAnnotationsFinder is an annotations.Repository that finds alert annotations by rule and time.
+type fakeAnnotationsFinder struct {
+	annotations.Repository
+	items   []*annotations.ItemDTO
+	query   annotations.ItemQuery
+	queries int
+}
+
+func (f *fakeAnnotationsFinder) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	f.query = *query
+	f.queries++
+	var result []*annotations.ItemDTO
+	for _, item := range f.items {
+		if query.AlertID != 0 && item.AlertID != query.AlertID {
+			continue
+		}
+		if query.Type == "alert" && item.AlertID == 0 {
+			continue
+		}
+		if query.From > 0 && query.To > 0 && (item.Time < query.From || item.Time > query.To) {
+			continue
+		}
+		if !matchesState(query.PrevState, item.PrevState) || !matchesState(query.NewState, item.NewState) {
+			continue
+		}
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if query.Ascending {
+			return result[i].Time < result[j].Time
+		}
+		return result[i].Time > result[j].Time
+	})
+	if query.Offset > 0 {
+		if int(query.Offset) > len(result) {
+			return nil, nil
+		}
+		result = result[query.Offset:]
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	if int(limit) < len(result) {
+		result = result[:limit]
+	}
+	// The annotation store does not order the annotations it returns.
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID%2 < result[j].ID%2
+	})
+	return result, nil
+}
+
 func createTestAnnotationBackendSut(t *testing.T) *AnnotationBackend {
 	t.Helper()
 	fakeAnnoRepo := annotationstest.NewFakeAnnotationsRepo()
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"regexp"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
 	"github.com/weaveworks/common/http/client"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -37,10 +39,20 @@ const (
 
 const defaultQueryRange = 6 * time.Hour
 
+// lokiQueryLimit is the maximum number of state transitions returned by a query without a limit.
+const lokiQueryLimit = 5000
+
+const (
+	// Directions of Loki log queries.
+	lokiDirectionForward  = "forward"
+	lokiDirectionBackward = "backward"
+)
+
 type remoteLokiClient interface {
 	ping(context.Context) error
 	push(context.Context, []stream) error
-	rangeQuery(ctx context.Context, selectors []Selector, start, end int64) (queryRes, error)
+	rangeQuery(ctx context.Context, logQL string, start, end, limit int64, direction string) (queryRes, error)
+	metricRangeQuery(ctx context.Context, logQL string, start, end int64, step time.Duration) (metricQueryRes, error)
 }
 
 type RemoteLokiBackend struct {
@@ -79,11 +91,75 @@ func (h *RemoteLokiBackend) RecordStatesAsync(ctx context.Context, rule history_
 }
 
 func (h *RemoteLokiBackend) QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
-	selectors, err := buildSelectors(query)
+	logQL, err := buildLogQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = withDefaultRange(query)
+	// Loki cannot skip lines, so we query all pages up to the requested one and drop the ones before it. Without a
+	// limit, the newest lines are queried, and one more line than the limit to know whether the result is truncated.
+	limit, direction := int64(lokiQueryLimit+1), lokiDirectionBackward
+	if query.Limit > 0 {
+		limit, direction = int64(query.Offset+query.Limit), lokiDirectionForward
+	}
+
+	// Timestamps are expected in RFC3339Nano.
+	res, err := h.client.rangeQuery(ctx, logQL, query.From.UnixNano(), query.To.UnixNano(), limit, direction)
+	if err != nil {
+		return nil, err
+	}
+	if direction == lokiDirectionBackward {
+		// The lines of each stream are returned from the newest, and are merged from the oldest.
+		for _, s := range res.Data.Result {
+			for i, j := 0, len(s.Values)-1; i < j; i, j = i+1, j-1 {
+				s.Values[i], s.Values[j] = s.Values[j], s.Values[i]
+			}
+		}
+	}
+	frame, err := merge(res, query.RuleUID)
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit == 0 && frame.Rows() > lokiQueryLimit {
+		frame = paginate(frame, models.HistoryQuery{Offset: frame.Rows() - lokiQueryLimit})
+		return setTruncated(frame, fmt.Sprintf("The result is limited to the newest %d state transitions that match the query.", lokiQueryLimit)), nil
+	}
+	return paginate(frame, query), nil
+}
+
+// QueryTransitionCounts counts the state transitions that match the query per rule and hour with a metric query.
+func (h *RemoteLokiBackend) QueryTransitionCounts(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	logQL, err := buildLogQuery(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build the provided selectors: %w", err)
+		return nil, err
+	}
+	interval := models.HistoryAggregationInterval
+	logQL = fmt.Sprintf("sum by (%s) (count_over_time(%s [%s]))", RuleUIDLabel, logQL, model.Duration(interval))
+
+	// The count at each step is the number of transitions in the interval before it, so the first step is the end of the
+	// interval the range starts in.
+	query = withDefaultRange(query)
+	start := query.From.Truncate(interval).Add(interval)
+	end := query.To.Truncate(interval).Add(interval)
+	res, err := h.client.metricRangeQuery(ctx, logQL, start.UnixNano(), end.UnixNano(), interval)
+	if err != nil {
+		return nil, err
+	}
+
+	counter := transitionCounter{}
+	for _, series := range res.Data.Result {
+		for _, sample := range series.Values {
+			if sample.V == 0 {
+				continue
+			}
+			counter.add(series.Metric[RuleUIDLabel], sample.T.Add(-interval), int64(sample.V))
+		}
 	}
+	return counter.frame(), nil
+}
 
+func withDefaultRange(query models.HistoryQuery) models.HistoryQuery {
 	now := time.Now().UTC()
 	if query.To.IsZero() {
 		query.To = now
@@ -91,18 +167,38 @@ func (h *RemoteLokiBackend) QueryStates(ctx context.Context, query models.Histor
 	if query.From.IsZero() {
 		query.From = now.Add(-defaultQueryRange)
 	}
+	return query
+}
 
-	// Timestamps are expected in RFC3339Nano.
-	res, err := h.client.rangeQuery(ctx, selectors, query.From.UnixNano(), query.To.UnixNano())
+// buildLogQuery returns the LogQL query of the state transitions that match the query.
+func buildLogQuery(query models.HistoryQuery) (string, error) {
+	selectors, err := buildSelectors(query)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("failed to build the provided selectors: %w", err)
+	}
+	logQL := selectorString(selectors)
+	if query.PreviousState == "" && query.CurrentState == "" {
+		return logQL, nil
 	}
-	return merge(res, query.RuleUID)
+	logQL += ` | json previous="previous", current="current"`
+	if query.PreviousState != "" {
+		logQL += fmt.Sprintf(" | previous=~%q", stateRegex(query.PreviousState))
+	}
+	if query.CurrentState != "" {
+		logQL += fmt.Sprintf(" | current=~%q", stateRegex(query.CurrentState))
+	}
+	return logQL, nil
+}
+
+// stateRegex returns the regular expression that matches the formatted state with or without a reason.
+func stateRegex(state string) string {
+	quoted := regexp.QuoteMeta(state)
+	return quoted + `|` + quoted + ` \(.*\)`
 }
 
 func buildSelectors(query models.HistoryQuery) ([]Selector, error) {
 	// +2 as OrgID and the state history label will always be selectors at the API level.
-	selectors := make([]Selector, len(query.Labels)+2)
+	selectors := make([]Selector, len(query.Labels)+2, len(query.Labels)+len(query.Matchers)+3)
 
 	// Set the predefined selector orgID.
 	selector, err := NewSelector(OrgIDLabel, "=", fmt.Sprintf("%d", query.OrgID))
@@ -129,6 +225,15 @@ func buildSelectors(query models.HistoryQuery) ([]Selector, error) {
 		i++
 	}
 
+	// Set the label matchers, which support all operators.
+	for _, m := range query.Matchers {
+		selector, err = NewSelector(m.Name, m.Type.String(), m.Value)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+
 	// Set the optional special selector rule_id
 	if query.RuleUID != "" {
 		rsel, err := NewSelector(RuleUIDLabel, "=", query.RuleUID)
@@ -211,28 +211,58 @@ func (c *httpLokiClient) setAuthAndTenantHeaders(req *http.Request) {
 		req.Header.Add("X-Scope-OrgID", c.cfg.TenantID)
 	}
 }
-func (c *httpLokiClient) rangeQuery(ctx context.Context, selectors []Selector, start, end int64) (queryRes, error) {
+
+// rangeQuery runs a log query. If limit is positive, it returns at most limit lines, from the oldest if the direction
+// is forward or from the newest if it is backward. Otherwise, the default limit and direction of Loki apply.
+func (c *httpLokiClient) rangeQuery(ctx context.Context, logQL string, start, end, limit int64, direction string) (queryRes, error) {
 	// Run the pre-flight checks for the query.
-	if len(selectors) == 0 {
-		return queryRes{}, fmt.Errorf("at least one selector required to query")
-	}
 	if start > end {
 		return queryRes{}, fmt.Errorf("start time cannot be after end time")
 	}
 
-	queryURL := c.cfg.ReadPathURL.JoinPath("/loki/api/v1/query_range")
+	values := url.Values{}
+	values.Set("query", logQL)
+	values.Set("start", fmt.Sprintf("%d", start))
+	values.Set("end", fmt.Sprintf("%d", end))
+	if limit > 0 {
+		values.Set("limit", fmt.Sprintf("%d", limit))
+		values.Set("direction", direction)
+	}
+
+	result := queryRes{}
+	if err := c.query(ctx, values, &result); err != nil {
+		return queryRes{}, err
+	}
+	return result, nil
+}
+
+// metricRangeQuery runs a metric query, such as count_over_time, that is evaluated every step between start and end.
+func (c *httpLokiClient) metricRangeQuery(ctx context.Context, logQL string, start, end int64, step time.Duration) (metricQueryRes, error) {
+	if start > end {
+		return metricQueryRes{}, fmt.Errorf("start time cannot be after end time")
+	}
 
 	values := url.Values{}
-	values.Set("query", selectorString(selectors))
+	values.Set("query", logQL)
 	values.Set("start", fmt.Sprintf("%d", start))
 	values.Set("end", fmt.Sprintf("%d", end))
+	values.Set("step", fmt.Sprintf("%d", int64(step.Seconds())))
 
+	result := metricQueryRes{}
+	if err := c.query(ctx, values, &result); err != nil {
+		return metricQueryRes{}, err
+	}
+	return result, nil
+}
+
+func (c *httpLokiClient) query(ctx context.Context, values url.Values, result interface{}) error {
+	queryURL := c.cfg.ReadPathURL.JoinPath("/loki/api/v1/query_range")
 	queryURL.RawQuery = values.Encode()
 
 	req, err := http.NewRequest(http.MethodGet,
 		queryURL.String(), nil)
 	if err != nil {
-		return queryRes{}, fmt.Errorf("error creating request: %w", err)
+		return fmt.Errorf("error creating request: %w", err)
 	}
 
 	req = req.WithContext(ctx)
@@ -240,7 +270,7 @@ func (c *httpLokiClient) rangeQuery(ctx context.Context, selectors []Selector, s
 
 	res, err := c.client.Do(req)
 	if err != nil {
-		return queryRes{}, fmt.Errorf("error executing request: %w", err)
+		return fmt.Errorf("error executing request: %w", err)
 	}
 
 	defer func() {
@@ -249,7 +279,7 @@ func (c *httpLokiClient) rangeQuery(ctx context.Context, selectors []Selector, s
 
 	data, err := io.ReadAll(res.Body)
 	if err != nil {
-		return queryRes{}, fmt.Errorf("error reading request response: %w", err)
+		return fmt.Errorf("error reading request response: %w", err)
 	}
 
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
@@ -258,17 +288,13 @@ func (c *httpLokiClient) rangeQuery(ctx context.Context, selectors []Selector, s
 		} else {
 			c.log.Error("Error response from Loki with an empty body", "status", res.StatusCode)
 		}
-		return queryRes{}, fmt.Errorf("received a non-200 response from loki, status: %d", res.StatusCode)
+		return fmt.Errorf("received a non-200 response from loki, status: %d", res.StatusCode)
 	}
 
-	result := queryRes{}
-	err = json.Unmarshal(data, &result)
-	if err != nil {
-		fmt.Println(string(data))
-		return queryRes{}, fmt.Errorf("error parsing request response: %w", err)
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("error parsing request response: %w", err)
 	}
-
-	return result, nil
+	return nil
 }
 
 func selectorString(selectors []Selector) string {
@@ -307,3 +333,47 @@ type queryRes struct {
 type queryData struct {
 	Result []stream `json:"result"`
 }
+
+type metricQueryRes struct {
+	Data metricQueryData `json:"data"`
+}
+
+type metricQueryData struct {
+	Result []metricSeries `json:"result"`
+}
+
+// metricSeries is a series of the matrix returned by a metric query.
+type metricSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []metricSample    `json:"values"`
+}
+
+type metricSample struct {
+	T time.Time
+	V float64
+}
+
+func (r *metricSample) UnmarshalJSON(b []byte) error {
+	// A sample of a matrix is formatted like a list with two elements, [At, Val]
+	// At is a number, the timestamp in seconds since the unix epoch.
+	// Val is a string containing the value of the sample.
+	var tuple [2]interface{}
+	if err := json.Unmarshal(b, &tuple); err != nil {
+		return fmt.Errorf("failed to deserialize sample in Loki response: %w", err)
+	}
+	ts, ok := tuple[0].(float64)
+	if !ok {
+		return fmt.Errorf("timestamp in Loki sample is not a number: %v", tuple[0])
+	}
+	str, ok := tuple[1].(string)
+	if !ok {
+		return fmt.Errorf("value in Loki sample is not a string: %v", tuple[1])
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return fmt.Errorf("value in Loki sample is not a number: %v", str)
+	}
+	r.T = time.UnixMilli(int64(ts * 1000))
+	r.V = v
+	return nil
+}
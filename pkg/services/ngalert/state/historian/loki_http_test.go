@@ -130,7 +130,7 @@ func TestLokiHTTPClient(t *testing.T) {
 		end := time.Now().UnixNano()
 
 		// Authorized request should not fail against Grafana Cloud.
-		res, err := client.rangeQuery(context.Background(), selectors, start, end)
+		res, err := client.rangeQuery(context.Background(), selectorString(selectors), start, end, 0, "")
 		require.NoError(t, err)
 		require.NotNil(t, res)
 	})
@@ -202,6 +202,28 @@ func TestRow(t *testing.T) {
 	})
 }
 
+func TestMetricSample(t *testing.T) {
+	t.Run("unmarshal", func(t *testing.T) {
+		jsn := []byte(`[1677664800.5, "3"]`)
+
+		row := metricSample{}
+		err := json.Unmarshal(jsn, &row)
+
+		require.NoError(t, err)
+		require.Equal(t, int64(1677664800500), row.T.UnixMilli())
+		require.Equal(t, 3.0, row.V)
+	})
+
+	t.Run("unmarshal bad value", func(t *testing.T) {
+		jsn := []byte(`[1677664800, "not-a-number"]`)
+
+		row := metricSample{}
+		err := json.Unmarshal(jsn, &row)
+
+		require.ErrorContains(t, err, "value in Loki sample")
+	})
+}
+
 func TestStream(t *testing.T) {
 	t.Run("marshal", func(t *testing.T) {
 		stream := stream{
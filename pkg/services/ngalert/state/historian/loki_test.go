@@ -1,6 +1,7 @@
 package historian
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -10,8 +11,10 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/stretchr/testify/require"
 )
 
@@ -141,6 +144,104 @@ func TestRemoteLokiBackend(t *testing.T) {
 	})
 }
 
+func TestRemoteLokiBackend_Query(t *testing.T) {
+	t.Run("buildLogQuery", func(t *testing.T) {
+		t.Run("selects matchers", func(t *testing.T) {
+			query := models.HistoryQuery{
+				OrgID:    1,
+				RuleUID:  "rule",
+				Matchers: labels.Matchers{mustMatcher(t, labels.MatchNotRegexp, "severity", "info|debug")},
+			}
+
+			logQL, err := buildLogQuery(query)
+
+			require.NoError(t, err)
+			require.Equal(t, `{orgID="1",from="state-history",severity!~"info|debug",ruleUID="rule"}`, logQL)
+		})
+
+		t.Run("filters states with a pipeline", func(t *testing.T) {
+			query := models.HistoryQuery{OrgID: 1, PreviousState: "Normal", CurrentState: "Alerting"}
+
+			logQL, err := buildLogQuery(query)
+
+			require.NoError(t, err)
+			require.Equal(t, `{orgID="1",from="state-history"} | json previous="previous", current="current" | previous=~"Normal|Normal \\(.*\\)" | current=~"Alerting|Alerting \\(.*\\)"`, logQL)
+		})
+	})
+
+	t.Run("QueryStates requests all pages up to the requested one", func(t *testing.T) {
+		client := &fakeLokiClient{
+			rangeRes: queryRes{Data: queryData{Result: []stream{{
+				Stream: map[string]string{"a": "b"},
+				Values: []sample{
+					{time.Unix(0, 1), `{"schemaVersion": 1, "previous": "Normal", "current": "Alerting"}`},
+					{time.Unix(0, 2), `{"schemaVersion": 1, "previous": "Alerting", "current": "Normal"}`},
+					{time.Unix(0, 3), `{"schemaVersion": 1, "previous": "Normal", "current": "Alerting"}`},
+				},
+			}}}},
+		}
+		sut := &RemoteLokiBackend{client: client, log: log.NewNopLogger()}
+
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: 1, Limit: 2, Offset: 1})
+
+		require.NoError(t, err)
+		require.Equal(t, int64(3), client.limit)
+		require.Equal(t, lokiDirectionForward, client.direction)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, time.Unix(0, 2), frame.Fields[0].At(0))
+		require.Equal(t, time.Unix(0, 3), frame.Fields[0].At(1))
+	})
+
+	t.Run("QueryStates without a limit returns the newest lines and marks the result as truncated", func(t *testing.T) {
+		values := make([]sample, 0, lokiQueryLimit+1)
+		for i := lokiQueryLimit + 1; i > 0; i-- {
+			values = append(values, sample{time.Unix(0, int64(i)), `{"schemaVersion": 1, "previous": "Normal", "current": "Alerting"}`})
+		}
+		client := &fakeLokiClient{
+			rangeRes: queryRes{Data: queryData{Result: []stream{{Stream: map[string]string{"a": "b"}, Values: values}}}},
+		}
+		sut := &RemoteLokiBackend{client: client, log: log.NewNopLogger()}
+
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: 1})
+
+		require.NoError(t, err)
+		require.Equal(t, int64(lokiQueryLimit+1), client.limit)
+		require.Equal(t, lokiDirectionBackward, client.direction)
+		require.Equal(t, lokiQueryLimit, frame.Rows())
+		require.Equal(t, time.Unix(0, 2), frame.Fields[0].At(0))
+		require.Equal(t, time.Unix(0, lokiQueryLimit+1), frame.Fields[0].At(lokiQueryLimit-1))
+		require.Equal(t, true, frame.Meta.Custom.(map[string]interface{})["truncated"])
+	})
+
+	t.Run("QueryTransitionCounts counts transitions per rule and hour", func(t *testing.T) {
+		hour := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+		client := &fakeLokiClient{
+			metricRes: metricQueryRes{Data: metricQueryData{Result: []metricSeries{
+				{Metric: map[string]string{RuleUIDLabel: "a"}, Values: []metricSample{{hour.Add(time.Hour), 3}, {hour.Add(2 * time.Hour), 0}}},
+				{Metric: map[string]string{RuleUIDLabel: "b"}, Values: []metricSample{{hour.Add(time.Hour), 1}}},
+			}}},
+		}
+		sut := &RemoteLokiBackend{client: client, log: log.NewNopLogger()}
+
+		frame, err := sut.QueryTransitionCounts(context.Background(), models.HistoryQuery{
+			OrgID: 1,
+			From:  hour.Add(30 * time.Minute),
+			To:    hour.Add(90 * time.Minute),
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, `sum by (ruleUID) (count_over_time({orgID="1",from="state-history"} [1h]))`, client.logQL)
+		require.Equal(t, hour.Add(time.Hour).UnixNano(), client.start)
+		require.Equal(t, hour.Add(2*time.Hour).UnixNano(), client.end)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, hour, frame.Fields[0].At(0))
+		require.Equal(t, "a", frame.Fields[1].At(0))
+		require.Equal(t, int64(3), frame.Fields[2].At(0))
+		require.Equal(t, "b", frame.Fields[1].At(1))
+		require.Equal(t, int64(1), frame.Fields[2].At(1))
+	})
+}
+
 func TestMerge(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -284,3 +385,38 @@ func requireEntry(t *testing.T, row sample) lokiEntry {
 	require.NoError(t, err)
 	return entry
 }
+
+func mustMatcher(t *testing.T, mt labels.MatchType, name, value string) *labels.Matcher {
+	t.Helper()
+	m, err := labels.NewMatcher(mt, name, value)
+	require.NoError(t, err)
+	return m
+}
+
+type fakeLokiClient struct {
+	rangeRes  queryRes
+	metricRes metricQueryRes
+
+	logQL      string
+	start, end int64
+	limit      int64
+	direction  string
+}
+
+func (c *fakeLokiClient) ping(context.Context) error {
+	return nil
+}
+
+func (c *fakeLokiClient) push(context.Context, []stream) error {
+	return nil
+}
+
+func (c *fakeLokiClient) rangeQuery(_ context.Context, logQL string, start, end, limit int64, direction string) (queryRes, error) {
+	c.logQL, c.start, c.end, c.limit, c.direction = logQL, start, end, limit, direction
+	return c.rangeRes, nil
+}
+
+func (c *fakeLokiClient) metricRangeQuery(_ context.Context, logQL string, start, end int64, _ time.Duration) (metricQueryRes, error) {
+	c.logQL, c.start, c.end = logQL, start, end
+	return c.metricRes, nil
+}
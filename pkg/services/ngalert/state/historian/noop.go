@@ -25,3 +25,7 @@ func (f *NoOpHistorian) RecordStatesAsync(ctx context.Context, _ history_model.R
 func (f *NoOpHistorian) QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
 	return data.NewFrame("states"), nil
 }
+
+func (f *NoOpHistorian) QueryTransitionCounts(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	return transitionCounter{}.frame(), nil
+}
@@ -2,8 +2,12 @@ package historian
 
 import (
 	"context"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
@@ -13,4 +17,127 @@ import (
 // TODO: This type should be moved to the side of the consumer, when the consumer is created in the future. We add it here temporarily to more clearly define this package's interface.
 type Querier interface {
 	QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
+	// QueryTransitionCounts returns the number of state transitions of each rule in each hour of the time range of the query.
+	QueryTransitionCounts(ctx context.Context, query models.HistoryQuery) (*data.Frame, error)
+}
+
+const (
+	// Name of the columns used in the dataframe of transition counts.
+	dfRuleUID = "ruleUID"
+	dfCount   = "count"
+)
+
+// queryMatchers returns the label matchers of the query, including the equality matchers of its labels.
+func queryMatchers(query models.HistoryQuery) (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(query.Labels)+len(query.Matchers))
+	for name, value := range query.Labels {
+		m, err := labels.NewMatcher(labels.MatchEqual, name, value)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return append(matchers, query.Matchers...), nil
+}
+
+// matchesLabels returns true if the labels match all matchers.
+func matchesLabels(lbls map[string]string, matchers labels.Matchers) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesStates returns true if the formatted states of a transition, such as "Normal (NoData)", match the state
+// filters of the query.
+func matchesStates(query models.HistoryQuery, previous, current string) bool {
+	return matchesState(query.PreviousState, previous) && matchesState(query.CurrentState, current)
+}
+
+func matchesState(filter, formatted string) bool {
+	return filter == "" || formatted == filter || strings.HasPrefix(formatted, filter+" (")
+}
+
+// page returns the bounds of the page of the query in a result of n state transitions.
+func page(query models.HistoryQuery, n int) (int, int) {
+	start := query.Offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if query.Limit > 0 && start+query.Limit < n {
+		end = start + query.Limit
+	}
+	return start, end
+}
+
+// paginate returns the rows of the frame that are in the page of the query. The frame must have the rows of all
+// pages before it.
+func paginate(frame *data.Frame, query models.HistoryQuery) *data.Frame {
+	rows := frame.Rows()
+	start, end := page(query, rows)
+	if start == 0 && end == rows {
+		return frame
+	}
+	result := frame.EmptyCopy()
+	for i := start; i < end; i++ {
+		result.AppendRow(frame.RowCopy(i)...)
+	}
+	return result
+}
+
+// setTruncated marks the frame as incomplete, because more state transitions match the query than the backend could
+// return. The frame meta has the custom field "truncated" for clients, and a notice with the reason.
+func setTruncated(frame *data.Frame, reason string) *data.Frame {
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.Custom = map[string]interface{}{"truncated": true}
+	frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: reason})
+	return frame
+}
+
+type transitionCountKey struct {
+	bucket  int64
+	ruleUID string
+}
+
+// transitionCounter counts state transitions per rule in buckets of models.HistoryAggregationInterval.
+type transitionCounter map[transitionCountKey]int64
+
+func (c transitionCounter) add(ruleUID string, t time.Time, n int64) {
+	bucket := t.Truncate(models.HistoryAggregationInterval).UnixNano()
+	c[transitionCountKey{bucket: bucket, ruleUID: ruleUID}] += n
+}
+
+// frame returns the transition counts as a frame with a row per rule and bucket, ordered by time and rule.
+func (c transitionCounter) frame() *data.Frame {
+	keys := make([]transitionCountKey, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].bucket == keys[j].bucket {
+			return keys[i].ruleUID < keys[j].ruleUID
+		}
+		return keys[i].bucket < keys[j].bucket
+	})
+
+	times := make([]time.Time, 0, len(keys))
+	ruleUIDs := make([]string, 0, len(keys))
+	counts := make([]int64, 0, len(keys))
+	for _, k := range keys {
+		times = append(times, time.Unix(0, k.bucket).UTC())
+		ruleUIDs = append(ruleUIDs, k.ruleUID)
+		counts = append(counts, c[k])
+	}
+
+	lbls := data.Labels(map[string]string{})
+	frame := data.NewFrame("transitions")
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfRuleUID, lbls, ruleUIDs))
+	frame.Fields = append(frame.Fields, data.NewField(dfCount, lbls, counts))
+	return frame
 }
@@ -2,31 +2,366 @@ package historian
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
+const (
+	// sqlQueryLimit is the maximum number of state transitions returned by a single query.
+	sqlQueryLimit = 5000
+	// sqlQueryBatchSize is the number of state transitions read from the database at once when querying.
+	sqlQueryBatchSize = 1000
+	// sqlQueryScanLimit is the maximum number of state transitions read from the database by a single query, which
+	// bounds the work of queries with filters that match few state transitions.
+	sqlQueryScanLimit = 50000
+)
+
+// SqlBackend is an implementation of state.Historian that records state transitions in the Grafana database.
 type SqlBackend struct {
-	log log.Logger
+	store store.StateHistoryStore
+	log   log.Logger
 }
 
-func NewSqlBackend() *SqlBackend {
+func NewSqlBackend(store store.StateHistoryStore) *SqlBackend {
 	return &SqlBackend{
-		log: log.New("ngalert.state.historian"),
+		store: store,
+		log:   log.New("ngalert.state.historian", "backend", "sql"),
 	}
 }
 
-func (h *SqlBackend) RecordStatesAsync(ctx context.Context, _ history_model.RuleMeta, _ []state.StateTransition) <-chan error {
-	errCh := make(chan error)
-	close(errCh)
+// DeleteExpiredService is a service to delete state history that was recorded by the sql backend and is past its retention.
+type DeleteExpiredService struct {
+	store store.StateHistoryAdminStore
+}
+
+func (s *DeleteExpiredService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.store.DeleteExpiredStateHistory(ctx)
+}
+
+func ProvideDeleteExpiredService(store *store.DBstore) *DeleteExpiredService {
+	return &DeleteExpiredService{store: store}
+}
+
+func (h *SqlBackend) RecordStatesAsync(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	// Build entries before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	entries := statesToEntries(rule, states, logger)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		if err := h.store.SaveStateHistory(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}()
 	return errCh
 }
 
 func (h *SqlBackend) QueryStates(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
-	return data.NewFrame("states"), nil
+	logger := h.log.FromContext(ctx)
+	entries, truncated, err := h.queryEntries(ctx, query, sqlQueryLimit)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := entriesToFrame(entries, logger)
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		return setTruncated(frame, fmt.Sprintf("The result is limited to the newest %d state transitions that match the query, and label filters are applied to at most the newest %d state transitions.", sqlQueryLimit, sqlQueryScanLimit)), nil
+	}
+	return frame, nil
+}
+
+// QueryTransitionCounts counts the state transitions that match the query per rule and hour. Without label filters,
+// the transitions are counted by the database.
+func (h *SqlBackend) QueryTransitionCounts(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	query = withDefaultRange(query)
+	query.Limit, query.Offset = 0, 0
+	counter := transitionCounter{}
+	if len(query.Labels) == 0 && len(query.Matchers) == 0 {
+		counts, err := h.store.CountStateHistory(ctx, storeQuery(query))
+		if err != nil {
+			return nil, fmt.Errorf("failed to count state history: %w", err)
+		}
+		for _, c := range counts {
+			counter.add(c.RuleUID, time.Unix(0, c.Bucket), c.Transitions)
+		}
+		return counter.frame(), nil
+	}
+
+	entries, truncated, err := h.queryEntries(ctx, query, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		counter.add(e.RuleUID, time.Unix(0, e.EvaluatedAt), 1)
+	}
+	if truncated {
+		return setTruncated(counter.frame(), fmt.Sprintf("Only the newest %d state transitions are counted, as the labels of the state transitions are filtered after they are read.", sqlQueryScanLimit)), nil
+	}
+	return counter.frame(), nil
+}
+
+// storeQuery returns the query of the store for the filters of the query that the database can apply.
+func storeQuery(query models.HistoryQuery) models.GetStateHistoryQuery {
+	return models.GetStateHistoryQuery{
+		OrgID:         query.OrgID,
+		RuleUID:       query.RuleUID,
+		From:          query.From,
+		To:            query.To,
+		PreviousState: query.PreviousState,
+		CurrentState:  query.CurrentState,
+	}
+}
+
+// queryEntries returns the state transitions that match the query, ordered by evaluation time, and whether the result
+// is truncated. If the query has a limit, the page of the query is returned, counted from the oldest state transition
+// in the time range. Otherwise, the newest state transitions are returned, up to the given limit if it is positive.
+//
+// The rule, time range and state filters are applied by the database. If the query has label filters, the entries
+// are read in batches and filtered before the page or the limit is applied, so that the label filters do not make
+// the result incomplete, and at most sqlQueryScanLimit entries are read.
+func (h *SqlBackend) queryEntries(ctx context.Context, query models.HistoryQuery, limit int) ([]models.StateHistoryEntry, bool, error) {
+	logger := h.log.FromContext(ctx)
+	query = withDefaultRange(query)
+	matchers, err := queryMatchers(query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	q := storeQuery(query)
+	paged := query.Limit > 0
+	if paged {
+		q.Ascending = true
+		limit = query.Offset + query.Limit
+	}
+
+	if len(matchers) == 0 && limit > 0 {
+		// All filters are applied by the database, so the entries are read at once. One more entry than the limit is
+		// read to know whether the result is truncated.
+		q.Limit = limit + 1
+		if paged {
+			q.Offset, q.Limit = query.Offset, query.Limit
+		}
+		entries, err := h.store.GetStateHistory(ctx, q)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to query state history: %w", err)
+		}
+		if !paged && len(entries) > limit {
+			return entries[len(entries)-limit:], true, nil
+		}
+		return entries, false, nil
+	}
+
+	// The entries are collected from the start of the page, or from the newest.
+	var result []models.StateHistoryEntry
+	collect := func(e models.StateHistoryEntry) bool {
+		if !entryMatches(e, query, matchers, logger) {
+			return false
+		}
+		result = append(result, e)
+		return limit > 0 && len(result) > limit
+	}
+	q.Limit = sqlQueryBatchSize
+	scanned := 0
+	truncated := false
+	for {
+		batch, err := h.store.GetStateHistory(ctx, q)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to query state history: %w", err)
+		}
+		full := false
+		if paged {
+			for i := 0; i < len(batch) && !full; i++ {
+				full = collect(batch[i])
+			}
+		} else {
+			for i := len(batch) - 1; i >= 0 && !full; i-- {
+				full = collect(batch[i])
+			}
+		}
+		if full {
+			result = result[:limit]
+			truncated = !paged
+			break
+		}
+		scanned += len(batch)
+		if len(batch) < q.Limit {
+			break
+		}
+		if scanned >= sqlQueryScanLimit {
+			logger.Warn("State history query reached the maximum number of scanned entries, the result is truncated", "limit", sqlQueryScanLimit)
+			truncated = true
+			break
+		}
+		if paged {
+			q.Offset += len(batch)
+		} else {
+			q.Before = &batch[0]
+		}
+	}
+
+	if paged {
+		if len(result) < query.Offset {
+			return nil, truncated, nil
+		}
+		return result[query.Offset:], truncated, nil
+	}
+	return reverseEntries(result), truncated, nil
+}
+
+// entryMatches returns true if the state transition matches the label and state filters of the query.
+func entryMatches(e models.StateHistoryEntry, query models.HistoryQuery, matchers labels.Matchers, logger log.Logger) bool {
+	if !matchesStates(query, e.PreviousState, e.CurrentState) {
+		return false
+	}
+	if len(matchers) == 0 {
+		return true
+	}
+	instanceLabels, err := entryLabels(e)
+	if err != nil {
+		logger.Error("Failed to parse labels of state history entry, skipping", "id", e.ID, "error", err)
+		return false
+	}
+	return matchesLabels(instanceLabels, matchers)
+}
+
+func reverseEntries(entries []models.StateHistoryEntry) []models.StateHistoryEntry {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []models.StateHistoryEntry {
+	entries := make([]models.StateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		labels := models.InstanceLabels(removePrivateLabels(state.State.Labels))
+		_, hash, err := labels.StringAndHash()
+		if err != nil {
+			logger.Error("Failed to hash labels of state, skipping", "error", err)
+			continue
+		}
+		lblsJSON, err := json.Marshal(labels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of state, skipping", "error", err)
+			continue
+		}
+		valuesJSON, err := json.Marshal(valuesAsDataBlob(state.State))
+		if err != nil {
+			logger.Error("Failed to serialize values of state, skipping", "error", err)
+			continue
+		}
+
+		entry := models.StateHistoryEntry{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			RuleGroup:     rule.Group,
+			NamespaceUID:  rule.NamespaceUID,
+			Labels:        string(lblsJSON),
+			LabelsHash:    hash,
+			PreviousState: state.PreviousFormatted(),
+			CurrentState:  state.Formatted(),
+			Values:        string(valuesJSON),
+			DashboardUID:  rule.DashboardUID,
+			PanelID:       rule.PanelID,
+			EvaluatedAt:   state.State.LastEvaluationTime.UnixNano(),
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.Error = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// entriesToFrame converts stored state transitions into the same frame that is returned by the Loki backend.
+// The entries must match the filters and the page of the query.
+func entriesToFrame(entries []models.StateHistoryEntry, logger log.Logger) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+
+	for _, e := range entries {
+		instanceLabels, err := entryLabels(e)
+		if err != nil {
+			logger.Error("Failed to parse labels of state history entry, skipping", "id", e.ID, "error", err)
+			continue
+		}
+
+		entry := lokiEntry{
+			SchemaVersion: 1,
+			Previous:      e.PreviousState,
+			Current:       e.CurrentState,
+			Error:         e.Error,
+			DashboardUID:  e.DashboardUID,
+			PanelID:       e.PanelID,
+		}
+		if e.Values != "" {
+			entry.Values = simplejson.New()
+			if err := entry.Values.UnmarshalJSON([]byte(e.Values)); err != nil {
+				logger.Error("Failed to parse values of state history entry, skipping", "id", e.ID, "error", err)
+				continue
+			}
+		}
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history entry: %w", err)
+		}
+		lblsJSON, err := json.Marshal(instanceLabels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history labels: %w", err)
+		}
+
+		times = append(times, time.Unix(0, e.EvaluatedAt))
+		lines = append(lines, line)
+		labels = append(labels, lblsJSON)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+
+	return frame, nil
+}
+
+// entryLabels returns the labels of a stored state transition, including the same labels that the Loki backend
+// adds to its streams.
+func entryLabels(e models.StateHistoryEntry) (data.Labels, error) {
+	var instanceLabels data.Labels
+	if err := json.Unmarshal([]byte(e.Labels), &instanceLabels); err != nil {
+		return nil, err
+	}
+	if instanceLabels == nil {
+		instanceLabels = data.Labels{}
+	}
+	instanceLabels[StateHistoryLabelKey] = StateHistoryLabelValue
+	instanceLabels[OrgIDLabel] = fmt.Sprint(e.OrgID)
+	instanceLabels[RuleUIDLabel] = e.RuleUID
+	instanceLabels[GroupLabel] = e.RuleGroup
+	instanceLabels[FolderUIDLabel] = e.NamespaceUID
+	return instanceLabels, nil
 }
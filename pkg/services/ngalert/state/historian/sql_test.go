@@ -0,0 +1,360 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestSqlBackend(t *testing.T) {
+	t.Run("statesToEntries", func(t *testing.T) {
+		t.Run("skips non-transitory states", func(t *testing.T) {
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{State: eval.Normal})
+
+			res := statesToEntries(rule, states, log.NewNopLogger())
+
+			require.Empty(t, res)
+		})
+
+		t.Run("maps evaluation errors", func(t *testing.T) {
+			rule := createTestRule()
+			states := singleFromNormal(&state.State{State: eval.Error, Error: fmt.Errorf("oh no")})
+
+			res := statesToEntries(rule, states, log.NewNopLogger())
+
+			require.Len(t, res, 1)
+			require.Contains(t, res[0].Error, "oh no")
+		})
+
+		t.Run("maps rule and labels", func(t *testing.T) {
+			rule := createTestRule()
+			now := time.Now()
+			states := singleFromNormal(&state.State{
+				State:              eval.Alerting,
+				Labels:             data.Labels{"a": "b", "__private__": "c"},
+				LastEvaluationTime: now,
+			})
+
+			res := statesToEntries(rule, states, log.NewNopLogger())
+
+			require.Len(t, res, 1)
+			require.Equal(t, rule.OrgID, res[0].OrgID)
+			require.Equal(t, rule.UID, res[0].RuleUID)
+			require.Equal(t, rule.Group, res[0].RuleGroup)
+			require.Equal(t, rule.NamespaceUID, res[0].NamespaceUID)
+			require.Equal(t, `{"a":"b"}`, res[0].Labels)
+			require.NotEmpty(t, res[0].LabelsHash)
+			require.Equal(t, "Normal", res[0].PreviousState)
+			require.Equal(t, "Alerting", res[0].CurrentState)
+			require.Equal(t, now.UnixNano(), res[0].EvaluatedAt)
+		})
+	})
+
+	t.Run("recorded states are queryable", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		rule := createTestRule()
+		now := time.Now()
+		states := []state.StateTransition{
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: now},
+			},
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "c"}, LastEvaluationTime: now.Add(-time.Second)},
+			},
+		}
+
+		err := <-sut.RecordStatesAsync(context.Background(), rule, states)
+		require.NoError(t, err)
+
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, RuleUID: rule.UID})
+
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, time.Unix(0, now.Add(-time.Second).UnixNano()), frame.Fields[0].At(0))
+
+		var entry lokiEntry
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+		require.Equal(t, "Alerting", entry.Current)
+		var lbls map[string]string
+		require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &lbls))
+		require.Equal(t, map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(rule.OrgID),
+			RuleUIDLabel:         rule.UID,
+			GroupLabel:           rule.Group,
+			FolderUIDLabel:       rule.NamespaceUID,
+			"a":                  "c",
+		}, lbls)
+	})
+
+	t.Run("query filters by labels", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		rule := createTestRule()
+		states := []state.StateTransition{
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: time.Now()},
+			},
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "c"}, LastEvaluationTime: time.Now()},
+			},
+		}
+		require.NoError(t, <-sut.RecordStatesAsync(context.Background(), rule, states))
+
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Labels: map[string]string{"a": "b"}})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+	})
+
+	t.Run("query filters by matchers and states and paginates", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		rule := createTestRule()
+		now := time.Now().Add(-time.Minute)
+		states := []state.StateTransition{
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: now},
+			},
+			{
+				PreviousState: eval.Alerting,
+				State:         &state.State{State: eval.Normal, StateReason: eval.NoData.String(), Labels: data.Labels{"a": "c"}, LastEvaluationTime: now.Add(time.Second)},
+			},
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "d"}, LastEvaluationTime: now.Add(2 * time.Second)},
+			},
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "e"}, LastEvaluationTime: now.Add(3 * time.Second)},
+			},
+		}
+		require.NoError(t, <-sut.RecordStatesAsync(context.Background(), rule, states))
+
+		matcher, err := labels.NewMatcher(labels.MatchRegexp, "a", "b|c|d")
+		require.NoError(t, err)
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{
+			OrgID:        rule.OrgID,
+			Matchers:     labels.Matchers{matcher},
+			CurrentState: "Alerting",
+			Limit:        1,
+			Offset:       1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, time.Unix(0, now.Add(2*time.Second).UnixNano()), frame.Fields[0].At(0))
+
+		frame, err = sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, CurrentState: "Normal"})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+	})
+
+	t.Run("query filters by labels before it is truncated", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		rule := createTestRule()
+		start := time.Now().Add(-time.Hour)
+		states := []state.StateTransition{{
+			PreviousState: eval.Normal,
+			State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: start},
+		}}
+		for i := 1; i <= sqlQueryBatchSize+1; i++ {
+			states = append(states, state.StateTransition{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "c"}, LastEvaluationTime: start.Add(time.Duration(i) * time.Millisecond)},
+			})
+		}
+		require.NoError(t, <-sut.RecordStatesAsync(context.Background(), rule, states))
+
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Labels: map[string]string{"a": "b"}})
+
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, time.Unix(0, start.UnixNano()), frame.Fields[0].At(0))
+	})
+
+	t.Run("truncated query keeps the newest entries", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		rule := createTestRule()
+		start := time.Now().Add(-time.Hour)
+		var states []state.StateTransition
+		for i := 0; i < sqlQueryLimit+10; i++ {
+			states = append(states, state.StateTransition{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: start.Add(time.Duration(i) * time.Millisecond)},
+			})
+		}
+		require.NoError(t, <-sut.RecordStatesAsync(context.Background(), rule, states))
+
+		frame, err := sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID})
+
+		require.NoError(t, err)
+		require.Equal(t, sqlQueryLimit, frame.Rows())
+		require.Equal(t, time.Unix(0, start.Add(10*time.Millisecond).UnixNano()), frame.Fields[0].At(0))
+		require.Equal(t, time.Unix(0, start.Add((sqlQueryLimit+9)*time.Millisecond).UnixNano()), frame.Fields[0].At(sqlQueryLimit-1))
+		require.Equal(t, map[string]interface{}{"truncated": true}, frame.Meta.Custom)
+		require.Len(t, frame.Meta.Notices, 1)
+
+		frame, err = sut.QueryStates(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Limit: 10})
+
+		require.NoError(t, err)
+		require.Equal(t, 10, frame.Rows())
+		require.Equal(t, time.Unix(0, start.UnixNano()), frame.Fields[0].At(0))
+		require.Nil(t, frame.Meta)
+	})
+
+	t.Run("transition counts are aggregated per rule and hour", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		rule := createTestRule()
+		hour := time.Now().Add(-2 * time.Hour).Truncate(time.Hour)
+		states := []state.StateTransition{
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: hour.Add(time.Minute)},
+			},
+			{
+				PreviousState: eval.Alerting,
+				State:         &state.State{State: eval.Normal, Labels: data.Labels{"a": "b"}, LastEvaluationTime: hour.Add(30 * time.Minute)},
+			},
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: hour.Add(time.Hour)},
+			},
+		}
+		require.NoError(t, <-sut.RecordStatesAsync(context.Background(), rule, states))
+
+		frame, err := sut.QueryTransitionCounts(context.Background(), models.HistoryQuery{OrgID: rule.OrgID})
+
+		require.NoError(t, err)
+		require.True(t, store.counted, "transitions without label filters should be counted by the store")
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, hour.UTC(), frame.Fields[0].At(0))
+		require.Equal(t, rule.UID, frame.Fields[1].At(0))
+		require.Equal(t, int64(2), frame.Fields[2].At(0))
+		require.Equal(t, hour.Add(time.Hour).UTC(), frame.Fields[0].At(1))
+		require.Equal(t, int64(1), frame.Fields[2].At(1))
+	})
+	t.Run("transition counts with label filters are aggregated per rule and hour", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sut := NewSqlBackend(store)
+		rule := createTestRule()
+		hour := time.Now().Add(-2 * time.Hour).Truncate(time.Hour)
+		states := []state.StateTransition{
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}, LastEvaluationTime: hour.Add(time.Minute)},
+			},
+			{
+				PreviousState: eval.Normal,
+				State:         &state.State{State: eval.Alerting, Labels: data.Labels{"a": "c"}, LastEvaluationTime: hour.Add(2 * time.Minute)},
+			},
+		}
+		require.NoError(t, <-sut.RecordStatesAsync(context.Background(), rule, states))
+
+		frame, err := sut.QueryTransitionCounts(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Labels: map[string]string{"a": "b"}})
+
+		require.NoError(t, err)
+		require.False(t, store.counted)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, int64(1), frame.Fields[2].At(0))
+	})
+}
+
+type fakeStateHistoryStore struct {
+	entries []models.StateHistoryEntry
+	counted bool
+}
+
+func (f *fakeStateHistoryStore) SaveStateHistory(_ context.Context, entries []models.StateHistoryEntry) error {
+	for _, e := range entries {
+		e.ID = int64(len(f.entries) + 1)
+		f.entries = append(f.entries, e)
+	}
+	return nil
+}
+
+func (f *fakeStateHistoryStore) GetStateHistory(_ context.Context, query models.GetStateHistoryQuery) ([]models.StateHistoryEntry, error) {
+	var result []models.StateHistoryEntry
+	for _, e := range f.entries {
+		if e.OrgID != query.OrgID || (query.RuleUID != "" && e.RuleUID != query.RuleUID) {
+			continue
+		}
+		if e.EvaluatedAt < query.From.UnixNano() || e.EvaluatedAt > query.To.UnixNano() {
+			continue
+		}
+		if query.Before != nil && (e.EvaluatedAt > query.Before.EvaluatedAt || e.EvaluatedAt == query.Before.EvaluatedAt && e.ID >= query.Before.ID) {
+			continue
+		}
+		if !matchesState(query.PreviousState, e.PreviousState) || !matchesState(query.CurrentState, e.CurrentState) {
+			continue
+		}
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].EvaluatedAt == result[j].EvaluatedAt {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].EvaluatedAt < result[j].EvaluatedAt
+	})
+	if query.Ascending {
+		if query.Offset > len(result) {
+			return nil, nil
+		}
+		result = result[query.Offset:]
+		if query.Limit > 0 && len(result) > query.Limit {
+			result = result[:query.Limit]
+		}
+		return result, nil
+	}
+	if query.Offset > len(result) {
+		return nil, nil
+	}
+	result = result[:len(result)-query.Offset]
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+	return result, nil
+}
+
+func (f *fakeStateHistoryStore) CountStateHistory(ctx context.Context, query models.GetStateHistoryQuery) ([]models.StateHistoryCount, error) {
+	f.counted = true
+	query.Before = nil
+	query.Limit = 0
+	entries, err := f.GetStateHistory(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[models.StateHistoryCount]int64)
+	for _, e := range entries {
+		key := models.StateHistoryCount{RuleUID: e.RuleUID, Bucket: e.EvaluatedAt - e.EvaluatedAt%models.HistoryAggregationInterval.Nanoseconds()}
+		counts[key]++
+	}
+	result := make([]models.StateHistoryCount, 0, len(counts))
+	for key, n := range counts {
+		key.Transitions = n
+		result = append(result, key)
+	}
+	return result, nil
+}
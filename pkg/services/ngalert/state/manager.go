@@ -136,24 +136,11 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID, err := entry.Labels.StringKey()
+			state, err := stateFromInstance(entry, ruleForEntry)
 			if err != nil {
 				st.log.Error("Error getting cacheId for entry", "error", err)
 			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
+			rulesStates.states[state.CacheID] = state
 			statesCount++
 		}
 	}
@@ -161,6 +148,68 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// WarmRule loads the states of the rule from the instance store into the cache, replacing the states of the rule
+// that are in the cache. It is used when the evaluation of the rule moves to this instance from another replica.
+func (st *Manager) WarmRule(ctx context.Context, rule *ngModels.AlertRule) error {
+	if st.instanceStore == nil {
+		return nil
+	}
+	states, err := st.LoadRuleStates(ctx, rule)
+	if err != nil {
+		return err
+	}
+	st.cache.setRuleStates(rule.OrgID, rule.UID, &ruleStates{states: states})
+	return nil
+}
+
+// LoadRuleStates returns the states of the rule in the instance store by cache ID, without changing the cache.
+// It returns no states if there is no instance store.
+func (st *Manager) LoadRuleStates(ctx context.Context, rule *ngModels.AlertRule) (map[string]*State, error) {
+	if st.instanceStore == nil {
+		return nil, nil
+	}
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		return nil, err
+	}
+	states := make(map[string]*State, len(cmd.Result))
+	for _, entry := range cmd.Result {
+		state, err := stateFromInstance(entry, rule)
+		if err != nil {
+			st.log.Error("Error getting cacheId for entry", "error", err)
+		}
+		states[state.CacheID] = state
+	}
+	return states, nil
+}
+
+// ForgetRule removes the states of the rule from the cache without deleting them from the instance store,
+// and without resolving them. It is used when the evaluation of the rule moves to another replica.
+func (st *Manager) ForgetRule(ruleKey ngModels.AlertRuleKey) []*State {
+	return st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+}
+
+func stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) (*State, error) {
+	cacheID, err := entry.Labels.StringKey()
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               map[string]string(entry.Labels),
+		ResultLabels:         map[string]string(entry.ResultLabels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+	}, err
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -277,6 +326,11 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 
 	switch result.State {
 	case eval.Normal:
+		if shouldKeepFiring(currentState, alertRule, result) {
+			logger.Debug("Setting next state", "handler", "resultKeepFiring")
+			resultKeepFiring(currentState, alertRule, result, logger)
+			break
+		}
 		logger.Debug("Setting next state", "handler", "resultNormal")
 		resultNormal(currentState, alertRule, result, logger)
 	case eval.Alerting:
@@ -301,6 +355,12 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = result.State.String()
 	}
 
+	if currentState.State == eval.Alerting && result.State == eval.Normal {
+		currentState.StateReason = ngModels.StateReasonKeepFiring
+	} else {
+		currentState.KeepFiringSince = time.Time{}
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
@@ -365,6 +425,7 @@ func (st *Manager) saveAlertStates(ctx context.Context, logger log.Logger, state
 		fields := ngModels.AlertInstance{
 			AlertInstanceKey:  key,
 			Labels:            ngModels.InstanceLabels(s.Labels),
+			ResultLabels:      ngModels.InstanceLabels(s.ResultLabels),
 			CurrentState:      ngModels.InstanceStateType(s.State.State.String()),
 			CurrentReason:     s.StateReason,
 			LastEvalTime:      s.LastEvaluationTime,
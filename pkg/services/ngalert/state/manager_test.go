@@ -320,8 +320,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -375,8 +376,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label_1":             "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label_1": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -399,8 +401,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label_2":             "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Alerting,
+					ResultLabels: data.Labels{"instance_label_2": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -457,8 +460,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -519,8 +523,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -592,8 +597,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Alerting,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -687,8 +693,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(30 * time.Second),
@@ -769,8 +776,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(20 * time.Second),
@@ -834,8 +842,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -899,8 +908,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -964,9 +974,10 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Alerting,
-					StateReason: eval.NoData.String(),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
+					StateReason:  eval.NoData.String(),
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1030,8 +1041,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1095,8 +1107,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1120,8 +1133,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"alertname":                    "test_title",
 						"label":                        "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(10 * time.Second),
@@ -1186,8 +1200,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test-1",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test-1"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1212,8 +1227,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test-2",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test-2"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1237,8 +1253,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"alertname":                    "test_title",
 						"label":                        "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(10 * time.Second),
@@ -1305,8 +1322,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1335,8 +1353,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"alertname":                    "test_title",
 						"label":                        "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(10 * time.Second),
@@ -1395,9 +1414,10 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Normal,
-					StateReason: eval.NoData.String(),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
+					StateReason:  eval.NoData.String(),
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1462,9 +1482,10 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Alerting,
-					StateReason: eval.NoData.String(),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
+					StateReason:  eval.NoData.String(),
 
 					Results: []state.Evaluation{
 						{
@@ -1531,10 +1552,11 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Pending,
-					StateReason: eval.Error.String(),
-					Error:       errors.New("test error"),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
+					StateReason:  eval.Error.String(),
+					Error:        errors.New("test error"),
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1623,9 +1645,10 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Alerting,
-					StateReason: eval.Error.String(),
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Alerting,
+					StateReason:  eval.Error.String(),
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(20 * time.Second),
@@ -1705,8 +1728,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"datasource_uid":               "datasource_uid_1",
 						"ref_id":                       "A",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Error,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Error,
 					Error: expr.QueryError{
 						RefID: "A",
 						Err:   errors.New("this is an error"),
@@ -1783,10 +1807,11 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Normal,
-					StateReason: eval.Error.String(),
-					Error:       nil,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
+					StateReason:  eval.Error.String(),
+					Error:        nil,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1857,10 +1882,11 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values:      make(map[string]float64),
-					State:       eval.Normal,
-					StateReason: eval.Error.String(),
-					Error:       nil,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
+					StateReason:  eval.Error.String(),
+					Error:        nil,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -1957,8 +1983,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Error,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Error,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(40 * time.Second),
@@ -2039,8 +2066,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Pending,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.Pending,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(30 * time.Second),
@@ -2127,8 +2155,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"instance_label":               "test",
 					},
-					Values: make(map[string]float64),
-					State:  eval.NoData,
+					ResultLabels: data.Labels{"instance_label": "test"},
+					Values:       make(map[string]float64),
+					State:        eval.NoData,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime.Add(30 * time.Second),
@@ -2190,8 +2219,9 @@ func TestProcessEvalResults(t *testing.T) {
 						"label":                        "test",
 						"job":                          "prod/grafana",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"cluster": "us-central-1", "namespace": "prod", "pod": "grafana"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
@@ -2275,6 +2305,61 @@ func TestProcessEvalResults(t *testing.T) {
 			require.Contains(t, savedStates, s.CacheID)
 		}
 	})
+
+	t.Run("should keep firing for the keep firing duration of the rule", func(t *testing.T) {
+		cfg := state.ManagerCfg{
+			Metrics:       testMetrics.GetStateMetrics(),
+			ExternalURL:   nil,
+			InstanceStore: &state.FakeInstanceStore{},
+			Images:        &state.NotAvailableImageService{},
+			Clock:         clock.New(),
+			Historian:     &state.FakeHistorian{},
+		}
+		st := state.NewManager(cfg)
+		rule := models.AlertRuleGen(models.WithFor(0), models.WithKeepFiringFor(30*time.Second), models.WithInterval(10*time.Second))()
+		rule.Labels = nil
+
+		process := func(evaluatedAt time.Time, s eval.State) state.StateTransition {
+			transitions := st.ProcessEvalResults(context.Background(), evaluatedAt, rule, eval.Results{
+				{Instance: data.Labels{"instance": "a"}, State: s, EvaluatedAt: evaluatedAt},
+			}, make(data.Labels))
+			require.Len(t, transitions, 1)
+			return transitions[0]
+		}
+
+		transition := process(evaluationTime, eval.Alerting)
+		require.Equal(t, eval.Alerting, transition.State.State)
+
+		// the condition is no longer met but the alert keeps firing
+		for i := 1; i <= 3; i++ {
+			evaluatedAt := evaluationTime.Add(time.Duration(i) * 10 * time.Second)
+			transition = process(evaluatedAt, eval.Normal)
+			require.Equal(t, eval.Alerting, transition.State.State)
+			require.Equal(t, models.StateReasonKeepFiring, transition.State.StateReason)
+			require.Equal(t, evaluationTime.Add(10*time.Second), transition.State.KeepFiringSince)
+			require.Equal(t, evaluationTime, transition.State.StartsAt)
+			require.Equal(t, evaluatedAt.Add(state.ResendDelay*3), transition.State.EndsAt)
+			require.False(t, transition.State.Resolved)
+		}
+
+		// the condition is met again, which resets the keep firing duration
+		transition = process(evaluationTime.Add(40*time.Second), eval.Alerting)
+		require.Equal(t, eval.Alerting, transition.State.State)
+		require.Empty(t, transition.State.StateReason)
+		require.Zero(t, transition.State.KeepFiringSince)
+		require.Equal(t, evaluationTime, transition.State.StartsAt)
+
+		transition = process(evaluationTime.Add(50*time.Second), eval.Normal)
+		require.Equal(t, eval.Alerting, transition.State.State)
+		require.Equal(t, evaluationTime.Add(50*time.Second), transition.State.KeepFiringSince)
+
+		// the alert resolves once the condition has not been met for the keep firing duration
+		transition = process(evaluationTime.Add(80*time.Second), eval.Normal)
+		require.Equal(t, eval.Normal, transition.State.State)
+		require.Empty(t, transition.State.StateReason)
+		require.Zero(t, transition.State.KeepFiringSince)
+		require.True(t, transition.State.Resolved)
+	})
 }
 
 func printAllAnnotations(annos map[int64]annotations.Item) string {
@@ -2360,8 +2445,9 @@ func TestStaleResultsHandler(t *testing.T) {
 						"alertname":                    rule.Title,
 						"test1":                        "testValue1",
 					},
-					Values: make(map[string]float64),
-					State:  eval.Normal,
+					ResultLabels: data.Labels{"test1": "testValue1"},
+					Values:       make(map[string]float64),
+					State:        eval.Normal,
 					Results: []state.Evaluation{
 						{
 							EvaluationTime:  evaluationTime,
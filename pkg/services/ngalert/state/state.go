@@ -54,13 +54,22 @@ type State struct {
 	// If a label is templated then the template is first evaluated to derive the final label.
 	Labels data.Labels
 
+	// ResultLabels contain the labels of the evaluation result that the state was created for, before the custom
+	// labels from the alert rule and the extra labels were added. They are nil for states restored from the database
+	// that were saved before the result labels were stored.
+	ResultLabels data.Labels
+
 	// Values contains the values of any instant vectors, reduce and math expressions, or classic
 	// conditions.
 	Values map[string]float64
 
-	StartsAt             time.Time
-	EndsAt               time.Time
-	LastSentAt           time.Time
+	StartsAt   time.Time
+	EndsAt     time.Time
+	LastSentAt time.Time
+	// KeepFiringSince is the time of the first evaluation since which the condition of the rule is no longer met
+	// while the state keeps firing because of the keep firing duration of the rule. It is zero otherwise.
+	// It is not persisted, so after a restart the keep firing duration starts again from the next evaluation.
+	KeepFiringSince      time.Time
 	LastEvaluationString string
 	LastEvaluationTime   time.Time
 	EvaluationDuration   time.Duration
@@ -194,6 +203,23 @@ func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger
 	}
 }
 
+// shouldKeepFiring returns true if the state must stay Alerting although the condition of the rule is no longer met,
+// because the rule has a keep firing duration that has not elapsed yet.
+func shouldKeepFiring(state *State, rule *models.AlertRule, result eval.Result) bool {
+	if state.State != eval.Alerting || rule.KeepFiringFor <= 0 {
+		return false
+	}
+	return state.KeepFiringSince.IsZero() || result.EvaluatedAt.Sub(state.KeepFiringSince) < rule.KeepFiringFor
+}
+
+func resultKeepFiring(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
+	if state.KeepFiringSince.IsZero() {
+		state.KeepFiringSince = result.EvaluatedAt
+	}
+	logger.Debug("Keeping state firing", "state", state.State, "keepFiringSince", state.KeepFiringSince, "keepFiringFor", rule.KeepFiringFor)
+	state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+}
+
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger) {
 	switch state.State {
 	case eval.Alerting:
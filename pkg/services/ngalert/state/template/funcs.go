@@ -11,6 +11,10 @@ import (
 type query struct {
 	Datasource string `json:"datasource"`
 	Expr       string `json:"expr"`
+	// Model is the model of the query run by the query function. It takes precedence over Expr.
+	Model json.RawMessage `json:"model,omitempty"`
+	// From is the duration of the relative time range of the query run by the query function.
+	From string `json:"from,omitempty"`
 }
 
 var (
@@ -0,0 +1,112 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/timestamp"
+	"github.com/prometheus/prometheus/promql"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// queryRefID is the RefID of the query that is run by the query function.
+	queryRefID = "A"
+	// defaultQueryFrom is the relative time range of the query if it does not have one.
+	defaultQueryFrom = 10 * time.Minute
+)
+
+// QueryFunc runs a query of a data source, or a server-side expression, at the given time. It returns a value
+// for each series of the result of the query.
+type QueryFunc func(ctx context.Context, q ngmodels.AlertQuery, ts time.Time) ([]Value, error)
+
+type queryFuncKey struct{}
+
+// WithQueryFunc returns a copy of the context with the function that runs the queries of the query function
+// of the templates. Templates that are expanded without it get no samples from the query function.
+func WithQueryFunc(ctx context.Context, f QueryFunc) context.Context {
+	return context.WithValue(ctx, queryFuncKey{}, f)
+}
+
+func queryFuncFromContext(ctx context.Context) (QueryFunc, bool) {
+	f, ok := ctx.Value(queryFuncKey{}).(QueryFunc)
+	return f, ok && f != nil
+}
+
+// toAlertQuery returns the query to run for the argument of the query function. The argument has the same
+// format as the one of graphLink and tableLink, with optional model and from fields. The model is sent
+// to the data source, or to the expression engine, as-is. Otherwise, the expression is run as an instant query.
+func (q query) toAlertQuery() (ngmodels.AlertQuery, error) {
+	if q.Datasource == "" {
+		return ngmodels.AlertQuery{}, errors.New("datasource must not be empty")
+	}
+	from := defaultQueryFrom
+	if q.From != "" {
+		d, err := prommodel.ParseDuration(q.From)
+		if err != nil {
+			return ngmodels.AlertQuery{}, fmt.Errorf("invalid from: %w", err)
+		}
+		from = time.Duration(d)
+	}
+	model := q.Model
+	if len(model) == 0 {
+		if q.Expr == "" {
+			return ngmodels.AlertQuery{}, errors.New("either expr or model must be set")
+		}
+		var err error
+		model, err = json.Marshal(map[string]interface{}{
+			"refId":   queryRefID,
+			"expr":    q.Expr,
+			"instant": true,
+			"range":   false,
+		})
+		if err != nil {
+			return ngmodels.AlertQuery{}, err
+		}
+	}
+	return ngmodels.AlertQuery{
+		RefID:             queryRefID,
+		DatasourceUID:     q.Datasource,
+		Model:             model,
+		RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(from)},
+	}, nil
+}
+
+// newPromQueryFunc adapts the QueryFunc of the context to the query function of the Prometheus template expander, so
+// that the results of the query can be used with first, label, value, sortByLabel and the other functions for query
+// results.
+func newPromQueryFunc(ctx context.Context) func(context.Context, string, time.Time) (promql.Vector, error) {
+	f, ok := queryFuncFromContext(ctx)
+	return func(ctx context.Context, s string, ts time.Time) (promql.Vector, error) {
+		if !ok {
+			return nil, nil
+		}
+		var q query
+		if err := json.Unmarshal([]byte(s), &q); err != nil {
+			return nil, fmt.Errorf("invalid query %q: %w", s, err)
+		}
+		alertQuery, err := q.toAlertQuery()
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %q: %w", s, err)
+		}
+		values, err := f(ctx, alertQuery, ts)
+		if err != nil {
+			return nil, err
+		}
+		t := timestamp.FromTime(ts)
+		vector := make(promql.Vector, 0, len(values))
+		for _, v := range values {
+			vector = append(vector, promql.Sample{
+				Point:  promql.Point{T: t, V: v.Value},
+				Metric: labels.FromMap(v.Labels),
+			})
+		}
+		return vector, nil
+	}
+}
@@ -2,9 +2,11 @@ package template
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
@@ -12,6 +14,7 @@ import (
 	ptr "github.com/xorcare/pointer"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 func TestLabelsString(t *testing.T) {
@@ -442,3 +445,87 @@ func TestExpandTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandQuery(t *testing.T) {
+	evaluatedAt := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	values := []Value{
+		{Labels: Labels{"pod": "b"}, Value: 2.5},
+		{Labels: Labels{"pod": "a"}, Value: 1},
+	}
+
+	var queries []ngmodels.AlertQuery
+	var queriedAt time.Time
+	ctx := WithQueryFunc(context.Background(), func(_ context.Context, q ngmodels.AlertQuery, ts time.Time) ([]Value, error) {
+		queries = append(queries, q)
+		queriedAt = ts
+		if q.DatasourceUID == "failing" {
+			return nil, errors.New("failed to query")
+		}
+		return values, nil
+	})
+
+	cases := []struct {
+		name            string
+		text            string
+		expected        string
+		expectedError   string
+		expectedQueries []ngmodels.AlertQuery
+	}{{
+		name:     "instant query of expr",
+		text:     `{{ range query "{\"datasource\": \"prom\", \"expr\": \"topk(5, rate(errors[5m]))\"}" | sortByLabel "pod" }}{{ .Labels.pod }}={{ .Value }} {{ end }}`,
+		expected: "a=1 b=2.5 ",
+		expectedQueries: []ngmodels.AlertQuery{{
+			RefID:             "A",
+			DatasourceUID:     "prom",
+			Model:             json.RawMessage(`{"expr":"topk(5, rate(errors[5m]))","instant":true,"range":false,"refId":"A"}`),
+			RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(10 * time.Minute)},
+		}},
+	}, {
+		name:     "query with model and from",
+		text:     `{{ query "{\"datasource\": \"__expr__\", \"model\": {\"type\": \"math\", \"expression\": \"1 + 1\"}, \"from\": \"1h\"}" | first | value }}`,
+		expected: "2.5",
+		expectedQueries: []ngmodels.AlertQuery{{
+			RefID:             "A",
+			DatasourceUID:     "__expr__",
+			Model:             json.RawMessage(`{"type": "math", "expression": "1 + 1"}`),
+			RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(time.Hour)},
+		}},
+	}, {
+		name:          "query that is not JSON",
+		text:          `{{ query "up" }}`,
+		expectedError: `error executing template __alert_test: template: __alert_test:1:79: executing "__alert_test" at <query "up">: error calling query: invalid query "up": invalid character 'u' looking for beginning of value`,
+	}, {
+		name:          "query without datasource",
+		text:          `{{ query "{\"expr\": \"up\"}" }}`,
+		expectedError: `error executing template __alert_test: template: __alert_test:1:79: executing "__alert_test" at <query "{\"expr\": \"up\"}">: error calling query: invalid query "{\"expr\": \"up\"}": datasource must not be empty`,
+	}, {
+		name:          "query without expr or model",
+		text:          `{{ query "{\"datasource\": \"prom\"}" }}`,
+		expectedError: `error executing template __alert_test: template: __alert_test:1:79: executing "__alert_test" at <query "{\"datasource\": \"prom\"}">: error calling query: invalid query "{\"datasource\": \"prom\"}": either expr or model must be set`,
+	}, {
+		name:          "query that fails",
+		text:          `{{ query "{\"datasource\": \"failing\", \"expr\": \"up\"}" }}`,
+		expectedError: `error executing template __alert_test: template: __alert_test:1:79: executing "__alert_test" at <query "{\"datasource\": \"failing\", \"expr\": \"up\"}">: error calling query: failed to query`,
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			queries = nil
+			v, err := Expand(ctx, "test", c.text, NewData(nil, eval.Result{}), nil, evaluatedAt)
+			if c.expectedError != "" {
+				require.EqualError(t, err, c.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, v)
+			require.True(t, evaluatedAt.Equal(queriedAt))
+			require.Len(t, queries, len(c.expectedQueries))
+			for i, q := range c.expectedQueries {
+				require.Equal(t, q.RefID, queries[i].RefID)
+				require.Equal(t, q.DatasourceUID, queries[i].DatasourceUID)
+				require.Equal(t, q.RelativeTimeRange, queries[i].RelativeTimeRange)
+				require.JSONEq(t, string(q.Model), string(queries[i].Model))
+			}
+		})
+	}
+}
@@ -14,6 +14,8 @@ var _ InstanceStore = &FakeInstanceStore{}
 type FakeInstanceStore struct {
 	mtx         sync.Mutex
 	RecordedOps []interface{}
+	// Instances are returned by ListAlertInstances.
+	Instances []*models.AlertInstance
 }
 
 type FakeInstanceStoreOp struct {
@@ -25,6 +27,11 @@ func (f *FakeInstanceStore) ListAlertInstances(_ context.Context, q *models.List
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
 	f.RecordedOps = append(f.RecordedOps, *q)
+	for _, instance := range f.Instances {
+		if instance.RuleOrgID == q.RuleOrgID && (q.RuleUID == "" || instance.RuleUID == q.RuleUID) {
+			q.Result = append(q.Result, instance)
+		}
+	}
 	return nil
 }
 
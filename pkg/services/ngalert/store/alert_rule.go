@@ -134,22 +134,25 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 			}
 			newRules = append(newRules, r)
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleUID:          r.UID,
-				RuleOrgID:        r.OrgID,
-				RuleNamespaceUID: r.NamespaceUID,
-				RuleGroup:        r.RuleGroup,
-				ParentVersion:    0,
-				Version:          r.Version,
-				Created:          r.Updated,
-				Condition:        r.Condition,
-				Title:            r.Title,
-				Data:             r.Data,
-				IntervalSeconds:  r.IntervalSeconds,
-				NoDataState:      r.NoDataState,
-				ExecErrState:     r.ExecErrState,
-				For:              r.For,
-				Annotations:      r.Annotations,
-				Labels:           r.Labels,
+				RuleUID:              r.UID,
+				RuleOrgID:            r.OrgID,
+				RuleNamespaceUID:     r.NamespaceUID,
+				RuleGroup:            r.RuleGroup,
+				ParentVersion:        0,
+				Version:              r.Version,
+				Created:              r.Updated,
+				Condition:            r.Condition,
+				Title:                r.Title,
+				Data:                 r.Data,
+				IntervalSeconds:      r.IntervalSeconds,
+				NoDataState:          r.NoDataState,
+				ExecErrState:         r.ExecErrState,
+				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
+				Annotations:          r.Annotations,
+				Labels:               r.Labels,
+				Record:               r.Record,
+				NotificationSettings: r.NotificationSettings,
 			})
 		}
 		if len(newRules) > 0 {
@@ -201,23 +204,26 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 			}
 			parentVersion = r.Existing.Version
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				RuleGroupIndex:   r.New.RuleGroupIndex,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version + 1,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:            r.New.OrgID,
+				RuleUID:              r.New.UID,
+				RuleNamespaceUID:     r.New.NamespaceUID,
+				RuleGroup:            r.New.RuleGroup,
+				RuleGroupIndex:       r.New.RuleGroupIndex,
+				ParentVersion:        parentVersion,
+				Version:              r.New.Version + 1,
+				Created:              r.New.Updated,
+				Condition:            r.New.Condition,
+				Title:                r.New.Title,
+				Data:                 r.New.Data,
+				IntervalSeconds:      r.New.IntervalSeconds,
+				NoDataState:          r.New.NoDataState,
+				ExecErrState:         r.New.ExecErrState,
+				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
+				Annotations:          r.New.Annotations,
+				Labels:               r.New.Labels,
+				Record:               r.New.Record,
+				NotificationSettings: r.New.NotificationSettings,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -339,6 +345,62 @@ func (st DBstore) GetRuleGroupInterval(ctx context.Context, orgID int64, namespa
 	})
 }
 
+// ListNotificationSettings returns the notification settings of the alert rules of the organization that have them.
+func (st DBstore) ListNotificationSettings(ctx context.Context, orgID int64) (map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings, error) {
+	result := make(map[ngmodels.AlertRuleKey]ngmodels.NotificationSettings)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("alert_rule").Select("uid, org_id, notification_settings").
+			Where("org_id = ? AND notification_settings IS NOT NULL", orgID)
+		rows, err := q.Rows(new(ngmodels.AlertRule))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		for rows.Next() {
+			rule := new(ngmodels.AlertRule)
+			if err := rows.Scan(rule); err != nil {
+				st.Logger.Error("Invalid rule found in DB store, ignoring it", "func", "ListNotificationSettings", "error", err)
+				continue
+			}
+			if rule.NotificationSettings == nil {
+				continue
+			}
+			result[rule.GetKey()] = *rule.NotificationSettings
+		}
+		return nil
+	})
+	return result, err
+}
+
+// RenameReceiverInNotificationSettings replaces the contact point oldReceiver of the notification settings of the alert
+// rules of the organization with newReceiver. The rules get a new version, so the scheduler picks up the change.
+// It returns the number of updated rules.
+func (st DBstore) RenameReceiverInNotificationSettings(ctx context.Context, orgID int64, oldReceiver, newReceiver string) (int, error) {
+	var rules []*ngmodels.AlertRule
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_rule").Where("org_id = ? AND notification_settings IS NOT NULL", orgID).Find(&rules)
+	})
+	if err != nil {
+		return 0, err
+	}
+	updates := make([]ngmodels.UpdateRule, 0)
+	for _, rule := range rules {
+		if rule.NotificationSettings == nil || rule.NotificationSettings.Receiver != oldReceiver {
+			continue
+		}
+		updated := rule.Copy()
+		updated.NotificationSettings.Receiver = newReceiver
+		updates = append(updates, ngmodels.UpdateRule{Existing: rule, New: *updated})
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	return len(updates), st.UpdateAlertRules(ctx, updates)
+}
+
 // GetUserVisibleNamespaces returns the folders that are visible to the user and have at least one alert in it
 func (st DBstore) GetUserVisibleNamespaces(ctx context.Context, orgID int64, user *user.SignedInUser) (map[string]*folder.Folder, error) {
 	namespaceMap := make(map[string]*folder.Folder)
@@ -568,5 +630,27 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
 	}
+
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.Record != nil {
+		if err := alertRule.Record.Validate(alertRule.Data); err != nil {
+			return err
+		}
+		if alertRule.Condition != alertRule.Record.From {
+			return fmt.Errorf("%w: the condition of a recording rule must be the query or expression to record", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if alertRule.NotificationSettings != nil {
+			return fmt.Errorf("%w: recording rules cannot have notification settings", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+
+	if alertRule.NotificationSettings != nil {
+		if err := alertRule.NotificationSettings.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/rand"
@@ -66,6 +67,43 @@ func TestIntegrationUpdateAlertRules(t *testing.T) {
 
 		require.ErrorIs(t, err, ErrOptimisticLock)
 	})
+
+	t.Run("should store the record of recording rules", func(t *testing.T) {
+		rule := createRule(t, store)
+		require.Nil(t, rule.Record)
+
+		newRule := models.CopyRule(rule)
+		newRule.Record = &models.Record{Metric: "test_metric", From: newRule.Data[0].RefID}
+		newRule.Condition = newRule.Data[0].RefID
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: rule,
+			New:      *newRule,
+		},
+		})
+		require.NoError(t, err)
+
+		dbrule := &models.AlertRule{}
+		err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			exist, err := sess.Table(models.AlertRule{}).ID(rule.ID).Get(dbrule)
+			require.Truef(t, exist, fmt.Sprintf("rule with ID %d does not exist", rule.ID))
+			return err
+		})
+		require.NoError(t, err)
+		require.Equal(t, newRule.Record, dbrule.Record)
+	})
+
+	t.Run("should fail if the record of a recording rule is not valid", func(t *testing.T) {
+		rule := createRule(t, store)
+
+		newRule := models.CopyRule(rule)
+		newRule.Record = &models.Record{Metric: "test_metric", From: "not-a-ref-id"}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: rule,
+			New:      *newRule,
+		},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
 }
 
 func withIntervalMatching(baseInterval time.Duration) func(*models.AlertRule) {
@@ -175,6 +213,73 @@ func TestIntegration_CountAlertRules(t *testing.T) {
 	}
 }
 
+func TestIntegration_ListNotificationSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{
+		SQLStore: sqlStore,
+		Cfg: setting.UnifiedAlertingSettings{
+			BaseInterval: time.Duration(rand.Int63n(100)+1) * time.Second,
+		},
+	}
+	rule := createRule(t, store)
+	other := createRule(t, store)
+
+	groupWait := model.Duration(time.Minute)
+	settings := models.NotificationSettings{
+		Receiver:  "team-a",
+		GroupBy:   []string{"alertname", "pod"},
+		GroupWait: &groupWait,
+	}
+	newRule := models.CopyRule(rule)
+	newRule.NotificationSettings = &settings
+	err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+		Existing: rule,
+		New:      *newRule,
+	}})
+	require.NoError(t, err)
+
+	result, err := store.ListNotificationSettings(context.Background(), rule.OrgID)
+	require.NoError(t, err)
+	require.Equal(t, map[models.AlertRuleKey]models.NotificationSettings{rule.GetKey(): settings}, result)
+
+	result, err = store.ListNotificationSettings(context.Background(), rule.OrgID+1)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	t.Run("should rename the contact point of the notification settings", func(t *testing.T) {
+		n, err := store.RenameReceiverInNotificationSettings(context.Background(), rule.OrgID, "team-a", "team-b")
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		result, err := store.ListNotificationSettings(context.Background(), rule.OrgID)
+		require.NoError(t, err)
+		require.Equal(t, "team-b", result[rule.GetKey()].Receiver)
+		require.Equal(t, settings.GroupBy, result[rule.GetKey()].GroupBy)
+
+		q := &models.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID}
+		require.NoError(t, store.GetAlertRuleByUID(context.Background(), q))
+		require.Greater(t, q.Result.Version, rule.Version, "the rule should get a new version")
+
+		n, err = store.RenameReceiverInNotificationSettings(context.Background(), rule.OrgID, "team-a", "team-c")
+		require.NoError(t, err)
+		require.Zero(t, n)
+	})
+
+	t.Run("should fail if the notification settings are not valid", func(t *testing.T) {
+		newRule := models.CopyRule(other)
+		newRule.NotificationSettings = &models.NotificationSettings{}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing: other,
+			New:      *newRule,
+		}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
 func createRule(t *testing.T, store *DBstore) *models.AlertRule {
 	rule := models.AlertRuleGen(withIntervalMatching(store.Cfg.BaseInterval))()
 	err := store.SQLStore.WithDbSession(context.Background(), func(sess *db.Session) error {
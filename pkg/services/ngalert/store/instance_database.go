@@ -61,7 +61,7 @@ func (st DBstore) SaveAlertInstances(ctx context.Context, cmd ...models.AlertIns
 		keyNames := []string{"rule_org_id", "rule_uid", "labels_hash"}
 		fieldNames := []string{
 			"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state",
-			"current_reason", "current_state_since", "current_state_end", "last_eval_time",
+			"current_reason", "current_state_since", "current_state_end", "last_eval_time", "result_labels",
 		}
 		fieldsPerRow := len(fieldNames)
 		maxRows := 20
@@ -86,6 +86,10 @@ func (st DBstore) SaveAlertInstances(ctx context.Context, cmd ...models.AlertIns
 			if err != nil {
 				return err
 			}
+			resultLabelTupleJSON, err := resultLabelsKey(alertInstance)
+			if err != nil {
+				return err
+			}
 
 			if err := models.ValidateAlertInstance(alertInstance); err != nil {
 				return err
@@ -94,7 +98,7 @@ func (st DBstore) SaveAlertInstances(ctx context.Context, cmd ...models.AlertIns
 			args = append(args,
 				alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash,
 				alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(),
-				alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+				alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(), resultLabelTupleJSON)
 
 			// If we've reached the maximum batch size, write to the database.
 			if values(args) >= maxArgs {
@@ -146,12 +150,16 @@ func (st DBstore) SaveAlertInstance(ctx context.Context, alertInstance models.Al
 		if err != nil {
 			return err
 		}
-		params := append(make([]interface{}, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix())
+		resultLabelTupleJSON, err := resultLabelsKey(alertInstance)
+		if err != nil {
+			return err
+		}
+		params := append(make([]interface{}, 0), alertInstance.RuleOrgID, alertInstance.RuleUID, labelTupleJSON, alertInstance.LabelsHash, alertInstance.CurrentState, alertInstance.CurrentReason, alertInstance.CurrentStateSince.Unix(), alertInstance.CurrentStateEnd.Unix(), alertInstance.LastEvalTime.Unix(), resultLabelTupleJSON)
 
 		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
 			"alert_instance",
 			[]string{"rule_org_id", "rule_uid", "labels_hash"},
-			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time"})
+			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "result_labels"})
 		_, err = sess.SQL(upsertSQL, params...).Query()
 		if err != nil {
 			return err
@@ -161,6 +169,14 @@ func (st DBstore) SaveAlertInstance(ctx context.Context, alertInstance models.Al
 	})
 }
 
+// resultLabelsKey returns the serialized result labels of the instance, or nil if they are unknown.
+func resultLabelsKey(alertInstance models.AlertInstance) (interface{}, error) {
+	if alertInstance.ResultLabels == nil {
+		return nil, nil
+	}
+	return alertInstance.ResultLabels.StringKey()
+}
+
 func (st DBstore) FetchOrgIds(ctx context.Context) ([]int64, error) {
 	orgIds := []int64{}
 
@@ -41,6 +41,7 @@ func BenchmarkAlertInstanceOperations(b *testing.B) {
 			CurrentState:  models.InstanceStateFiring,
 			CurrentReason: string(models.InstanceStateError),
 			Labels:        labels,
+			ResultLabels:  models.InstanceLabels{"test": "resultValue"},
 		}
 		instances = append(instances, instance)
 		keys = append(keys, instance.AlertInstanceKey)
@@ -179,6 +180,7 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, alertRule1.OrgID, listCmd.Result[0].RuleOrgID)
 		require.Equal(t, alertRule1.UID, listCmd.Result[0].RuleUID)
 		require.Equal(t, instance.CurrentReason, listCmd.Result[0].CurrentReason)
+		require.Equal(t, instance.ResultLabels, listCmd.Result[0].ResultLabels)
 	})
 
 	t.Run("can save and read new alert instance with no labels", func(t *testing.T) {
@@ -208,6 +210,7 @@ func TestIntegrationAlertInstanceOperations(t *testing.T) {
 		require.Equal(t, alertRule2.OrgID, listCmd.Result[0].RuleOrgID)
 		require.Equal(t, alertRule2.UID, listCmd.Result[0].RuleUID)
 		require.Equal(t, instance.Labels, listCmd.Result[0].Labels)
+		require.Nil(t, listCmd.Result[0].ResultLabels)
 	})
 
 	t.Run("can save two instances with same org_id, uid and different labels", func(t *testing.T) {
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type NotificationLogStore interface {
+	// SaveNotificationLog saves attempts to send notifications.
+	SaveNotificationLog(ctx context.Context, entries []models.NotificationLogEntry) error
+	// GetNotificationLog returns the entries of the notification log that match the query, newest first.
+	GetNotificationLog(ctx context.Context, query models.GetNotificationLogQuery) ([]models.NotificationLogEntry, error)
+	// GetNotificationLogEntry returns the entry of the notification log with the given ID, or
+	// models.ErrNotificationLogEntryNotFound if it does not exist in the organization.
+	GetNotificationLogEntry(ctx context.Context, orgID, id int64) (*models.NotificationLogEntry, error)
+}
+
+type NotificationLogAdminStore interface {
+	NotificationLogStore
+
+	// DeleteExpiredNotificationLog deletes entries of the notification log that are older than the configured
+	// retention. It returns the number of deleted entries or an error.
+	DeleteExpiredNotificationLog(context.Context) (int64, error)
+}
+
+func (st DBstore) SaveNotificationLog(ctx context.Context, entries []models.NotificationLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Table(&models.NotificationLogEntry{}).InsertMulti(entries); err != nil {
+			return fmt.Errorf("failed to insert notification log entries: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetNotificationLog(ctx context.Context, query models.GetNotificationLogQuery) ([]models.NotificationLogEntry, error) {
+	var entries []models.NotificationLogEntry
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(&models.NotificationLogEntry{}).Where("org_id = ?", query.OrgID)
+		if query.Receiver != "" {
+			q = q.And("receiver = ?", query.Receiver)
+		}
+		if query.Status != "" {
+			q = q.And("status = ?", query.Status)
+		}
+		if !query.From.IsZero() {
+			q = q.And("sent_at >= ?", query.From.UnixNano())
+		}
+		if !query.To.IsZero() {
+			q = q.And("sent_at <= ?", query.To.UnixNano())
+		}
+		q = q.Desc("sent_at", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		return q.Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification log: %w", err)
+	}
+	return entries, nil
+}
+
+func (st DBstore) GetNotificationLogEntry(ctx context.Context, orgID, id int64) (*models.NotificationLogEntry, error) {
+	entry := models.NotificationLogEntry{}
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND id = ?", orgID, id).Get(&entry)
+		if err != nil {
+			return fmt.Errorf("failed to get notification log entry: %w", err)
+		}
+		if !exists {
+			return models.ErrNotificationLogEntryNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (st DBstore) DeleteExpiredNotificationLog(ctx context.Context) (int64, error) {
+	if st.Cfg.NotificationLog.Retention <= 0 {
+		return 0, nil
+	}
+	before := TimeNow().Add(-st.Cfg.NotificationLog.Retention)
+	var n int64
+	if err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("sent_at < ?", before.UnixNano()).Delete(&models.NotificationLogEntry{})
+		if err != nil {
+			return fmt.Errorf("failed to delete expired notification log: %w", err)
+		}
+		n = rows
+		return nil
+	}); err != nil {
+		return -1, err
+	}
+	return n, nil
+}
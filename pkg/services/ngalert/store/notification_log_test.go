@@ -0,0 +1,91 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationNotificationLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	dbstore.Cfg.NotificationLog.Retention = time.Hour
+
+	now := time.Now()
+	entry := func(orgID int64, receiver, status string, at time.Time) models.NotificationLogEntry {
+		return models.NotificationLogEntry{
+			OrgID:           orgID,
+			Receiver:        receiver,
+			IntegrationUID:  "uid",
+			IntegrationType: "webhook",
+			GroupKey:        `{}:{alertname="test"}`,
+			GroupLabels:     `{"alertname":"test"}`,
+			Alerts:          `[]`,
+			Status:          status,
+			Attempt:         1,
+			SentAt:          at.UnixNano(),
+		}
+	}
+	require.NoError(t, dbstore.SaveNotificationLog(ctx, []models.NotificationLogEntry{
+		entry(1, "team-a", models.NotificationStatusSuccess, now.Add(-2*time.Hour)),
+		entry(1, "team-a", models.NotificationStatusFailed, now.Add(-2*time.Minute)),
+		entry(1, "team-a", models.NotificationStatusSuccess, now.Add(-time.Minute)),
+		entry(1, "team-b", models.NotificationStatusSuccess, now.Add(-time.Minute)),
+		entry(2, "team-a", models.NotificationStatusSuccess, now.Add(-time.Minute)),
+	}))
+
+	t.Run("should filter by org, receiver and time range, newest first", func(t *testing.T) {
+		res, err := dbstore.GetNotificationLog(ctx, models.GetNotificationLogQuery{
+			OrgID:    1,
+			Receiver: "team-a",
+			From:     now.Add(-time.Hour),
+			To:       now,
+		})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, now.Add(-time.Minute).UnixNano(), res[0].SentAt)
+		require.Equal(t, now.Add(-2*time.Minute).UnixNano(), res[1].SentAt)
+	})
+
+	t.Run("should filter by status and respect the limit", func(t *testing.T) {
+		res, err := dbstore.GetNotificationLog(ctx, models.GetNotificationLogQuery{OrgID: 1, Status: models.NotificationStatusFailed})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		res, err = dbstore.GetNotificationLog(ctx, models.GetNotificationLogQuery{OrgID: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+	})
+
+	t.Run("should get an entry by ID only in its org", func(t *testing.T) {
+		res, err := dbstore.GetNotificationLog(ctx, models.GetNotificationLogQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+
+		e, err := dbstore.GetNotificationLogEntry(ctx, 2, res[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, "team-a", e.Receiver)
+
+		_, err = dbstore.GetNotificationLogEntry(ctx, 1, res[0].ID)
+		require.ErrorIs(t, err, models.ErrNotificationLogEntryNotFound)
+	})
+
+	t.Run("should delete entries past the retention", func(t *testing.T) {
+		n, err := dbstore.DeleteExpiredNotificationLog(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+
+		res, err := dbstore.GetNotificationLog(ctx, models.GetNotificationLogQuery{OrgID: 1, Receiver: "team-a"})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+	})
+}
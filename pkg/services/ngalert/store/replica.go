@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ReplicaStore stores the heartbeats of the instances of Grafana that share the evaluation of alert rules.
+type ReplicaStore interface {
+	// SaveReplicaHeartbeat records that the replica was alive at the given time.
+	SaveReplicaHeartbeat(ctx context.Context, replica string, at time.Time) error
+	// GetLiveReplicas returns the names of the replicas whose last heartbeat is not before since, in order.
+	GetLiveReplicas(ctx context.Context, since time.Time) ([]string, error)
+	// DeleteReplicaHeartbeats deletes the heartbeats of the replicas whose last heartbeat is before the given time.
+	DeleteReplicaHeartbeats(ctx context.Context, before time.Time) (int64, error)
+	// DeleteReplica deletes the heartbeat of the replica, for example when it shuts down.
+	DeleteReplica(ctx context.Context, replica string) error
+}
+
+func (st DBstore) SaveReplicaHeartbeat(ctx context.Context, replica string, at time.Time) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		heartbeat := models.ReplicaHeartbeat{Replica: replica, LastHeartbeat: at.Unix()}
+		// MySQL does not count rows whose values do not change as updated, so check whether the row exists
+		// rather than relying on the number of updated rows.
+		exists, err := sess.Where("replica = ?", replica).Exist(&models.ReplicaHeartbeat{})
+		if err != nil {
+			return fmt.Errorf("failed to get replica heartbeat: %w", err)
+		}
+		if exists {
+			if _, err := sess.Where("replica = ?", replica).Cols("last_heartbeat").Update(&heartbeat); err != nil {
+				return fmt.Errorf("failed to update replica heartbeat: %w", err)
+			}
+			return nil
+		}
+		if _, err := sess.Insert(&heartbeat); err != nil {
+			return fmt.Errorf("failed to insert replica heartbeat: %w", err)
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetLiveReplicas(ctx context.Context, since time.Time) ([]string, error) {
+	var replicas []string
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table(&models.ReplicaHeartbeat{}).Where("last_heartbeat >= ?", since.Unix()).Asc("replica").Cols("replica").Find(&replicas)
+	})
+	return replicas, err
+}
+
+func (st DBstore) DeleteReplicaHeartbeats(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		deleted, err = sess.Where("last_heartbeat < ?", before.Unix()).Delete(&models.ReplicaHeartbeat{})
+		return err
+	})
+	return deleted, err
+}
+
+func (st DBstore) DeleteReplica(ctx context.Context, replica string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("replica = ?", replica).Delete(&models.ReplicaHeartbeat{})
+		return err
+	})
+}
@@ -0,0 +1,52 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationReplicaHeartbeats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now()
+	require.NoError(t, dbstore.SaveReplicaHeartbeat(ctx, "b", now))
+	require.NoError(t, dbstore.SaveReplicaHeartbeat(ctx, "b", now))
+	require.NoError(t, dbstore.SaveReplicaHeartbeat(ctx, "a", now.Add(-time.Hour)))
+	require.NoError(t, dbstore.SaveReplicaHeartbeat(ctx, "c", now.Add(-time.Hour)))
+
+	t.Run("should return the replicas with a recent heartbeat in order", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveReplicaHeartbeat(ctx, "a", now))
+
+		replicas, err := dbstore.GetLiveReplicas(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, replicas)
+	})
+
+	t.Run("should delete expired heartbeats", func(t *testing.T) {
+		deleted, err := dbstore.DeleteReplicaHeartbeats(ctx, now.Add(-time.Minute))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+
+		replicas, err := dbstore.GetLiveReplicas(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, replicas)
+	})
+
+	t.Run("should delete the heartbeat of a replica", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteReplica(ctx, "a"))
+
+		replicas, err := dbstore.GetLiveReplicas(ctx, time.Time{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"b"}, replicas)
+	})
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type StateHistoryStore interface {
+	// SaveStateHistory saves the given state transitions.
+	SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error
+	// GetStateHistory returns the state transitions that match the query, ordered by evaluation time. If the query
+	// has a limit, the newest state transitions are returned, or the oldest if the query is ascending.
+	GetStateHistory(ctx context.Context, query models.GetStateHistoryQuery) ([]models.StateHistoryEntry, error)
+	// CountStateHistory returns the number of state transitions that match the query per rule and bucket of
+	// models.HistoryAggregationInterval. The limit and the cursor of the query are ignored.
+	CountStateHistory(ctx context.Context, query models.GetStateHistoryQuery) ([]models.StateHistoryCount, error)
+}
+
+type StateHistoryAdminStore interface {
+	StateHistoryStore
+
+	// DeleteExpiredStateHistory deletes state transitions that are older than the configured
+	// retention. It returns the number of deleted entries or an error.
+	DeleteExpiredStateHistory(context.Context) (int64, error)
+}
+
+// stateHistoryBatchSize is the maximum number of entries inserted in a single statement.
+// This makes sure we don't create statements that are too long for some databases to process.
+const stateHistoryBatchSize = 50
+
+func (st DBstore) SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for i := 0; i < len(entries); i += stateHistoryBatchSize {
+			end := i + stateHistoryBatchSize
+			if end > len(entries) {
+				end = len(entries)
+			}
+			if _, err := sess.Table(&models.StateHistoryEntry{}).InsertMulti(entries[i:end]); err != nil {
+				return fmt.Errorf("failed to insert state history: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (st DBstore) GetStateHistory(ctx context.Context, query models.GetStateHistoryQuery) ([]models.StateHistoryEntry, error) {
+	var entries []models.StateHistoryEntry
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		cond, args := stateHistoryConditions(query)
+		q := sess.Table(&models.StateHistoryEntry{}).Where(cond, args...)
+		if query.Before != nil {
+			q = q.And("(evaluated_at < ? OR (evaluated_at = ? AND id < ?))", query.Before.EvaluatedAt, query.Before.EvaluatedAt, query.Before.ID)
+		}
+		if query.Ascending {
+			q = q.Asc("evaluated_at", "id")
+		} else {
+			// Order from the newest so that the limit drops the oldest entries.
+			q = q.Desc("evaluated_at", "id")
+		}
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit, query.Offset)
+		}
+		return q.Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state history: %w", err)
+	}
+	if !query.Ascending {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+	return entries, nil
+}
+
+func (st DBstore) CountStateHistory(ctx context.Context, query models.GetStateHistoryQuery) ([]models.StateHistoryCount, error) {
+	var counts []models.StateHistoryCount
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		cond, args := stateHistoryConditions(query)
+		// The interval is a constant, so it is part of the statement rather than a parameter, which some databases
+		// do not accept in the expressions of GROUP BY.
+		bucket := fmt.Sprintf("evaluated_at - (evaluated_at %% %d)", models.HistoryAggregationInterval.Nanoseconds())
+		return sess.Table(&models.StateHistoryEntry{}).
+			Select("rule_uid, "+bucket+" AS bucket, COUNT(*) AS transitions").
+			Where(cond, args...).
+			GroupBy("rule_uid, bucket").
+			Find(&counts)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count state history: %w", err)
+	}
+	return counts, nil
+}
+
+// stateHistoryConditions returns the WHERE clause and its arguments for the filters of the query.
+func stateHistoryConditions(query models.GetStateHistoryQuery) (string, []interface{}) {
+	conds := []string{"org_id = ?"}
+	args := []interface{}{query.OrgID}
+	if query.RuleUID != "" {
+		conds = append(conds, "rule_uid = ?")
+		args = append(args, query.RuleUID)
+	}
+	if !query.From.IsZero() {
+		conds = append(conds, "evaluated_at >= ?")
+		args = append(args, query.From.UnixNano())
+	}
+	if !query.To.IsZero() {
+		conds = append(conds, "evaluated_at <= ?")
+		args = append(args, query.To.UnixNano())
+	}
+	// States with a reason, such as "Normal (NoData)", match the state too.
+	if query.PreviousState != "" {
+		conds = append(conds, "(previous_state = ? OR previous_state LIKE ?)")
+		args = append(args, query.PreviousState, query.PreviousState+" (%")
+	}
+	if query.CurrentState != "" {
+		conds = append(conds, "(current_state = ? OR current_state LIKE ?)")
+		args = append(args, query.CurrentState, query.CurrentState+" (%")
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (st DBstore) DeleteExpiredStateHistory(ctx context.Context) (int64, error) {
+	if st.Cfg.StateHistory.SQLRetention <= 0 {
+		return 0, nil
+	}
+	before := TimeNow().Add(-st.Cfg.StateHistory.SQLRetention)
+	var n int64
+	if err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rows, err := sess.Where("evaluated_at < ?", before.UnixNano()).Delete(&models.StateHistoryEntry{})
+		if err != nil {
+			return fmt.Errorf("failed to delete expired state history: %w", err)
+		}
+		n = rows
+		return nil
+	}); err != nil {
+		return -1, err
+	}
+	return n, nil
+}
@@ -0,0 +1,135 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationStateHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	dbstore.Cfg.StateHistory.SQLRetention = time.Hour
+
+	now := time.Now()
+	entry := func(orgID int64, ruleUID string, at time.Time) models.StateHistoryEntry {
+		return models.StateHistoryEntry{
+			OrgID:         orgID,
+			RuleUID:       ruleUID,
+			Labels:        `{"a":"b"}`,
+			PreviousState: "Normal",
+			CurrentState:  "Alerting",
+			EvaluatedAt:   at.UnixNano(),
+		}
+	}
+	require.NoError(t, dbstore.SaveStateHistory(ctx, []models.StateHistoryEntry{
+		entry(1, "rule-1", now.Add(-2*time.Hour)),
+		entry(1, "rule-1", now.Add(-time.Minute)),
+		entry(1, "rule-1", now.Add(-2*time.Minute)),
+		entry(1, "rule-2", now.Add(-time.Minute)),
+		entry(2, "rule-1", now.Add(-time.Minute)),
+	}))
+
+	t.Run("should filter by org, rule and time range", func(t *testing.T) {
+		res, err := dbstore.GetStateHistory(ctx, models.GetStateHistoryQuery{
+			OrgID:   1,
+			RuleUID: "rule-1",
+			From:    now.Add(-time.Hour),
+			To:      now,
+		})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, now.Add(-2*time.Minute).UnixNano(), res[0].EvaluatedAt)
+		require.Equal(t, now.Add(-time.Minute).UnixNano(), res[1].EvaluatedAt)
+	})
+
+	t.Run("should return the newest entries within the limit", func(t *testing.T) {
+		res, err := dbstore.GetStateHistory(ctx, models.GetStateHistoryQuery{OrgID: 1, RuleUID: "rule-1", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, now.Add(-2*time.Minute).UnixNano(), res[0].EvaluatedAt)
+		require.Equal(t, now.Add(-time.Minute).UnixNano(), res[1].EvaluatedAt)
+	})
+
+	t.Run("should return a page of the entries from the oldest", func(t *testing.T) {
+		res, err := dbstore.GetStateHistory(ctx, models.GetStateHistoryQuery{OrgID: 1, RuleUID: "rule-1", Ascending: true, Offset: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, now.Add(-2*time.Minute).UnixNano(), res[0].EvaluatedAt)
+	})
+
+	t.Run("should return the entries before the given one", func(t *testing.T) {
+		newest, err := dbstore.GetStateHistory(ctx, models.GetStateHistoryQuery{OrgID: 1, RuleUID: "rule-1", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, newest, 1)
+
+		res, err := dbstore.GetStateHistory(ctx, models.GetStateHistoryQuery{OrgID: 1, RuleUID: "rule-1", Before: &newest[0], Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, now.Add(-2*time.Minute).UnixNano(), res[0].EvaluatedAt)
+	})
+
+	t.Run("should filter by previous and current state", func(t *testing.T) {
+		noData := entry(3, "rule-1", now.Add(-time.Minute))
+		noData.PreviousState = "Alerting"
+		noData.CurrentState = "Normal (NoData)"
+		require.NoError(t, dbstore.SaveStateHistory(ctx, []models.StateHistoryEntry{noData, entry(3, "rule-1", now.Add(-2*time.Minute))}))
+
+		res, err := dbstore.GetStateHistory(ctx, models.GetStateHistoryQuery{OrgID: 3, CurrentState: "Normal"})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, "Normal (NoData)", res[0].CurrentState)
+
+		res, err = dbstore.GetStateHistory(ctx, models.GetStateHistoryQuery{OrgID: 3, PreviousState: "Normal", CurrentState: "Alerting"})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, now.Add(-2*time.Minute).UnixNano(), res[0].EvaluatedAt)
+	})
+
+	t.Run("should count the entries per rule and bucket", func(t *testing.T) {
+		res, err := dbstore.CountStateHistory(ctx, models.GetStateHistoryQuery{OrgID: 1})
+		require.NoError(t, err)
+
+		bucket := func(t time.Time) int64 {
+			return t.Truncate(models.HistoryAggregationInterval).UnixNano()
+		}
+		expected := map[models.StateHistoryCount]struct{}{}
+		for _, c := range []models.StateHistoryCount{
+			{RuleUID: "rule-1", Bucket: bucket(now.Add(-2 * time.Hour)), Transitions: 1},
+			{RuleUID: "rule-2", Bucket: bucket(now.Add(-time.Minute)), Transitions: 1},
+		} {
+			expected[c] = struct{}{}
+		}
+		// The two recent transitions of rule-1 are in the same bucket unless the hour started between them.
+		if bucket(now.Add(-time.Minute)) == bucket(now.Add(-2*time.Minute)) {
+			expected[models.StateHistoryCount{RuleUID: "rule-1", Bucket: bucket(now.Add(-time.Minute)), Transitions: 2}] = struct{}{}
+		} else {
+			expected[models.StateHistoryCount{RuleUID: "rule-1", Bucket: bucket(now.Add(-time.Minute)), Transitions: 1}] = struct{}{}
+			expected[models.StateHistoryCount{RuleUID: "rule-1", Bucket: bucket(now.Add(-2 * time.Minute)), Transitions: 1}] = struct{}{}
+		}
+		actual := map[models.StateHistoryCount]struct{}{}
+		for _, c := range res {
+			actual[c] = struct{}{}
+		}
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("should delete entries past the retention", func(t *testing.T) {
+		n, err := dbstore.DeleteExpiredStateHistory(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+
+		res, err := dbstore.GetStateHistory(ctx, models.GetStateHistoryQuery{OrgID: 1, RuleUID: "rule-1"})
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+	})
+}
@@ -0,0 +1,109 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// PrometheusWriter writes the series of recording rules to a Prometheus remote write endpoint.
+type PrometheusWriter struct {
+	url               string
+	basicAuthUser     string
+	basicAuthPassword string
+	client            *http.Client
+	logger            log.Logger
+}
+
+func NewPrometheusWriter(cfg setting.UnifiedAlertingRecordingRulesSettings, l log.Logger) (*PrometheusWriter, error) {
+	if _, err := url.Parse(cfg.URL); err != nil {
+		return nil, fmt.Errorf("failed to parse remote write URL: %w", err)
+	}
+	return &PrometheusWriter{
+		url:               cfg.URL,
+		basicAuthUser:     cfg.BasicAuthUsername,
+		basicAuthPassword: cfg.BasicAuthPassword,
+		client:            &http.Client{Timeout: cfg.Timeout},
+		logger:            l,
+	}, nil
+}
+
+func (w *PrometheusWriter) Write(ctx context.Context, orgID int64, name string, t time.Time, points []Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	series, err := timeSeriesFromPoints(name, t, points)
+	if err != nil {
+		return err
+	}
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.basicAuthUser != "" || w.basicAuthPassword != "" {
+		req.SetBasicAuth(w.basicAuthUser, w.basicAuthPassword)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("remote write endpoint responded with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	w.logger.Debug("Wrote series of recording rule", "org_id", orgID, "metric", name, "series", len(series))
+	return nil
+}
+
+// timeSeriesFromPoints returns a series with a single sample at time t for each point.
+func timeSeriesFromPoints(name string, t time.Time, points []Point) ([]prompb.TimeSeries, error) {
+	timestamp := t.UnixNano() / int64(time.Millisecond)
+	series := make([]prompb.TimeSeries, 0, len(points))
+	for _, p := range points {
+		labels := make([]prompb.Label, 0, len(p.Labels)+1)
+		labels = append(labels, prompb.Label{Name: model.MetricNameLabel, Value: name})
+		for k, v := range p.Labels {
+			if k == model.MetricNameLabel {
+				continue
+			}
+			if !model.LabelName(k).IsValid() {
+				return nil, fmt.Errorf("invalid label name %q", k)
+			}
+			labels = append(labels, prompb.Label{Name: k, Value: v})
+		}
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].Name < labels[j].Name
+		})
+		series = append(series, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Timestamp: timestamp, Value: p.Value}},
+		})
+	}
+	return series, nil
+}
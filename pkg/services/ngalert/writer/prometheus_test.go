@@ -0,0 +1,79 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPrometheusWriter_Write(t *testing.T) {
+	var received *prompb.WriteRequest
+	var user, password string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ = r.BasicAuth()
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, b)
+		require.NoError(t, err)
+		received = &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(decoded, received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	w, err := NewPrometheusWriter(setting.UnifiedAlertingRecordingRulesSettings{
+		URL:               srv.URL,
+		BasicAuthUsername: "user",
+		BasicAuthPassword: "password",
+		Timeout:           time.Second,
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	now := time.UnixMilli(1000)
+	err = w.Write(context.Background(), 1, "test_metric", now, []Point{
+		{Labels: data.Labels{"team": "b", "instance": "a"}, Value: 1},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "user", user)
+	require.Equal(t, "password", password)
+	require.Equal(t, []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "test_metric"},
+				{Name: "instance", Value: "a"},
+				{Name: "team", Value: "b"},
+			},
+			Samples: []prompb.Sample{{Timestamp: 1000, Value: 1}},
+		},
+	}, received.Timeseries)
+
+	t.Run("returns error if the endpoint responds with an error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "out of order sample", http.StatusBadRequest)
+		}))
+		t.Cleanup(srv.Close)
+
+		w, err := NewPrometheusWriter(setting.UnifiedAlertingRecordingRulesSettings{URL: srv.URL, Timeout: time.Second}, log.NewNopLogger())
+		require.NoError(t, err)
+		err = w.Write(context.Background(), 1, "test_metric", now, []Point{{Labels: data.Labels{}, Value: 1}})
+		require.EqualError(t, err, "remote write endpoint responded with status 400: out of order sample")
+	})
+
+	t.Run("returns error if a label name is invalid", func(t *testing.T) {
+		err = w.Write(context.Background(), 1, "test_metric", now, []Point{{Labels: data.Labels{"invalid-name": "a"}, Value: 1}})
+		require.EqualError(t, err, `invalid label name "invalid-name"`)
+	})
+}
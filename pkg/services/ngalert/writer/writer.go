@@ -0,0 +1,76 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Writer writes the series of recording rules.
+type Writer interface {
+	// Write writes the points of the metric name at time t for the organization orgID.
+	Write(ctx context.Context, orgID int64, name string, t time.Time, points []Point) error
+}
+
+// Point is the value of a series at the time of an evaluation.
+type Point struct {
+	Labels data.Labels
+	Value  float64
+}
+
+// PointsFromFrames returns a point for each numeric field in the frames. Each field is a series,
+// and its value is the last value of the field that is not null, so that both numbers and time series
+// can be recorded. Fields without values are skipped. It returns an error if two fields have the same labels.
+func PointsFromFrames(frames data.Frames) ([]Point, error) {
+	var points []Point
+	seen := make(map[string]struct{})
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			value, ok := lastValue(field)
+			if !ok {
+				continue
+			}
+			labels := field.Labels.Copy()
+			if labels == nil {
+				labels = data.Labels{}
+			}
+			key := labels.String()
+			if _, ok := seen[key]; ok {
+				return nil, fmt.Errorf("duplicate series with labels {%s}", key)
+			}
+			seen[key] = struct{}{}
+			points = append(points, Point{Labels: labels, Value: value})
+		}
+	}
+	return points, nil
+}
+
+func lastValue(field *data.Field) (float64, bool) {
+	for i := field.Len() - 1; i >= 0; i-- {
+		if _, ok := field.ConcreteAt(i); !ok {
+			continue
+		}
+		v, err := field.FloatAt(i)
+		if err != nil {
+			return 0, false
+		}
+		return v, true
+	}
+	return 0, false
+}
+
+// NoopWriter is a Writer that discards the points, to be used when no destination for the series of recording rules is configured.
+type NoopWriter struct{}
+
+func NewNoopWriter() *NoopWriter {
+	return &NoopWriter{}
+}
+
+func (w *NoopWriter) Write(_ context.Context, _ int64, _ string, _ time.Time, _ []Point) error {
+	return nil
+}
@@ -0,0 +1,73 @@
+package writer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointsFromFrames(t *testing.T) {
+	one, two := 1.0, 2.0
+	now := time.Now()
+
+	testCases := []struct {
+		name     string
+		frames   data.Frames
+		expected []Point
+		err      string
+	}{
+		{
+			name: "numbers",
+			frames: data.Frames{
+				data.NewFrame("", data.NewField("", data.Labels{"instance": "a"}, []*float64{&one})),
+				data.NewFrame("", data.NewField("", data.Labels{"instance": "b"}, []*float64{&two})),
+			},
+			expected: []Point{
+				{Labels: data.Labels{"instance": "a"}, Value: 1},
+				{Labels: data.Labels{"instance": "b"}, Value: 2},
+			},
+		},
+		{
+			name: "time series use the last value that is not null",
+			frames: data.Frames{
+				data.NewFrame("",
+					data.NewField("time", nil, []time.Time{now.Add(-2 * time.Minute), now.Add(-time.Minute), now}),
+					data.NewField("value", nil, []*float64{&one, &two, nil}),
+				),
+			},
+			expected: []Point{
+				{Labels: data.Labels{}, Value: 2},
+			},
+		},
+		{
+			name: "fields without values are skipped",
+			frames: data.Frames{
+				data.NewFrame("", data.NewField("", data.Labels{"instance": "a"}, []*float64{nil})),
+				data.NewFrame("", data.NewField("", data.Labels{"instance": "b"}, []float64{})),
+			},
+			expected: nil,
+		},
+		{
+			name: "duplicate series",
+			frames: data.Frames{
+				data.NewFrame("", data.NewField("", data.Labels{"instance": "a"}, []*float64{&one})),
+				data.NewFrame("", data.NewField("", data.Labels{"instance": "a"}, []*float64{&two})),
+			},
+			err: "duplicate series with labels {instance=a}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			points, err := PointsFromFrames(tc.frames)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, points)
+		})
+	}
+}
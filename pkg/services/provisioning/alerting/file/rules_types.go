@@ -67,18 +67,68 @@ type AlertRuleGroupWithFolderTitle struct {
 }
 
 type AlertRuleV1 struct {
-	UID          values.StringValue    `json:"uid" yaml:"uid"`
-	Title        values.StringValue    `json:"title" yaml:"title"`
-	Condition    values.StringValue    `json:"condition" yaml:"condition"`
-	Data         []QueryV1             `json:"data" yaml:"data"`
-	DashboardUID values.StringValue    `json:"dasboardUid" yaml:"dashboardUid"`
-	PanelID      values.Int64Value     `json:"panelId" yaml:"panelId"`
-	NoDataState  values.StringValue    `json:"noDataState" yaml:"noDataState"`
-	ExecErrState values.StringValue    `json:"execErrState" yaml:"execErrState"`
-	For          values.StringValue    `json:"for" yaml:"for"`
-	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
-	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
-	IsPaused     values.BoolValue      `json:"isPaused" yaml:"isPaused"`
+	UID                  values.StringValue      `json:"uid" yaml:"uid"`
+	Title                values.StringValue      `json:"title" yaml:"title"`
+	Condition            values.StringValue      `json:"condition" yaml:"condition"`
+	Data                 []QueryV1               `json:"data" yaml:"data"`
+	DashboardUID         values.StringValue      `json:"dasboardUid" yaml:"dashboardUid"`
+	PanelID              values.Int64Value       `json:"panelId" yaml:"panelId"`
+	NoDataState          values.StringValue      `json:"noDataState" yaml:"noDataState"`
+	ExecErrState         values.StringValue      `json:"execErrState" yaml:"execErrState"`
+	For                  values.StringValue      `json:"for" yaml:"for"`
+	KeepFiringFor        values.StringValue      `json:"keepFiringFor" yaml:"keepFiringFor"`
+	Annotations          values.StringMapValue   `json:"annotations" yaml:"annotations"`
+	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
+	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
+	Record               *RecordV1               `json:"record" yaml:"record"`
+	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
+}
+
+type RecordV1 struct {
+	Metric values.StringValue `json:"metric" yaml:"metric"`
+	From   values.StringValue `json:"from" yaml:"from"`
+}
+
+type NotificationSettingsV1 struct {
+	Receiver          values.StringValue   `json:"receiver" yaml:"receiver"`
+	GroupBy           []values.StringValue `json:"group_by" yaml:"group_by"`
+	GroupWait         values.StringValue   `json:"group_wait" yaml:"group_wait"`
+	GroupInterval     values.StringValue   `json:"group_interval" yaml:"group_interval"`
+	RepeatInterval    values.StringValue   `json:"repeat_interval" yaml:"repeat_interval"`
+	MuteTimeIntervals []values.StringValue `json:"mute_time_intervals" yaml:"mute_time_intervals"`
+}
+
+func (s *NotificationSettingsV1) mapToModel() (models.NotificationSettings, error) {
+	result := models.NotificationSettings{
+		Receiver: s.Receiver.Value(),
+	}
+	for _, l := range s.GroupBy {
+		result.GroupBy = append(result.GroupBy, l.Value())
+	}
+	for _, name := range s.MuteTimeIntervals {
+		result.MuteTimeIntervals = append(result.MuteTimeIntervals, name.Value())
+	}
+	parseDuration := func(name string, v values.StringValue) (*model.Duration, error) {
+		if v.Value() == "" {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(v.Value())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		return &d, nil
+	}
+	var err error
+	if result.GroupWait, err = parseDuration("group_wait", s.GroupWait); err != nil {
+		return models.NotificationSettings{}, err
+	}
+	if result.GroupInterval, err = parseDuration("group_interval", s.GroupInterval); err != nil {
+		return models.NotificationSettings{}, err
+	}
+	if result.RepeatInterval, err = parseDuration("repeat_interval", s.RepeatInterval); err != nil {
+		return models.NotificationSettings{}, err
+	}
+	return result, nil
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -97,6 +147,13 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
 	}
 	alertRule.For = time.Duration(duration)
+	if keepFiringFor := rule.KeepFiringFor.Value(); keepFiringFor != "" {
+		duration, err := model.ParseDuration(keepFiringFor)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse keepFiringFor: %w", alertRule.Title, err)
+		}
+		alertRule.KeepFiringFor = time.Duration(duration)
+	}
 	dashboardUID := rule.DashboardUID.Value()
 	alertRule.DashboardUID = &dashboardUID
 	panelID := rule.PanelID.Value()
@@ -120,6 +177,16 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 	}
 	alertRule.NoDataState = noDataState
 	alertRule.Condition = rule.Condition.Value()
+	if rule.Record != nil {
+		alertRule.Record = &models.Record{
+			Metric: rule.Record.Metric.Value(),
+			From:   rule.Record.From.Value(),
+		}
+		// the condition of a recording rule is the query or expression to record
+		if alertRule.Condition == "" {
+			alertRule.Condition = alertRule.Record.From
+		}
+	}
 	if alertRule.Condition == "" {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no condition set", alertRule.Title)
 	}
@@ -136,6 +203,13 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no data set", alertRule.Title)
 	}
 	alertRule.IsPaused = rule.IsPaused.Value()
+	if rule.NotificationSettings != nil {
+		settings, err := rule.NotificationSettings.mapToModel()
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse notification settings: %w", alertRule.Title, err)
+		}
+		alertRule.NotificationSettings = &settings
+	}
 	return alertRule, nil
 }
 
@@ -178,13 +252,6 @@ func (queryV1 *QueryV1) mapToModel() (models.AlertQuery, error) {
 
 // Response structs
 
-// AlertingFileExport is the full provisioned file export.
-// swagger:model
-type AlertingFileExport struct {
-	APIVersion int64                  `json:"apiVersion" yaml:"apiVersion"`
-	Groups     []AlertRuleGroupExport `json:"groups" yaml:"groups"`
-}
-
 // AlertRuleGroupExport is the provisioned file export of AlertRuleGroupV1.
 type AlertRuleGroupExport struct {
 	OrgID    int64             `json:"orgId" yaml:"orgId"`
@@ -192,22 +259,43 @@ type AlertRuleGroupExport struct {
 	Folder   string            `json:"folder" yaml:"folder"`
 	Interval model.Duration    `json:"interval" yaml:"interval"`
 	Rules    []AlertRuleExport `json:"rules" yaml:"rules"`
+	// FolderUID is not part of the provisioned file, as rule groups are provisioned by folder title.
+	FolderUID string `json:"-" yaml:"-"`
 }
 
 // AlertRuleExport is the provisioned file export of models.AlertRule.
 type AlertRuleExport struct {
-	UID          string                     `json:"uid" yaml:"uid"`
-	Title        string                     `json:"title" yaml:"title"`
-	Condition    string                     `json:"condition" yaml:"condition"`
-	Data         []AlertQueryExport         `json:"data" yaml:"data"`
-	DashboardUID string                     `json:"dasboardUid,omitempty" yaml:"dashboardUid,omitempty"`
-	PanelID      int64                      `json:"panelId,omitempty" yaml:"panelId,omitempty"`
-	NoDataState  models.NoDataState         `json:"noDataState" yaml:"noDataState"`
-	ExecErrState models.ExecutionErrorState `json:"execErrState" yaml:"execErrState"`
-	For          model.Duration             `json:"for" yaml:"for"`
-	Annotations  map[string]string          `json:"annotations,omitempty" yaml:"annotations,omitempty"`
-	Labels       map[string]string          `json:"labels,omitempty" yaml:"labels,omitempty"`
-	IsPaused     bool                       `json:"isPaused" yaml:"isPaused"`
+	UID                  string                               `json:"uid" yaml:"uid"`
+	Title                string                               `json:"title" yaml:"title"`
+	Condition            string                               `json:"condition" yaml:"condition"`
+	Data                 []AlertQueryExport                   `json:"data" yaml:"data"`
+	DashboardUID         string                               `json:"dasboardUid,omitempty" yaml:"dashboardUid,omitempty"`
+	PanelID              int64                                `json:"panelId,omitempty" yaml:"panelId,omitempty"`
+	NoDataState          models.NoDataState                   `json:"noDataState" yaml:"noDataState"`
+	ExecErrState         models.ExecutionErrorState           `json:"execErrState" yaml:"execErrState"`
+	For                  model.Duration                       `json:"for" yaml:"for"`
+	KeepFiringFor        model.Duration                       `json:"keepFiringFor,omitempty" yaml:"keepFiringFor,omitempty"`
+	Annotations          map[string]string                    `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Labels               map[string]string                    `json:"labels,omitempty" yaml:"labels,omitempty"`
+	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused"`
+	Record               *AlertRecordExport                   `json:"record,omitempty" yaml:"record,omitempty"`
+	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+}
+
+// AlertRecordExport is the provisioned export of models.Record.
+type AlertRecordExport struct {
+	Metric string `json:"metric" yaml:"metric"`
+	From   string `json:"from" yaml:"from"`
+}
+
+// AlertRuleNotificationSettingsExport is the provisioned export of models.NotificationSettings.
+type AlertRuleNotificationSettingsExport struct {
+	Receiver          string          `json:"receiver" yaml:"receiver"`
+	GroupBy           []string        `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+	GroupWait         *model.Duration `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+	GroupInterval     *model.Duration `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+	RepeatInterval    *model.Duration `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+	MuteTimeIntervals []string        `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -219,17 +307,17 @@ type AlertQueryExport struct {
 	Model             map[string]interface{}   `json:"model" yaml:"model"`
 }
 
-// NewAlertingFileExport creates an AlertingFileExport DTO from []AlertRuleGroupWithFolderTitle.
-func NewAlertingFileExport(groups []AlertRuleGroupWithFolderTitle) (AlertingFileExport, error) {
-	f := AlertingFileExport{APIVersion: 1}
+// NewAlertRuleGroupExports creates AlertRuleGroupExport DTOs from []AlertRuleGroupWithFolderTitle.
+func NewAlertRuleGroupExports(groups []AlertRuleGroupWithFolderTitle) ([]AlertRuleGroupExport, error) {
+	exports := make([]AlertRuleGroupExport, 0, len(groups))
 	for _, group := range groups {
 		export, err := newAlertRuleGroupExport(group)
 		if err != nil {
-			return AlertingFileExport{}, err
+			return nil, err
 		}
-		f.Groups = append(f.Groups, export)
+		exports = append(exports, export)
 	}
-	return f, nil
+	return exports, nil
 }
 
 // newAlertRuleGroupExport creates a AlertRuleGroupExport DTO from models.AlertRuleGroup.
@@ -243,11 +331,12 @@ func newAlertRuleGroupExport(d AlertRuleGroupWithFolderTitle) (AlertRuleGroupExp
 		rules = append(rules, alert)
 	}
 	return AlertRuleGroupExport{
-		OrgID:    d.OrgID,
-		Name:     d.Title,
-		Folder:   d.FolderTitle,
-		Interval: model.Duration(time.Duration(d.Interval) * time.Second),
-		Rules:    rules,
+		OrgID:     d.OrgID,
+		Name:      d.Title,
+		Folder:    d.FolderTitle,
+		Interval:  model.Duration(time.Duration(d.Interval) * time.Second),
+		Rules:     rules,
+		FolderUID: d.FolderUID,
 	}, nil
 }
 
@@ -272,19 +361,42 @@ func newAlertRuleExport(rule models.AlertRule) (AlertRuleExport, error) {
 		panelID = *rule.PanelID
 	}
 
+	var record *AlertRecordExport
+	if rule.Record != nil {
+		record = &AlertRecordExport{
+			Metric: rule.Record.Metric,
+			From:   rule.Record.From,
+		}
+	}
+
+	var notificationSettings *AlertRuleNotificationSettingsExport
+	if rule.NotificationSettings != nil {
+		notificationSettings = &AlertRuleNotificationSettingsExport{
+			Receiver:          rule.NotificationSettings.Receiver,
+			GroupBy:           rule.NotificationSettings.GroupBy,
+			GroupWait:         rule.NotificationSettings.GroupWait,
+			GroupInterval:     rule.NotificationSettings.GroupInterval,
+			RepeatInterval:    rule.NotificationSettings.RepeatInterval,
+			MuteTimeIntervals: rule.NotificationSettings.MuteTimeIntervals,
+		}
+	}
+
 	return AlertRuleExport{
-		UID:          rule.UID,
-		Title:        rule.Title,
-		For:          model.Duration(rule.For),
-		Condition:    rule.Condition,
-		Data:         data,
-		DashboardUID: dashboardUID,
-		PanelID:      panelID,
-		NoDataState:  rule.NoDataState,
-		ExecErrState: rule.ExecErrState,
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		IsPaused:     rule.IsPaused,
+		UID:                  rule.UID,
+		Title:                rule.Title,
+		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
+		Condition:            rule.Condition,
+		Data:                 data,
+		DashboardUID:         dashboardUID,
+		PanelID:              panelID,
+		NoDataState:          rule.NoDataState,
+		ExecErrState:         rule.ExecErrState,
+		Annotations:          rule.Annotations,
+		Labels:               rule.Labels,
+		IsPaused:             rule.IsPaused,
+		Record:               record,
+		NotificationSettings: notificationSettings,
 	}, nil
 }
 
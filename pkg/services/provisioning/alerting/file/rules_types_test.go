@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
@@ -125,6 +126,31 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 48*time.Hour, ruleMapped.For)
 	})
+	t.Run("a rule with out a keepFiringFor duration should not keep firing", func(t *testing.T) {
+		rule := validRuleV1(t)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Zero(t, ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with an invalid keepFiringFor duration should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		keepFiringFor := values.StringValue{}
+		err := yaml.Unmarshal([]byte("10x"), &keepFiringFor)
+		rule.KeepFiringFor = keepFiringFor
+		require.NoError(t, err)
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with a keepFiringFor duration should map it correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		keepFiringFor := values.StringValue{}
+		err := yaml.Unmarshal([]byte("15m"), &keepFiringFor)
+		rule.KeepFiringFor = keepFiringFor
+		require.NoError(t, err)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 15*time.Minute, ruleMapped.KeepFiringFor)
+	})
 	t.Run("a rule with out a condition should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Condition = values.StringValue{}
@@ -187,6 +213,50 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, ruleMapped.NoDataState, models.NoData)
 	})
+	t.Run("a recording rule with out a condition should use the recorded query as condition", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.Condition = values.StringValue{}
+		var metric, from values.StringValue
+		require.NoError(t, yaml.Unmarshal([]byte("test_metric"), &metric))
+		require.NoError(t, yaml.Unmarshal([]byte("A"), &from))
+		rule.Record = &RecordV1{Metric: metric, From: from}
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, &models.Record{Metric: "test_metric", From: "A"}, ruleMapped.Record)
+		require.Equal(t, "A", ruleMapped.Condition)
+	})
+	t.Run("a rule with notification settings should map them correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		var settings NotificationSettingsV1
+		err := yaml.Unmarshal([]byte(`
+receiver: test-receiver
+group_by: [pod]
+group_wait: 30s
+repeat_interval: 4h
+mute_time_intervals: [weekends]
+`), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		groupWait, repeatInterval := model.Duration(30*time.Second), model.Duration(4*time.Hour)
+		require.Equal(t, &models.NotificationSettings{
+			Receiver:          "test-receiver",
+			GroupBy:           []string{"pod"},
+			GroupWait:         &groupWait,
+			RepeatInterval:    &repeatInterval,
+			MuteTimeIntervals: []string{"weekends"},
+		}, ruleMapped.NotificationSettings)
+	})
+	t.Run("a rule with invalid notification settings timings should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		var settings NotificationSettingsV1
+		err := yaml.Unmarshal([]byte("{receiver: test-receiver, group_interval: 10x}"), &settings)
+		require.NoError(t, err)
+		rule.NotificationSettings = &settings
+		_, err = rule.mapToModel(1)
+		require.ErrorContains(t, err, "failed to parse group_interval")
+	})
 }
 
 func validRuleGroupV1(t *testing.T) AlertRuleGroupV1 {
@@ -268,6 +268,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 	}
 	ruleService := provisioning.NewAlertRuleService(
 		st,
+		&st,
 		st,
 		ps.dashboardService,
 		ps.quotaService,
@@ -275,11 +276,11 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		int64(ps.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ps.Cfg.UnifiedAlerting.BaseInterval.Seconds()),
 		ps.log)
-	contactPointService := provisioning.NewContactPointService(&st, ps.secretService,
+	contactPointService := provisioning.NewContactPointService(&st, st, ps.secretService,
 		st, ps.SQLStore, ps.log)
 	notificationPolicyService := provisioning.NewNotificationPolicyService(&st,
 		st, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
-	mutetimingsService := provisioning.NewMuteTimingService(&st, st, &st, ps.log)
+	mutetimingsService := provisioning.NewMuteTimingService(&st, st, st, &st, ps.log)
 	templateService := provisioning.NewTemplateService(&st, st, &st, ps.log)
 	cfg := prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
@@ -105,6 +105,8 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	AddExternalAlertmanagerToDatasourceMigration(mg)
 
 	addFolderMigrations(mg)
+
+	addLivePipelineMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
@@ -160,6 +160,10 @@ func newStorage(cfg RootStorageConfig, localWorkCache string) (storageRuntime, e
 		return newDiskStorage(RootStorageMeta{}, cfg), nil
 	case rootStorageTypeGit:
 		return newGitStorage(RootStorageMeta{}, cfg, localWorkCache), nil
+	case rootStorageTypeS3:
+		return newS3Storage(RootStorageMeta{}, cfg), nil
+	case rootStorageTypeGCS:
+		return newGCSStorage(RootStorageMeta{}, cfg), nil
 	}
 
 	return nil, fmt.Errorf("unsupported store: " + cfg.Type)
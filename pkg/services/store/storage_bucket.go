@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"gocloud.dev/blob"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+)
+
+var _ storageRuntime = &rootStorageBucket{}
+
+// rootStorageBucket keeps files in an object store bucket (S3, GCS). Folders are
+// emulated by filestorage, so the storage behaves like a local disk that can be
+// shared between all Grafana instances.
+type rootStorageBucket struct {
+	meta  RootStorageMeta
+	store filestorage.FileStorage
+}
+
+type openBucketFunc func(ctx context.Context) (*blob.Bucket, error)
+
+func newBucketStorage(meta RootStorageMeta, scfg RootStorageConfig, bucketName string, folder string, openBucket openBucketFunc) *rootStorageBucket {
+	meta.Config = scfg
+	if scfg.Prefix == "" {
+		meta.Notice = append(meta.Notice, data.Notice{
+			Severity: data.NoticeSeverityError,
+			Text:     "Missing prefix",
+		})
+	}
+	if bucketName == "" {
+		meta.Notice = append(meta.Notice, data.Notice{
+			Severity: data.NoticeSeverityError,
+			Text:     "Missing bucket configuration",
+		})
+	}
+
+	s := &rootStorageBucket{}
+
+	if meta.Notice == nil {
+		bucket, err := openBucket(context.Background())
+		if err != nil {
+			grafanaStorageLogger.Warn("error loading storage", "prefix", scfg.Prefix, "err", err)
+			meta.Notice = append(meta.Notice, data.Notice{
+				Severity: data.NoticeSeverityError,
+				Text:     "Failed to initialize storage",
+			})
+		} else {
+			s.store = filestorage.NewCdkBlobStorage(grafanaStorageLogger,
+				bucket, getBucketRootFolder(folder), nil)
+
+			meta.Ready = true
+		}
+	}
+
+	s.meta = meta
+	return s
+}
+
+// getBucketRootFolder converts a folder within a bucket to the root folder
+// expected by filestorage, e.g. "/a/b" => "a/b/".
+func getBucketRootFolder(folder string) string {
+	folder = strings.Trim(folder, filestorage.Delimiter)
+	if folder == "" {
+		return ""
+	}
+	return folder + filestorage.Delimiter
+}
+
+func (s *rootStorageBucket) Meta() RootStorageMeta {
+	return s.meta
+}
+
+func (s *rootStorageBucket) Store() filestorage.FileStorage {
+	return s.store
+}
+
+func (s *rootStorageBucket) Sync() error {
+	return nil // always in sync
+}
+
+func (s *rootStorageBucket) Write(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error) {
+	path := cmd.Path
+	if !strings.HasPrefix(path, filestorage.Delimiter) {
+		path = filestorage.Delimiter + path
+	}
+	err := s.store.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:     path,
+		Contents: []byte(cmd.Body),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WriteValueResponse{Code: 200}, nil
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+)
+
+func TestBucketStorage(t *testing.T) {
+	ctx := context.Background()
+	bucket := memblob.OpenBucket(nil)
+	s := newBucketStorage(RootStorageMeta{}, RootStorageConfig{Prefix: "bucket"}, "test", "/grafana/", func(ctx context.Context) (*blob.Bucket, error) {
+		return bucket, nil
+	})
+	require.True(t, s.Meta().Ready)
+	require.Empty(t, s.Meta().Notice)
+
+	_, err := s.Write(ctx, &WriteValueRequest{Path: "dashboards/a.json", Body: []byte(`{"title":"a"}`)})
+	require.NoError(t, err)
+	err = s.Store().CreateFolder(ctx, "/images")
+	require.NoError(t, err)
+
+	// Files are kept under the configured folder.
+	exists, err := bucket.Exists(ctx, "grafana/dashboards/a.json")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	file, ok, err := s.Store().Get(ctx, "/dashboards/a.json", nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, `{"title":"a"}`, string(file.Contents))
+
+	resp, err := s.Store().List(ctx, "/", nil, &filestorage.ListOptions{WithFolders: true})
+	require.NoError(t, err)
+	paths := make([]string, 0, len(resp.Files))
+	for _, f := range resp.Files {
+		paths = append(paths, f.FullPath)
+	}
+	require.ElementsMatch(t, []string{"/dashboards", "/images"}, paths)
+
+	err = s.Store().Delete(ctx, "/dashboards/a.json")
+	require.NoError(t, err)
+	_, ok, err = s.Store().Get(ctx, "/dashboards/a.json", nil)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	err = s.Store().DeleteFolder(ctx, "/images", &filestorage.DeleteFolderOptions{})
+	require.NoError(t, err)
+	resp, err = s.Store().List(ctx, "/", nil, &filestorage.ListOptions{WithFolders: true})
+	require.NoError(t, err)
+	for _, f := range resp.Files {
+		require.NotEqual(t, "/images", f.FullPath)
+	}
+}
+
+func TestNewObjectStorage(t *testing.T) {
+	t.Run("s3 storage requires bucket", func(t *testing.T) {
+		s := newS3Storage(RootStorageMeta{}, RootStorageConfig{Prefix: "s3"})
+		require.False(t, s.Meta().Ready)
+		require.Equal(t, rootStorageTypeS3, s.Meta().Config.Type)
+		require.Len(t, s.Meta().Notice, 1)
+		require.Equal(t, "Missing bucket configuration", s.Meta().Notice[0].Text)
+	})
+
+	t.Run("s3 storage with bucket", func(t *testing.T) {
+		s := newS3Storage(RootStorageMeta{}, RootStorageConfig{
+			Prefix: "s3",
+			S3: &StorageS3Config{
+				Bucket:    "grafana",
+				Region:    "us-east-1",
+				AccessKey: "key",
+				SecretKey: "secret",
+			},
+		})
+		require.True(t, s.Meta().Ready)
+		require.NotNil(t, s.Store())
+	})
+
+	t.Run("gcs storage with missing credentials file", func(t *testing.T) {
+		s := newGCSStorage(RootStorageMeta{}, RootStorageConfig{
+			Prefix: "gcs",
+			GCS: &StorageGCSConfig{
+				Bucket:          "grafana",
+				CredentialsFile: filepath.Join(t.TempDir(), "missing.json"),
+			},
+		})
+		require.False(t, s.Meta().Ready)
+		require.Equal(t, rootStorageTypeGCS, s.Meta().Config.Type)
+		require.Equal(t, "Failed to initialize storage", s.Meta().Notice[0].Text)
+	})
+}
+
+func TestGetBucketRootFolder(t *testing.T) {
+	require.Equal(t, "", getBucketRootFolder(""))
+	require.Equal(t, "", getBucketRootFolder("/"))
+	require.Equal(t, "a/b/", getBucketRootFolder("/a/b"))
+	require.Equal(t, "a/", getBucketRootFolder("a/"))
+}
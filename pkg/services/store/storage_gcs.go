@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+
+	"cloud.google.com/go/storage"
+	"gocloud.dev/blob"
+	"google.golang.org/api/option"
+)
+
+const rootStorageTypeGCS = "gcs"
+
+func newGCSStorage(meta RootStorageMeta, scfg RootStorageConfig) *rootStorageBucket {
+	cfg := scfg.GCS
+	if cfg == nil {
+		cfg = &StorageGCSConfig{}
+		scfg.GCS = cfg
+	}
+	scfg.Type = rootStorageTypeGCS
+
+	return newBucketStorage(meta, scfg, cfg.Bucket, cfg.Folder, func(ctx context.Context) (*blob.Bucket, error) {
+		var opts []option.ClientOption
+		// Use the application default credentials when credentials file is not configured.
+		if cfg.CredentialsFile != "" {
+			opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+		}
+		client, err := storage.NewClient(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return openGCSBucket(client, cfg.Bucket), nil
+	})
+}
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"cloud.google.com/go/storage"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/driver"
+	"gocloud.dev/gcerrors"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+// gcsBucket is a gocloud blob driver for GCS on top of the GCS client library.
+// The gocloud gcsblob driver can not be used together with the pinned genproto version.
+type gcsBucket struct {
+	name   string
+	client *storage.Client
+}
+
+var _ driver.Bucket = &gcsBucket{}
+
+var errGCSNotImplemented = errors.New("not implemented")
+
+const gcsDefaultPageSize = 1000
+
+func openGCSBucket(client *storage.Client, name string) *blob.Bucket {
+	return blob.NewBucket(&gcsBucket{name: name, client: client})
+}
+
+func (b *gcsBucket) object(key string) *storage.ObjectHandle {
+	return b.client.Bucket(b.name).Object(key)
+}
+
+func (b *gcsBucket) ErrorCode(err error) gcerrors.ErrorCode {
+	if errors.Is(err, storage.ErrObjectNotExist) || errors.Is(err, storage.ErrBucketNotExist) {
+		return gcerrors.NotFound
+	}
+	if errors.Is(err, errGCSNotImplemented) {
+		return gcerrors.Unimplemented
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusNotFound:
+			return gcerrors.NotFound
+		case http.StatusPreconditionFailed:
+			return gcerrors.FailedPrecondition
+		case http.StatusForbidden, http.StatusUnauthorized:
+			return gcerrors.PermissionDenied
+		case http.StatusTooManyRequests:
+			return gcerrors.ResourceExhausted
+		}
+	}
+	return gcerrors.Unknown
+}
+
+func (b *gcsBucket) As(i interface{}) bool {
+	p, ok := i.(**storage.Client)
+	if !ok {
+		return false
+	}
+	*p = b.client
+	return true
+}
+
+func (b *gcsBucket) ErrorAs(err error, i interface{}) bool {
+	return errors.As(err, i)
+}
+
+func (b *gcsBucket) Attributes(ctx context.Context, key string) (*driver.Attributes, error) {
+	attrs, err := b.object(key).Attrs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &driver.Attributes{
+		CacheControl:       attrs.CacheControl,
+		ContentDisposition: attrs.ContentDisposition,
+		ContentEncoding:    attrs.ContentEncoding,
+		ContentLanguage:    attrs.ContentLanguage,
+		ContentType:        attrs.ContentType,
+		Metadata:           attrs.Metadata,
+		CreateTime:         attrs.Created,
+		ModTime:            attrs.Updated,
+		Size:               attrs.Size,
+		MD5:                attrs.MD5,
+		ETag:               attrs.Etag,
+	}, nil
+}
+
+func (b *gcsBucket) ListPaged(ctx context.Context, opts *driver.ListOptions) (*driver.ListPage, error) {
+	pageSize := opts.PageSize
+	if pageSize == 0 {
+		pageSize = gcsDefaultPageSize
+	}
+	it := b.client.Bucket(b.name).Objects(ctx, &storage.Query{
+		Prefix:    opts.Prefix,
+		Delimiter: opts.Delimiter,
+	})
+	var objects []*storage.ObjectAttrs
+	nextPageToken, err := iterator.NewPager(it, pageSize, string(opts.PageToken)).NextPage(&objects)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &driver.ListPage{Objects: make([]*driver.ListObject, 0, len(objects))}
+	for _, obj := range objects {
+		if obj.Prefix != "" {
+			page.Objects = append(page.Objects, &driver.ListObject{
+				Key:   obj.Prefix,
+				IsDir: true,
+			})
+			continue
+		}
+		page.Objects = append(page.Objects, &driver.ListObject{
+			Key:     obj.Name,
+			ModTime: obj.Updated,
+			Size:    obj.Size,
+			MD5:     obj.MD5,
+		})
+	}
+	if nextPageToken != "" {
+		page.NextPageToken = []byte(nextPageToken)
+	}
+	return page, nil
+}
+
+type gcsReader struct {
+	*storage.Reader
+}
+
+func (r *gcsReader) Attributes() *driver.ReaderAttributes {
+	return &driver.ReaderAttributes{
+		ContentType: r.Reader.Attrs.ContentType,
+		ModTime:     r.Reader.Attrs.LastModified,
+		Size:        r.Reader.Attrs.Size,
+	}
+}
+
+func (r *gcsReader) As(i interface{}) bool {
+	p, ok := i.(**storage.Reader)
+	if !ok {
+		return false
+	}
+	*p = r.Reader
+	return true
+}
+
+func (b *gcsBucket) NewRangeReader(ctx context.Context, key string, offset, length int64, _ *driver.ReaderOptions) (driver.Reader, error) {
+	r, err := b.object(key).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	return &gcsReader{Reader: r}, nil
+}
+
+func (b *gcsBucket) NewTypedWriter(ctx context.Context, key, contentType string, opts *driver.WriterOptions) (driver.Writer, error) {
+	w := b.object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	w.CacheControl = opts.CacheControl
+	w.ContentDisposition = opts.ContentDisposition
+	w.ContentEncoding = opts.ContentEncoding
+	w.ContentLanguage = opts.ContentLanguage
+	w.MD5 = opts.ContentMD5
+	w.Metadata = opts.Metadata
+	if opts.BufferSize > 0 {
+		w.ChunkSize = opts.BufferSize
+	}
+	return w, nil
+}
+
+func (b *gcsBucket) Copy(ctx context.Context, dstKey, srcKey string, _ *driver.CopyOptions) error {
+	_, err := b.object(dstKey).CopierFrom(b.object(srcKey)).Run(ctx)
+	return err
+}
+
+func (b *gcsBucket) Delete(ctx context.Context, key string) error {
+	return b.object(key).Delete(ctx)
+}
+
+func (b *gcsBucket) SignedURL(_ context.Context, _ string, _ *driver.SignedURLOptions) (string, error) {
+	return "", errGCSNotImplemented
+}
+
+func (b *gcsBucket) Close() error {
+	return b.client.Close()
+}
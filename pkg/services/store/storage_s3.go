@@ -0,0 +1,38 @@
+package store
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/s3blob"
+)
+
+const rootStorageTypeS3 = "s3"
+
+func newS3Storage(meta RootStorageMeta, scfg RootStorageConfig) *rootStorageBucket {
+	cfg := scfg.S3
+	if cfg == nil {
+		cfg = &StorageS3Config{}
+		scfg.S3 = cfg
+	}
+	scfg.Type = rootStorageTypeS3
+
+	return newBucketStorage(meta, scfg, cfg.Bucket, cfg.Folder, func(ctx context.Context) (*blob.Bucket, error) {
+		awsCfg := aws.NewConfig()
+		if cfg.Region != "" {
+			awsCfg = awsCfg.WithRegion(cfg.Region)
+		}
+		// Use the default AWS credentials chain when keys are not configured.
+		if cfg.AccessKey != "" && cfg.SecretKey != "" {
+			awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""))
+		}
+		sess, err := session.NewSession(awsCfg)
+		if err != nil {
+			return nil, err
+		}
+		return s3blob.OpenBucket(ctx, sess, cfg.Bucket, nil)
+	})
+}
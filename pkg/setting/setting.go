@@ -450,6 +450,9 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LivePipelineStorage is a type of storage for Live pipeline channel rules
+	// and write configs: "file" or "database".
+	LivePipelineStorage string
 
 	// Github OAuth
 	GithubSkipOrgRoleSync bool
@@ -1839,6 +1842,12 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return fmt.Errorf("unsupported live HA engine type: %s", cfg.LiveHAEngine)
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
+	cfg.LivePipelineStorage = section.Key("pipeline_storage").MustString("file")
+	switch cfg.LivePipelineStorage {
+	case "file", "database":
+	default:
+		return fmt.Errorf("unsupported live pipeline storage type: %s", cfg.LivePipelineStorage)
+	}
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
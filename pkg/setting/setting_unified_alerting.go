@@ -58,8 +58,33 @@ const (
 	// with intervals that are not exactly divided by this number not to be evaluated
 	SchedulerBaseInterval = 10 * time.Second
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
-	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
-	stateHistoryDefaultEnabled    = true
+	DefaultRuleEvaluationInterval   = SchedulerBaseInterval * 6 // == 60 seconds
+	stateHistoryDefaultEnabled      = true
+	stateHistoryDefaultSQLRetention = 30 * 24 * time.Hour
+	notificationLogDefaultEnabled   = true
+	notificationLogDefaultRetention = 7 * 24 * time.Hour
+	recordingRulesDefaultTimeout    = 10 * time.Second
+	haShardingDefaultHeartbeat      = 10 * time.Second
+	haShardingDefaultTimeout        = time.Minute
+)
+
+const (
+	// HAShardingNone disables the sharding of alert rules. Every instance evaluates every alert rule.
+	HAShardingNone = "none"
+	// HAShardingGossip shards alert rules across the members of the HA cluster of the Alertmanager.
+	HAShardingGossip = "gossip"
+	// HAShardingDatabase shards alert rules across the instances that heartbeat in the database.
+	HAShardingDatabase = "database"
+)
+
+const (
+	// JitterEvaluationsDisabled evaluates every alert rule at the start of its evaluation interval.
+	JitterEvaluationsDisabled = "disabled"
+	// JitterEvaluationsByGroup spreads the evaluations of rule groups across their evaluation interval.
+	// The rules of a group are evaluated at the same time.
+	JitterEvaluationsByGroup = "group"
+	// JitterEvaluationsByRule spreads the evaluations of alert rules across their evaluation interval.
+	JitterEvaluationsByRule = "rule"
 )
 
 type UnifiedAlertingSettings struct {
@@ -86,6 +111,18 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	RecordingRules                UnifiedAlertingRecordingRulesSettings
+	NotificationLog               UnifiedAlertingNotificationLogSettings
+	// HASharding is the source of membership used to shard the evaluation of alert rules across instances.
+	HASharding string
+	// HAShardingHeartbeatInterval is the interval between heartbeats when HASharding is HAShardingDatabase.
+	HAShardingHeartbeatInterval time.Duration
+	// HAShardingHeartbeatTimeout is the time after which an instance that has not sent a heartbeat is considered gone.
+	HAShardingHeartbeatTimeout time.Duration
+	// SequentialRuleGroupEvaluation enables the evaluation of the rules of a group in order, each rule after the previous one.
+	SequentialRuleGroupEvaluation bool
+	// JitterEvaluations defines how the evaluations of alert rules are spread across their evaluation interval.
+	JitterEvaluations string
 }
 
 type UnifiedAlertingScreenshotSettings struct {
@@ -111,6 +148,29 @@ type UnifiedAlertingStateHistorySettings struct {
 	LokiBasicAuthPassword string
 	LokiBasicAuthUsername string
 	ExternalLabels        map[string]string
+	// SQLRetention is how long state transitions are kept by the sql backend.
+	// Zero or a negative value disables the clean up of old entries.
+	SQLRetention time.Duration
+}
+
+type UnifiedAlertingRecordingRulesSettings struct {
+	Enabled bool
+	// URL is the Prometheus remote write endpoint the series of recording rules are written to.
+	// If it is empty, the series are not written.
+	URL string
+	// BasicAuthUsername and BasicAuthPassword are used for basic auth
+	// if one of them is set.
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
+type UnifiedAlertingNotificationLogSettings struct {
+	// Enabled records every attempt of the Grafana Alertmanager to send a notification in the database.
+	Enabled bool
+	// Retention is how long the entries of the notification log are kept.
+	// Zero or a negative value disables the clean up of old entries.
+	Retention time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -230,6 +290,26 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 			uaCfg.HAPeers = append(uaCfg.HAPeers, peer)
 		}
 	}
+	uaCfg.HASharding = valueAsString(ua, "ha_sharding", HAShardingNone)
+	switch uaCfg.HASharding {
+	case HAShardingNone, HAShardingGossip, HAShardingDatabase:
+	default:
+		return fmt.Errorf("invalid value for ha_sharding: %q, must be one of %q, %q or %q", uaCfg.HASharding, HAShardingNone, HAShardingGossip, HAShardingDatabase)
+	}
+	if uaCfg.HASharding == HAShardingGossip && len(uaCfg.HAPeers) == 0 {
+		return errors.New("ha_sharding 'gossip' requires ha_peers to be configured")
+	}
+	uaCfg.HAShardingHeartbeatInterval, err = gtime.ParseDuration(valueAsString(ua, "ha_sharding_heartbeat_interval", haShardingDefaultHeartbeat.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.HAShardingHeartbeatTimeout, err = gtime.ParseDuration(valueAsString(ua, "ha_sharding_heartbeat_timeout", haShardingDefaultTimeout.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.HAShardingHeartbeatTimeout <= uaCfg.HAShardingHeartbeatInterval {
+		return fmt.Errorf("ha_sharding_heartbeat_timeout (%s) must be greater than ha_sharding_heartbeat_interval (%s)", uaCfg.HAShardingHeartbeatTimeout, uaCfg.HAShardingHeartbeatInterval)
+	}
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -295,6 +375,14 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfg.DefaultRuleEvaluationInterval = uaMinInterval
 	}
 
+	uaCfg.SequentialRuleGroupEvaluation = ua.Key("sequential_rule_group_evaluation").MustBool(false)
+	uaCfg.JitterEvaluations = valueAsString(ua, "jitter_evaluations", JitterEvaluationsDisabled)
+	switch uaCfg.JitterEvaluations {
+	case JitterEvaluationsDisabled, JitterEvaluationsByGroup, JitterEvaluationsByRule:
+	default:
+		return fmt.Errorf("invalid value for jitter_evaluations: %q, must be one of %q, %q or %q", uaCfg.JitterEvaluations, JitterEvaluationsDisabled, JitterEvaluationsByGroup, JitterEvaluationsByRule)
+	}
+
 	screenshots := iniFile.Section("unified_alerting.screenshots")
 	uaCfgScreenshots := uaCfg.Screenshots
 
@@ -331,9 +419,25 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		LokiBasicAuthUsername: stateHistory.Key("loki_basic_auth_username").MustString(""),
 		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
+		SQLRetention:          stateHistory.Key("sql_retention").MustDuration(stateHistoryDefaultSQLRetention),
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfg.RecordingRules = UnifiedAlertingRecordingRulesSettings{
+		Enabled:           recordingRules.Key("enabled").MustBool(false),
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+		Timeout:           recordingRules.Key("timeout").MustDuration(recordingRulesDefaultTimeout),
+	}
+
+	notificationLog := iniFile.Section("unified_alerting.notification_log")
+	uaCfg.NotificationLog = UnifiedAlertingNotificationLogSettings{
+		Enabled:   notificationLog.Key("enabled").MustBool(notificationLogDefaultEnabled),
+		Retention: notificationLog.Key("retention").MustDuration(notificationLogDefaultRetention),
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -266,3 +266,69 @@ func TestMinInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestHASharding(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected string
+		err      string
+	}{
+		{desc: "should default to none", expected: HAShardingNone},
+		{desc: "should accept database", value: HAShardingDatabase, expected: HAShardingDatabase},
+		{desc: "should fail on an invalid value", value: "databse", err: `invalid value for ha_sharding: "databse", must be one of "none", "gossip" or "database"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := ini.Empty()
+			cfg := NewCfg()
+			cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+			section, err := f.NewSection("unified_alerting")
+			require.NoError(t, err)
+			if tc.value != "" {
+				_, err = section.NewKey("ha_sharding", tc.value)
+				require.NoError(t, err)
+			}
+			err = cfg.ReadUnifiedAlertingSettings(f)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cfg.UnifiedAlerting.HASharding)
+		})
+	}
+}
+
+func TestJitterEvaluations(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected string
+		err      string
+	}{
+		{desc: "should default to disabled", expected: JitterEvaluationsDisabled},
+		{desc: "should accept rule", value: JitterEvaluationsByRule, expected: JitterEvaluationsByRule},
+		{desc: "should fail on an invalid value", value: "rules", err: `invalid value for jitter_evaluations: "rules", must be one of "disabled", "group" or "rule"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := ini.Empty()
+			cfg := NewCfg()
+			cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+			section, err := f.NewSection("unified_alerting")
+			require.NoError(t, err)
+			if tc.value != "" {
+				_, err = section.NewKey("jitter_evaluations", tc.value)
+				require.NoError(t, err)
+			}
+			err = cfg.ReadUnifiedAlertingSettings(f)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cfg.UnifiedAlerting.JitterEvaluations)
+		})
+	}
+}
@@ -48,6 +48,16 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
   { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: ReducerID.first, label: 'First', description: 'Get the first value' },
+  { value: ReducerID.diff, label: 'Difference', description: 'Get the difference between the last and first value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'p90', label: '90th percentile', description: 'Get the 90th percentile of the values' },
+  { value: 'p95', label: '95th percentile', description: 'Get the 95th percentile of the values' },
+  { value: 'p99', label: '99th percentile', description: 'Get the 99th percentile of the values' },
+  { value: ReducerID.stdDev, label: 'StdDev', description: 'Get the standard deviation of the values' },
+  { value: ReducerID.variance, label: 'Variance', description: 'Get the variance of the values' },
+  { value: 'rate', label: 'Rate', description: 'Get the per-second increase of a counter' },
+  { value: 'increase', label: 'Increase', description: 'Get the increase of a counter' },
 ];
 
 export enum ReducerMode {
@@ -80,12 +90,28 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.max, label: 'Max', description: 'Fill with the maximum value' },
   { value: ReducerID.mean, label: 'Mean', description: 'Fill with the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
+  {
+    value: 'time_weighted_mean',
+    label: 'Time-weighted mean',
+    description: 'Fill with the average value, weighted by how long each value lasted',
+  },
+  {
+    value: 'last_before_end',
+    label: 'Last before end',
+    description: 'Fill with the last non-null value before the end of the window',
+  },
 ];
 
 export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'pad', label: 'pad', description: 'fill with the last known value' },
   { value: 'backfilling', label: 'backfilling', description: 'fill with the next known value' },
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
+  { value: 'linear', label: 'linear', description: 'Interpolate between the last and the next known value' },
+  {
+    value: 'last_before_end',
+    label: 'last before end',
+    description: 'Fill with the last known non-null value, skipping null values unlike pad',
+  },
 ];
 
 export const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [
@@ -120,6 +146,11 @@ export interface ClassicCondition {
     params: number[];
     type: EvalFunction;
   };
+  // resolving threshold of a threshold expression, a firing series resolves only when it is met
+  unloadEvaluator?: {
+    params: number[];
+    type: EvalFunction;
+  };
   operator?: {
     type: string;
   };
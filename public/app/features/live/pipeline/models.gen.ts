@@ -46,6 +46,23 @@ export interface MultipleOutputterConfig {
   outputs: FrameOutputterConfig[];
 }
 export interface ManagedStreamOutputConfig {}
+export interface KafkaOutputConfig {
+  uid: string;
+  topic?: string;
+}
+export interface MQTTOutputConfig {
+  uid: string;
+  topic?: string;
+  qos?: number;
+  retained?: boolean;
+}
+export interface NATSOutputConfig {
+  uid: string;
+  subject?: string;
+}
+export interface HistoryOutputConfig {
+  size: number;
+}
 export interface FrameOutputterConfig {
   type: Omit<keyof FrameOutputterConfig, 'type'>;
   managedStream?: ManagedStreamOutputConfig;
@@ -56,6 +73,10 @@ export interface FrameOutputterConfig {
   remoteWrite?: RemoteWriteOutputConfig;
   loki?: LokiOutputConfig;
   changeLog?: ChangeLogOutputConfig;
+  kafka?: KafkaOutputConfig;
+  mqtt?: MQTTOutputConfig;
+  nats?: NATSOutputConfig;
+  history?: HistoryOutputConfig;
 }
 export interface MultipleFrameProcessorConfig {
   processors: FrameProcessorConfig[];
@@ -66,13 +87,28 @@ export interface KeepFieldsFrameProcessorConfig {
 export interface DropFieldsFrameProcessorConfig {
   fieldNames: string[];
 }
+export interface WindowAggregateFieldConfig {
+  fieldName: string;
+  reducers: string[];
+}
+export interface WindowAggregateFrameProcessorConfig {
+  windowMilliseconds: number;
+  slideMilliseconds?: number;
+  fields: WindowAggregateFieldConfig[];
+  groupBy?: string[];
+}
 export interface FrameProcessorConfig {
   type: Omit<keyof FrameProcessorConfig, 'type'>;
   dropFields?: DropFieldsFrameProcessorConfig;
   keepFields?: KeepFieldsFrameProcessorConfig;
   multiple?: MultipleFrameProcessorConfig;
+  windowAggregate?: WindowAggregateFrameProcessorConfig;
 }
 export interface JsonFrameConverterConfig {}
+export interface PrometheusConverterConfig {}
+export interface OTLPConverterConfig {
+  encoding?: string;
+}
 export interface AutoInfluxConverterConfig {
   frameFormat: string;
 }
@@ -99,6 +135,8 @@ export interface ConverterConfig {
   jsonExact?: ExactJsonConverterConfig;
   influxAuto?: AutoInfluxConverterConfig;
   jsonFrame?: JsonFrameConverterConfig;
+  prometheus?: PrometheusConverterConfig;
+  otlp?: OTLPConverterConfig;
 }
 export interface LokiOutputConfig {
   uid: string;
@@ -110,13 +148,21 @@ export interface DataOutputterConfig {
   type: Omit<keyof DataOutputterConfig, 'type'>;
   redirect?: RedirectDataOutputConfig;
   loki?: LokiOutputConfig;
+  kafka?: KafkaOutputConfig;
+  mqtt?: MQTTOutputConfig;
+  nats?: NATSOutputConfig;
 }
 export interface MultipleSubscriberConfig {
   subscribers: SubscriberConfig[];
 }
+export interface HistorySubscriberConfig {
+  maxFrames: number;
+  defaultFrames?: number;
+}
 export interface SubscriberConfig {
   type: Omit<keyof SubscriberConfig, 'type'>;
   multiple?: MultipleSubscriberConfig;
+  history?: HistorySubscriberConfig;
 }
 export interface ChannelRuleSettings {
   auth?: ChannelAuthConfig;
@@ -129,4 +175,5 @@ export interface ChannelRuleSettings {
 export interface ChannelRule {
   pattern: string;
   settings: ChannelRuleSettings;
+  version?: number;
 }
@@ -120,6 +120,7 @@ export enum AccessControlAction {
   // Alerting provisioning actions
   AlertingProvisioningRead = 'alert.provisioning:read',
   AlertingProvisioningWrite = 'alert.provisioning:write',
+  AlertingProvisioningReadSecrets = 'alert.provisioning.secrets:read',
 
   ActionAPIKeysRead = 'apikeys:read',
   ActionAPIKeysCreate = 'apikeys:create',
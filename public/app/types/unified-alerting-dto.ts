@@ -116,11 +116,13 @@ export interface PromAlertingRuleDTO extends PromRuleDTOBase {
     annotations: Annotations;
     state: Exclude<PromAlertingRuleState | GrafanaAlertStateWithReason, PromAlertingRuleState.Inactive>;
     activeAt: string;
+    keepFiringSince?: string;
     value: string;
   }>;
   labels: Labels;
   annotations?: Annotations;
   duration?: number; // for
+  keepFiringFor?: number; // keep_firing_for
   state: PromAlertingRuleState;
   type: PromRuleType.Alerting;
 }
@@ -168,6 +170,7 @@ export interface RulerRecordingRuleDTO extends RulerRuleBaseDTO {
 export interface RulerAlertingRuleDTO extends RulerRuleBaseDTO {
   alert: string;
   for?: string;
+  keep_firing_for?: string;
   annotations?: Annotations;
 }
 
@@ -201,7 +204,24 @@ export interface PostableGrafanaRuleDefinition {
   exec_err_state: GrafanaAlertStateDecision;
   data: AlertQuery[];
   is_paused?: boolean;
+  record?: GrafanaRuleRecord;
+  notification_settings?: GrafanaNotificationSettings;
 }
+
+export interface GrafanaRuleRecord {
+  metric: string;
+  from: string;
+}
+
+export interface GrafanaNotificationSettings {
+  receiver: string;
+  group_by?: string[];
+  group_wait?: string;
+  group_interval?: string;
+  repeat_interval?: string;
+  mute_time_intervals?: string[];
+}
+
 export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
   id?: string;
   uid: string;
@@ -213,6 +233,7 @@ export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
 export interface RulerGrafanaRuleDTO {
   grafana_alert: GrafanaRuleDefinition;
   for: string;
+  keep_firing_for?: string;
   annotations: Annotations;
   labels: Labels;
 }
@@ -220,6 +241,7 @@ export interface RulerGrafanaRuleDTO {
 export interface PostableRuleGrafanaRuleDTO {
   grafana_alert: PostableGrafanaRuleDefinition;
   for: string;
+  keep_firing_for?: string;
   annotations: Annotations;
   labels: Labels;
 }